@@ -0,0 +1,106 @@
+package signer
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/diamnet/go/keypair"
+	"github.com/diamnet/go/network"
+	proto "github.com/diamnet/go/protocols/signer"
+	"github.com/diamnet/go/support/errors"
+	"github.com/diamnet/go/xdr"
+)
+
+// SignTransaction sends tx to the signing server and returns its signature.
+// If AccountID is set, the signature is verified against it.
+func (c *Client) SignTransaction(tx *xdr.Transaction, networkPassphrase string) (xdr.DecoratedSignature, error) {
+	var sig xdr.DecoratedSignature
+
+	txXDR, err := xdr.MarshalBase64(tx)
+	if err != nil {
+		return sig, errors.Wrap(err, "encode transaction failed")
+	}
+
+	body, err := json.Marshal(proto.SignRequest{
+		Transaction:       txXDR,
+		NetworkPassphrase: networkPassphrase,
+	})
+	if err != nil {
+		return sig, errors.Wrap(err, "encode request failed")
+	}
+
+	req, err := http.NewRequest("POST", strings.TrimRight(c.URL, "/")+"/sign", bytes.NewReader(body))
+	if err != nil {
+		return sig, errors.Wrap(err, "create request failed")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.AuthToken != "" {
+		req.Header.Set("Authorization", "Bearer "+c.AuthToken)
+	}
+
+	hresp, err := c.HTTP.Do(req)
+	if err != nil {
+		return sig, errors.Wrap(err, "http request errored")
+	}
+	defer hresp.Body.Close()
+
+	decoder := json.NewDecoder(io.LimitReader(hresp.Body, SignResponseMaxSize))
+	if hresp.StatusCode != http.StatusOK {
+		serr := &Error{}
+		err = decoder.Decode(&serr.Problem)
+		if err != nil || serr.Problem.Title == "" {
+			serr.Problem.Title = http.StatusText(hresp.StatusCode)
+			serr.Problem.Status = hresp.StatusCode
+		}
+		return sig, serr
+	}
+
+	var resp proto.SignResponse
+	err = decoder.Decode(&resp)
+	if err != nil {
+		return sig, errors.Wrap(err, "decode response failed")
+	}
+
+	err = xdr.SafeUnmarshalBase64(resp.Signature, &sig)
+	if err != nil {
+		return sig, errors.Wrap(err, "decode signature failed")
+	}
+
+	if c.AccountID != "" {
+		err = c.verify(tx, networkPassphrase, resp.AccountID, sig)
+		if err != nil {
+			return xdr.DecoratedSignature{}, err
+		}
+	}
+
+	return sig, nil
+}
+
+// verify checks that sig is a signature of tx by the key of c.AccountID.
+func (c *Client) verify(tx *xdr.Transaction, networkPassphrase, accountID string, sig xdr.DecoratedSignature) error {
+	if accountID != c.AccountID {
+		return errors.Errorf("transaction signed by %s instead of %s", accountID, c.AccountID)
+	}
+
+	kp, err := keypair.Parse(c.AccountID)
+	if err != nil {
+		return errors.Wrap(err, "parse account id failed")
+	}
+
+	hash, err := network.HashTransaction(tx, networkPassphrase)
+	if err != nil {
+		return errors.Wrap(err, "hash transaction failed")
+	}
+
+	if xdr.SignatureHint(kp.Hint()) != sig.Hint {
+		return errors.New("invalid signature hint")
+	}
+	err = kp.Verify(hash[:], sig.Signature)
+	if err != nil {
+		return errors.Wrap(err, "invalid signature")
+	}
+	return nil
+}
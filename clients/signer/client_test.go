@@ -0,0 +1,82 @@
+package signer
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/diamnet/go/keypair"
+	"github.com/diamnet/go/network"
+	"github.com/diamnet/go/support/http/httptest"
+	"github.com/diamnet/go/xdr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignTransaction(t *testing.T) {
+	kp := keypair.MustParse("SDZT3EJZ7FZRYNTLOZ7VH6G5UYBFO2IO3Q5PGONMILPCZU3AL7QNZHTE").(*keypair.Full)
+	other := keypair.Master(network.TestNetworkPassphrase).(*keypair.Full)
+	hmock := httptest.NewClient()
+	c := &Client{URL: "https://signer.example.com/", AccountID: kp.Address(), HTTP: hmock}
+
+	tx := &xdr.Transaction{
+		SourceAccount: xdr.MustAddress(kp.Address()),
+		Fee:           100,
+		SeqNum:        1,
+	}
+	sign := func(signer *keypair.Full) string {
+		sig, err := signer.SignTransaction(tx, network.TestNetworkPassphrase)
+		require.NoError(t, err)
+		sigXDR, err := xdr.MarshalBase64(sig)
+		require.NoError(t, err)
+		return sigXDR
+	}
+
+	// happy path
+	hmock.On("POST", "https://signer.example.com/sign").
+		ReturnJSON(http.StatusOK, map[string]string{
+			"account_id": kp.Address(),
+			"signature":  sign(kp),
+		})
+	sig, err := c.SignTransaction(tx, network.TestNetworkPassphrase)
+	if assert.NoError(t, err) {
+		assert.Equal(t, xdr.SignatureHint(kp.Hint()), sig.Hint)
+	}
+
+	// signature of another key
+	hmock.On("POST", "https://signer.example.com/sign").
+		ReturnJSON(http.StatusOK, map[string]string{
+			"account_id": kp.Address(),
+			"signature":  sign(other),
+		})
+	_, err = c.SignTransaction(tx, network.TestNetworkPassphrase)
+	assert.EqualError(t, err, "invalid signature hint")
+
+	// signature of another network
+	sig, err = kp.SignTransaction(tx, network.PublicNetworkPassphrase)
+	require.NoError(t, err)
+	sigXDR, err := xdr.MarshalBase64(sig)
+	require.NoError(t, err)
+	hmock.On("POST", "https://signer.example.com/sign").
+		ReturnJSON(http.StatusOK, map[string]string{
+			"account_id": kp.Address(),
+			"signature":  sigXDR,
+		})
+	_, err = c.SignTransaction(tx, network.TestNetworkPassphrase)
+	assert.EqualError(t, err, "invalid signature: signature verification failed")
+
+	// rejected by the server
+	hmock.On("POST", "https://signer.example.com/sign").
+		ReturnJSON(http.StatusForbidden, map[string]interface{}{
+			"type":   "policy_violation",
+			"title":  "Transaction rejected by policy",
+			"status": http.StatusForbidden,
+			"detail": "operation 0: account_merge operations are not allowed",
+		})
+	_, err = c.SignTransaction(tx, network.TestNetworkPassphrase)
+	if assert.Error(t, err) {
+		serr, ok := err.(*Error)
+		require.True(t, ok)
+		assert.Equal(t, "policy_violation", serr.Problem.Type)
+		assert.Equal(t, "signing server: Transaction rejected by policy: operation 0: account_merge operations are not allowed", err.Error())
+	}
+}
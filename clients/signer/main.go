@@ -0,0 +1,51 @@
+// Package signer provides a keypair.Signer delegating the signing of
+// transactions to a remote signing server, so that seeds don't need to be
+// available to the process building transactions.
+package signer
+
+import (
+	"net/http"
+
+	"github.com/diamnet/go/keypair"
+	"github.com/diamnet/go/support/render/problem"
+)
+
+// SignResponseMaxSize is the maximum size of a response from a signing server
+const SignResponseMaxSize = 100 * 1024
+
+// Client represents a client of a signing server implementing the protocol of
+// the github.com/diamnet/go/protocols/signer package. It signs transactions
+// with the key held by the server.
+type Client struct {
+	// URL is the base URL of the signing server.
+	URL string
+	// AccountID is the address of the key the server is expected to sign
+	// with. When set, signatures are verified before being returned.
+	AccountID string
+	// AuthToken is sent as a bearer token to the server when set.
+	AuthToken string
+	HTTP      HTTP
+}
+
+// HTTP represents the http client that a signer client uses to make http
+// requests.
+type HTTP interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Error represents an error response from a signing server, e.g. when the
+// transaction is rejected by its policy.
+type Error struct {
+	Problem problem.P
+}
+
+func (e *Error) Error() string {
+	if e.Problem.Detail != "" {
+		return "signing server: " + e.Problem.Title + ": " + e.Problem.Detail
+	}
+	return "signing server: " + e.Problem.Title
+}
+
+// confirm interface conformity
+var _ keypair.Signer = &Client{}
+var _ HTTP = http.DefaultClient
@@ -107,12 +107,17 @@ func (dblrc *DBLedgerReader) init() error {
 // a per-transaction view of the data when Read() is called.
 func (dblrc *DBLedgerReader) storeTransactions(lcm ledgerbackend.LedgerCloseMeta) {
 	for i := range lcm.TransactionEnvelope {
-		dblrc.transactions = append(dblrc.transactions, LedgerTransaction{
-			Index:      uint32(i + 1), // Transactions start at '1'
-			Envelope:   lcm.TransactionEnvelope[i],
-			Result:     lcm.TransactionResult[i],
-			Meta:       lcm.TransactionMeta[i],
-			FeeChanges: lcm.TransactionFeeChanges[i],
-		})
+		transaction := LedgerTransaction{
+			Index:    uint32(i + 1), // Transactions start at '1'
+			Envelope: lcm.TransactionEnvelope[i],
+			Result:   lcm.TransactionResult[i],
+		}
+		if lcm.TransactionMetaUnavailable {
+			transaction.MetaUnavailable = true
+		} else {
+			transaction.Meta = lcm.TransactionMeta[i]
+			transaction.FeeChanges = lcm.TransactionFeeChanges[i]
+		}
+		dblrc.transactions = append(dblrc.transactions, transaction)
 	}
 }
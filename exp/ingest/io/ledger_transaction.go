@@ -65,8 +65,13 @@ func (c *Change) AccountSignersChanged() bool {
 
 // GetChanges returns a developer friendly representation of LedgerEntryChanges.
 // It contains fee changes, transaction changes and operation changes in that
-// order.
-func (t *LedgerTransaction) GetChanges() []Change {
+// order. ErrMetaUnavailable is returned when the transaction meta is not
+// available (MetaUnavailable is true).
+func (t *LedgerTransaction) GetChanges() ([]Change, error) {
+	if t.MetaUnavailable {
+		return nil, ErrMetaUnavailable
+	}
+
 	// Fee meta
 	changes := getChangesFromLedgerEntryChanges(t.FeeChanges)
 
@@ -85,7 +90,7 @@ func (t *LedgerTransaction) GetChanges() []Change {
 		changes = append(changes, opChanges...)
 	}
 
-	return changes
+	return changes, nil
 }
 
 // getChangesFromLedgerEntryChanges transforms LedgerEntryChanges to []Change.
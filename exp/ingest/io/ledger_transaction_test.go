@@ -199,3 +199,14 @@ func TestChangeAccountSignersChangedSignerWeightChanged(t *testing.T) {
 
 	assert.True(t, change.AccountSignersChanged())
 }
+
+func TestGetChangesMetaUnavailable(t *testing.T) {
+	transaction := LedgerTransaction{
+		Index:           1,
+		MetaUnavailable: true,
+	}
+
+	changes, err := transaction.GetChanges()
+	assert.Equal(t, ErrMetaUnavailable, err)
+	assert.Nil(t, changes)
+}
@@ -7,6 +7,10 @@ import (
 
 var ErrNotFound = errors.New("not found")
 
+// ErrMetaUnavailable is returned by LedgerTransaction.GetChanges when the
+// ledger backend could not provide the meta of the transaction.
+var ErrMetaUnavailable = errors.New("transaction meta is not available")
+
 // StateReader reads state data from history archive buckets for a single
 // checkpoint ledger / HAS.
 type StateReader interface {
@@ -69,4 +73,7 @@ type LedgerTransaction struct {
 	Result     xdr.TransactionResultPair
 	Meta       xdr.TransactionMeta
 	FeeChanges xdr.LedgerEntryChanges
+	// MetaUnavailable is true when the ledger backend could not provide Meta
+	// and FeeChanges, for example when reading ledgers from history archives.
+	MetaUnavailable bool
 }
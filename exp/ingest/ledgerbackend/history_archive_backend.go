@@ -0,0 +1,234 @@
+package ledgerbackend
+
+import (
+	"io"
+	"sync"
+
+	"github.com/diamnet/go/network"
+	"github.com/diamnet/go/support/errors"
+	"github.com/diamnet/go/support/historyarchive"
+	"github.com/diamnet/go/xdr"
+)
+
+// Ensure HistoryArchiveBackend implements LedgerBackend
+var _ LedgerBackend = (*HistoryArchiveBackend)(nil)
+
+// HistoryArchiveBackend implements a ledger data store backed by the checkpoint
+// files (ledger headers, transactions and results) of a history archive.
+//
+// History archives do not contain transaction meta nor fee changes so every
+// LedgerCloseMeta returned by HistoryArchiveBackend has TransactionMetaUnavailable
+// set to true and empty TransactionMeta and TransactionFeeChanges slices.
+type HistoryArchiveBackend struct {
+	archive           historyarchive.ArchiveInterface
+	networkPassphrase string
+
+	// mutex protects the ledgers of the most recently loaded checkpoint
+	mutex      sync.Mutex
+	checkpoint uint32
+	ledgers    map[uint32]LedgerCloseMeta
+}
+
+// NewHistoryArchiveBackendFromURL returns a HistoryArchiveBackend connected to
+// the history archive at archiveURL. The network passphrase is needed to match
+// transaction envelopes with their results.
+func NewHistoryArchiveBackendFromURL(archiveURL, networkPassphrase string) (*HistoryArchiveBackend, error) {
+	archive, err := historyarchive.Connect(archiveURL, historyarchive.ConnectOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "could not connect to history archive")
+	}
+
+	return NewHistoryArchiveBackendFromArchive(archive, networkPassphrase), nil
+}
+
+// NewHistoryArchiveBackendFromArchive returns a HistoryArchiveBackend reading
+// ledgers from the given archive.
+func NewHistoryArchiveBackendFromArchive(
+	archive historyarchive.ArchiveInterface,
+	networkPassphrase string,
+) *HistoryArchiveBackend {
+	return &HistoryArchiveBackend{
+		archive:           archive,
+		networkPassphrase: networkPassphrase,
+	}
+}
+
+// GetLatestLedgerSequence returns the sequence of the latest checkpoint ledger published in the archive.
+func (hab *HistoryArchiveBackend) GetLatestLedgerSequence() (uint32, error) {
+	has, err := hab.archive.GetRootHAS()
+	if err != nil {
+		return 0, errors.Wrap(err, "could not get root HAS")
+	}
+
+	return has.CurrentLedger, nil
+}
+
+// GetLedger returns the LedgerCloseMeta for the given ledger sequence number.
+// The first returned value is false when the checkpoint containing the ledger
+// has not been published in the archive yet.
+// All the ledgers of a checkpoint are loaded at once and kept in memory until a
+// ledger from a different checkpoint is requested so reading ledgers in order is cheap.
+func (hab *HistoryArchiveBackend) GetLedger(sequence uint32) (bool, LedgerCloseMeta, error) {
+	hab.mutex.Lock()
+	defer hab.mutex.Unlock()
+
+	checkpoint := checkpointForLedger(sequence)
+	if hab.ledgers == nil || hab.checkpoint != checkpoint {
+		exists, err := hab.archive.CategoryCheckpointExists("ledger", checkpoint)
+		if err != nil {
+			return false, LedgerCloseMeta{}, errors.Wrap(err, "error checking if ledger checkpoint exists")
+		}
+		if !exists {
+			return false, LedgerCloseMeta{}, nil
+		}
+
+		ledgers, err := hab.loadCheckpoint(checkpoint)
+		if err != nil {
+			return false, LedgerCloseMeta{}, errors.Wrapf(err, "error loading checkpoint %d", checkpoint)
+		}
+		hab.checkpoint = checkpoint
+		hab.ledgers = ledgers
+	}
+
+	lcm, ok := hab.ledgers[sequence]
+	return ok, lcm, nil
+}
+
+// checkpointForLedger returns the sequence of the checkpoint ledger
+// whose checkpoint files contain the given ledger
+func checkpointForLedger(sequence uint32) uint32 {
+	return (sequence/historyarchive.CheckpointFreq+1)*historyarchive.CheckpointFreq - 1
+}
+
+// loadCheckpoint reads the ledger headers, transactions and results of all the
+// ledgers in the given checkpoint.
+func (hab *HistoryArchiveBackend) loadCheckpoint(checkpoint uint32) (map[uint32]LedgerCloseMeta, error) {
+	ledgers := map[uint32]LedgerCloseMeta{}
+
+	err := hab.readCategory("ledger", checkpoint, func(stream *historyarchive.XdrStream) error {
+		var entry xdr.LedgerHeaderHistoryEntry
+		if err := stream.ReadOne(&entry); err != nil {
+			return err
+		}
+		ledgers[uint32(entry.Header.LedgerSeq)] = LedgerCloseMeta{
+			LedgerHeader:               entry,
+			TransactionMetaUnavailable: true,
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	envelopes := map[uint32][]xdr.TransactionEnvelope{}
+	err = hab.readCategory("transactions", checkpoint, func(stream *historyarchive.XdrStream) error {
+		var entry xdr.TransactionHistoryEntry
+		if err := stream.ReadOne(&entry); err != nil {
+			return err
+		}
+		envelopes[uint32(entry.LedgerSeq)] = entry.TxSet.Txs
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = hab.readCategory("results", checkpoint, func(stream *historyarchive.XdrStream) error {
+		var entry xdr.TransactionHistoryResultEntry
+		if err := stream.ReadOne(&entry); err != nil {
+			return err
+		}
+
+		sequence := uint32(entry.LedgerSeq)
+		lcm, ok := ledgers[sequence]
+		if !ok {
+			return errors.Errorf("results found for ledger %d which is not in the checkpoint", sequence)
+		}
+		ordered, err := hab.orderEnvelopes(envelopes[sequence], entry.TxResultSet.Results)
+		if err != nil {
+			return errors.Wrapf(err, "error matching transactions with results in ledger %d", sequence)
+		}
+		lcm.TransactionEnvelope = ordered
+		lcm.TransactionResult = entry.TxResultSet.Results
+		ledgers[sequence] = lcm
+		delete(envelopes, sequence)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(envelopes) > 0 {
+		return nil, errors.New("transactions file contains ledgers without results")
+	}
+
+	return ledgers, nil
+}
+
+// readCategory calls readOne for every entry in the given checkpoint file until
+// readOne returns io.EOF.
+func (hab *HistoryArchiveBackend) readCategory(
+	category string,
+	checkpoint uint32,
+	readOne func(*historyarchive.XdrStream) error,
+) error {
+	stream, err := hab.archive.GetXdrStream(historyarchive.CategoryCheckpointPath(category, checkpoint))
+	if err != nil {
+		return errors.Wrapf(err, "error opening %s file", category)
+	}
+	defer stream.Close()
+
+	for {
+		err = readOne(stream)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.Wrapf(err, "error reading %s file", category)
+		}
+	}
+}
+
+// orderEnvelopes returns the envelopes in the order of the given results, i.e. in the
+// order the transactions were applied. Transaction sets in history archives are not
+// guaranteed to be stored in apply order so envelopes are matched using their hashes.
+func (hab *HistoryArchiveBackend) orderEnvelopes(
+	envelopes []xdr.TransactionEnvelope,
+	results []xdr.TransactionResultPair,
+) ([]xdr.TransactionEnvelope, error) {
+	if len(envelopes) != len(results) {
+		return nil, errors.Errorf(
+			"number of transactions (%d) does not match number of results (%d)",
+			len(envelopes),
+			len(results),
+		)
+	}
+
+	byHash := map[xdr.Hash]xdr.TransactionEnvelope{}
+	for _, envelope := range envelopes {
+		hash, err := network.HashTransaction(&envelope.Tx, hab.networkPassphrase)
+		if err != nil {
+			return nil, errors.Wrap(err, "error hashing transaction")
+		}
+		byHash[xdr.Hash(hash)] = envelope
+	}
+
+	ordered := make([]xdr.TransactionEnvelope, 0, len(results))
+	for _, result := range results {
+		envelope, ok := byHash[result.TransactionHash]
+		if !ok {
+			return nil, errors.Errorf("transaction %x not found in transaction set", result.TransactionHash)
+		}
+		ordered = append(ordered, envelope)
+	}
+	return ordered, nil
+}
+
+// Close releases the ledgers kept in memory.
+func (hab *HistoryArchiveBackend) Close() error {
+	hab.mutex.Lock()
+	defer hab.mutex.Unlock()
+
+	hab.ledgers = nil
+	return nil
+}
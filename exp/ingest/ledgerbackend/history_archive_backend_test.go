@@ -0,0 +1,154 @@
+package ledgerbackend
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io/ioutil"
+	"testing"
+
+	"github.com/diamnet/go/network"
+	"github.com/diamnet/go/support/historyarchive"
+	"github.com/diamnet/go/xdr"
+	"github.com/stretchr/testify/assert"
+)
+
+func xdrStream(t *testing.T, entries ...interface{}) *historyarchive.XdrStream {
+	var buf bytes.Buffer
+	for _, entry := range entries {
+		assert.NoError(t, historyarchive.WriteFramedXdr(&buf, entry))
+	}
+	return historyarchive.NewXdrStream(ioutil.NopCloser(&buf))
+}
+
+func testEnvelope(seqNum xdr.SequenceNumber) xdr.TransactionEnvelope {
+	return xdr.TransactionEnvelope{
+		Tx: xdr.Transaction{
+			SourceAccount: xdr.MustAddress("GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H"),
+			Fee:           100,
+			SeqNum:        seqNum,
+			Operations:    []xdr.Operation{},
+		},
+		Signatures: []xdr.DecoratedSignature{},
+	}
+}
+
+func testResult(t *testing.T, envelope xdr.TransactionEnvelope) xdr.TransactionResultPair {
+	hash, err := network.HashTransaction(&envelope.Tx, network.TestNetworkPassphrase)
+	assert.NoError(t, err)
+	return xdr.TransactionResultPair{
+		TransactionHash: xdr.Hash(hash),
+		Result: xdr.TransactionResult{
+			FeeCharged: 100,
+			Result: xdr.TransactionResultResult{
+				Code:    xdr.TransactionResultCodeTxSuccess,
+				Results: &[]xdr.OperationResult{},
+			},
+		},
+	}
+}
+
+func testHeader(sequence uint32) xdr.LedgerHeaderHistoryEntry {
+	return xdr.LedgerHeaderHistoryEntry{
+		Hash: xdr.Hash{byte(sequence)},
+		Header: xdr.LedgerHeader{
+			LedgerSeq: xdr.Uint32(sequence),
+			ScpValue:  xdr.DiamNetValue{Upgrades: []xdr.UpgradeType{}},
+		},
+	}
+}
+
+func TestHistoryArchiveBackend(t *testing.T) {
+	archive := &historyarchive.MockArchive{}
+	backend := NewHistoryArchiveBackendFromArchive(archive, network.TestNetworkPassphrase)
+
+	first := testEnvelope(1)
+	second := testEnvelope(2)
+
+	archive.On("GetRootHAS").
+		Return(historyarchive.HistoryArchiveState{CurrentLedger: 127}, nil).Once()
+	archive.On("CategoryCheckpointExists", "ledger", uint32(127)).
+		Return(true, nil).Once()
+	archive.On("CategoryCheckpointExists", "ledger", uint32(191)).
+		Return(false, nil).Once()
+	archive.On("GetXdrStream", historyarchive.CategoryCheckpointPath("ledger", 127)).
+		Return(xdrStream(t, testHeader(126), testHeader(127)), nil).Once()
+	archive.On("GetXdrStream", historyarchive.CategoryCheckpointPath("transactions", 127)).
+		Return(xdrStream(t, xdr.TransactionHistoryEntry{
+			LedgerSeq: 127,
+			TxSet: xdr.TransactionSet{
+				Txs: []xdr.TransactionEnvelope{first, second},
+			},
+		}), nil).Once()
+	// results are stored in apply order which can differ from the tx set order
+	archive.On("GetXdrStream", historyarchive.CategoryCheckpointPath("results", 127)).
+		Return(xdrStream(t, xdr.TransactionHistoryResultEntry{
+			LedgerSeq: 127,
+			TxResultSet: xdr.TransactionResultSet{
+				Results: []xdr.TransactionResultPair{testResult(t, second), testResult(t, first)},
+			},
+		}), nil).Once()
+
+	latest, err := backend.GetLatestLedgerSequence()
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(127), latest)
+
+	exists, lcm, err := backend.GetLedger(126)
+	assert.NoError(t, err)
+	assert.True(t, exists)
+	assert.Equal(t, testHeader(126), lcm.LedgerHeader)
+	assert.Empty(t, lcm.TransactionEnvelope)
+	assert.True(t, lcm.TransactionMetaUnavailable)
+
+	// the checkpoint is cached so the archive is not queried again
+	exists, lcm, err = backend.GetLedger(127)
+	assert.NoError(t, err)
+	assert.True(t, exists)
+	assert.Equal(t, testHeader(127), lcm.LedgerHeader)
+	assert.Equal(t, []xdr.TransactionEnvelope{second, first}, lcm.TransactionEnvelope)
+	assert.Equal(t, []xdr.TransactionResultPair{testResult(t, second), testResult(t, first)}, lcm.TransactionResult)
+	assert.Empty(t, lcm.TransactionMeta)
+	assert.Empty(t, lcm.TransactionFeeChanges)
+	assert.True(t, lcm.TransactionMetaUnavailable)
+
+	exists, _, err = backend.GetLedger(128)
+	assert.NoError(t, err)
+	assert.False(t, exists)
+
+	assert.NoError(t, backend.Close())
+	archive.AssertExpectations(t)
+}
+
+func TestHistoryArchiveBackendMissingTransaction(t *testing.T) {
+	archive := &historyarchive.MockArchive{}
+	backend := NewHistoryArchiveBackendFromArchive(archive, network.TestNetworkPassphrase)
+
+	archive.On("CategoryCheckpointExists", "ledger", uint32(63)).
+		Return(true, nil).Once()
+	archive.On("GetXdrStream", historyarchive.CategoryCheckpointPath("ledger", 63)).
+		Return(xdrStream(t, testHeader(63)), nil).Once()
+	archive.On("GetXdrStream", historyarchive.CategoryCheckpointPath("transactions", 63)).
+		Return(xdrStream(t, xdr.TransactionHistoryEntry{
+			LedgerSeq: 63,
+			TxSet: xdr.TransactionSet{
+				Txs: []xdr.TransactionEnvelope{testEnvelope(1)},
+			},
+		}), nil).Once()
+	archive.On("GetXdrStream", historyarchive.CategoryCheckpointPath("results", 63)).
+		Return(xdrStream(t, xdr.TransactionHistoryResultEntry{
+			LedgerSeq: 63,
+			TxResultSet: xdr.TransactionResultSet{
+				Results: []xdr.TransactionResultPair{testResult(t, testEnvelope(2))},
+			},
+		}), nil).Once()
+
+	missing := testResult(t, testEnvelope(2)).TransactionHash
+	_, _, err := backend.GetLedger(63)
+	assert.EqualError(
+		t,
+		err,
+		"error loading checkpoint 63: error reading results file: "+
+			"error matching transactions with results in ledger 63: transaction "+
+			hex.EncodeToString(missing[:])+" not found in transaction set",
+	)
+	archive.AssertExpectations(t)
+}
@@ -25,6 +25,10 @@ type LedgerCloseMeta struct {
 	TransactionResult     []xdr.TransactionResultPair
 	TransactionMeta       []xdr.TransactionMeta
 	TransactionFeeChanges []xdr.LedgerEntryChanges
+	// TransactionMetaUnavailable is true when the backend cannot provide
+	// TransactionMeta and TransactionFeeChanges (e.g. HistoryArchiveBackend).
+	// Both slices are empty in such case.
+	TransactionMetaUnavailable bool
 }
 
 // ledgerHeaderHistory is a helper struct used to unmarshall header fields from a diamnet-core DB.
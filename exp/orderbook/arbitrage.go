@@ -0,0 +1,255 @@
+package orderbook
+
+import (
+	"math"
+	"sort"
+
+	"github.com/diamnet/go/price"
+	"github.com/diamnet/go/support/errors"
+	"github.com/diamnet/go/xdr"
+)
+
+var errInvalidCycleLength = errors.New("cycle length must be at least 2")
+
+// ArbitrageCycle represents a sequence of trades which starts and ends with the
+// same asset and yields more of that asset than was spent
+type ArbitrageCycle struct {
+	// Assets contains the assets traded in the cycle in order. The cycle
+	// starts by selling Assets[0] and ends by buying Assets[0].
+	Assets []xdr.Asset
+	// Offers contains all the offers the cycle trades with ordered by
+	// the position of the trade in the cycle and by price
+	Offers []xdr.OfferEntry
+	// SourceAmount is the largest amount of Assets[0] which can be spent
+	// on the cycle while every part of the trade remains profitable
+	SourceAmount xdr.Int64
+	// DestinationAmount is the amount of Assets[0] obtained by spending
+	// SourceAmount on the cycle
+	DestinationAmount xdr.Int64
+}
+
+// Profit returns the amount of Assets[0] gained by executing the cycle
+func (cycle ArbitrageCycle) Profit() xdr.Int64 {
+	return cycle.DestinationAmount - cycle.SourceAmount
+}
+
+// cycleSearch holds the state of a search for arbitrage cycles starting at a given asset
+type cycleSearch struct {
+	graph          *OrderBookGraph
+	maxCycleLength int
+	minProfit      xdr.Int64
+	startString    string
+	// canonicalOnly is true when every cycle should be reported only once,
+	// starting with the asset which has the smallest string representation
+	canonicalOnly bool
+	visited       map[string]bool
+	cycles        []ArbitrageCycle
+}
+
+// FindArbitrageCycles returns profitable cycles of at most `maxCycleLength` trades
+// which start and end with one of the `sourceAssets`. Only cycles which yield at
+// least `minProfit` units of the source asset are returned. If `sourceAssets` is
+// empty every asset in the order book is considered and each cycle is returned
+// once, starting from the asset which has the smallest string representation.
+// The cycles are sorted by profit from the highest to the lowest.
+func (graph *OrderBookGraph) FindArbitrageCycles(
+	maxCycleLength int,
+	minProfit xdr.Int64,
+	sourceAssets []xdr.Asset,
+) ([]ArbitrageCycle, error) {
+	if maxCycleLength < 2 {
+		return nil, errInvalidCycleLength
+	}
+
+	graph.lock.RLock()
+	defer graph.lock.RUnlock()
+
+	canonicalOnly := len(sourceAssets) == 0
+	if canonicalOnly {
+		for _, edges := range graph.edgesForBuyingAsset {
+			for _, offers := range edges {
+				if len(offers) > 0 {
+					sourceAssets = append(sourceAssets, offers[0].Buying)
+					break
+				}
+			}
+		}
+	}
+
+	cycles := []ArbitrageCycle{}
+	for _, sourceAsset := range sourceAssets {
+		search := &cycleSearch{
+			graph:          graph,
+			maxCycleLength: maxCycleLength,
+			minProfit:      minProfit,
+			startString:    sourceAsset.String(),
+			canonicalOnly:  canonicalOnly,
+			visited:        map[string]bool{},
+			cycles:         []ArbitrageCycle{},
+		}
+		err := search.dfs([]xdr.Asset{sourceAsset}, []string{search.startString})
+		if err != nil {
+			return nil, errors.Wrap(err, "could not determine arbitrage cycles")
+		}
+		cycles = append(cycles, search.cycles...)
+	}
+
+	sort.SliceStable(cycles, func(i, j int) bool {
+		if cycles[i].Profit() != cycles[j].Profit() {
+			return cycles[i].Profit() > cycles[j].Profit()
+		}
+		return cycleKey(cycles[i]) < cycleKey(cycles[j])
+	})
+	return cycles, nil
+}
+
+func cycleKey(cycle ArbitrageCycle) string {
+	key := ""
+	for _, asset := range cycle.Assets {
+		key += asset.String() + ","
+	}
+	return key
+}
+
+// dfs extends the cycle candidate consisting of the given assets by one trade
+func (search *cycleSearch) dfs(assets []xdr.Asset, assetStrings []string) error {
+	currentString := assetStrings[len(assetStrings)-1]
+	search.visited[currentString] = true
+	defer func() {
+		search.visited[currentString] = false
+	}()
+
+	for nextString, offers := range search.graph.edgesForBuyingAsset[currentString] {
+		if len(offers) == 0 {
+			continue
+		}
+
+		if nextString == search.startString {
+			if len(assets) < 2 {
+				continue
+			}
+			if err := search.evaluate(assets, assetStrings); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if search.visited[nextString] || len(assets) >= search.maxCycleLength {
+			continue
+		}
+		if search.canonicalOnly && nextString < search.startString {
+			continue
+		}
+
+		err := search.dfs(
+			append(assets[:len(assets):len(assets)], offers[0].Selling),
+			append(assetStrings[:len(assetStrings):len(assetStrings)], nextString),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// evaluate determines how much can be gained by trading along the cycle consisting of
+// the given assets. The trades are executed against the best offers one step at a time.
+// Every step trades the largest amount which does not exceed the best offer of any
+// trade in the cycle. The search stops once a step is no longer profitable.
+func (search *cycleSearch) evaluate(assets []xdr.Asset, assetStrings []string) error {
+	cycle := ArbitrageCycle{
+		Assets: append([]xdr.Asset{}, assets...),
+	}
+	usage := offerUsage{}
+	maxSteps := -1
+
+	for step := 0; maxSteps < 0 || step < maxSteps; step++ {
+		hops := make([][]xdr.OfferEntry, len(assetStrings))
+		totalOffers := 0
+		for i, assetString := range assetStrings {
+			nextString := assetStrings[(i+1)%len(assetStrings)]
+			hops[i] = usage.offers(search.graph.edgesForBuyingAsset[assetString][nextString])
+			if len(hops[i]) == 0 {
+				hops = nil
+				break
+			}
+			totalOffers += len(hops[i])
+		}
+		if hops == nil {
+			break
+		}
+		if maxSteps < 0 {
+			// every step consumes at least one offer entirely
+			maxSteps = totalOffers + 1
+		}
+
+		amount, err := topOfBookCapacity(hops)
+		if err != nil {
+			return err
+		}
+		if amount <= 0 {
+			break
+		}
+
+		stepUsage := usage.clone()
+		received := amount
+		for _, offers := range hops {
+			received, err = consumeBuyingOffers(offers, nil, received, stepUsage)
+			if err != nil {
+				return err
+			}
+			if received <= 0 {
+				break
+			}
+		}
+		if received <= amount {
+			break
+		}
+
+		usage = stepUsage
+		cycle.SourceAmount += amount
+		cycle.DestinationAmount += received
+	}
+
+	profit := cycle.Profit()
+	if profit <= 0 || profit < search.minProfit {
+		return nil
+	}
+
+	cycle.Offers = []xdr.OfferEntry{}
+	for i, assetString := range assetStrings {
+		nextString := assetStrings[(i+1)%len(assetStrings)]
+		for _, offer := range search.graph.edgesForBuyingAsset[assetString][nextString] {
+			if usage[offer.OfferId] > 0 {
+				cycle.Offers = append(cycle.Offers, offer)
+			}
+		}
+	}
+	search.cycles = append(search.cycles, cycle)
+	return nil
+}
+
+// topOfBookCapacity returns the largest amount of the first asset which can be
+// traded along the given hops without exceeding the best offer of any hop
+func topOfBookCapacity(hops [][]xdr.OfferEntry) (xdr.Int64, error) {
+	capacity := int64(math.MaxInt64)
+	for i := len(hops) - 1; i >= 0; i-- {
+		offer := hops[i][0]
+		if offer.Price.D == 0 {
+			return -1, errOfferPriceDenominatorIsZero
+		}
+
+		needed, _, err := price.ConvertToBuyingUnits(
+			int64(offer.Amount),
+			capacity,
+			int64(offer.Price.N),
+			int64(offer.Price.D),
+		)
+		if err != nil {
+			return -1, errors.Wrap(err, "could not determine buying units")
+		}
+		capacity = needed
+	}
+	return xdr.Int64(capacity), nil
+}
@@ -0,0 +1,236 @@
+package orderbook
+
+import (
+	"math/big"
+
+	"github.com/diamnet/go/price"
+	"github.com/diamnet/go/support/errors"
+	"github.com/diamnet/go/xdr"
+)
+
+// PriceLevel is an aggregation of all the offers in a trading pair which
+// share the same price
+type PriceLevel struct {
+	// Price is the price of the offers in the level. Ask prices are taken as is
+	// from the offers while bid prices are inverted so that both are expressed
+	// in terms of the counter asset per unit of the base asset.
+	Price xdr.Price
+	// Amount is the sum of the amounts of all offers in the level. Like in
+	// diamnet-core's order book summary, ask amounts are denominated in the
+	// base asset and bid amounts are denominated in the counter asset.
+	Amount xdr.Int64
+	// CumulativeAmount is the sum of Amount of this level and all the levels
+	// which have a better price
+	CumulativeAmount xdr.Int64
+}
+
+// OrderBookSummary is a summary of the offers of a trading pair
+// grouped by price
+type OrderBookSummary struct {
+	// Bids are the offers buying the base asset sorted from the
+	// highest to the lowest price
+	Bids []PriceLevel
+	// Asks are the offers selling the base asset sorted from the
+	// lowest to the highest price
+	Asks []PriceLevel
+}
+
+// TradeImpact describes the result of executing a market order
+// against the offers of a single trading pair
+type TradeImpact struct {
+	// AmountSold is the amount of the selling asset spent by the order
+	AmountSold xdr.Int64
+	// AmountBought is the amount of the buying asset obtained by the order
+	AmountBought xdr.Int64
+	// Filled is true when the order was executed in its entirety.
+	// Otherwise, the order book does not have enough liquidity and AmountSold and
+	// AmountBought describe the part of the order which could be executed.
+	Filled bool
+	// OffersConsumed is the number of offers the order traded with
+	OffersConsumed int
+	// BestPrice and WorstPrice are the prices of the first and the last offer
+	// the order traded with, expressed in terms of the selling asset per unit
+	// of the buying asset. Both are zero if the order could not trade at all.
+	BestPrice  xdr.Price
+	WorstPrice xdr.Price
+}
+
+// OrderBookSummary returns the order book of the trading pair
+// where `baseAsset` is traded for `counterAsset`. At most `limit`
+// price levels are returned on each side of the order book.
+func (graph *OrderBookGraph) OrderBookSummary(
+	baseAsset, counterAsset xdr.Asset,
+	limit int,
+) (OrderBookSummary, error) {
+	graph.lock.RLock()
+	defer graph.lock.RUnlock()
+
+	asks := graph.edgesForSellingAsset[baseAsset.String()][counterAsset.String()]
+	bids := graph.edgesForSellingAsset[counterAsset.String()][baseAsset.String()]
+
+	summary := OrderBookSummary{}
+	var err error
+	if summary.Asks, err = priceLevels(asks, limit, false); err != nil {
+		return OrderBookSummary{}, err
+	}
+	if summary.Bids, err = priceLevels(bids, limit, true); err != nil {
+		return OrderBookSummary{}, err
+	}
+	return summary, nil
+}
+
+// priceLevels groups the given offers, which must be sorted from the cheapest
+// to the most expensive, into at most limit price levels.
+// If invert is true the prices of the offers are inverted.
+func priceLevels(offers []xdr.OfferEntry, limit int, invert bool) ([]PriceLevel, error) {
+	levels := []PriceLevel{}
+	var levelPrice *big.Rat
+	cumulative := xdr.Int64(0)
+
+	for _, offer := range offers {
+		if offer.Price.D == 0 {
+			return nil, errOfferPriceDenominatorIsZero
+		}
+		offerPrice := big.NewRat(int64(offer.Price.N), int64(offer.Price.D))
+		cumulative += offer.Amount
+
+		if levelPrice != nil && levelPrice.Cmp(offerPrice) == 0 {
+			last := &levels[len(levels)-1]
+			last.Amount += offer.Amount
+			last.CumulativeAmount = cumulative
+			continue
+		}
+
+		if len(levels) == limit {
+			break
+		}
+
+		levelPrice = offerPrice
+		level := PriceLevel{
+			Price:            offer.Price,
+			Amount:           offer.Amount,
+			CumulativeAmount: cumulative,
+		}
+		if invert {
+			level.Price = xdr.Price{N: offer.Price.D, D: offer.Price.N}
+		}
+		levels = append(levels, level)
+	}
+
+	return levels, nil
+}
+
+// SellImpact returns the result of selling `amount` of `sellingAsset`
+// for as much `buyingAsset` as possible
+func (graph *OrderBookGraph) SellImpact(
+	sellingAsset, buyingAsset xdr.Asset,
+	amount xdr.Int64,
+) (TradeImpact, error) {
+	if amount <= 0 {
+		return TradeImpact{}, errAssetAmountIsZero
+	}
+
+	graph.lock.RLock()
+	defer graph.lock.RUnlock()
+
+	impact := TradeImpact{}
+	remaining := amount
+	for _, offer := range graph.edgesForSellingAsset[buyingAsset.String()][sellingAsset.String()] {
+		if offer.Price.D == 0 {
+			return TradeImpact{}, errOfferPriceDenominatorIsZero
+		}
+		n := int64(offer.Price.N)
+		d := int64(offer.Price.D)
+
+		// check if we can sell the remaining amount to the current offer
+		// otherwise consume the entire offer and move on to the next one
+		amountBought, err := price.MulFractionRoundDown(int64(remaining), d, n)
+		if err != nil {
+			return TradeImpact{}, errors.Wrap(err, "could not determine buying units")
+		}
+		if amountBought == 0 {
+			break
+		}
+		impact.recordOffer(offer.Price)
+
+		if xdr.Int64(amountBought) <= offer.Amount {
+			impact.AmountSold += remaining
+			impact.AmountBought += xdr.Int64(amountBought)
+			remaining = 0
+			break
+		}
+
+		sold, bought, err := price.ConvertToBuyingUnits(
+			int64(offer.Amount),
+			int64(offer.Amount),
+			n,
+			d,
+		)
+		if err != nil {
+			return TradeImpact{}, errors.Wrap(err, "could not determine selling units")
+		}
+		impact.AmountSold += xdr.Int64(sold)
+		impact.AmountBought += xdr.Int64(bought)
+		remaining -= xdr.Int64(sold)
+		if remaining <= 0 {
+			break
+		}
+	}
+
+	impact.Filled = remaining <= 0
+	return impact, nil
+}
+
+// BuyImpact returns the result of buying `amount` of `buyingAsset`
+// by paying as little `sellingAsset` as possible
+func (graph *OrderBookGraph) BuyImpact(
+	sellingAsset, buyingAsset xdr.Asset,
+	amount xdr.Int64,
+) (TradeImpact, error) {
+	if amount <= 0 {
+		return TradeImpact{}, errAssetAmountIsZero
+	}
+
+	graph.lock.RLock()
+	defer graph.lock.RUnlock()
+
+	impact := TradeImpact{}
+	remaining := amount
+	for _, offer := range graph.edgesForSellingAsset[buyingAsset.String()][sellingAsset.String()] {
+		if offer.Price.D == 0 {
+			return TradeImpact{}, errOfferPriceDenominatorIsZero
+		}
+
+		sold, bought, err := price.ConvertToBuyingUnits(
+			int64(offer.Amount),
+			int64(remaining),
+			int64(offer.Price.N),
+			int64(offer.Price.D),
+		)
+		if err != nil {
+			return TradeImpact{}, errors.Wrap(err, "could not determine buying units")
+		}
+		if bought == 0 {
+			continue
+		}
+		impact.recordOffer(offer.Price)
+
+		impact.AmountSold += xdr.Int64(sold)
+		impact.AmountBought += xdr.Int64(bought)
+		remaining -= xdr.Int64(bought)
+		if remaining <= 0 {
+			break
+		}
+	}
+
+	impact.Filled = remaining <= 0
+	return impact, nil
+}
+
+func (impact *TradeImpact) recordOffer(offerPrice xdr.Price) {
+	if impact.OffersConsumed == 0 {
+		impact.BestPrice = offerPrice
+	}
+	impact.WorstPrice = offerPrice
+	impact.OffersConsumed++
+}
@@ -114,6 +114,9 @@ type sellingGraphSearchState struct {
 	ignoreOffersFrom       xdr.AccountId
 	targetAssets           map[string]xdr.Int64
 	paths                  []Path
+	// usage is optional, if it is provided the offer amounts will be reduced
+	// by the amounts which have already been consumed
+	usage offerUsage
 }
 
 func (state *sellingGraphSearchState) isTerminalNode(
@@ -161,6 +164,10 @@ func (state *sellingGraphSearchState) consumeOffers(
 	offers []xdr.OfferEntry,
 ) (xdr.Asset, xdr.Int64, error) {
 	var nextAsset xdr.Asset
+	offers = state.usage.offers(offers)
+	if len(offers) == 0 {
+		return nextAsset, 0, nil
+	}
 	nextAmount, err := consumeOffersForSellingAsset(offers, state.ignoreOffersFrom, currentAssetAmount)
 	if err == nil {
 		nextAsset = offers[0].Buying
@@ -183,6 +190,9 @@ type buyingGraphSearchState struct {
 	ignoreOffersFrom  *xdr.AccountId
 	targetAssets      map[string]bool
 	paths             []Path
+	// usage is optional, if it is provided the offer amounts will be reduced
+	// by the amounts which have already been consumed
+	usage offerUsage
 }
 
 func (state *buyingGraphSearchState) isTerminalNode(
@@ -224,6 +234,10 @@ func (state *buyingGraphSearchState) consumeOffers(
 	offers []xdr.OfferEntry,
 ) (xdr.Asset, xdr.Int64, error) {
 	var nextAsset xdr.Asset
+	offers = state.usage.offers(offers)
+	if len(offers) == 0 {
+		return nextAsset, 0, nil
+	}
 	nextAmount, err := consumeOffersForBuyingAsset(offers, state.ignoreOffersFrom, currentAssetAmount)
 	if err == nil {
 		nextAsset = offers[0].Selling
@@ -236,6 +250,17 @@ func consumeOffersForSellingAsset(
 	offers []xdr.OfferEntry,
 	ignoreOffersFrom xdr.AccountId,
 	currentAssetAmount xdr.Int64,
+) (xdr.Int64, error) {
+	return consumeSellingOffers(offers, ignoreOffersFrom, currentAssetAmount, nil)
+}
+
+// consumeSellingOffers is the implementation of consumeOffersForSellingAsset.
+// If usage is not nil, the amount taken from each offer is added to it.
+func consumeSellingOffers(
+	offers []xdr.OfferEntry,
+	ignoreOffersFrom xdr.AccountId,
+	currentAssetAmount xdr.Int64,
+	usage offerUsage,
 ) (xdr.Int64, error) {
 	totalConsumed := xdr.Int64(0)
 
@@ -267,6 +292,7 @@ func consumeOffersForSellingAsset(
 
 		totalConsumed += xdr.Int64(buyingUnitsFromOffer)
 		currentAssetAmount -= xdr.Int64(sellingUnitsFromOffer)
+		usage.record(offer.OfferId, xdr.Int64(sellingUnitsFromOffer))
 
 		if currentAssetAmount <= 0 {
 			return totalConsumed, nil
@@ -280,6 +306,17 @@ func consumeOffersForBuyingAsset(
 	offers []xdr.OfferEntry,
 	ignoreOffersFrom *xdr.AccountId,
 	currentAssetAmount xdr.Int64,
+) (xdr.Int64, error) {
+	return consumeBuyingOffers(offers, ignoreOffersFrom, currentAssetAmount, nil)
+}
+
+// consumeBuyingOffers is the implementation of consumeOffersForBuyingAsset.
+// If usage is not nil, the amount taken from each offer is added to it.
+func consumeBuyingOffers(
+	offers []xdr.OfferEntry,
+	ignoreOffersFrom *xdr.AccountId,
+	currentAssetAmount xdr.Int64,
+	usage offerUsage,
 ) (xdr.Int64, error) {
 	totalConsumed := xdr.Int64(0)
 
@@ -310,6 +347,7 @@ func consumeOffersForBuyingAsset(
 		}
 		if amountSoldXDR := xdr.Int64(amountSold); amountSoldXDR <= offer.Amount {
 			totalConsumed += amountSoldXDR
+			usage.record(offer.OfferId, amountSoldXDR)
 			return totalConsumed, nil
 		}
 
@@ -325,6 +363,7 @@ func consumeOffersForBuyingAsset(
 
 		totalConsumed += xdr.Int64(sellingUnitsFromOffer)
 		currentAssetAmount -= xdr.Int64(buyingUnitsFromOffer)
+		usage.record(offer.OfferId, xdr.Int64(sellingUnitsFromOffer))
 
 		if currentAssetAmount <= 0 {
 			return totalConsumed, nil
@@ -3,6 +3,7 @@ package orderbook
 import (
 	"bytes"
 	"encoding"
+	"reflect"
 	"testing"
 
 	"github.com/diamnet/go/keypair"
@@ -1487,3 +1488,727 @@ func TestFindPathsStartingAt(t *testing.T) {
 
 	assertPathEquals(t, paths, expectedPaths)
 }
+
+func TestSplitAmount(t *testing.T) {
+	for _, testCase := range []struct {
+		name     string
+		amount   xdr.Int64
+		maxParts int
+		expected []xdr.Int64
+	}{
+		{"single part", 10, 1, []xdr.Int64{10}},
+		{"non positive parts", 10, 0, []xdr.Int64{10}},
+		{"even split", 10, 5, []xdr.Int64{2, 2, 2, 2, 2}},
+		{"remainder is spread", 11, 3, []xdr.Int64{4, 4, 3}},
+		{"more parts than amount", 2, 4, []xdr.Int64{1, 1}},
+		{"zero amount", 0, 4, []xdr.Int64{}},
+		{"negative amount", -5, 4, []xdr.Int64{}},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			parts := splitAmount(testCase.amount, testCase.maxParts)
+			if len(parts) != len(testCase.expected) {
+				t.Fatalf("expected %v but got %v", testCase.expected, parts)
+			}
+			for i := range parts {
+				if parts[i] != testCase.expected[i] {
+					t.Fatalf("expected %v but got %v", testCase.expected, parts)
+				}
+			}
+		})
+	}
+}
+
+func TestOfferUsageOffers(t *testing.T) {
+	offers := []xdr.OfferEntry{eurOffer, twoEurOffer, threeEurOffer}
+
+	usage := offerUsage{}
+	if available := usage.offers(offers); &available[0] != &offers[0] {
+		t.Fatalf("expected offers to be shared when nothing is consumed")
+	}
+
+	usage.record(quarterOffer.OfferId, 1)
+	if available := usage.offers(offers); &available[0] != &offers[0] {
+		t.Fatalf("expected offers to be shared when none of them is consumed")
+	}
+
+	usage.record(eurOffer.OfferId, eurOffer.Amount)
+	usage.record(threeEurOffer.OfferId, 1)
+	available := usage.offers(offers)
+	expected := []xdr.OfferEntry{twoEurOffer, threeEurOffer}
+	expected[1].Amount--
+	if !reflect.DeepEqual(available, expected) {
+		t.Fatalf("expected %v but got %v", expected, available)
+	}
+	if offers[2].Amount != threeEurOffer.Amount {
+		t.Fatalf("expected offers to be copied when they are consumed")
+	}
+}
+
+func splitPathTestGraph(t *testing.T) *OrderBookGraph {
+	graph := NewOrderBookGraph()
+
+	usdEurOffer := xdr.OfferEntry{
+		SellerId: issuer,
+		OfferId:  xdr.Int64(11),
+		Buying:   usdAsset,
+		Selling:  eurAsset,
+		Price: xdr.Price{
+			N: 1,
+			D: 3,
+		},
+		Amount: xdr.Int64(500),
+	}
+
+	err := graph.
+		AddOffer(quarterOffer).
+		AddOffer(fiftyCentsOffer).
+		AddOffer(eurOffer).
+		AddOffer(usdEurOffer).
+		Apply()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	return graph
+}
+
+func TestFindSplitPaths(t *testing.T) {
+	graph := splitPathTestGraph(t)
+
+	kp, err := keypair.Random()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	ignoreOffersFrom := xdr.MustAddress(kp.Address())
+
+	paths, err := graph.FindPaths(
+		3,
+		nativeAsset,
+		1000,
+		ignoreOffersFrom,
+		[]xdr.Asset{usdAsset},
+		[]xdr.Int64{10000},
+		5,
+	)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	// the direct path is the only one with enough liquidity
+	assertPathEquals(t, paths, []Path{
+		Path{
+			SourceAmount:      375,
+			SourceAsset:       usdAsset,
+			InteriorNodes:     []xdr.Asset{},
+			DestinationAsset:  nativeAsset,
+			DestinationAmount: 1000,
+		},
+	})
+
+	splitPaths, err := graph.FindSplitPaths(
+		3,
+		4,
+		nativeAsset,
+		1000,
+		ignoreOffersFrom,
+		[]xdr.Asset{usdAsset},
+		[]xdr.Int64{10000},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(splitPaths) != 1 {
+		t.Fatalf("expected one split path but got %v", splitPaths)
+	}
+
+	split := splitPaths[0]
+	if split.SourceAmount != 335 {
+		t.Fatalf("expected source amount 335 but got %v", split.SourceAmount)
+	}
+	if split.DestinationAmount != 1000 {
+		t.Fatalf("expected destination amount 1000 but got %v", split.DestinationAmount)
+	}
+	assertPathEquals(t, split.Allocations, []Path{
+		Path{
+			SourceAmount:      251,
+			SourceAsset:       usdAsset,
+			InteriorNodes:     []xdr.Asset{},
+			DestinationAsset:  nativeAsset,
+			DestinationAmount: 750,
+		},
+		Path{
+			SourceAmount:      84,
+			SourceAsset:       usdAsset,
+			InteriorNodes:     []xdr.Asset{eurAsset},
+			DestinationAsset:  nativeAsset,
+			DestinationAmount: 250,
+		},
+	})
+
+	// the balance is not sufficient to fund the payment
+	splitPaths, err = graph.FindSplitPaths(
+		3,
+		4,
+		nativeAsset,
+		1000,
+		ignoreOffersFrom,
+		[]xdr.Asset{usdAsset},
+		[]xdr.Int64{300},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(splitPaths) != 0 {
+		t.Fatalf("expected no split paths but got %v", splitPaths)
+	}
+
+	for _, amount := range []xdr.Int64{0, -1000} {
+		_, err = graph.FindSplitPaths(
+			3,
+			4,
+			nativeAsset,
+			amount,
+			ignoreOffersFrom,
+			[]xdr.Asset{usdAsset},
+			[]xdr.Int64{10000},
+		)
+		if err != errSplitAmountNotPositive {
+			t.Fatalf("expected error %v but got %v", errSplitAmountNotPositive, err)
+		}
+	}
+}
+
+func TestFindFixedSplitPaths(t *testing.T) {
+	graph := splitPathTestGraph(t)
+
+	paths, err := graph.FindFixedPaths(
+		3,
+		nil,
+		usdAsset,
+		200,
+		nativeAsset,
+	)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	assertPathEquals(t, paths, []Path{
+		Path{
+			SourceAmount:      200,
+			SourceAsset:       usdAsset,
+			InteriorNodes:     []xdr.Asset{},
+			DestinationAsset:  nativeAsset,
+			DestinationAmount: 650,
+		},
+	})
+
+	splitPaths, err := graph.FindFixedSplitPaths(
+		3,
+		2,
+		nil,
+		usdAsset,
+		200,
+		nativeAsset,
+	)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(splitPaths) != 1 {
+		t.Fatalf("expected one split path but got %v", splitPaths)
+	}
+
+	split := splitPaths[0]
+	if split.SourceAmount != 200 {
+		t.Fatalf("expected source amount 200 but got %v", split.SourceAmount)
+	}
+	if split.DestinationAmount != 700 {
+		t.Fatalf("expected destination amount 700 but got %v", split.DestinationAmount)
+	}
+	assertPathEquals(t, split.Allocations, []Path{
+		Path{
+			SourceAmount:      100,
+			SourceAsset:       usdAsset,
+			InteriorNodes:     []xdr.Asset{},
+			DestinationAsset:  nativeAsset,
+			DestinationAmount: 400,
+		},
+		Path{
+			SourceAmount:      100,
+			SourceAsset:       usdAsset,
+			InteriorNodes:     []xdr.Asset{eurAsset},
+			DestinationAsset:  nativeAsset,
+			DestinationAmount: 300,
+		},
+	})
+
+	// there is not enough liquidity to spend the entire amount
+	splitPaths, err = graph.FindFixedSplitPaths(
+		3,
+		2,
+		nil,
+		usdAsset,
+		1000,
+		nativeAsset,
+	)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(splitPaths) != 0 {
+		t.Fatalf("expected no split paths but got %v", splitPaths)
+	}
+
+	for _, amount := range []xdr.Int64{0, -200} {
+		_, err = graph.FindFixedSplitPaths(
+			3,
+			2,
+			nil,
+			usdAsset,
+			amount,
+			nativeAsset,
+		)
+		if err != errSplitAmountNotPositive {
+			t.Fatalf("expected error %v but got %v", errSplitAmountNotPositive, err)
+		}
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	graph := NewOrderBookGraph()
+	err := graph.
+		AddOffer(dollarOffer).
+		AddOffer(threeEurOffer).
+		AddOffer(eurOffer).
+		AddOffer(twoEurOffer).
+		AddOffer(quarterOffer).
+		AddOffer(fiftyCentsOffer).
+		Apply()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	var buffer bytes.Buffer
+	if err = graph.WriteSnapshot(&buffer, 123); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	serialized := buffer.Bytes()
+
+	snapshot, err := ReadSnapshot(bytes.NewReader(serialized))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if snapshot.LedgerSequence != 123 {
+		t.Fatalf("expected ledger sequence 123 but got %v", snapshot.LedgerSequence)
+	}
+
+	restored := NewOrderBookGraph()
+	for _, offer := range snapshot.Offers {
+		restored.AddOffer(offer)
+	}
+	if err = restored.Apply(); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	assertGraphEquals(t, graph, restored)
+
+	// a snapshot of an empty graph is valid
+	buffer.Reset()
+	if err = NewOrderBookGraph().WriteSnapshot(&buffer, 5); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	snapshot, err = ReadSnapshot(&buffer)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if snapshot.LedgerSequence != 5 || len(snapshot.Offers) != 0 {
+		t.Fatalf("expected empty snapshot at ledger 5 but got %v", snapshot)
+	}
+}
+
+func TestReadInvalidSnapshot(t *testing.T) {
+	graph := NewOrderBookGraph()
+	err := graph.
+		AddOffer(dollarOffer).
+		AddOffer(eurOffer).
+		Apply()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	var buffer bytes.Buffer
+	if err = graph.WriteSnapshot(&buffer, 123); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	serialized := buffer.Bytes()
+
+	corrupted := append([]byte{}, serialized...)
+	corrupted[len(corrupted)-1] ^= 0xff
+	if _, err = ReadSnapshot(bytes.NewReader(corrupted)); err != ErrSnapshotChecksumMismatch {
+		t.Fatalf("expected checksum mismatch error but got %v", err)
+	}
+
+	// changing the ledger sequence in the header invalidates the checksum
+	corrupted = append([]byte{}, serialized...)
+	corrupted[11]++
+	if _, err = ReadSnapshot(bytes.NewReader(corrupted)); err != ErrSnapshotChecksumMismatch {
+		t.Fatalf("expected checksum mismatch error but got %v", err)
+	}
+
+	unsupported := append([]byte{}, serialized...)
+	unsupported[7]++
+	if _, err = ReadSnapshot(bytes.NewReader(unsupported)); err != ErrSnapshotVersionMismatch {
+		t.Fatalf("expected version mismatch error but got %v", err)
+	}
+
+	invalid := append([]byte{}, serialized...)
+	invalid[0] = 0
+	if _, err = ReadSnapshot(bytes.NewReader(invalid)); err != ErrSnapshotInvalid {
+		t.Fatalf("expected invalid snapshot error but got %v", err)
+	}
+
+	if _, err = ReadSnapshot(bytes.NewReader(serialized[:len(serialized)-10])); err == nil {
+		t.Fatal("expected error reading truncated snapshot")
+	}
+}
+
+func depthTestGraph(t *testing.T) *OrderBookGraph {
+	otherEurOffer := eurOffer
+	otherEurOffer.OfferId = 10
+	otherEurOffer.Price = xdr.Price{N: 2, D: 2}
+	otherEurOffer.Amount = 100
+
+	bidOffer := xdr.OfferEntry{
+		SellerId: issuer,
+		OfferId:  xdr.Int64(11),
+		Buying:   nativeAsset,
+		Selling:  eurAsset,
+		Price: xdr.Price{
+			N: 2,
+			D: 1,
+		},
+		Amount: xdr.Int64(200),
+	}
+	otherBidOffer := bidOffer
+	otherBidOffer.OfferId = 12
+	otherBidOffer.Price = xdr.Price{N: 4, D: 1}
+	otherBidOffer.Amount = 300
+
+	graph := NewOrderBookGraph()
+	err := graph.
+		AddOffer(threeEurOffer).
+		AddOffer(eurOffer).
+		AddOffer(twoEurOffer).
+		AddOffer(otherEurOffer).
+		AddOffer(bidOffer).
+		AddOffer(otherBidOffer).
+		AddOffer(dollarOffer).
+		Apply()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	return graph
+}
+
+func TestOrderBookSummary(t *testing.T) {
+	graph := depthTestGraph(t)
+
+	summary, err := graph.OrderBookSummary(nativeAsset, eurAsset, 2)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	expectedAsks := []PriceLevel{
+		{Price: xdr.Price{N: 1, D: 1}, Amount: 600, CumulativeAmount: 600},
+		{Price: xdr.Price{N: 2, D: 1}, Amount: 500, CumulativeAmount: 1100},
+	}
+	expectedBids := []PriceLevel{
+		{Price: xdr.Price{N: 1, D: 2}, Amount: 200, CumulativeAmount: 200},
+		{Price: xdr.Price{N: 1, D: 4}, Amount: 300, CumulativeAmount: 500},
+	}
+	if !reflect.DeepEqual(summary.Asks, expectedAsks) {
+		t.Fatalf("expected asks %v but got %v", expectedAsks, summary.Asks)
+	}
+	if !reflect.DeepEqual(summary.Bids, expectedBids) {
+		t.Fatalf("expected bids %v but got %v", expectedBids, summary.Bids)
+	}
+
+	summary, err = graph.OrderBookSummary(nativeAsset, eurAsset, 200)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(summary.Asks) != 3 || summary.Asks[2].CumulativeAmount != 1600 {
+		t.Fatalf("expected 3 ask levels but got %v", summary.Asks)
+	}
+
+	summary, err = graph.OrderBookSummary(eurAsset, usdAsset, 10)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(summary.Asks) != 0 || len(summary.Bids) != 0 {
+		t.Fatalf("expected empty summary but got %v", summary)
+	}
+}
+
+func TestBuyImpact(t *testing.T) {
+	graph := depthTestGraph(t)
+
+	impact, err := graph.BuyImpact(eurAsset, nativeAsset, 700)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	expected := TradeImpact{
+		AmountSold:     800,
+		AmountBought:   700,
+		Filled:         true,
+		OffersConsumed: 3,
+		BestPrice:      xdr.Price{N: 1, D: 1},
+		WorstPrice:     xdr.Price{N: 2, D: 1},
+	}
+	if impact != expected {
+		t.Fatalf("expected %v but got %v", expected, impact)
+	}
+
+	impact, err = graph.BuyImpact(eurAsset, nativeAsset, 2000)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	expected = TradeImpact{
+		AmountSold:     3100,
+		AmountBought:   1600,
+		Filled:         false,
+		OffersConsumed: 4,
+		BestPrice:      xdr.Price{N: 1, D: 1},
+		WorstPrice:     xdr.Price{N: 3, D: 1},
+	}
+	if impact != expected {
+		t.Fatalf("expected %v but got %v", expected, impact)
+	}
+
+	impact, err = graph.BuyImpact(usdAsset, eurAsset, 10)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if impact != (TradeImpact{}) {
+		t.Fatalf("expected empty impact but got %v", impact)
+	}
+
+	if _, err = graph.BuyImpact(eurAsset, nativeAsset, 0); err != errAssetAmountIsZero {
+		t.Fatalf("expected error %v but got %v", errAssetAmountIsZero, err)
+	}
+}
+
+func TestSellImpact(t *testing.T) {
+	graph := depthTestGraph(t)
+
+	impact, err := graph.SellImpact(nativeAsset, eurAsset, 300)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	expected := TradeImpact{
+		AmountSold:     300,
+		AmountBought:   150,
+		Filled:         true,
+		OffersConsumed: 1,
+		BestPrice:      xdr.Price{N: 2, D: 1},
+		WorstPrice:     xdr.Price{N: 2, D: 1},
+	}
+	if impact != expected {
+		t.Fatalf("expected %v but got %v", expected, impact)
+	}
+
+	impact, err = graph.SellImpact(nativeAsset, eurAsset, 1000)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	expected = TradeImpact{
+		AmountSold:     1000,
+		AmountBought:   350,
+		Filled:         true,
+		OffersConsumed: 2,
+		BestPrice:      xdr.Price{N: 2, D: 1},
+		WorstPrice:     xdr.Price{N: 4, D: 1},
+	}
+	if impact != expected {
+		t.Fatalf("expected %v but got %v", expected, impact)
+	}
+
+	impact, err = graph.SellImpact(nativeAsset, eurAsset, 5000)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	expected = TradeImpact{
+		AmountSold:     1600,
+		AmountBought:   500,
+		Filled:         false,
+		OffersConsumed: 2,
+		BestPrice:      xdr.Price{N: 2, D: 1},
+		WorstPrice:     xdr.Price{N: 4, D: 1},
+	}
+	if impact != expected {
+		t.Fatalf("expected %v but got %v", expected, impact)
+	}
+}
+
+func arbitrageTestGraph(t *testing.T, offers ...xdr.OfferEntry) *OrderBookGraph {
+	graph := NewOrderBookGraph()
+	for _, offer := range offers {
+		graph.AddOffer(offer)
+	}
+	if err := graph.Apply(); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	return graph
+}
+
+func TestFindArbitrageCycles(t *testing.T) {
+	usdForNative := xdr.OfferEntry{
+		SellerId: issuer,
+		OfferId:  xdr.Int64(20),
+		Buying:   nativeAsset,
+		Selling:  usdAsset,
+		Price:    xdr.Price{N: 1, D: 1},
+		Amount:   xdr.Int64(100),
+	}
+	eurForUsd := xdr.OfferEntry{
+		SellerId: issuer,
+		OfferId:  xdr.Int64(21),
+		Buying:   usdAsset,
+		Selling:  eurAsset,
+		Price:    xdr.Price{N: 1, D: 1},
+		Amount:   xdr.Int64(100),
+	}
+	nativeForEur := xdr.OfferEntry{
+		SellerId: issuer,
+		OfferId:  xdr.Int64(22),
+		Buying:   eurAsset,
+		Selling:  nativeAsset,
+		Price:    xdr.Price{N: 1, D: 2},
+		Amount:   xdr.Int64(150),
+	}
+	expensiveNativeForEur := xdr.OfferEntry{
+		SellerId: issuer,
+		OfferId:  xdr.Int64(23),
+		Buying:   eurAsset,
+		Selling:  nativeAsset,
+		Price:    xdr.Price{N: 1, D: 1},
+		Amount:   xdr.Int64(1000),
+	}
+	graph := arbitrageTestGraph(
+		t,
+		usdForNative,
+		eurForUsd,
+		nativeForEur,
+		expensiveNativeForEur,
+		dollarOffer,
+	)
+
+	cycles, err := graph.FindArbitrageCycles(3, 0, []xdr.Asset{nativeAsset})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(cycles) != 1 {
+		t.Fatalf("expected 1 cycle but got %v", cycles)
+	}
+	assertCycleEquals(t, cycles[0], ArbitrageCycle{
+		Assets:            []xdr.Asset{nativeAsset, usdAsset, eurAsset},
+		Offers:            []xdr.OfferEntry{usdForNative, eurForUsd, nativeForEur},
+		SourceAmount:      75,
+		DestinationAmount: 150,
+	})
+	if cycles[0].Profit() != 75 {
+		t.Fatalf("expected profit 75 but got %v", cycles[0].Profit())
+	}
+
+	// every cycle is reported once when no source assets are given
+	cycles, err = graph.FindArbitrageCycles(3, 0, nil)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(cycles) != 1 {
+		t.Fatalf("expected 1 cycle but got %v", cycles)
+	}
+	assertCycleEquals(t, cycles[0], ArbitrageCycle{
+		Assets:            []xdr.Asset{eurAsset, nativeAsset, usdAsset},
+		Offers:            []xdr.OfferEntry{nativeForEur, usdForNative, eurForUsd},
+		SourceAmount:      50,
+		DestinationAmount: 100,
+	})
+
+	cycles, err = graph.FindArbitrageCycles(3, 76, []xdr.Asset{nativeAsset})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(cycles) != 0 {
+		t.Fatalf("expected no cycles but got %v", cycles)
+	}
+
+	cycles, err = graph.FindArbitrageCycles(2, 0, []xdr.Asset{nativeAsset})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(cycles) != 0 {
+		t.Fatalf("expected no cycles but got %v", cycles)
+	}
+
+	if _, err = graph.FindArbitrageCycles(1, 0, nil); err != errInvalidCycleLength {
+		t.Fatalf("expected error %v but got %v", errInvalidCycleLength, err)
+	}
+}
+
+func TestFindArbitrageCyclesUnprofitable(t *testing.T) {
+	usdForNative := xdr.OfferEntry{
+		SellerId: issuer,
+		OfferId:  xdr.Int64(20),
+		Buying:   nativeAsset,
+		Selling:  usdAsset,
+		Price:    xdr.Price{N: 1, D: 1},
+		Amount:   xdr.Int64(100),
+	}
+	nativeForUsd := xdr.OfferEntry{
+		SellerId: issuer,
+		OfferId:  xdr.Int64(21),
+		Buying:   usdAsset,
+		Selling:  nativeAsset,
+		Price:    xdr.Price{N: 2, D: 1},
+		Amount:   xdr.Int64(100),
+	}
+	graph := arbitrageTestGraph(t, usdForNative, nativeForUsd)
+
+	cycles, err := graph.FindArbitrageCycles(4, 0, nil)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(cycles) != 0 {
+		t.Fatalf("expected no cycles but got %v", cycles)
+	}
+
+	// a cheaper offer makes the two asset cycle profitable
+	cheapNativeForUsd := nativeForUsd
+	cheapNativeForUsd.OfferId = 22
+	cheapNativeForUsd.Price = xdr.Price{N: 1, D: 2}
+	cheapNativeForUsd.Amount = 50
+	graph = arbitrageTestGraph(t, usdForNative, nativeForUsd, cheapNativeForUsd)
+
+	cycles, err = graph.FindArbitrageCycles(2, 0, []xdr.Asset{nativeAsset})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(cycles) != 1 {
+		t.Fatalf("expected 1 cycle but got %v", cycles)
+	}
+	assertCycleEquals(t, cycles[0], ArbitrageCycle{
+		Assets:            []xdr.Asset{nativeAsset, usdAsset},
+		Offers:            []xdr.OfferEntry{usdForNative, cheapNativeForUsd},
+		SourceAmount:      25,
+		DestinationAmount: 50,
+	})
+}
+
+func assertCycleEquals(t *testing.T, a, b ArbitrageCycle) {
+	if a.SourceAmount != b.SourceAmount || a.DestinationAmount != b.DestinationAmount {
+		t.Fatalf("expected cycles to have same amounts but got %v %v", a, b)
+	}
+	if len(a.Assets) != len(b.Assets) {
+		t.Fatalf("expected cycles to have same assets but got %v %v", a, b)
+	}
+	for i := range a.Assets {
+		if !a.Assets[i].Equals(b.Assets[i]) {
+			t.Fatalf("expected cycles to have same assets but got %v %v", a, b)
+		}
+	}
+	assertOfferListEquals(t, a.Offers, b.Offers)
+}
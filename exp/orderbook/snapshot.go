@@ -0,0 +1,126 @@
+package orderbook
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"io"
+
+	"github.com/diamnet/go/support/errors"
+	"github.com/diamnet/go/xdr"
+)
+
+const (
+	// snapshotMagic identifies order book snapshot files ("OBGS")
+	snapshotMagic uint32 = 0x4f424753
+	// snapshotVersion is the version of the snapshot format. It must be
+	// incremented every time the format changes so stale snapshots are
+	// rejected instead of being misinterpreted.
+	snapshotVersion uint32 = 1
+)
+
+var (
+	// ErrSnapshotInvalid is returned when the data being restored is not an order book snapshot
+	ErrSnapshotInvalid = errors.New("data is not an order book snapshot")
+	// ErrSnapshotVersionMismatch is returned when the snapshot was written using a
+	// different version of the snapshot format
+	ErrSnapshotVersionMismatch = errors.New("order book snapshot version is not supported")
+	// ErrSnapshotChecksumMismatch is returned when the snapshot contents do not match
+	// the checksum stored in the snapshot
+	ErrSnapshotChecksumMismatch = errors.New("order book snapshot checksum does not match")
+)
+
+// Snapshot contains all the offers of an order book graph
+// as of the end of the ledger LedgerSequence
+type Snapshot struct {
+	LedgerSequence uint32
+	Offers         []xdr.OfferEntry
+}
+
+// snapshotHeader is written at the beginning of every snapshot
+type snapshotHeader struct {
+	Magic          uint32
+	Version        uint32
+	LedgerSequence uint32
+	OfferCount     uint64
+}
+
+// WriteSnapshot serializes all the offers in the order book graph together with the
+// sequence of the ledger the graph reflects. The snapshot ends with a SHA-256 checksum
+// of its contents which is verified by ReadSnapshot.
+func (graph *OrderBookGraph) WriteSnapshot(w io.Writer, ledgerSequence uint32) error {
+	graph.lock.RLock()
+	defer graph.lock.RUnlock()
+
+	hash := sha256.New()
+	buffered := bufio.NewWriter(w)
+	out := io.MultiWriter(buffered, hash)
+
+	header := snapshotHeader{
+		Magic:          snapshotMagic,
+		Version:        snapshotVersion,
+		LedgerSequence: ledgerSequence,
+		OfferCount:     uint64(len(graph.tradingPairForOffer)),
+	}
+	if err := binary.Write(out, binary.BigEndian, header); err != nil {
+		return errors.Wrap(err, "could not write snapshot header")
+	}
+
+	for _, edges := range graph.edgesForSellingAsset {
+		for _, offers := range edges {
+			for _, offer := range offers {
+				if _, err := xdr.Marshal(out, offer); err != nil {
+					return errors.Wrap(err, "could not write offer")
+				}
+			}
+		}
+	}
+
+	if _, err := buffered.Write(hash.Sum(nil)); err != nil {
+		return errors.Wrap(err, "could not write snapshot checksum")
+	}
+
+	return buffered.Flush()
+}
+
+// ReadSnapshot reads a snapshot created by WriteSnapshot. ReadSnapshot does not
+// return any offers unless the entire snapshot was read and its checksum is valid.
+func ReadSnapshot(r io.Reader) (Snapshot, error) {
+	hash := sha256.New()
+	buffered := bufio.NewReader(r)
+	in := io.TeeReader(buffered, hash)
+
+	var header snapshotHeader
+	if err := binary.Read(in, binary.BigEndian, &header); err != nil {
+		return Snapshot{}, errors.Wrap(err, "could not read snapshot header")
+	}
+	if header.Magic != snapshotMagic {
+		return Snapshot{}, ErrSnapshotInvalid
+	}
+	if header.Version != snapshotVersion {
+		return Snapshot{}, ErrSnapshotVersionMismatch
+	}
+
+	offers := []xdr.OfferEntry{}
+	for i := uint64(0); i < header.OfferCount; i++ {
+		var offer xdr.OfferEntry
+		if _, err := xdr.Unmarshal(in, &offer); err != nil {
+			return Snapshot{}, errors.Wrap(err, "could not read offer")
+		}
+		offers = append(offers, offer)
+	}
+
+	checksum := make([]byte, sha256.Size)
+	if _, err := io.ReadFull(buffered, checksum); err != nil {
+		return Snapshot{}, errors.Wrap(err, "could not read snapshot checksum")
+	}
+	if !bytes.Equal(checksum, hash.Sum(nil)) {
+		return Snapshot{}, ErrSnapshotChecksumMismatch
+	}
+
+	return Snapshot{
+		LedgerSequence: header.LedgerSequence,
+		Offers:         offers,
+	}, nil
+}
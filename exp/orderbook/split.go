@@ -0,0 +1,362 @@
+package orderbook
+
+import (
+	"sort"
+
+	"github.com/diamnet/go/support/errors"
+	"github.com/diamnet/go/xdr"
+)
+
+var (
+	errSplitPathDiverged      = errors.New("could not replay payment path against the order book")
+	errSplitAmountNotPositive = errors.New("amount to split must be positive")
+)
+
+// SplitPath represents a payment which is divided across one or more payment paths.
+// All paths in Allocations share the same source and destination assets.
+// The allocations are computed one after another and each allocation only
+// consumes liquidity which was not consumed by the preceding allocations.
+// Therefore, offers which are shared between multiple paths are never
+// double counted and SourceAmount / DestinationAmount are the totals
+// for the combined payment.
+type SplitPath struct {
+	SourceAsset       xdr.Asset
+	SourceAmount      xdr.Int64
+	DestinationAsset  xdr.Asset
+	DestinationAmount xdr.Int64
+	Allocations       []Path
+}
+
+// offerUsage maps an offer id to the amount of the offer's selling asset
+// which has been consumed by previously allocated payment paths
+type offerUsage map[xdr.Int64]xdr.Int64
+
+// record adds amount to the consumed amount of the given offer.
+// record is a no-op on a nil offerUsage.
+func (usage offerUsage) record(offerID xdr.Int64, amount xdr.Int64) {
+	if usage == nil {
+		return
+	}
+	usage[offerID] += amount
+}
+
+// clone returns a copy of the offer usage
+func (usage offerUsage) clone() offerUsage {
+	copied := make(offerUsage, len(usage))
+	for offerID, amount := range usage {
+		copied[offerID] = amount
+	}
+	return copied
+}
+
+// offers returns the given offers with their amounts reduced by the amounts
+// which have already been consumed. Offers which have been fully consumed are
+// omitted. The offers are only copied if some of them have been consumed, so
+// the offers of the order book graph are shared otherwise.
+func (usage offerUsage) offers(offers []xdr.OfferEntry) []xdr.OfferEntry {
+	if len(usage) == 0 {
+		return offers
+	}
+
+	var available []xdr.OfferEntry
+	for i, offer := range offers {
+		consumed := usage[offer.OfferId]
+		if consumed == 0 {
+			if available != nil {
+				available = append(available, offer)
+			}
+			continue
+		}
+		if available == nil {
+			available = make([]xdr.OfferEntry, i, len(offers))
+			copy(available, offers[:i])
+		}
+		if consumed >= offer.Amount {
+			continue
+		}
+		offer.Amount -= consumed
+		available = append(available, offer)
+	}
+	if available == nil {
+		return offers
+	}
+	return available
+}
+
+// splitAmount divides amount into at most maxParts positive parts of (almost) equal size.
+// No parts are returned if amount is not positive.
+func splitAmount(amount xdr.Int64, maxParts int) []xdr.Int64 {
+	if amount <= 0 {
+		return []xdr.Int64{}
+	}
+	if maxParts < 1 {
+		maxParts = 1
+	}
+	if amount < xdr.Int64(maxParts) {
+		maxParts = int(amount)
+	}
+
+	parts := make([]xdr.Int64, maxParts)
+	base := amount / xdr.Int64(maxParts)
+	remainder := amount % xdr.Int64(maxParts)
+	for i := range parts {
+		parts[i] = base
+		if xdr.Int64(i) < remainder {
+			parts[i]++
+		}
+	}
+	return parts
+}
+
+// addAllocation merges the given path into the split path. If the split path
+// already routes funds through the same sequence of assets the amounts are added
+// to the existing allocation.
+func (split *SplitPath) addAllocation(path Path) {
+	split.SourceAmount += path.SourceAmount
+	split.DestinationAmount += path.DestinationAmount
+
+	for i := range split.Allocations {
+		if sameInteriorNodes(split.Allocations[i].InteriorNodes, path.InteriorNodes) {
+			split.Allocations[i].SourceAmount += path.SourceAmount
+			split.Allocations[i].DestinationAmount += path.DestinationAmount
+			return
+		}
+	}
+	split.Allocations = append(split.Allocations, path)
+}
+
+func sameInteriorNodes(a, b []xdr.Asset) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].Equals(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// FindSplitPaths returns payments which deliver `destinationAmount` of `destinationAsset`
+// by dividing the amount into at most `maxParts` parts. Each part is routed through the
+// cheapest path which is available once the preceding parts have been allocated.
+// At most one SplitPath is returned for every source asset. Source assets which
+// cannot fund the entire payment (either because of insufficient liquidity or
+// insufficient balance) are omitted.
+// No offers created by `sourceAccountID` will be considered when evaluating payment paths.
+func (graph *OrderBookGraph) FindSplitPaths(
+	maxPathLength int,
+	maxParts int,
+	destinationAsset xdr.Asset,
+	destinationAmount xdr.Int64,
+	sourceAccountID xdr.AccountId,
+	sourceAssets []xdr.Asset,
+	sourceAssetBalances []xdr.Int64,
+) ([]SplitPath, error) {
+	if destinationAmount <= 0 {
+		return nil, errSplitAmountNotPositive
+	}
+	parts := splitAmount(destinationAmount, maxParts)
+
+	graph.lock.RLock()
+	defer graph.lock.RUnlock()
+
+	splitPaths := []SplitPath{}
+	for i, sourceAsset := range sourceAssets {
+		sourceAssetString := sourceAsset.String()
+		split := SplitPath{
+			SourceAsset:      sourceAsset,
+			DestinationAsset: destinationAsset,
+			Allocations:      []Path{},
+		}
+		usage := offerUsage{}
+		found := true
+
+		for _, part := range parts {
+			searchState := &sellingGraphSearchState{
+				graph:                  graph,
+				destinationAsset:       destinationAsset,
+				destinationAssetAmount: part,
+				ignoreOffersFrom:       sourceAccountID,
+				targetAssets: map[string]xdr.Int64{
+					sourceAssetString: sourceAssetBalances[i] - split.SourceAmount,
+				},
+				paths: []Path{},
+				usage: usage,
+			}
+			err := dfs(
+				searchState,
+				maxPathLength,
+				map[string]bool{},
+				[]xdr.Asset{},
+				destinationAsset.String(),
+				destinationAsset,
+				part,
+			)
+			if err != nil {
+				return nil, errors.Wrap(err, "could not determine paths")
+			}
+			if len(searchState.paths) == 0 {
+				found = false
+				break
+			}
+
+			best := searchState.paths[0]
+			for _, path := range searchState.paths[1:] {
+				if path.SourceAmount < best.SourceAmount ||
+					(path.SourceAmount == best.SourceAmount &&
+						len(path.InteriorNodes) < len(best.InteriorNodes)) {
+					best = path
+				}
+			}
+
+			best.SourceAmount, err = graph.consumeSellingPath(best, sourceAccountID, usage)
+			if err != nil {
+				return nil, errors.Wrap(err, "could not allocate payment path")
+			}
+			split.addAllocation(best)
+		}
+
+		if found {
+			splitPaths = append(splitPaths, split)
+		}
+	}
+
+	sort.Slice(splitPaths, func(i, j int) bool {
+		return splitPaths[i].SourceAsset.String() < splitPaths[j].SourceAsset.String()
+	})
+	return splitPaths, nil
+}
+
+// FindFixedSplitPaths returns a payment which spends exactly `amountToSpend` of
+// `sourceAsset` and delivers some positive amount of `destinationAsset` by dividing
+// `amountToSpend` into at most `maxParts` parts. Each part is routed through the
+// path which yields the most `destinationAsset` once the preceding parts have
+// been allocated. The returned list is empty if the entire amount cannot be spent,
+// otherwise it contains exactly one SplitPath.
+// `sourceAccountID` is optional. if `sourceAccountID` is provided then no offers
+// created by `sourceAccountID` will be considered when evaluating payment paths
+func (graph *OrderBookGraph) FindFixedSplitPaths(
+	maxPathLength int,
+	maxParts int,
+	sourceAccountID *xdr.AccountId,
+	sourceAsset xdr.Asset,
+	amountToSpend xdr.Int64,
+	destinationAsset xdr.Asset,
+) ([]SplitPath, error) {
+	if amountToSpend <= 0 {
+		return nil, errSplitAmountNotPositive
+	}
+	parts := splitAmount(amountToSpend, maxParts)
+	split := SplitPath{
+		SourceAsset:      sourceAsset,
+		DestinationAsset: destinationAsset,
+		Allocations:      []Path{},
+	}
+	usage := offerUsage{}
+
+	graph.lock.RLock()
+	defer graph.lock.RUnlock()
+
+	for _, part := range parts {
+		searchState := &buyingGraphSearchState{
+			graph:             graph,
+			sourceAsset:       sourceAsset,
+			sourceAssetAmount: part,
+			ignoreOffersFrom:  sourceAccountID,
+			targetAssets:      map[string]bool{destinationAsset.String(): true},
+			paths:             []Path{},
+			usage:             usage,
+		}
+		err := dfs(
+			searchState,
+			maxPathLength,
+			map[string]bool{},
+			[]xdr.Asset{},
+			sourceAsset.String(),
+			sourceAsset,
+			part,
+		)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not determine paths")
+		}
+		if len(searchState.paths) == 0 {
+			return []SplitPath{}, nil
+		}
+
+		best := searchState.paths[0]
+		for _, path := range searchState.paths[1:] {
+			if path.DestinationAmount > best.DestinationAmount ||
+				(path.DestinationAmount == best.DestinationAmount &&
+					len(path.InteriorNodes) < len(best.InteriorNodes)) {
+				best = path
+			}
+		}
+
+		best.DestinationAmount, err = graph.consumeBuyingPath(best, sourceAccountID, usage)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not allocate payment path")
+		}
+		split.addAllocation(best)
+	}
+
+	return []SplitPath{split}, nil
+}
+
+// consumeSellingPath walks the given path from the destination asset to the source asset,
+// records all the offers consumed along the way in usage, and returns the amount of
+// source asset needed to deliver the path's destination amount
+func (graph *OrderBookGraph) consumeSellingPath(
+	path Path,
+	ignoreOffersFrom xdr.AccountId,
+	usage offerUsage,
+) (xdr.Int64, error) {
+	assets := []xdr.Asset{path.DestinationAsset}
+	for i := len(path.InteriorNodes) - 1; i >= 0; i-- {
+		assets = append(assets, path.InteriorNodes[i])
+	}
+	assets = append(assets, path.SourceAsset)
+
+	amount := path.DestinationAmount
+	for i := 0; i < len(assets)-1; i++ {
+		offers := usage.offers(graph.edgesForSellingAsset[assets[i].String()][assets[i+1].String()])
+		next, err := consumeSellingOffers(offers, ignoreOffersFrom, amount, usage)
+		if err != nil {
+			return -1, err
+		}
+		if next <= 0 {
+			return -1, errSplitPathDiverged
+		}
+		amount = next
+	}
+
+	return amount, nil
+}
+
+// consumeBuyingPath walks the given path from the source asset to the destination asset,
+// records all the offers consumed along the way in usage, and returns the amount of
+// destination asset obtained by spending the path's source amount
+func (graph *OrderBookGraph) consumeBuyingPath(
+	path Path,
+	ignoreOffersFrom *xdr.AccountId,
+	usage offerUsage,
+) (xdr.Int64, error) {
+	assets := []xdr.Asset{path.SourceAsset}
+	assets = append(assets, path.InteriorNodes...)
+	assets = append(assets, path.DestinationAsset)
+
+	amount := path.SourceAmount
+	for i := 0; i < len(assets)-1; i++ {
+		offers := usage.offers(graph.edgesForBuyingAsset[assets[i].String()][assets[i+1].String()])
+		next, err := consumeBuyingOffers(offers, ignoreOffersFrom, amount, usage)
+		if err != nil {
+			return -1, err
+		}
+		if next <= 0 {
+			return -1, errSplitPathDiverged
+		}
+		amount = next
+	}
+
+	return amount, nil
+}
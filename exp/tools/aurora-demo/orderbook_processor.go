@@ -9,6 +9,7 @@ import (
 	ingestpipeline "github.com/diamnet/go/exp/ingest/pipeline"
 	"github.com/diamnet/go/exp/orderbook"
 	"github.com/diamnet/go/exp/support/pipeline"
+	"github.com/diamnet/go/support/errors"
 	"github.com/diamnet/go/xdr"
 )
 
@@ -65,7 +66,12 @@ func (p *OrderbookProcessor) ProcessLedger(ctx context.Context, store *pipeline.
 			continue
 		}
 
-		for _, change := range transaction.GetChanges() {
+		changes, err := transaction.GetChanges()
+		if err != nil {
+			return errors.Wrap(err, "Error getting transaction changes")
+		}
+
+		for _, change := range changes {
 			if change.Type != xdr.LedgerEntryTypeOffer {
 				continue
 			}
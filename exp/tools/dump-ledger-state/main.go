@@ -81,6 +81,7 @@ func archive() (*historyarchive.Archive, error) {
 		historyarchive.ConnectOptions{
 			S3Region:         "eu-west-1",
 			UnsignedRequests: true,
+			CacheDir:         os.Getenv("ARCHIVE_CACHE_DIR"),
 		},
 	)
 }
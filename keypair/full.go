@@ -3,6 +3,7 @@ package keypair
 import (
 	"bytes"
 
+	"github.com/diamnet/go/network"
 	"github.com/diamnet/go/strkey"
 	"github.com/diamnet/go/xdr"
 
@@ -53,6 +54,16 @@ func (kp *Full) SignDecorated(input []byte) (xdr.DecoratedSignature, error) {
 	}, nil
 }
 
+// SignTransaction signs the hash of tx for the network identified by
+// networkPassphrase, so that a *Full can be used as a Signer.
+func (kp *Full) SignTransaction(tx *xdr.Transaction, networkPassphrase string) (xdr.DecoratedSignature, error) {
+	hash, err := network.HashTransaction(tx, networkPassphrase)
+	if err != nil {
+		return xdr.DecoratedSignature{}, err
+	}
+	return kp.SignDecorated(hash[:])
+}
+
 func (kp *Full) publicKey() ed25519.PublicKey {
 	pub, _ := kp.keys()
 	return pub
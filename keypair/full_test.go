@@ -3,6 +3,8 @@ package keypair
 import (
 	"encoding/hex"
 
+	"github.com/diamnet/go/network"
+	"github.com/diamnet/go/xdr"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
@@ -41,6 +43,23 @@ var _ = Describe("keypair.Full", func() {
 		}),
 	)
 
+	Describe("SignTransaction()", func() {
+		It("signs the hash of the transaction", func() {
+			kp := &Full{seed}
+			tx := &xdr.Transaction{SourceAccount: xdr.MustAddress(address), Fee: 100, SeqNum: 1}
+			sig, err := kp.SignTransaction(tx, network.TestNetworkPassphrase)
+			Expect(err).To(BeNil())
+			Expect(sig.Hint).To(BeEquivalentTo(hint))
+
+			hash, err := network.HashTransaction(tx, network.TestNetworkPassphrase)
+			Expect(err).To(BeNil())
+			Expect(kp.Verify(hash[:], sig.Signature)).To(BeNil())
+
+			_, err = kp.SignTransaction(tx, "")
+			Expect(err).ToNot(BeNil())
+		})
+	})
+
 	Describe("SignDecorated()", func() {
 		It("returns the correct xdr struct", func() {
 			sig, err := subject.SignDecorated(message)
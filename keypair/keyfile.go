@@ -0,0 +1,226 @@
+package keypair
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/diamnet/go/strkey"
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// KeyFileVersion is the version of the key file format written by
+	// NewKeyFile.
+	KeyFileVersion = 1
+
+	keyFileKDF    = "scrypt"
+	keyFileCipher = "xsalsa20-poly1305"
+
+	// maxKeyFileScryptN bounds the cost of loading a key file, so a crafted
+	// file can't make Decrypt allocate an unbounded amount of memory.
+	maxKeyFileScryptN = 1 << 20
+)
+
+// scrypt parameters of new key files, N=2^15, r=8 and p=1 cost ~32MB of
+// memory per key derivation.
+var (
+	keyFileScryptN = 1 << 15
+	keyFileScryptR = 8
+	keyFileScryptP = 1
+)
+
+// KeyFile is a seed encrypted with a password. The key is derived from the
+// password with scrypt and the seed is encrypted and authenticated with
+// XSalsa20 and Poly1305 (NaCl secretbox). The address is stored in the clear so
+// the key file can be identified without the password.
+type KeyFile struct {
+	Version int           `json:"version"`
+	Address string        `json:"address"`
+	Crypto  KeyFileCrypto `json:"crypto"`
+}
+
+// KeyFileCrypto contains the parameters needed to decrypt the seed of a key
+// file.
+type KeyFileCrypto struct {
+	KDF        string           `json:"kdf"`
+	KDFParams  KeyFileKDFParams `json:"kdf_params"`
+	Cipher     string           `json:"cipher"`
+	Nonce      []byte           `json:"nonce"`
+	Ciphertext []byte           `json:"ciphertext"`
+}
+
+// KeyFileKDFParams are the scrypt parameters used to derive the encryption key
+// from the password.
+type KeyFileKDFParams struct {
+	N    int    `json:"n"`
+	R    int    `json:"r"`
+	P    int    `json:"p"`
+	Salt []byte `json:"salt"`
+}
+
+// NewKeyFile encrypts the seed of kp with password.
+func NewKeyFile(kp *Full, password []byte) (*KeyFile, error) {
+	if len(password) == 0 {
+		return nil, errors.New("password is empty")
+	}
+
+	rawSeed, err := strkey.Decode(strkey.VersionByteSeed, kp.Seed())
+	if err != nil {
+		return nil, err
+	}
+
+	params := KeyFileKDFParams{
+		N:    keyFileScryptN,
+		R:    keyFileScryptR,
+		P:    keyFileScryptP,
+		Salt: make([]byte, 32),
+	}
+	_, err = io.ReadFull(rand.Reader, params.Salt)
+	if err != nil {
+		return nil, err
+	}
+
+	var nonce [24]byte
+	_, err = io.ReadFull(rand.Reader, nonce[:])
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := params.deriveKey(password)
+	if err != nil {
+		return nil, err
+	}
+
+	return &KeyFile{
+		Version: KeyFileVersion,
+		Address: kp.Address(),
+		Crypto: KeyFileCrypto{
+			KDF:        keyFileKDF,
+			KDFParams:  params,
+			Cipher:     keyFileCipher,
+			Nonce:      nonce[:],
+			Ciphertext: secretbox.Seal(nil, rawSeed, &nonce, key),
+		},
+	}, nil
+}
+
+// ParseKeyFile parses a JSON encoded key file. The seed stays encrypted until
+// Decrypt is called.
+func ParseKeyFile(data []byte) (*KeyFile, error) {
+	var f KeyFile
+	err := json.Unmarshal(data, &f)
+	if err != nil {
+		return nil, err
+	}
+
+	if f.Version != KeyFileVersion {
+		return nil, fmt.Errorf("unsupported key file version %d", f.Version)
+	}
+	if f.Crypto.KDF != keyFileKDF {
+		return nil, fmt.Errorf("unsupported key file kdf %q", f.Crypto.KDF)
+	}
+	if f.Crypto.Cipher != keyFileCipher {
+		return nil, fmt.Errorf("unsupported key file cipher %q", f.Crypto.Cipher)
+	}
+	_, err = strkey.Decode(strkey.VersionByteAccountID, f.Address)
+	if err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+// Decrypt decrypts the seed of the key file with password. ErrInvalidPassword
+// is returned when the password is wrong or the key file was tampered with.
+func (f *KeyFile) Decrypt(password []byte) (*Full, error) {
+	if len(f.Crypto.Nonce) != 24 {
+		return nil, errors.New("invalid key file nonce")
+	}
+	var nonce [24]byte
+	copy(nonce[:], f.Crypto.Nonce)
+
+	key, err := f.Crypto.KDFParams.deriveKey(password)
+	if err != nil {
+		return nil, err
+	}
+
+	rawSeed, ok := secretbox.Open(nil, f.Crypto.Ciphertext, &nonce, key)
+	if !ok || len(rawSeed) != 32 {
+		return nil, ErrInvalidPassword
+	}
+
+	var seed [32]byte
+	copy(seed[:], rawSeed)
+	kp, err := FromRawSeed(seed)
+	if err != nil {
+		return nil, err
+	}
+
+	// The address isn't covered by the authentication tag, make sure it
+	// wasn't replaced.
+	if kp.Address() != f.Address {
+		return nil, errors.New("key file address doesn't match its seed")
+	}
+	return kp, nil
+}
+
+func (p KeyFileKDFParams) deriveKey(password []byte) (*[32]byte, error) {
+	if p.N < 2 || p.N > maxKeyFileScryptN || p.N&(p.N-1) != 0 {
+		return nil, fmt.Errorf("invalid key file scrypt N %d", p.N)
+	}
+	if p.R < 1 || p.R > 32 || p.P < 1 || p.P > 16 {
+		return nil, fmt.Errorf("invalid key file scrypt r %d or p %d", p.R, p.P)
+	}
+	if len(p.Salt) < 16 {
+		return nil, errors.New("key file salt is too short")
+	}
+
+	derived, err := scrypt.Key(password, p.Salt, p.N, p.R, p.P, 32)
+	if err != nil {
+		return nil, err
+	}
+	var key [32]byte
+	copy(key[:], derived)
+	return &key, nil
+}
+
+// SaveKeyFile encrypts the seed of kp with password and writes it to a new
+// file at path, readable only by its owner. Existing files are never
+// overwritten.
+func SaveKeyFile(path string, kp *Full, password []byte) error {
+	f, err := NewKeyFile(kp, password)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(append(data, '\n'))
+	if err != nil {
+		file.Close()
+		os.Remove(path)
+		return err
+	}
+	return file.Close()
+}
+
+// LoadKeyFile reads and parses the key file at path. Its address can be read
+// before asking for the password needed by Decrypt.
+func LoadKeyFile(path string) (*KeyFile, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseKeyFile(data)
+}
@@ -0,0 +1,112 @@
+package keypair
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("keypair.KeyFile", func() {
+	var (
+		kp       *Full
+		password = []byte("correct horse battery staple")
+	)
+
+	BeforeEach(func() {
+		kp = &Full{seed}
+		// keep key derivation cheap in tests
+		keyFileScryptN = 1 << 10
+	})
+
+	AfterEach(func() {
+		keyFileScryptN = 1 << 15
+	})
+
+	It("stores the address in the clear and decrypts the seed", func() {
+		f, err := NewKeyFile(kp, password)
+		Expect(err).To(BeNil())
+		Expect(f.Address).To(Equal(address))
+
+		data, err := json.Marshal(f)
+		Expect(err).To(BeNil())
+		Expect(string(data)).ToNot(ContainSubstring(seed))
+
+		parsed, err := ParseKeyFile(data)
+		Expect(err).To(BeNil())
+		decrypted, err := parsed.Decrypt(password)
+		Expect(err).To(BeNil())
+		Expect(decrypted.Seed()).To(Equal(seed))
+	})
+
+	It("rejects a wrong password", func() {
+		f, err := NewKeyFile(kp, password)
+		Expect(err).To(BeNil())
+		_, err = f.Decrypt([]byte("wrong password"))
+		Expect(err).To(Equal(ErrInvalidPassword))
+	})
+
+	It("rejects an empty password", func() {
+		_, err := NewKeyFile(kp, nil)
+		Expect(err).ToNot(BeNil())
+	})
+
+	It("rejects tampered key files", func() {
+		f, err := NewKeyFile(kp, password)
+		Expect(err).To(BeNil())
+
+		f.Crypto.Ciphertext[0] ^= 1
+		_, err = f.Decrypt(password)
+		Expect(err).To(Equal(ErrInvalidPassword))
+		f.Crypto.Ciphertext[0] ^= 1
+
+		f.Address = "GCLOMB72ODBFUGK4E2BK7VMR3RNZ5WSTMEOGNA2YUVHFR3WMH2XBAB6H"
+		_, err = f.Decrypt(password)
+		Expect(err).ToNot(BeNil())
+		f.Address = address
+
+		f.Crypto.KDFParams.N = 1 << 30
+		_, err = f.Decrypt(password)
+		Expect(err).ToNot(BeNil())
+	})
+
+	It("rejects unsupported key files", func() {
+		f, err := NewKeyFile(kp, password)
+		Expect(err).To(BeNil())
+		f.Crypto.KDF = "argon2id"
+		data, err := json.Marshal(f)
+		Expect(err).To(BeNil())
+		_, err = ParseKeyFile(data)
+		Expect(err).ToNot(BeNil())
+
+		_, err = ParseKeyFile([]byte(`{"version": 2}`))
+		Expect(err).ToNot(BeNil())
+	})
+
+	It("saves and loads key files", func() {
+		dir, err := ioutil.TempDir("", "keyfile")
+		Expect(err).To(BeNil())
+		defer os.RemoveAll(dir)
+		path := filepath.Join(dir, "key.json")
+
+		err = SaveKeyFile(path, kp, password)
+		Expect(err).To(BeNil())
+		info, err := os.Stat(path)
+		Expect(err).To(BeNil())
+		Expect(info.Mode().Perm()).To(Equal(os.FileMode(0600)))
+
+		// existing files are not overwritten
+		err = SaveKeyFile(path, kp, password)
+		Expect(err).ToNot(BeNil())
+
+		f, err := LoadKeyFile(path)
+		Expect(err).To(BeNil())
+		Expect(f.Address).To(Equal(address))
+		decrypted, err := f.Decrypt(password)
+		Expect(err).To(BeNil())
+		Expect(decrypted.Seed()).To(Equal(seed))
+	})
+})
@@ -23,6 +23,10 @@ var (
 	// ErrCannotSign is returned when attempting to sign a message when
 	// the keypair does not have the secret key available
 	ErrCannotSign = errors.New("cannot sign")
+
+	// ErrInvalidPassword is returned when the seed of a key file can't be
+	// decrypted with the provided password
+	ErrInvalidPassword = errors.New("invalid password")
 )
 
 const (
@@ -40,6 +44,15 @@ type KP interface {
 	SignDecorated(input []byte) (xdr.DecoratedSignature, error)
 }
 
+// Signer signs transactions on behalf of an account. Implementations don't need
+// to hold the seed of the account: a *Full signs in process, while other
+// signers may delegate signing to a remote service or an offline device.
+type Signer interface {
+	// SignTransaction returns the signature of tx for the network identified
+	// by networkPassphrase.
+	SignTransaction(tx *xdr.Transaction, networkPassphrase string) (xdr.DecoratedSignature, error)
+}
+
 // Random creates a random full keypair
 func Random() (*Full, error) {
 	var rawSeed [32]byte
@@ -123,6 +123,17 @@ func (a *Account) GetData(key string) ([]byte, error) {
 	return base64.StdEncoding.DecodeString(a.Data[key])
 }
 
+// SignerSummary returns a map of the signers' keys of the account to their
+// weights. It can be used to verify SEP 10 challenges with
+// txnbuild.VerifyChallengeTxThreshold.
+func (a Account) SignerSummary() map[string]int32 {
+	m := map[string]int32{}
+	for _, s := range a.Signers {
+		m[s.Key] = s.Weight
+	}
+	return m
+}
+
 // AccountSigner is the account signer information.
 type AccountSigner struct {
 	Links struct {
@@ -250,6 +261,40 @@ type OrderBookSummary struct {
 	Buying  Asset        `json:"counter"`
 }
 
+// OrderBookDepth represents the cumulative depth of a given order book together
+// with the price impact of market orders of a given size
+type OrderBookDepth struct {
+	Bids       []DepthLevel `json:"bids"`
+	Asks       []DepthLevel `json:"asks"`
+	Selling    Asset        `json:"base"`
+	Buying     Asset        `json:"counter"`
+	SellImpact *PriceImpact `json:"sell_impact,omitempty"`
+	BuyImpact  *PriceImpact `json:"buy_impact,omitempty"`
+}
+
+// DepthLevel represents a price level of an order book together with the sum of
+// amounts of this level and all the levels with a better price
+type DepthLevel struct {
+	PriceLevel
+	CumulativeAmount string `json:"cumulative_amount"`
+}
+
+// PriceImpact represents the result of selling or buying a given amount of the
+// base asset of an order book at the best available prices.
+// All prices are expressed in units of the counter asset per unit of the base asset.
+type PriceImpact struct {
+	Amount        string `json:"amount"`
+	FilledAmount  string `json:"filled_amount"`
+	CounterAmount string `json:"counter_amount"`
+	Filled        bool   `json:"filled"`
+	AveragePrice  string `json:"average_price,omitempty"`
+	BestPrice     string `json:"best_price,omitempty"`
+	WorstPrice    string `json:"worst_price,omitempty"`
+	// PriceImpact is the relative difference between the average
+	// price and the best price, e.g. 0.0125 means 1.25%
+	PriceImpact string `json:"price_impact,omitempty"`
+}
+
 // Path represents a single payment path.
 type Path struct {
 	SourceAssetType        string  `json:"source_asset_type"`
@@ -268,6 +313,26 @@ func (p Path) PagingToken() string {
 	return ""
 }
 
+// SplitPath represents a payment which is divided across several payment paths.
+// SourceAmount and DestinationAmount are the totals for the combined payment
+// and Allocations contains the amounts routed through each individual path.
+type SplitPath struct {
+	SourceAssetType        string `json:"source_asset_type"`
+	SourceAssetCode        string `json:"source_asset_code,omitempty"`
+	SourceAssetIssuer      string `json:"source_asset_issuer,omitempty"`
+	SourceAmount           string `json:"source_amount"`
+	DestinationAssetType   string `json:"destination_asset_type"`
+	DestinationAssetCode   string `json:"destination_asset_code,omitempty"`
+	DestinationAssetIssuer string `json:"destination_asset_issuer,omitempty"`
+	DestinationAmount      string `json:"destination_amount"`
+	Allocations            []Path `json:"allocations"`
+}
+
+// stub implementation to satisfy pageable interface
+func (p SplitPath) PagingToken() string {
+	return ""
+}
+
 // Price represents a price
 type Price base.Price
 
@@ -650,3 +715,11 @@ type PathsPage struct {
 		Records []Path
 	} `json:"_embedded"`
 }
+
+// SplitPathsPage contains records of split payment paths found by aurora
+type SplitPathsPage struct {
+	Links    hal.Links `json:"_links"`
+	Embedded struct {
+		Records []SplitPath
+	} `json:"_embedded"`
+}
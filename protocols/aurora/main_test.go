@@ -49,6 +49,16 @@ func TestAccount_MustGetData(t *testing.T) {
 	assert.Panics(t, func() { exampleAccount.MustGetData("invalid") }, "panics on invalid input")
 }
 
+func TestAccount_SignerSummary(t *testing.T) {
+	account := Account{
+		Signers: []Signer{
+			{Key: "GA", Weight: 1, Type: "ed25519_public_key"},
+			{Key: "GB", Weight: 2, Type: "ed25519_public_key"},
+		},
+	}
+	assert.Equal(t, map[string]int32{"GA": 1, "GB": 2}, account.SignerSummary())
+}
+
 // Transaction Tests
 func TestTransactionJSONMarshal(t *testing.T) {
 	transaction := Transaction{
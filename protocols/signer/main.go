@@ -0,0 +1,26 @@
+// Package signer contains the types of the remote signing protocol, used to
+// delegate the signing of transactions to a signing server holding the seed.
+//
+// Transactions are signed by POSTing a SignRequest as JSON to the /sign
+// endpoint of the server, which responds with a SignResponse or, if it refuses
+// to sign, with a problem.
+package signer
+
+// SignRequest represents a request to sign a transaction.
+type SignRequest struct {
+	// Transaction is the base64 encoded XDR of the transaction to sign, not
+	// of its envelope.
+	Transaction string `json:"transaction"`
+	// NetworkPassphrase is the passphrase of the network the transaction is
+	// signed for.
+	NetworkPassphrase string `json:"network_passphrase"`
+}
+
+// SignResponse represents the signature of a transaction by a signing
+// server.
+type SignResponse struct {
+	// AccountID is the address of the key that signed the transaction.
+	AccountID string `json:"account_id"`
+	// Signature is the base64 encoded XDR of the decorated signature.
+	Signature string `json:"signature"`
+}
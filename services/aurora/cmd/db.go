@@ -12,7 +12,6 @@ import (
 	"github.com/spf13/viper"
 	"github.com/diamnet/go/services/aurora/internal/db2/schema"
 	"github.com/diamnet/go/services/aurora/internal/ingest"
-	"github.com/diamnet/go/services/aurora/internal/util"
 	"github.com/diamnet/go/support/db"
 	"github.com/diamnet/go/support/errors"
 	hlog "github.com/diamnet/go/support/log"
@@ -231,7 +230,7 @@ var dbReingestCmd = &cobra.Command{
 var dbReingestRangeCmd = &cobra.Command{
 	Use:   "range [Start sequence number] [End sequence number]",
 	Short: "reingests ledgers within a range",
-	Long:  "reingests ledgers between X and Y sequence number (closed intervals)",
+	Long:  "reingests ledgers between X and Y sequence number (closed intervals). The range is split into jobs reingested concurrently, each in its own database transaction.",
 	Run: func(cmd *cobra.Command, args []string) {
 		if len(args) != 2 {
 			cmd.Usage()
@@ -252,6 +251,8 @@ var dbReingestRangeCmd = &cobra.Command{
 	},
 }
 
+var reingestRangeConfig ingest.ParallelReingestConfig
+
 var dbReingestOutdatedCmd = &cobra.Command{
 	Use:   "outdated",
 	Short: "reingests all outdated ledgers",
@@ -278,6 +279,31 @@ func init() {
 		dbRebaseCmd,
 	)
 	dbReingestCmd.AddCommand(dbReingestRangeCmd, dbReingestOutdatedCmd)
+
+	dbReingestRangeCmd.Flags().IntVar(
+		&reingestRangeConfig.Workers,
+		"parallel-workers",
+		ingest.DefaultReingestWorkers,
+		"number of ledger ranges reingested concurrently",
+	)
+	dbReingestRangeCmd.Flags().Int32Var(
+		&reingestRangeConfig.ChunkSize,
+		"parallel-job-size",
+		ingest.DefaultReingestChunkSize,
+		"number of ledgers in a range reingested by a single worker in one database transaction",
+	)
+	dbReingestRangeCmd.Flags().IntVar(
+		&reingestRangeConfig.Retries,
+		"retries",
+		ingest.DefaultReingestRetries,
+		"number of times a failed range is retried",
+	)
+	dbReingestRangeCmd.Flags().StringVar(
+		&reingestRangeConfig.ProgressFile,
+		"progress-file",
+		"",
+		"file where completed ranges are recorded, rerunning the command with the same file skips them",
+	)
 }
 
 func ingestSystem(ingestConfig ingest.Config) *ingest.System {
@@ -368,73 +394,11 @@ func reingest(cmd reingestType, args ...int32) {
 	}
 }
 
-type ledgerRange struct {
-	from, to int32
-}
-
 func reingestRange(i *ingest.System, from, to int32) error {
 	if to < from {
 		return errors.New("Invalid range")
 	}
 
-	var (
-		size    int32 = 10000
-		workers int   = 10
-	)
-
-	var pool util.WorkersPool
-	hlog.Info("Creating work...")
-	for current := from; current <= to; current += size {
-		lr := ledgerRange{from: current, to: current + size - 1}
-		if lr.to > to {
-			lr.to = to
-		}
-		pool.AddWork(lr)
-	}
-
-	allJobs := pool.WorkSize()
-
-	pool.SetWorker(func(workerID int, job interface{}) {
-		lr, ok := job.(ledgerRange)
-		if !ok {
-			hlog.Error("job is not a ledgerRange")
-			os.Exit(1)
-		}
-
-		localLog := hlog.WithFields(hlog.F{
-			"id":   workerID,
-			"from": lr.from,
-			"to":   lr.to,
-		})
-
-		localLog.Info("Worker starting range...")
-
-		_, err := i.ReingestRange(lr.from, lr.to)
-		if err != nil {
-			localLog.WithField("err", err).Error("Worker failed range, work will be processed again")
-			// Add the work again
-			pool.AddWork(lr)
-			return
-		}
-		localLog.Info("Worker finished range")
-	})
-
-	hlog.Infof("Starting %d workers...", workers)
-	done := make(chan bool)
-	go func() {
-		ticker := time.NewTicker(10 * time.Second)
-		for {
-			select {
-			case <-done:
-				ticker.Stop()
-				return
-			case <-ticker.C:
-			}
-			hlog.WithField("progress", float32(allJobs-pool.WorkSize())/float32(allJobs)*100).Info("Work status")
-		}
-	}()
-	pool.Start(workers)
-	done <- true
-	hlog.Info("Done")
-	return nil
+	_, err := i.ReingestRangeParallel(from, to, reingestRangeConfig)
+	return err
 }
@@ -12,10 +12,10 @@ import (
 	"github.com/spf13/viper"
 	aurora "github.com/diamnet/go/services/aurora/internal"
 	"github.com/diamnet/go/services/aurora/internal/db2/schema"
+	"github.com/diamnet/go/services/aurora/internal/ratelimit"
 	apkg "github.com/diamnet/go/support/app"
 	support "github.com/diamnet/go/support/config"
 	"github.com/diamnet/go/support/log"
-	"github.com/throttled/throttled"
 )
 
 var config aurora.Config
@@ -105,6 +105,13 @@ var configOpts = []*support.ConfigOption{
 		FlagDefault: uint(8000),
 		Usage:       "tcp port to listen on for http requests",
 	},
+	&support.ConfigOption{
+		Name:        "admin-port",
+		ConfigKey:   &config.AdminPort,
+		OptType:     types.Uint,
+		FlagDefault: uint(0),
+		Usage:       "WARNING: this should not be accessible from the Internet and does not use TLS, tcp port to listen on for admin requests, serving metrics in the Prometheus format at /metrics, 0 (default) disables the admin server",
+	},
 	&support.ConfigOption{
 		Name:        "max-db-connections",
 		ConfigKey:   &config.MaxDBConnections,
@@ -146,7 +153,7 @@ var configOpts = []*support.ConfigOption{
 		OptType:        types.Int,
 		FlagDefault:    5,
 		CustomSetValue: support.SetDuration,
-		Usage:          "defines how often streams should check if there's a new ledger (in seconds), may need to increase in case of big number of streams",
+		Usage:          "defines the minimum interval between two updates of a single stream (in seconds), may need to increase in case of big number of streams",
 	},
 	&support.ConfigOption{
 		Name:           "connection-timeout",
@@ -162,23 +169,32 @@ var configOpts = []*support.ConfigOption{
 		OptType:     types.Int,
 		FlagDefault: 3600,
 		CustomSetValue: func(co *support.ConfigOption) {
-			var rateLimit *throttled.RateQuota = nil
 			perHourRateLimit := viper.GetInt(co.Name)
 			if perHourRateLimit != 0 {
-				rateLimit = &throttled.RateQuota{
-					MaxRate:  throttled.PerHour(perHourRateLimit),
-					MaxBurst: 100,
-				}
-				*(co.ConfigKey.(**throttled.RateQuota)) = rateLimit
+				rateLimit := ratelimit.PerHour(perHourRateLimit, 100)
+				*(co.ConfigKey.(**ratelimit.Quota)) = &rateLimit
 			}
 		},
-		Usage: "max count of requests allowed in a one hour period, by remote ip address",
+		Usage: "max count of requests allowed in a one hour period, by remote ip address, for requests without an API key",
+	},
+	&support.ConfigOption{
+		Name:        "per-ip-max-streams",
+		ConfigKey:   &config.MaxStreamsPerIP,
+		OptType:     types.Int,
+		FlagDefault: 0,
+		Usage:       "max count of concurrent streams, by remote ip address, for requests without an API key, 0 (default) for no limit",
+	},
+	&support.ConfigOption{
+		Name:      "rate-limit-config",
+		ConfigKey: &config.RateLimitConfigPath,
+		OptType:   types.String,
+		Usage:     "path of a TOML file configuring the API keys, with their own rate limits and concurrent streams limits, and the costs of routes counted against the rate limits",
 	},
 	&support.ConfigOption{
 		Name:      "rate-limit-redis-key",
 		ConfigKey: &config.RateLimitRedisKey,
 		OptType:   types.String,
-		Usage:     "redis key for storing rate limit data, useful when deploying a cluster of Auroras, ignored when redis-url is empty",
+		Usage:     "prefix of the redis keys storing rate limit data, useful when deploying a cluster of Auroras to share the rate limits and concurrent streams limits, ignored when redis-url is empty",
 	},
 	&support.ConfigOption{
 		Name:      "redis-url",
@@ -272,6 +288,13 @@ var configOpts = []*support.ConfigOption{
 		FlagDefault: false,
 		Usage:       "causes this aurora process to ingest failed transactions data",
 	},
+	&support.ConfigOption{
+		Name:        "stream-updates-postgres",
+		ConfigKey:   &config.StreamUpdatesPostgres,
+		OptType:     types.Bool,
+		FlagDefault: false,
+		Usage:       "sends notifications about ingested ledgers using Postgres LISTEN/NOTIFY so streams served by non-ingesting aurora instances are updated immediately, must be set on all instances sharing the database",
+	},
 	&support.ConfigOption{
 		Name:        "cursor-name",
 		EnvVar:      "CURSOR_NAME",
@@ -322,6 +345,27 @@ var configOpts = []*support.ConfigOption{
 		FlagDefault: "memory",
 		Usage:       "defines where to store temporary objects during state ingestion: `memory` (default, more RAM usage, faster) or `postgres` (less RAM usage, slower)",
 	},
+	&support.ConfigOption{
+		Name:        "orderbook-snapshot-path",
+		ConfigKey:   &config.OrderBookSnapshotPath,
+		OptType:     types.String,
+		FlagDefault: "",
+		Usage:       "[EXPERIMENTAL] path of a file where the in memory order book is saved periodically and on shutdown, and restored from on startup. Snapshots are disabled when empty",
+	},
+	&support.ConfigOption{
+		Name:        "history-archive-cache-dir",
+		ConfigKey:   &config.HistoryArchiveCacheDir,
+		OptType:     types.String,
+		FlagDefault: "",
+		Usage:       "[EXPERIMENTAL] local directory where the files read from history archives during state ingestion are cached, so restarting ingestion does not download them again. Caching is disabled when empty",
+	},
+	&support.ConfigOption{
+		Name:        "history-archive-quorum",
+		ConfigKey:   &config.HistoryArchiveQuorum,
+		OptType:     types.Int,
+		FlagDefault: 1,
+		Usage:       "[EXPERIMENTAL] number of history archives (from history-archive-urls) which must publish the same history archive state before it's used for state ingestion. Other files are read from any archive, trying the next one on errors. It can not be greater than the number of history archives",
+	},
 }
 
 func init() {
@@ -10,6 +10,7 @@ import (
 	"github.com/diamnet/go/services/aurora/internal/db2"
 	"github.com/diamnet/go/services/aurora/internal/db2/core"
 	"github.com/diamnet/go/services/aurora/internal/db2/history"
+	"github.com/diamnet/go/services/aurora/internal/pubsub"
 	"github.com/diamnet/go/services/aurora/internal/test"
 	"github.com/diamnet/go/support/errors"
 	"github.com/diamnet/go/support/render/hal"
@@ -25,7 +26,7 @@ func TestGetAccountInfo(t *testing.T) {
 	tt := test.Start(t).Scenario("allow_trust")
 	defer tt.Finish()
 
-	w := mustInitWeb(context.Background(), &history.Q{tt.AuroraSession()}, &core.Q{tt.CoreSession()}, time.Duration(5), 0, true)
+	w := mustInitWeb(context.Background(), &history.Q{tt.AuroraSession()}, &core.Q{tt.CoreSession()}, pubsub.NewHub(), time.Duration(5), 0, true)
 
 	res, err := w.getAccountInfo(tt.Ctx, &showActionQueryParams{AccountID: "GCXKG6RN4ONIEPCMNFB732A436Z5PNDSRLGWK7GBLCMQLIFO4S7EYWVU"})
 	tt.Assert.NoError(err)
@@ -55,7 +56,7 @@ func TestGetTransactionPage(t *testing.T) {
 	defer tt.Finish()
 
 	ctx := context.Background()
-	w := mustInitWeb(ctx, &history.Q{tt.AuroraSession()}, &core.Q{tt.CoreSession()}, time.Duration(5), 0, true)
+	w := mustInitWeb(ctx, &history.Q{tt.AuroraSession()}, &core.Q{tt.CoreSession()}, pubsub.NewHub(), time.Duration(5), 0, true)
 
 	// filter by account
 	params := &indexActionQueryParams{
@@ -9,7 +9,8 @@ import (
 	"time"
 
 	auroraContext "github.com/diamnet/go/services/aurora/internal/context"
-	"github.com/diamnet/go/services/aurora/internal/ledger"
+	"github.com/diamnet/go/services/aurora/internal/pubsub"
+	"github.com/diamnet/go/services/aurora/internal/ratelimit"
 	"github.com/diamnet/go/services/aurora/internal/render"
 	hProblem "github.com/diamnet/go/services/aurora/internal/render/problem"
 	"github.com/diamnet/go/services/aurora/internal/render/sse"
@@ -69,21 +70,42 @@ func (base *Base) Execute(action interface{}) {
 
 		stream := sse.NewStream(ctx, base.W)
 
+		// Subscribe before the first query so no ledger is missed. The filter
+		// matches all ledgers until the action knows what data it streams.
+		app := base.R.Context().Value(&auroraContext.AppContextKey)
+		updates := app.(LedgerUpdatesProvider).GetLedgerUpdates().Subscribe(pubsub.Filter{})
+		defer updates.Close()
+
+		// Count the stream against the concurrent streams limit of the client
+		// until it's closed.
+		rateLimiter := app.(RateLimiterProvider).GetRateLimiter()
+		client, _ := ratelimit.ClientFromContext(base.R.Context())
+		if rateLimiter != nil {
+			ok, release, err := rateLimiter.StartStream(client)
+			if err != nil {
+				stream.Err(errors.Wrap(err, "RateLimiter error"))
+				return
+			}
+			if !ok {
+				stream.Err(sse.ErrTooManyStreams)
+				return
+			}
+			defer release()
+		}
+
 		var oldHash [32]byte
 		for {
-			lastLedgerState := ledger.CurrentState()
+			lastUpdate := time.Now()
 
 			// Rate limit the request if it's a call to stream since it queries the DB every second. See
 			// https://github.com/diamnet/go/issues/715 for more details.
-			app := base.R.Context().Value(&auroraContext.AppContextKey)
-			rateLimiter := app.(RateLimiterProvider).GetRateLimiter()
 			if rateLimiter != nil {
-				limited, _, err := rateLimiter.RateLimiter.RateLimit(rateLimiter.VaryBy.Key(base.R), 1)
+				result, err := rateLimiter.RateLimit(client, 1)
 				if err != nil {
 					stream.Err(errors.Wrap(err, "RateLimiter error"))
 					return
 				}
-				if limited {
+				if result != nil && result.Limited {
 					stream.Err(sse.ErrRateLimited)
 					return
 				}
@@ -128,23 +150,16 @@ func (base *Base) Execute(action interface{}) {
 				return
 			}
 
-			// Make sure this is buffered channel of size 1. Otherwise, the go routine below
-			// will never return if `newLedgers` channel is not read. From Effective Go:
-			// > If the channel is unbuffered, the sender blocks until the receiver has received the value.
-			newLedgers := make(chan bool, 1)
-			go func() {
-				for {
-					time.Sleep(base.sseUpdateFrequency)
-					currentLedgerState := ledger.CurrentState()
-					if currentLedgerState.HistoryLatest >= lastLedgerState.HistoryLatest+1 {
-						newLedgers <- true
-						return
-					}
-				}
-			}()
+			if filterer, ok := action.(StreamFilterer); ok {
+				updates.SetFilter(filterer.StreamFilter())
+			}
 
 			select {
-			case <-newLedgers:
+			case <-updates.Updates():
+				// Do not reload a single stream more often than sseUpdateFrequency.
+				if wait := base.sseUpdateFrequency - time.Since(lastUpdate); wait > 0 {
+					time.Sleep(wait)
+				}
 				continue
 			case <-ctx.Done():
 			case <-base.appCtx.Done():
@@ -0,0 +1,9 @@
+package actions
+
+import "github.com/diamnet/go/services/aurora/internal/pubsub"
+
+// LedgerUpdatesProvider is an interface that provides access to the hub
+// notifying streams about ingested ledgers.
+type LedgerUpdatesProvider interface {
+	GetLedgerUpdates() *pubsub.Hub
+}
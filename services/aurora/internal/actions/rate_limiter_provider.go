@@ -1,8 +1,8 @@
 package actions
 
-import "github.com/throttled/throttled"
+import "github.com/diamnet/go/services/aurora/internal/ratelimit"
 
-// RateLimiterProvider is an interface that provides access to the type's HTTPRateLimiter.
+// RateLimiterProvider is an interface that provides access to the type's rate limiter.
 type RateLimiterProvider interface {
-	GetRateLimiter() *throttled.HTTPRateLimiter
+	GetRateLimiter() *ratelimit.Limiter
 }
@@ -1,6 +1,9 @@
 package actions
 
-import "github.com/diamnet/go/services/aurora/internal/render/sse"
+import (
+	"github.com/diamnet/go/services/aurora/internal/pubsub"
+	"github.com/diamnet/go/services/aurora/internal/render/sse"
+)
 
 // JSONer implementors can respond to a request whose response type was negotiated
 // to be MimeHal or MimeJSON.
@@ -26,3 +29,10 @@ type EventStreamer interface {
 type SingleObjectStreamer interface {
 	LoadEvent() (sse.Event, error)
 }
+
+// StreamFilterer implementors describe the data streamed to the client so the
+// stream is only reloaded when a ledger affecting that data is ingested.
+// StreamFilter is called after the first call to SSE or LoadEvent.
+type StreamFilterer interface {
+	StreamFilter() pubsub.Filter
+}
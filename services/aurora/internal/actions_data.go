@@ -3,6 +3,7 @@ package aurora
 import (
 	"github.com/diamnet/go/services/aurora/internal/actions"
 	"github.com/diamnet/go/services/aurora/internal/db2/core"
+	"github.com/diamnet/go/services/aurora/internal/pubsub"
 	"github.com/diamnet/go/services/aurora/internal/render/sse"
 	"github.com/diamnet/go/support/render/hal"
 )
@@ -11,6 +12,7 @@ import (
 var _ actions.JSONer = (*DataShowAction)(nil)
 var _ actions.RawDataResponder = (*DataShowAction)(nil)
 var _ actions.EventStreamer = (*DataShowAction)(nil)
+var _ actions.StreamFilterer = (*DataShowAction)(nil)
 
 // DataShowAction renders a account summary found by its address.
 type DataShowAction struct {
@@ -60,6 +62,11 @@ func (action *DataShowAction) SSE(stream *sse.Stream) error {
 	return action.Err
 }
 
+// StreamFilter is a method for actions.StreamFilterer
+func (action *DataShowAction) StreamFilter() pubsub.Filter {
+	return pubsub.Filter{Account: action.Address}
+}
+
 func (action *DataShowAction) loadParams() {
 	action.Address = action.GetAddress("account_id", actions.RequiredParam)
 	action.Key = action.GetString("key")
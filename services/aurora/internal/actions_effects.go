@@ -7,6 +7,7 @@ import (
 	"github.com/diamnet/go/services/aurora/internal/actions"
 	"github.com/diamnet/go/services/aurora/internal/db2"
 	"github.com/diamnet/go/services/aurora/internal/db2/history"
+	"github.com/diamnet/go/services/aurora/internal/pubsub"
 	"github.com/diamnet/go/services/aurora/internal/render/sse"
 	"github.com/diamnet/go/services/aurora/internal/resourceadapter"
 	"github.com/diamnet/go/support/errors"
@@ -21,6 +22,7 @@ import (
 // Interface verifications
 var _ actions.JSONer = (*EffectIndexAction)(nil)
 var _ actions.EventStreamer = (*EffectIndexAction)(nil)
+var _ actions.StreamFilterer = (*EffectIndexAction)(nil)
 
 // EffectIndexAction renders a page of effect resources, identified by
 // a normal page query and optionally filtered by an account, ledger,
@@ -91,6 +93,11 @@ func (action *EffectIndexAction) SSE(stream *sse.Stream) error {
 	return action.Err
 }
 
+// StreamFilter is a method for actions.StreamFilterer
+func (action *EffectIndexAction) StreamFilter() pubsub.Filter {
+	return pubsub.Filter{Account: action.AccountFilter}
+}
+
 // loadLedgers populates the ledger cache for this action
 func (action *EffectIndexAction) loadLedgers() {
 	action.Ledgers = &history.LedgerCache{}
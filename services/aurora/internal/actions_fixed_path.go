@@ -22,9 +22,12 @@ type FixedPathIndexAction struct {
 	sourceAsset      xdr.Asset
 	amountToSpend    xdr.Int64
 	destinationAsset xdr.Asset
+	// split is true when the payment should be divided across several paths
+	split bool
 
-	Records []paths.Path
-	Page    hal.BasePage
+	Records      []paths.Path
+	SplitRecords []paths.SplitPath
+	Page         hal.BasePage
 }
 
 // JSON implements actions.JSON
@@ -48,16 +51,28 @@ func (action *FixedPathIndexAction) loadQuery() {
 	action.destinationAsset = action.GetAsset("destination_")
 	action.sourceAsset = action.GetAsset("source_")
 	action.amountToSpend = action.GetPositiveAmount("source_amount")
+	action.split = action.GetBool("split")
 }
 
 func (action *FixedPathIndexAction) loadRecords() {
-	action.Records, action.Err = action.App.paths.FindFixedPaths(
-		action.sourceAccount,
-		action.sourceAsset,
-		action.amountToSpend,
-		action.destinationAsset,
-		action.App.config.MaxPathLength,
-	)
+	if action.split {
+		action.SplitRecords, action.Err = action.App.paths.FindFixedSplitPaths(
+			action.sourceAccount,
+			action.sourceAsset,
+			action.amountToSpend,
+			action.destinationAsset,
+			action.App.config.MaxPathLength,
+			0,
+		)
+	} else {
+		action.Records, action.Err = action.App.paths.FindFixedPaths(
+			action.sourceAccount,
+			action.sourceAsset,
+			action.amountToSpend,
+			action.destinationAsset,
+			action.App.config.MaxPathLength,
+		)
+	}
 	if action.Err == simplepath.ErrEmptyInMemoryOrderBook {
 		action.Err = problem.StillIngesting
 	}
@@ -65,6 +80,19 @@ func (action *FixedPathIndexAction) loadRecords() {
 
 func (action *FixedPathIndexAction) loadPage() {
 	action.Page.Init()
+	if action.split {
+		for _, p := range action.SplitRecords {
+			var res aurora.SplitPath
+			action.Err = resourceadapter.PopulateSplitPath(action.R.Context(), &res, p)
+
+			if action.Err != nil {
+				return
+			}
+			action.Page.Add(res)
+		}
+		return
+	}
+
 	for _, p := range action.Records {
 		var res aurora.Path
 		action.Err = resourceadapter.PopulatePath(action.R.Context(), &res, p)
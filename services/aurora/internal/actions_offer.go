@@ -6,6 +6,7 @@ import (
 	"github.com/diamnet/go/services/aurora/internal/db2"
 	"github.com/diamnet/go/services/aurora/internal/db2/core"
 	"github.com/diamnet/go/services/aurora/internal/db2/history"
+	"github.com/diamnet/go/services/aurora/internal/pubsub"
 	"github.com/diamnet/go/services/aurora/internal/render/sse"
 	"github.com/diamnet/go/services/aurora/internal/resourceadapter"
 	"github.com/diamnet/go/support/render/hal"
@@ -16,6 +17,7 @@ import (
 // Interface verifications
 var _ actions.JSONer = (*OffersByAccountAction)(nil)
 var _ actions.EventStreamer = (*OffersByAccountAction)(nil)
+var _ actions.StreamFilterer = (*OffersByAccountAction)(nil)
 
 // OffersByAccountAction renders a page of offer resources, for a given
 // account.  These offers are present in the ledger as of the latest validated
@@ -74,6 +76,11 @@ func (action *OffersByAccountAction) SSE(stream *sse.Stream) error {
 	return action.Err
 }
 
+// StreamFilter is a method for actions.StreamFilterer
+func (action *OffersByAccountAction) StreamFilter() pubsub.Filter {
+	return pubsub.Filter{Account: action.Address}
+}
+
 func (action *OffersByAccountAction) loadParams() {
 	action.PageQuery = action.GetPageQuery()
 	action.Address = action.GetAddress("account_id")
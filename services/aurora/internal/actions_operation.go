@@ -8,6 +8,7 @@ import (
 	"github.com/diamnet/go/services/aurora/internal/db2"
 	"github.com/diamnet/go/services/aurora/internal/db2/history"
 	"github.com/diamnet/go/services/aurora/internal/ledger"
+	"github.com/diamnet/go/services/aurora/internal/pubsub"
 	"github.com/diamnet/go/services/aurora/internal/render/problem"
 	"github.com/diamnet/go/services/aurora/internal/render/sse"
 	"github.com/diamnet/go/services/aurora/internal/resourceadapter"
@@ -26,6 +27,7 @@ import (
 // Interface verifications
 var _ actions.JSONer = (*OperationIndexAction)(nil)
 var _ actions.EventStreamer = (*OperationIndexAction)(nil)
+var _ actions.StreamFilterer = (*OperationIndexAction)(nil)
 
 const (
 	joinTransactions = "transactions"
@@ -109,6 +111,11 @@ func (action *OperationIndexAction) SSE(stream *sse.Stream) error {
 	return action.Err
 }
 
+// StreamFilter is a method for actions.StreamFilterer
+func (action *OperationIndexAction) StreamFilter() pubsub.Filter {
+	return pubsub.Filter{Account: action.AccountFilter}
+}
+
 func parseJoinField(action *actions.Base) (map[string]bool, error) {
 	join := action.GetString("join")
 	validJoins := map[string]bool{}
@@ -3,9 +3,12 @@ package aurora
 import (
 	"net/http"
 
+	"github.com/diamnet/go/exp/orderbook"
 	"github.com/diamnet/go/protocols/aurora"
 	"github.com/diamnet/go/services/aurora/internal/actions"
 	"github.com/diamnet/go/services/aurora/internal/db2/core"
+	"github.com/diamnet/go/services/aurora/internal/pubsub"
+	hProblem "github.com/diamnet/go/services/aurora/internal/render/problem"
 	"github.com/diamnet/go/services/aurora/internal/render/sse"
 	"github.com/diamnet/go/services/aurora/internal/resourceadapter"
 	"github.com/diamnet/go/support/render/hal"
@@ -16,15 +19,32 @@ import (
 // Interface verifications
 var _ actions.JSONer = (*OrderBookShowAction)(nil)
 var _ actions.SingleObjectStreamer = (*OrderBookShowAction)(nil)
+var _ actions.StreamFilterer = (*OrderBookShowAction)(nil)
+var _ actions.JSONer = (*OrderBookDepthAction)(nil)
+
+var invalidOrderBookProblem = problem.P{
+	Type:   "invalid_order_book",
+	Title:  "Invalid Order Book Parameters",
+	Status: http.StatusBadRequest,
+	Detail: "The parameters that specify what order book to view are invalid in some way. " +
+		"Please ensure that your type parameters (selling_asset_type and buying_asset_type) are one the " +
+		"following valid values: native, credit_alphanum4, credit_alphanum12.  Also ensure that you " +
+		"have specified selling_asset_code and selling_asset_issuer if selling_asset_type is not 'native', as well " +
+		"as buying_asset_code and buying_asset_issuer if buying_asset_type is not 'native'",
+}
 
 // OrderBookShowAction renders a account summary found by its address.
+// If the experimental ingestion is enabled and the in memory order book is
+// populated the summary is computed from the in memory order book instead of
+// diamnet-core's database.
 type OrderBookShowAction struct {
 	Action
-	Selling  xdr.Asset
-	Buying   xdr.Asset
-	Record   core.OrderBookSummary
-	Resource aurora.OrderBookSummary
-	Limit    uint64
+	Selling        xdr.Asset
+	Buying         xdr.Asset
+	Record         core.OrderBookSummary
+	InMemoryRecord *orderbook.OrderBookSummary
+	Resource       aurora.OrderBookSummary
+	Limit          uint64
 }
 
 // LoadQuery sets action.Query from the request params
@@ -34,21 +54,23 @@ func (action *OrderBookShowAction) LoadQuery() {
 	action.Limit = action.GetLimit("limit", 20, 200)
 
 	if action.Err != nil {
-		action.Err = &problem.P{
-			Type:   "invalid_order_book",
-			Title:  "Invalid Order Book Parameters",
-			Status: http.StatusBadRequest,
-			Detail: "The parameters that specify what order book to view are invalid in some way. " +
-				"Please ensure that your type parameters (selling_asset_type and buying_asset_type) are one the " +
-				"following valid values: native, credit_alphanum4, credit_alphanum12.  Also ensure that you " +
-				"have specified selling_asset_code and selling_asset_issuer if selling_asset_type is not 'native', as well " +
-				"as buying_asset_code and buying_asset_issuer if buying_asset_type is not 'native'",
-		}
+		action.Err = &invalidOrderBookProblem
 	}
 }
 
-// LoadRecord populates action.Record
+// LoadRecord populates action.Record or action.InMemoryRecord
 func (action *OrderBookShowAction) LoadRecord() {
+	if graph := action.App.orderBookGraph; graph != nil && !graph.IsEmpty() {
+		var summary orderbook.OrderBookSummary
+		summary, action.Err = graph.OrderBookSummary(
+			action.Selling,
+			action.Buying,
+			int(action.Limit),
+		)
+		action.InMemoryRecord = &summary
+		return
+	}
+
 	action.Err = action.CoreQ().GetOrderBookSummary(
 		&action.Record,
 		action.Selling,
@@ -57,8 +79,19 @@ func (action *OrderBookShowAction) LoadRecord() {
 	)
 }
 
-// LoadResource populates action.Record
+// LoadResource populates action.Resource
 func (action *OrderBookShowAction) LoadResource() {
+	if action.InMemoryRecord != nil {
+		action.Err = resourceadapter.PopulateInMemoryOrderBookSummary(
+			action.R.Context(),
+			&action.Resource,
+			action.Selling,
+			action.Buying,
+			*action.InMemoryRecord,
+		)
+		return
+	}
+
 	action.Err = resourceadapter.PopulateOrderBookSummary(
 		action.R.Context(),
 		&action.Resource,
@@ -83,3 +116,96 @@ func (action *OrderBookShowAction) LoadEvent() (sse.Event, error) {
 	action.Do(action.LoadQuery, action.LoadRecord, action.LoadResource)
 	return sse.Event{Data: action.Resource}, action.Err
 }
+
+// StreamFilter is a method for actions.StreamFilterer
+func (action *OrderBookShowAction) StreamFilter() pubsub.Filter {
+	return pubsub.Filter{
+		Assets: []string{action.Selling.String(), action.Buying.String()},
+	}
+}
+
+// OrderBookDepthAction renders the cumulative depth of an order book and, if
+// an amount is provided, the price impact of selling and buying that amount of
+// the base asset. It is served from the in memory order book.
+type OrderBookDepthAction struct {
+	Action
+	Selling    xdr.Asset
+	Buying     xdr.Asset
+	Amount     xdr.Int64
+	Limit      uint64
+	Record     orderbook.OrderBookSummary
+	SellImpact *orderbook.TradeImpact
+	BuyImpact  *orderbook.TradeImpact
+	Resource   aurora.OrderBookDepth
+}
+
+// LoadQuery sets action.Query from the request params
+func (action *OrderBookDepthAction) LoadQuery() {
+	action.Selling = action.GetAsset("selling_")
+	action.Buying = action.GetAsset("buying_")
+	action.Limit = action.GetLimit("limit", 20, 200)
+
+	if action.Err != nil {
+		action.Err = &invalidOrderBookProblem
+		return
+	}
+
+	if action.GetString("amount") != "" {
+		action.Amount = action.GetPositiveAmount("amount")
+	}
+}
+
+// LoadRecord populates action.Record and the price impacts
+func (action *OrderBookDepthAction) LoadRecord() {
+	graph := action.App.orderBookGraph
+	if graph == nil || graph.IsEmpty() {
+		action.Err = hProblem.StillIngesting
+		return
+	}
+
+	action.Record, action.Err = graph.OrderBookSummary(
+		action.Selling,
+		action.Buying,
+		int(action.Limit),
+	)
+	if action.Err != nil || action.Amount == 0 {
+		return
+	}
+
+	var sellImpact, buyImpact orderbook.TradeImpact
+	sellImpact, action.Err = graph.SellImpact(action.Selling, action.Buying, action.Amount)
+	if action.Err != nil {
+		return
+	}
+	buyImpact, action.Err = graph.BuyImpact(action.Buying, action.Selling, action.Amount)
+	if action.Err != nil {
+		return
+	}
+	action.SellImpact = &sellImpact
+	action.BuyImpact = &buyImpact
+}
+
+// LoadResource populates action.Resource
+func (action *OrderBookDepthAction) LoadResource() {
+	action.Err = resourceadapter.PopulateOrderBookDepth(
+		action.R.Context(),
+		&action.Resource,
+		action.Selling,
+		action.Buying,
+		action.Record,
+		action.Amount,
+		action.SellImpact,
+		action.BuyImpact,
+	)
+}
+
+// JSON is a method for actions.JSON
+func (action *OrderBookDepthAction) JSON() error {
+	action.Do(
+		action.LoadQuery,
+		action.LoadRecord,
+		action.LoadResource,
+		func() { hal.Render(action.W, action.Resource) },
+	)
+	return action.Err
+}
@@ -4,7 +4,14 @@ import (
 	"encoding/json"
 	"testing"
 
+	sq "github.com/Masterminds/squirrel"
+	"github.com/diamnet/go/exp/orderbook"
 	"github.com/diamnet/go/protocols/aurora"
+	"github.com/diamnet/go/services/aurora/internal/db2/core"
+	"github.com/diamnet/go/services/aurora/internal/db2/history"
+	"github.com/diamnet/go/services/aurora/internal/render/problem"
+	"github.com/diamnet/go/services/aurora/internal/test"
+	"github.com/diamnet/go/xdr"
 )
 
 func TestOrderBookActions_Show(t *testing.T) {
@@ -118,3 +125,119 @@ func TestOrderBookActions_Show(t *testing.T) {
 		ht.Assert.Equal("10.0000000", result.Bids[0].Amount)
 	}
 }
+
+func loadOrderBookOffers(tt *test.T, orderBookGraph *orderbook.OrderBookGraph) {
+	coreQ := &core.Q{Session: tt.CoreSession()}
+	offers := []core.Offer{}
+	tt.Assert.NoError(coreQ.Select(&offers, sq.Select("*").From("offers")))
+	for _, offer := range offers {
+		orderBookGraph.AddOffer(xdr.OfferEntry{
+			SellerId: xdr.MustAddress(offer.SellerID),
+			OfferId:  xdr.Int64(offer.OfferID),
+			Selling:  offer.SellingAsset,
+			Buying:   offer.BuyingAsset,
+			Amount:   offer.Amount,
+			Price:    xdr.Price{N: xdr.Int32(offer.Pricen), D: xdr.Int32(offer.Priced)},
+		})
+	}
+	tt.Assert.NoError(orderBookGraph.Apply())
+}
+
+func TestOrderBookActions_ShowInMemory(t *testing.T) {
+	ht := StartHTTPTest(t, "order_books")
+	defer ht.Finish()
+
+	urls := []string{
+		"/order_book?selling_asset_type=native&buying_asset_type=native",
+		"/order_book?selling_asset_type=native&buying_asset_type=credit_alphanum4&buying_asset_code=USD&buying_asset_issuer=GC23QF2HUE52AMXUFUH3AYJAXXGXXV2VHXYYR6EYXETPKDXZSAW67XO4",
+		"/order_book?selling_asset_type=native&buying_asset_type=credit_alphanum4&buying_asset_code=USD&buying_asset_issuer=GC23QF2HUE52AMXUFUH3AYJAXXGXXV2VHXYYR6EYXETPKDXZSAW67XO4&limit=1",
+	}
+
+	expected := []aurora.OrderBookSummary{}
+	for _, url := range urls {
+		var result aurora.OrderBookSummary
+		w := ht.Get(url)
+		ht.Require.Equal(200, w.Code)
+		ht.Require.NoError(json.Unmarshal(w.Body.Bytes(), &result))
+		expected = append(expected, result)
+	}
+
+	orderBookGraph := orderbook.NewOrderBookGraph()
+	loadOrderBookOffers(ht.T, orderBookGraph)
+	ht.App.orderBookGraph = orderBookGraph
+
+	for i, url := range urls {
+		var result aurora.OrderBookSummary
+		w := ht.Get(url)
+		ht.Require.Equal(200, w.Code)
+		ht.Require.NoError(json.Unmarshal(w.Body.Bytes(), &result))
+		ht.Assert.Equal(expected[i], result)
+	}
+}
+
+func TestOrderBookActions_Depth(t *testing.T) {
+	ht := StartHTTPTest(t, "order_books")
+	ht.App.config.EnableExperimentalIngestion = true
+	defer ht.Finish()
+	q := &history.Q{Session: ht.AuroraSession()}
+	ht.Assert.NoError(q.UpdateLastLedgerExpIngest(3))
+
+	url := "/order_book/depth?selling_asset_type=native&buying_asset_type=credit_alphanum4&buying_asset_code=USD&buying_asset_issuer=GC23QF2HUE52AMXUFUH3AYJAXXGXXV2VHXYYR6EYXETPKDXZSAW67XO4"
+
+	// in memory order book is not populated yet
+	w := ht.Get(url)
+	ht.Assert.Equal(problem.StillIngesting.Status, w.Code)
+
+	orderBookGraph := orderbook.NewOrderBookGraph()
+	loadOrderBookOffers(ht.T, orderBookGraph)
+	ht.App.orderBookGraph = orderBookGraph
+
+	w = ht.Get(url + "&amount=abc")
+	ht.Assert.Equal(400, w.Code)
+
+	var result aurora.OrderBookDepth
+	w = ht.Get(url)
+	if ht.Assert.Equal(200, w.Code) {
+		ht.Require.NoError(json.Unmarshal(w.Body.Bytes(), &result))
+		ht.Require.Len(result.Asks, 3)
+		ht.Require.Len(result.Bids, 3)
+
+		ht.Assert.Equal("100.0000000", result.Asks[0].CumulativeAmount)
+		ht.Assert.Equal("1000.0000000", result.Asks[1].CumulativeAmount)
+		ht.Assert.Equal("6000.0000000", result.Asks[2].CumulativeAmount)
+		ht.Assert.Equal("10.0000000", result.Bids[0].CumulativeAmount)
+		ht.Assert.Equal("110.0000000", result.Bids[1].CumulativeAmount)
+		ht.Assert.Equal("1110.0000000", result.Bids[2].CumulativeAmount)
+		ht.Assert.Nil(result.SellImpact)
+		ht.Assert.Nil(result.BuyImpact)
+	}
+
+	result = aurora.OrderBookDepth{}
+	w = ht.Get(url + "&limit=1&amount=50")
+	if ht.Assert.Equal(200, w.Code) {
+		ht.Require.NoError(json.Unmarshal(w.Body.Bytes(), &result))
+		ht.Require.Len(result.Asks, 1)
+		ht.Require.Len(result.Bids, 1)
+
+		// buying 50 units of the native asset is filled by the first ask level
+		ht.Require.NotNil(result.BuyImpact)
+		ht.Assert.True(result.BuyImpact.Filled)
+		ht.Assert.Equal("50.0000000", result.BuyImpact.FilledAmount)
+		ht.Assert.Equal(result.Asks[0].Price, result.BuyImpact.BestPrice)
+		ht.Assert.Equal("0.0000000", result.BuyImpact.PriceImpact)
+
+		ht.Require.NotNil(result.SellImpact)
+		ht.Assert.Equal("50.0000000", result.SellImpact.Amount)
+	}
+
+	// there is not enough liquidity to buy the entire amount
+	result = aurora.OrderBookDepth{}
+	w = ht.Get(url + "&amount=100000")
+	if ht.Assert.Equal(200, w.Code) {
+		ht.Require.NoError(json.Unmarshal(w.Body.Bytes(), &result))
+		ht.Require.NotNil(result.BuyImpact)
+		ht.Assert.False(result.BuyImpact.Filled)
+		ht.Assert.Equal("100000.0000000", result.BuyImpact.Amount)
+		ht.Assert.NotEqual("100000.0000000", result.BuyImpact.FilledAmount)
+	}
+}
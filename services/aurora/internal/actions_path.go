@@ -16,9 +16,12 @@ var _ actions.JSONer = (*PathIndexAction)(nil)
 // PathIndexAction provides path finding
 type PathIndexAction struct {
 	Action
-	Query   paths.Query
-	Records []paths.Path
-	Page    hal.BasePage
+	Query paths.Query
+	// Split is true when the payment should be divided across several paths
+	Split        bool
+	Records      []paths.Path
+	SplitRecords []paths.SplitPath
+	Page         hal.BasePage
 }
 
 // JSON implements actions.JSON
@@ -37,6 +40,7 @@ func (action *PathIndexAction) loadQuery() {
 	action.Query.DestinationAmount = action.GetPositiveAmount("destination_amount")
 	action.Query.DestinationAsset = action.GetAsset("destination_")
 	action.Query.SourceAccount = action.Base.GetAccountID("source_account")
+	action.Split = action.GetBool("split")
 }
 
 func (action *PathIndexAction) loadSourceAssets() {
@@ -48,9 +52,18 @@ func (action *PathIndexAction) loadSourceAssets() {
 func (action *PathIndexAction) loadRecords() {
 	if len(action.Query.SourceAssets) == 0 {
 		action.Records = []paths.Path{}
+		action.SplitRecords = []paths.SplitPath{}
 		return
 	}
-	action.Records, action.Err = action.App.paths.Find(action.Query, action.App.config.MaxPathLength)
+	if action.Split {
+		action.SplitRecords, action.Err = action.App.paths.FindSplitPaths(
+			action.Query,
+			action.App.config.MaxPathLength,
+			0,
+		)
+	} else {
+		action.Records, action.Err = action.App.paths.Find(action.Query, action.App.config.MaxPathLength)
+	}
 	if action.Err == simplepath.ErrEmptyInMemoryOrderBook {
 		action.Err = problem.StillIngesting
 	}
@@ -58,6 +71,19 @@ func (action *PathIndexAction) loadRecords() {
 
 func (action *PathIndexAction) loadPage() {
 	action.Page.Init()
+	if action.Split {
+		for _, p := range action.SplitRecords {
+			var res aurora.SplitPath
+			action.Err = resourceadapter.PopulateSplitPath(action.R.Context(), &res, p)
+
+			if action.Err != nil {
+				return
+			}
+			action.Page.Add(res)
+		}
+		return
+	}
+
 	for _, p := range action.Records {
 		var res aurora.Path
 		action.Err = resourceadapter.PopulatePath(action.R.Context(), &res, p)
@@ -6,6 +6,7 @@ import (
 	"strconv"
 	"testing"
 
+	"github.com/diamnet/go/amount"
 	"github.com/diamnet/go/exp/orderbook"
 	"github.com/diamnet/go/protocols/aurora"
 	"github.com/diamnet/go/services/aurora/internal/db2"
@@ -253,3 +254,65 @@ func TestPathActionsStrictSend(t *testing.T) {
 		}
 	}
 }
+
+func TestPathActionsStrictSendSplit(t *testing.T) {
+	ht := StartHTTPTest(t, "paths")
+	defer ht.Finish()
+
+	orderBookGraph := orderbook.NewOrderBookGraph()
+
+	loadOffers(ht.T, orderBookGraph, "GA2NC4ZOXMXLVQAQQ5IQKJX47M3PKBQV2N5UV5Z4OXLQJ3CKMBA2O2YL")
+	loadOffers(ht.T, orderBookGraph, "GDSBCQO34HWPGUGQSP3QBFEXVTSR2PW46UIGTHVWGWJGQKH3AFNHXHXN")
+
+	ht.App.paths = simplepath.NewInMemoryFinder(orderBookGraph)
+
+	var q = make(url.Values)
+
+	q.Add(
+		"source_asset_issuer",
+		"GDSBCQO34HWPGUGQSP3QBFEXVTSR2PW46UIGTHVWGWJGQKH3AFNHXHXN",
+	)
+	q.Add(
+		"source_account",
+		"GARSFJNXJIHO6ULUBK3DBYKVSIZE7SC72S5DYBCHU7DKL22UXKVD7MXP",
+	)
+	q.Add(
+		"destination_asset_issuer",
+		"GDSBCQO34HWPGUGQSP3QBFEXVTSR2PW46UIGTHVWGWJGQKH3AFNHXHXN",
+	)
+	q.Add("source_asset_type", "credit_alphanum4")
+	q.Add("source_asset_code", "USD")
+	q.Add("source_amount", "10")
+	q.Add("destination_asset_type", "credit_alphanum4")
+	q.Add("destination_asset_code", "EUR")
+
+	w := ht.Get("/paths/strict-send?" + q.Encode())
+	ht.Assert.Equal(http.StatusOK, w.Code)
+	singlePaths := []aurora.Path{}
+	ht.UnmarshalPage(w.Body, &singlePaths)
+	ht.Assert.NotEmpty(singlePaths)
+
+	q.Add("split", "true")
+	w = ht.Get("/paths/strict-send?" + q.Encode())
+	ht.Assert.Equal(http.StatusOK, w.Code)
+	splitPaths := []aurora.SplitPath{}
+	ht.UnmarshalPage(w.Body, &splitPaths)
+	ht.Assert.Len(splitPaths, 1)
+
+	split := splitPaths[0]
+	ht.Assert.Equal("USD", split.SourceAssetCode)
+	ht.Assert.Equal("EUR", split.DestinationAssetCode)
+	ht.Assert.Equal("10.0000000", split.SourceAmount)
+	ht.Assert.NotEmpty(split.Allocations)
+
+	sourceTotal := xdr.Int64(0)
+	destinationTotal := xdr.Int64(0)
+	for _, allocation := range split.Allocations {
+		ht.Assert.Equal("USD", allocation.SourceAssetCode)
+		ht.Assert.Equal("EUR", allocation.DestinationAssetCode)
+		sourceTotal += amount.MustParse(allocation.SourceAmount)
+		destinationTotal += amount.MustParse(allocation.DestinationAmount)
+	}
+	ht.Assert.Equal(amount.MustParse(split.SourceAmount), sourceTotal)
+	ht.Assert.Equal(amount.MustParse(split.DestinationAmount), destinationTotal)
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/diamnet/go/services/aurora/internal/actions"
 	"github.com/diamnet/go/services/aurora/internal/db2"
 	"github.com/diamnet/go/services/aurora/internal/db2/history"
+	"github.com/diamnet/go/services/aurora/internal/pubsub"
 	"github.com/diamnet/go/services/aurora/internal/render/sse"
 	"github.com/diamnet/go/services/aurora/internal/resourceadapter"
 	"github.com/diamnet/go/support/errors"
@@ -19,6 +20,7 @@ import (
 // Interface verifications
 var _ actions.JSONer = (*TradeIndexAction)(nil)
 var _ actions.EventStreamer = (*TradeIndexAction)(nil)
+var _ actions.StreamFilterer = (*TradeIndexAction)(nil)
 
 type TradeIndexAction struct {
 	Action
@@ -71,6 +73,18 @@ func (action *TradeIndexAction) SSE(stream *sse.Stream) error {
 	return action.Err
 }
 
+// StreamFilter is a method for actions.StreamFilterer
+func (action *TradeIndexAction) StreamFilter() pubsub.Filter {
+	filter := pubsub.Filter{Account: action.AccountFilter}
+	if action.HasBaseAssetFilter && action.HasCounterAssetFilter {
+		filter.Assets = []string{
+			action.BaseAssetFilter.String(),
+			action.CounterAssetFilter.String(),
+		}
+	}
+	return filter
+}
+
 // loadParams sets action.Query from the request params
 func (action *TradeIndexAction) loadParams() {
 	action.PagingParams = action.GetPageQuery()
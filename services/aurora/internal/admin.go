@@ -0,0 +1,56 @@
+package aurora
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/diamnet/go/services/aurora/internal/prometheus"
+	"github.com/diamnet/go/support/log"
+)
+
+// serveAdmin starts the admin server on the admin port. It serves the metrics
+// of aurora in the Prometheus text format at /metrics and is stopped when the
+// app is closed.
+func (a *App) serveAdmin() {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", a.prometheusHandler())
+
+	srv := &http.Server{
+		Addr:              fmt.Sprintf(":%d", a.config.AdminPort),
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	go func() {
+		<-a.ctx.Done()
+		srv.Close()
+	}()
+
+	log.Infof("Starting admin server on %s", srv.Addr)
+	err := srv.ListenAndServe()
+	if err != nil && err != http.ErrServerClosed {
+		log.WithField("err", err.Error()).Error("admin server failed")
+	}
+}
+
+// prometheusHandler renders the metrics registry, the durations of requests by
+// route, method and status, and the connection pool stats of the databases in
+// the Prometheus text format.
+func (a *App) prometheusHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var buf bytes.Buffer
+		pw := prometheus.NewWriter(&buf, "aurora")
+		pw.Registry(a.metrics)
+		a.web.requestMetrics.Write(pw)
+		pw.DBStats(map[string]sql.DBStats{
+			"history": a.historyQ.Session.DB.Stats(),
+			"core":    a.coreQ.Session.DB.Stats(),
+		})
+
+		w.Header().Set("Content-Type", prometheus.ContentType)
+		w.Write(buf.Bytes())
+	})
+}
@@ -23,13 +23,14 @@ import (
 	"github.com/diamnet/go/services/aurora/internal/logmetrics"
 	"github.com/diamnet/go/services/aurora/internal/operationfeestats"
 	"github.com/diamnet/go/services/aurora/internal/paths"
+	"github.com/diamnet/go/services/aurora/internal/pubsub"
+	"github.com/diamnet/go/services/aurora/internal/ratelimit"
 	"github.com/diamnet/go/services/aurora/internal/reap"
 	"github.com/diamnet/go/services/aurora/internal/txsub"
 	"github.com/diamnet/go/support/app"
 	"github.com/diamnet/go/support/db"
 	"github.com/diamnet/go/support/errors"
 	"github.com/diamnet/go/support/log"
-	"github.com/throttled/throttled"
 	"golang.org/x/net/http2"
 	graceful "gopkg.in/tylerb/graceful.v1"
 )
@@ -49,6 +50,8 @@ type App struct {
 	coreSupportedProtocolVersion int32
 	submitter                    *txsub.System
 	paths                        paths.Finder
+	orderBookGraph               *orderbook.OrderBookGraph
+	ledgerUpdates                *pubsub.Hub
 	ingester                     *ingest.System
 	expingester                  *expingest.System
 	reaper                       *reap.System
@@ -62,6 +65,7 @@ type App struct {
 	coreLatestLedgerGauge    metrics.Gauge
 	coreConnGauge            metrics.Gauge
 	goroutineGauge           metrics.Gauge
+	ingestionLagGauge        metrics.Gauge
 }
 
 // NewApp constructs an new App instance from the provided config.
@@ -104,10 +108,18 @@ func (a *App) Serve() {
 
 	go a.run()
 
+	if a.config.AdminPort != 0 {
+		go a.serveAdmin()
+	}
+
 	if a.expingester != nil {
 		go a.expingester.Run()
 	}
 
+	if a.config.StreamUpdatesPostgres {
+		go a.listenLedgerUpdates()
+	}
+
 	var err error
 	if a.config.TLSCert != "" {
 		err = srv.ListenAndServeTLS(a.config.TLSCert, a.config.TLSKey)
@@ -209,6 +221,17 @@ func (a *App) UpdateLedgerState() {
 		return
 	}
 
+	// When ledgers are ingested by a different aurora instance and notifications
+	// are not sent through Postgres, streams are updated every time a new ledger
+	// shows up in the history DB.
+	if !a.config.Ingest && !a.config.StreamUpdatesPostgres &&
+		next.HistoryLatest > ledger.CurrentState().HistoryLatest {
+		a.ledgerUpdates.Publish(pubsub.LedgerClosed{
+			Sequence: next.HistoryLatest,
+			Unknown:  true,
+		})
+	}
+
 	ledger.SetState(next)
 }
 
@@ -337,6 +360,8 @@ func (a *App) UpdateMetrics() {
 	a.historyLatestLedgerGauge.Update(int64(ls.HistoryLatest))
 	a.historyElderLedgerGauge.Update(int64(ls.HistoryElder))
 	a.coreLatestLedgerGauge.Update(int64(ls.CoreLatest))
+	// number of ledgers closed by diamnet-core which are not ingested yet
+	a.ingestionLagGauge.Update(int64(ls.CoreLatest - ls.HistoryLatest))
 
 	a.auroraConnGauge.Update(int64(a.historyQ.Session.DB.Stats().OpenConnections))
 	a.coreConnGauge.Update(int64(a.coreQ.Session.DB.Stats().OpenConnections))
@@ -397,12 +422,16 @@ func (a *App) init() {
 	mustInitAuroraDB(a)
 	mustInitCoreDB(a)
 
+	// ledger updates for streams
+	a.ledgerUpdates = pubsub.NewHub()
+
 	// ingester
 	initIngester(a)
 
 	var orderBookGraph *orderbook.OrderBookGraph
 	if a.config.EnableExperimentalIngestion {
 		orderBookGraph = orderbook.NewOrderBookGraph()
+		a.orderBookGraph = orderBookGraph
 		// expingester
 		initExpIngester(a, orderBookGraph)
 	}
@@ -417,10 +446,13 @@ func (a *App) init() {
 	a.reaper = reap.New(a.config.HistoryRetentionCount, a.AuroraSession(context.Background()))
 
 	// web.init
-	a.web = mustInitWeb(a.ctx, a.historyQ, a.coreQ, a.config.SSEUpdateFrequency, a.config.StaleThreshold, a.config.IngestFailedTransactions)
+	a.web = mustInitWeb(a.ctx, a.historyQ, a.coreQ, a.ledgerUpdates, a.config.SSEUpdateFrequency, a.config.StaleThreshold, a.config.IngestFailedTransactions)
+
+	// redis
+	initRedis(a)
 
 	// web.rate-limiter
-	a.web.rateLimiter = maybeInitWebRateLimiter(a.config.RateQuota)
+	a.web.rateLimiter = maybeInitWebRateLimiter(a.config, a.redis)
 
 	// web.middleware
 	// Note that we passed in `a` here for putting the whole App in the context.
@@ -450,9 +482,6 @@ func (a *App) init() {
 
 	// ingester.metrics
 	initIngesterMetrics(a)
-
-	// redis
-	initRedis(a)
 }
 
 // run is the function that runs in the background that triggers Tick each
@@ -474,8 +503,33 @@ func withAppContext(ctx context.Context, a *App) context.Context {
 	return context.WithValue(ctx, &auroraContext.AppContextKey, a)
 }
 
-// GetRateLimiter returns the HTTPRateLimiter of the App.
-func (a *App) GetRateLimiter() *throttled.HTTPRateLimiter {
+// listenLedgerUpdates forwards notifications sent by the ingesting aurora
+// instance through Postgres to the streams served by this instance.
+func (a *App) listenLedgerUpdates() {
+	for {
+		err := pubsub.ListenPostgres(a.ctx, a.config.DatabaseURL, a.ledgerUpdates)
+		if err == nil {
+			log.Info("finished listening to ledger updates")
+			return
+		}
+
+		log.WithField("err", err.Error()).Error("error listening to ledger updates")
+		select {
+		case <-a.ctx.Done():
+			return
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// GetLedgerUpdates returns the hub notifying streams about ingested ledgers.
+func (a *App) GetLedgerUpdates() *pubsub.Hub {
+	return a.ledgerUpdates
+}
+
+// GetRateLimiter returns the rate limiter of the App, nil when requests are
+// not limited.
+func (a *App) GetRateLimiter() *ratelimit.Limiter {
 	return a.web.rateLimiter
 }
 
@@ -4,8 +4,8 @@ import (
 	"net/url"
 	"time"
 
+	"github.com/diamnet/go/services/aurora/internal/ratelimit"
 	"github.com/sirupsen/logrus"
-	"github.com/throttled/throttled"
 )
 
 // Config is the configuration for aurora.  It gets populated by the
@@ -16,6 +16,9 @@ type Config struct {
 	DiamNetCoreURL         string
 	HistoryArchiveURLs     []string
 	Port                   uint
+	// AdminPort is the port of the admin server serving metrics in the
+	// Prometheus format at /metrics. The admin server is disabled when 0.
+	AdminPort uint
 
 	// MaxDBConnections has a priority over all 4 values below.
 	MaxDBConnections            int
@@ -26,12 +29,22 @@ type Config struct {
 
 	SSEUpdateFrequency time.Duration
 	ConnectionTimeout  time.Duration
-	RateQuota          *throttled.RateQuota
-	RateLimitRedisKey  string
-	RedisURL           string
-	FriendbotURL       *url.URL
-	LogLevel           logrus.Level
-	LogFile            string
+	// RateQuota is the rate limit of the requests without an API key, by
+	// remote IP address. Requests are not limited when nil.
+	RateQuota *ratelimit.Quota
+	// MaxStreamsPerIP is the maximum number of concurrent streams of the
+	// requests without an API key, by remote IP address, no limit when 0.
+	MaxStreamsPerIP int
+	// RateLimitConfigPath is the path of the file configuring the API keys and
+	// the costs of routes, see ratelimit.Config.
+	RateLimitConfigPath string
+	// RateLimitRedisKey is the prefix of the Redis keys storing the rate limits
+	// and the open streams when RedisURL is set.
+	RateLimitRedisKey string
+	RedisURL          string
+	FriendbotURL      *url.URL
+	LogLevel          logrus.Level
+	LogFile           string
 	// MaxPathLength is the maximum length of the path returned by `/paths` endpoint.
 	MaxPathLength     uint
 	NetworkPassphrase string
@@ -52,6 +65,15 @@ type Config struct {
 	// IngestStateReaderTempSet defines where to store temporary objects during state
 	// ingestion. Possible options are `memory` and `postgres`.
 	IngestStateReaderTempSet string
+	// HistoryArchiveCacheDir is a local directory where the files read from
+	// history archives during state ingestion are cached.
+	HistoryArchiveCacheDir string
+	// HistoryArchiveQuorum is the number of history archives which must agree
+	// on a history archive state before it's used for state ingestion.
+	HistoryArchiveQuorum int
+	// OrderBookSnapshotPath is the path of a file used to persist the in memory
+	// order book between restarts. Snapshots are disabled when empty.
+	OrderBookSnapshotPath string
 	// IngestFailedTransactions toggles whether to ingest failed transactions
 	IngestFailedTransactions bool
 	// CursorName is the cursor used for ingesting from diamnet-core.
@@ -76,4 +98,8 @@ type Config struct {
 	// Enabling it has a negative impact on CPU when ingesting ledgers full of
 	// many different assets related operations.
 	EnableAssetStats bool
+	// StreamUpdatesPostgres toggles sending notifications about ingested
+	// ledgers through Postgres NOTIFY so streams served by all aurora instances
+	// sharing the database are updated as soon as a ledger is ingested.
+	StreamUpdatesPostgres bool
 }
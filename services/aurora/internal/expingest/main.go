@@ -38,21 +38,36 @@ type Config struct {
 	CoreSession    *db.Session
 	DiamNetCoreURL string
 
-	HistorySession    *db.Session
-	HistoryArchiveURL string
-	TempSet           io.TempSet
+	HistorySession     *db.Session
+	HistoryArchiveURLs []string
+	// HistoryArchiveQuorum is the number of history archives which must agree
+	// on a history archive state before it's used for state ingestion.
+	HistoryArchiveQuorum int
+	// HistoryArchiveCacheDir is a local directory where the files read from
+	// the history archive are cached. Caching is disabled when empty.
+	HistoryArchiveCacheDir string
+	TempSet                io.TempSet
 
 	OrderBookGraph *orderbook.OrderBookGraph
+	// OrderBookSnapshotPath is the path of a file where the order book graph is
+	// periodically saved to and restored from on startup. Snapshots are
+	// disabled when empty.
+	OrderBookSnapshotPath string
 }
 
 type System struct {
-	session  *ingest.LiveSession
-	historyQ *history.Q
-	graph    *orderbook.OrderBookGraph
+	session     *ingest.LiveSession
+	historyQ    *history.Q
+	graph       *orderbook.OrderBookGraph
+	snapshotter *orderBookSnapshotter
 }
 
 func NewSystem(config Config) (*System, error) {
-	archive, err := createArchive(config.HistoryArchiveURL)
+	archive, err := createArchive(
+		config.HistoryArchiveURLs,
+		config.HistoryArchiveQuorum,
+		config.HistoryArchiveCacheDir,
+	)
 	if err != nil {
 		return nil, errors.Wrap(err, "error creating history archive")
 	}
@@ -79,23 +94,29 @@ func NewSystem(config Config) (*System, error) {
 		TempSet: config.TempSet,
 	}
 
+	snapshotter := &orderBookSnapshotter{
+		path:  config.OrderBookSnapshotPath,
+		graph: config.OrderBookGraph,
+	}
+
 	addPipelineHooks(
 		session.StatePipeline,
 		config.HistorySession,
 		session,
-		config.OrderBookGraph,
+		snapshotter,
 	)
 	addPipelineHooks(
 		session.LedgerPipeline,
 		config.HistorySession,
 		session,
-		config.OrderBookGraph,
+		snapshotter,
 	)
 
 	return &System{
-		session:  session,
-		historyQ: historyQ,
-		graph:    config.OrderBookGraph,
+		session:     session,
+		historyQ:    historyQ,
+		graph:       config.OrderBookGraph,
+		snapshotter: snapshotter,
 	}, nil
 }
 
@@ -128,6 +149,9 @@ func NewSystem(config Config) (*System, error) {
 //     a database.
 //   * If instances is a NOT leader, it runs ledger pipeline without updating a
 //     a database so order book graph is updated but database is not overwritten.
+//   * If a recent order book snapshot is available the graph is restored from
+//     the snapshot instead of a database and the ledgers between the snapshot
+//     and the last ingested ledger are replayed without updating a database.
 func (s *System) Run() {
 	// retryOnError loop is needed only in case of initial state sync errors.
 	// If the state is successfully ingested `resumeFromLedger` method continues
@@ -191,16 +215,19 @@ func (s *System) Run() {
 			}
 		} else {
 			// The other node already ingested a state (just now or in the past)
-			// so we need to get offers from a DB, then resume session normally.
-			// State pipeline is NOT processed.
+			// so we need to get offers from a snapshot or a DB, then resume
+			// session normally. State pipeline is NOT processed.
 			log.WithField("last_ledger", lastIngestedLedger).
 				Info("Resuming ingestion system from last processed ledger...")
 
-			err = loadOrderBookGraphFromDB(s.historyQ, s.graph)
-			if err != nil {
-				return errors.Wrap(err, "Error loading order book graph from db")
+			if snapshotLedger, ok := s.snapshotter.restore(lastIngestedLedger); ok {
+				lastIngestedLedger = snapshotLedger
+			} else {
+				err = loadOrderBookGraphFromDB(s.historyQ, s.graph)
+				if err != nil {
+					return errors.Wrap(err, "Error loading order book graph from db")
+				}
 			}
-
 		}
 
 		s.resumeFromLedger(lastIngestedLedger)
@@ -259,12 +286,29 @@ func (s *System) resumeFromLedger(lastIngestedLedger uint32) {
 func (s *System) Shutdown() {
 	log.Info("Shutting down ingestion system...")
 	s.session.Shutdown()
+	if err := s.snapshotter.write(); err != nil {
+		log.WithField("err", err).Error("Error writing order book snapshot")
+	}
 }
 
-func createArchive(archiveURL string) (*historyarchive.Archive, error) {
-	return historyarchive.Connect(
-		archiveURL,
-		historyarchive.ConnectOptions{},
+func createArchive(archiveURLs []string, quorum int, cacheDir string) (historyarchive.ArchiveInterface, error) {
+	if quorum > len(archiveURLs) {
+		return nil, errors.Errorf(
+			"history archive quorum %d is greater than the number of history archives (%d)",
+			quorum,
+			len(archiveURLs),
+		)
+	}
+	if len(archiveURLs) == 1 {
+		return historyarchive.Connect(
+			archiveURLs[0],
+			historyarchive.ConnectOptions{CacheDir: cacheDir},
+		)
+	}
+	return historyarchive.ConnectMulti(
+		archiveURLs,
+		quorum,
+		historyarchive.ConnectOptions{CacheDir: cacheDir},
 	)
 }
 
@@ -0,0 +1,155 @@
+package expingest
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"github.com/diamnet/go/exp/orderbook"
+	"github.com/diamnet/go/support/errors"
+	ilog "github.com/diamnet/go/support/log"
+)
+
+const (
+	// orderBookSnapshotInterval is the number of ledgers between periodic
+	// order book snapshots. It is equal to the checkpoint frequency.
+	orderBookSnapshotInterval = 64
+	// maxOrderBookSnapshotLag is the maximum number of ledgers a snapshot can be
+	// behind the last ingested ledger. Older snapshots are ignored because
+	// replaying a long range of ledgers is slower than loading offers from a
+	// database and the ledgers may no longer be available in diamnet-core.
+	maxOrderBookSnapshotLag = 720
+)
+
+// orderBookSnapshotter keeps track of the ledger reflected by the order book
+// graph and persists the graph to a local file so it can be restored when
+// aurora restarts. If path is empty snapshots are disabled.
+type orderBookSnapshotter struct {
+	path  string
+	graph *orderbook.OrderBookGraph
+
+	// lock ensures that ledger always reflects the state of the graph
+	lock   sync.Mutex
+	ledger uint32
+}
+
+// apply applies the pending order book changes for the given ledger and writes
+// a snapshot every orderBookSnapshotInterval ledgers.
+func (s *orderBookSnapshotter) apply(ledgerSeq uint32) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if err := s.graph.Apply(); err != nil {
+		return err
+	}
+	s.ledger = ledgerSeq
+
+	if ledgerSeq%orderBookSnapshotInterval == 0 {
+		// Failing to write a snapshot must not stop ingestion.
+		if err := s.writeSnapshot(); err != nil {
+			log.WithField("err", err).Error("Error writing order book snapshot")
+		}
+	}
+	return nil
+}
+
+// write writes a snapshot of the order book graph to disk. It's used when
+// aurora is shutting down.
+func (s *orderBookSnapshotter) write() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.writeSnapshot()
+}
+
+func (s *orderBookSnapshotter) writeSnapshot() error {
+	if s.path == "" || s.ledger == 0 {
+		return nil
+	}
+
+	start := time.Now()
+	// Write to a temporary file first so a crash in the middle of writing
+	// never leaves a partial snapshot behind.
+	tmpPath := s.path + ".tmp"
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		return errors.Wrap(err, "could not create snapshot file")
+	}
+
+	if err = s.graph.WriteSnapshot(file, s.ledger); err != nil {
+		file.Close()
+		return errors.Wrap(err, "could not write snapshot")
+	}
+	if err = file.Sync(); err != nil {
+		file.Close()
+		return errors.Wrap(err, "could not sync snapshot file")
+	}
+	if err = file.Close(); err != nil {
+		return errors.Wrap(err, "could not close snapshot file")
+	}
+	if err = os.Rename(tmpPath, s.path); err != nil {
+		return errors.Wrap(err, "could not rename snapshot file")
+	}
+
+	log.WithFields(ilog.F{
+		"ledger":   s.ledger,
+		"duration": time.Since(start).Seconds(),
+	}).Info("Wrote order book snapshot")
+	return nil
+}
+
+// restore loads the order book graph from a snapshot. It returns the ledger
+// sequence reflected by the snapshot and true if the graph was restored.
+// The graph is not modified (and false is returned) if there is no snapshot,
+// the snapshot is invalid, or it cannot be used to catch up to lastIngestedLedger.
+// In such case the graph must be rebuilt.
+func (s *orderBookSnapshotter) restore(lastIngestedLedger uint32) (uint32, bool) {
+	if s.path == "" {
+		return 0, false
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	file, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		log.WithField("path", s.path).Info("Order book snapshot not found")
+		return 0, false
+	} else if err != nil {
+		log.WithField("err", err).Error("Error opening order book snapshot")
+		return 0, false
+	}
+	defer file.Close()
+
+	start := time.Now()
+	snapshot, err := orderbook.ReadSnapshot(file)
+	if err != nil {
+		log.WithField("err", err).Error("Error reading order book snapshot")
+		return 0, false
+	}
+
+	if snapshot.LedgerSequence > lastIngestedLedger ||
+		lastIngestedLedger-snapshot.LedgerSequence > maxOrderBookSnapshotLag {
+		log.WithFields(ilog.F{
+			"snapshot_ledger":      snapshot.LedgerSequence,
+			"last_ingested_ledger": lastIngestedLedger,
+		}).Info("Order book snapshot cannot be used")
+		return 0, false
+	}
+
+	defer s.graph.Discard()
+	for _, offer := range snapshot.Offers {
+		s.graph.AddOffer(offer)
+	}
+	if err := s.graph.Apply(); err != nil {
+		log.WithField("err", err).Error("Error applying order book snapshot")
+		return 0, false
+	}
+	s.ledger = snapshot.LedgerSequence
+
+	log.WithFields(ilog.F{
+		"ledger":   snapshot.LedgerSequence,
+		"offers":   len(snapshot.Offers),
+		"duration": time.Since(start).Seconds(),
+	}).Info("Restored order book graph from snapshot")
+	return snapshot.LedgerSequence, true
+}
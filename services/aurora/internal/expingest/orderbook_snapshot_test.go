@@ -0,0 +1,88 @@
+package expingest
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/diamnet/go/exp/orderbook"
+	"github.com/diamnet/go/xdr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrderBookSnapshotter(t *testing.T) {
+	dir, err := ioutil.TempDir("", "orderbook-snapshot")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "orderbook.snapshot")
+
+	graph := orderbook.NewOrderBookGraph()
+	snapshotter := &orderBookSnapshotter{path: path, graph: graph}
+
+	graph.AddOffer(eurOffer)
+	assert.NoError(t, snapshotter.apply(orderBookSnapshotInterval-1))
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+
+	graph.AddOffer(twoEurOffer)
+	assert.NoError(t, snapshotter.apply(orderBookSnapshotInterval))
+	_, err = os.Stat(path)
+	assert.NoError(t, err)
+
+	// snapshot is too old
+	restored := orderbook.NewOrderBookGraph()
+	restorer := &orderBookSnapshotter{path: path, graph: restored}
+	_, ok := restorer.restore(orderBookSnapshotInterval + maxOrderBookSnapshotLag + 1)
+	assert.False(t, ok)
+	assert.True(t, restored.IsEmpty())
+
+	// snapshot is newer than the last ingested ledger
+	_, ok = restorer.restore(orderBookSnapshotInterval - 1)
+	assert.False(t, ok)
+	assert.True(t, restored.IsEmpty())
+
+	ledger, ok := restorer.restore(orderBookSnapshotInterval + 10)
+	assert.True(t, ok)
+	assert.Equal(t, uint32(orderBookSnapshotInterval), ledger)
+	assert.ElementsMatch(t, []xdr.OfferEntry{eurOffer, twoEurOffer}, restored.Offers())
+
+	// shutdown writes the latest state of the graph
+	graph.RemoveOffer(eurOffer.OfferId)
+	assert.NoError(t, snapshotter.apply(orderBookSnapshotInterval+1))
+	assert.NoError(t, snapshotter.write())
+
+	restored = orderbook.NewOrderBookGraph()
+	restorer = &orderBookSnapshotter{path: path, graph: restored}
+	ledger, ok = restorer.restore(orderBookSnapshotInterval + 1)
+	assert.True(t, ok)
+	assert.Equal(t, uint32(orderBookSnapshotInterval+1), ledger)
+	assert.Equal(t, []xdr.OfferEntry{twoEurOffer}, restored.Offers())
+}
+
+func TestOrderBookSnapshotterInvalidSnapshot(t *testing.T) {
+	dir, err := ioutil.TempDir("", "orderbook-snapshot")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "orderbook.snapshot")
+
+	graph := orderbook.NewOrderBookGraph()
+	snapshotter := &orderBookSnapshotter{path: path, graph: graph}
+
+	// snapshot does not exist
+	_, ok := snapshotter.restore(100)
+	assert.False(t, ok)
+
+	assert.NoError(t, ioutil.WriteFile(path, []byte("not a snapshot"), 0644))
+	_, ok = snapshotter.restore(100)
+	assert.False(t, ok)
+	assert.True(t, graph.IsEmpty())
+
+	// snapshots are disabled
+	snapshotter = &orderBookSnapshotter{graph: graph}
+	graph.AddOffer(eurOffer)
+	assert.NoError(t, snapshotter.apply(orderBookSnapshotInterval))
+	assert.NoError(t, snapshotter.write())
+	_, ok = snapshotter.restore(orderBookSnapshotInterval)
+	assert.False(t, ok)
+}
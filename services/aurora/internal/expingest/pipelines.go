@@ -111,7 +111,7 @@ func addPipelineHooks(
 	p supportPipeline.PipelineInterface,
 	historySession *db.Session,
 	ingestSession ingest.Session,
-	graph *orderbook.OrderBookGraph,
+	snapshotter *orderBookSnapshotter,
 ) {
 	var pipelineType pType
 	switch p.(type) {
@@ -178,7 +178,7 @@ func addPipelineHooks(
 
 	p.AddPostProcessingHook(func(ctx context.Context, err error) error {
 		defer historySession.Rollback()
-		defer graph.Discard()
+		defer snapshotter.graph.Discard()
 
 		ledgerSeq := pipeline.GetLedgerSequenceFromContext(ctx)
 
@@ -222,7 +222,7 @@ func addPipelineHooks(
 			}
 		}
 
-		if err := graph.Apply(); err != nil {
+		if err := snapshotter.apply(ledgerSeq); err != nil {
 			return errors.Wrap(err, "Error applying order book changes")
 		}
 
@@ -8,6 +8,7 @@ import (
 	"github.com/diamnet/go/exp/ingest/io"
 	ingestpipeline "github.com/diamnet/go/exp/ingest/pipeline"
 	"github.com/diamnet/go/exp/support/pipeline"
+	"github.com/diamnet/go/support/errors"
 	"github.com/diamnet/go/xdr"
 )
 
@@ -57,7 +58,12 @@ func (p *OrderbookProcessor) ProcessLedger(ctx context.Context, store *pipeline.
 			continue
 		}
 
-		for _, change := range transaction.GetChanges() {
+		changes, err := transaction.GetChanges()
+		if err != nil {
+			return errors.Wrap(err, "Error getting transaction changes")
+		}
+
+		for _, change := range changes {
 			if change.Type != xdr.LedgerEntryTypeOffer {
 				continue
 			}
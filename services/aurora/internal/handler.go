@@ -13,6 +13,8 @@ import (
 	"github.com/diamnet/go/services/aurora/internal/db2"
 	"github.com/diamnet/go/services/aurora/internal/hchi"
 	"github.com/diamnet/go/services/aurora/internal/ledger"
+	"github.com/diamnet/go/services/aurora/internal/pubsub"
+	"github.com/diamnet/go/services/aurora/internal/ratelimit"
 	"github.com/diamnet/go/services/aurora/internal/render"
 	hProblem "github.com/diamnet/go/services/aurora/internal/render/problem"
 	"github.com/diamnet/go/services/aurora/internal/render/sse"
@@ -74,20 +76,39 @@ func (we *web) streamHandler(jfn interface{}, sfn streamFunc, params interface{}
 		ctx := r.Context()
 
 		stream := sse.NewStream(ctx, w)
+		updates := we.ledgerUpdates.Subscribe(streamFilter(params))
+		defer updates.Close()
+
+		// Count the stream against the concurrent streams limit of the client
+		// until it's closed.
+		rateLimiter := we.rateLimiter
+		client, _ := ratelimit.ClientFromContext(ctx)
+		if rateLimiter != nil {
+			ok, release, err := rateLimiter.StartStream(client)
+			if err != nil {
+				stream.Err(errors.Wrap(err, "RateLimiter error"))
+				return
+			}
+			if !ok {
+				stream.Err(sse.ErrTooManyStreams)
+				return
+			}
+			defer release()
+		}
+
 		var oldHash [32]byte
 		for {
-			lastLedgerState := ledger.CurrentState()
+			lastUpdate := time.Now()
 
 			// Rate limit the request if it's a call to stream since it queries the DB every second. See
 			// https://github.com/diamnet/go/issues/715 for more details.
-			rateLimiter := we.rateLimiter
 			if rateLimiter != nil {
-				limited, _, err := rateLimiter.RateLimiter.RateLimit(rateLimiter.VaryBy.Key(r), 1)
+				result, err := rateLimiter.RateLimit(client, 1)
 				if err != nil {
 					stream.Err(errors.Wrap(err, "RateLimiter error"))
 					return
 				}
-				if limited {
+				if result != nil && result.Limited {
 					stream.Err(sse.ErrRateLimited)
 					return
 				}
@@ -131,23 +152,12 @@ func (we *web) streamHandler(jfn interface{}, sfn streamFunc, params interface{}
 				return
 			}
 
-			// Make sure this is buffered channel of size 1. Otherwise, the go routine below
-			// will never return if `newLedgers` channel is not read. From Effective Go:
-			// > If the channel is unbuffered, the sender blocks until the receiver has received the value.
-			newLedgers := make(chan bool, 1)
-			go func() {
-				for {
-					time.Sleep(we.sseUpdateFrequency)
-					currentLedgerState := ledger.CurrentState()
-					if currentLedgerState.HistoryLatest >= lastLedgerState.HistoryLatest+1 {
-						newLedgers <- true
-						return
-					}
-				}
-			}()
-
 			select {
-			case <-newLedgers:
+			case <-updates.Updates():
+				// Do not reload a single stream more often than sseUpdateFrequency.
+				if wait := we.sseUpdateFrequency - time.Since(lastUpdate); wait > 0 {
+					time.Sleep(wait)
+				}
 				continue
 			case <-ctx.Done():
 			case <-we.appCtx.Done():
@@ -159,6 +169,19 @@ func (we *web) streamHandler(jfn interface{}, sfn streamFunc, params interface{}
 	})
 }
 
+// streamFilter returns the filter of ledger updates relevant to a stream
+// with the given params.
+func streamFilter(params interface{}) pubsub.Filter {
+	switch p := params.(type) {
+	case *indexActionQueryParams:
+		return pubsub.Filter{Account: p.AccountID}
+	case *showActionQueryParams:
+		return pubsub.Filter{Account: p.AccountID}
+	default:
+		return pubsub.Filter{}
+	}
+}
+
 // streamShowActionHandler gets the showAction query params from the request
 // and pass it on to streamableEndpointHandler.
 func (we *web) streamShowActionHandler(jfn interface{}, requireAccountID bool) http.HandlerFunc {
@@ -11,9 +11,9 @@ import (
 	"github.com/go-chi/chi"
 	"github.com/diamnet/go/network"
 	"github.com/diamnet/go/services/aurora/internal/actions"
+	"github.com/diamnet/go/services/aurora/internal/ratelimit"
 	"github.com/diamnet/go/services/aurora/internal/test"
 	supportLog "github.com/diamnet/go/support/log"
-	"github.com/throttled/throttled"
 )
 
 func NewTestApp() *App {
@@ -21,13 +21,11 @@ func NewTestApp() *App {
 }
 
 func NewTestConfig() Config {
+	rateQuota := ratelimit.PerHour(1000, 100)
 	return Config{
-		DatabaseURL:            test.DatabaseURL(),
-		DiamNetCoreDatabaseURL: test.DiamNetCoreDatabaseURL(),
-		RateQuota: &throttled.RateQuota{
-			MaxRate:  throttled.PerHour(1000),
-			MaxBurst: 100,
-		},
+		DatabaseURL:              test.DatabaseURL(),
+		DiamNetCoreDatabaseURL:   test.DiamNetCoreDatabaseURL(),
+		RateQuota:                &rateQuota,
 		ConnectionTimeout:        55 * time.Second, // Default
 		LogLevel:                 supportLog.InfoLevel,
 		NetworkPassphrase:        network.TestNetworkPassphrase,
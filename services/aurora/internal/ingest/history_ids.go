@@ -0,0 +1,183 @@
+package ingest
+
+import (
+	"sort"
+
+	"github.com/diamnet/go/services/aurora/internal/db2/history"
+	"github.com/diamnet/go/services/aurora/internal/ingest/participants"
+	"github.com/diamnet/go/support/errors"
+	"github.com/diamnet/go/xdr"
+)
+
+// historyIDsBatchSize is the number of accounts collected by
+// Session.createHistoryIDs before their rows are created.
+const historyIDsBatchSize = 1000
+
+// historyIDs collects accounts and assets, in the order in which they are first
+// seen, and creates their rows in `history_accounts` and `history_assets`.
+type historyIDs struct {
+	q          *history.Q
+	addresses  []string
+	assets     []xdr.Asset
+	seenAssets map[string]bool
+	seen       map[string]bool
+}
+
+// createHistoryIDs creates the rows in `history_accounts` and `history_assets`
+// of all the accounts and assets of the ledgers of the session, in ledger order,
+// in the transaction of the session. Otherwise these rows are created in the
+// order in which the session ingests operations and effects.
+func (is *Session) createHistoryIDs() error {
+	start, end := is.Cursor.FirstLedger, is.Cursor.LastLedger
+	if start > end {
+		start, end = end, start
+	}
+
+	ids := &historyIDs{q: &history.Q{Session: is.Ingestion.DB}}
+	ids.reset()
+
+	var assetStats *AssetStats
+	if is.Config.EnableAssetStats {
+		assetStats = &AssetStats{}
+	}
+
+	// the ledgers are read again by the session
+	cursor := &Cursor{
+		FirstLedger: start,
+		LastLedger:  end,
+		CoreDB:      is.Cursor.CoreDB,
+		Name:        is.Cursor.Name,
+	}
+	for cursor.NextLedger() {
+		for cursor.NextTx() {
+			tx := cursor.Transaction()
+			accounts, err := participants.ForTransaction(
+				&tx.Envelope.Tx,
+				&tx.ResultMeta,
+				&cursor.TransactionFee().Changes,
+			)
+			if err != nil {
+				return errors.Wrap(err, "participants.ForTransaction error")
+			}
+			// participants are returned in random order
+			addresses := make([]string, 0, len(accounts))
+			for _, account := range accounts {
+				addresses = append(addresses, account.Address())
+			}
+			sort.Strings(addresses)
+			for _, address := range addresses {
+				ids.addAccount(address)
+			}
+
+			if !tx.IsSuccessful() {
+				continue
+			}
+
+			for cursor.NextOp() {
+				trades, _, _ := operationTrades(cursor)
+				for _, trade := range trades {
+					// see Session.ingestTrades
+					if trade.AmountBought == 0 && trade.AmountSold == 0 {
+						continue
+					}
+					ids.addAccount(trade.SellerId.Address())
+					ids.addAsset(trade.AssetSold)
+					ids.addAsset(trade.AssetBought)
+				}
+
+				if assetStats != nil {
+					err = assetStats.IngestOperation(cursor.Operation(), &tx.Envelope.Tx.SourceAccount)
+					if err != nil {
+						return errors.Wrap(err, "AssetStats.IngestOperation error")
+					}
+				}
+			}
+		}
+
+		if len(ids.addresses) >= historyIDsBatchSize {
+			if err := ids.create(); err != nil {
+				return err
+			}
+		}
+	}
+	if cursor.Err != nil {
+		return errors.Wrap(cursor.Err, "Cursor.NextLedger error")
+	}
+
+	if assetStats != nil {
+		keys := make([]string, 0, len(assetStats.toUpdate))
+		for key := range assetStats.toUpdate {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			ids.addAsset(assetStats.toUpdate[key])
+		}
+	}
+
+	return ids.create()
+}
+
+func (ids *historyIDs) reset() {
+	ids.addresses = nil
+	ids.assets = nil
+	ids.seen = map[string]bool{}
+	ids.seenAssets = map[string]bool{}
+}
+
+func (ids *historyIDs) addAccount(address string) {
+	if !ids.seen[address] {
+		ids.seen[address] = true
+		ids.addresses = append(ids.addresses, address)
+	}
+}
+
+func (ids *historyIDs) addAsset(asset xdr.Asset) {
+	key := asset.String()
+	if !ids.seenAssets[key] {
+		ids.seenAssets[key] = true
+		ids.assets = append(ids.assets, asset)
+	}
+}
+
+// create creates the rows of the collected accounts and assets which do not
+// exist yet, in the order in which they were collected.
+func (ids *historyIDs) create() error {
+	if len(ids.addresses) > 0 {
+		existing := []history.Account{}
+		err := ids.q.AccountsByAddresses(&existing, ids.addresses)
+		if err != nil {
+			return errors.Wrap(err, "q.AccountsByAddresses error")
+		}
+
+		exists := map[string]bool{}
+		for _, row := range existing {
+			exists[row.Address] = true
+		}
+
+		addresses := make([]string, 0, len(ids.addresses))
+		for _, address := range ids.addresses {
+			if !exists[address] {
+				addresses = append(addresses, address)
+			}
+		}
+
+		if len(addresses) > 0 {
+			created := make([]history.Account, 0, len(addresses))
+			err = ids.q.CreateAccounts(&created, addresses)
+			if err != nil {
+				return errors.Wrap(err, "q.CreateAccounts error")
+			}
+		}
+	}
+
+	for _, asset := range ids.assets {
+		_, err := ids.q.GetCreateAssetID(asset)
+		if err != nil {
+			return errors.Wrap(err, "q.GetCreateAssetID error")
+		}
+	}
+
+	ids.reset()
+	return nil
+}
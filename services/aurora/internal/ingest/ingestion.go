@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"math"
+	"sort"
 	"time"
 
 	sq "github.com/Masterminds/squirrel"
@@ -160,6 +161,9 @@ func (ingest *Ingestion) UpdateAccountIDs(tables []TableName) error {
 	}
 
 	if len(addresses) > 0 {
+		// Insert addresses in a deterministic order so concurrent sessions
+		// (ex. parallel reingestion) lock rows in the same order and do not deadlock.
+		sort.Strings(addresses)
 		// TODO we should probably batch this too
 		dbAccounts = make([]history.Account, 0, len(addresses))
 		err = q.CreateAccounts(&dbAccounts, addresses)
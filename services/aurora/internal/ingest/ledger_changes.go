@@ -0,0 +1,131 @@
+package ingest
+
+import (
+	"sort"
+
+	"github.com/diamnet/go/services/aurora/internal/db2/core"
+	"github.com/diamnet/go/services/aurora/internal/ingest/participants"
+	"github.com/diamnet/go/services/aurora/internal/pubsub"
+	"github.com/diamnet/go/support/errors"
+	"github.com/diamnet/go/xdr"
+)
+
+// ledgerChanges collects the accounts and assets affected by a single ledger
+// so streaming requests can be notified once the ledger is ingested.
+type ledgerChanges struct {
+	sequence int32
+	accounts map[string]bool
+	assets   map[string]bool
+	unknown  bool
+}
+
+func newLedgerChanges(sequence int32) *ledgerChanges {
+	return &ledgerChanges{
+		sequence: sequence,
+		accounts: map[string]bool{},
+		assets:   map[string]bool{},
+	}
+}
+
+// addTransaction records the accounts and assets affected by the transaction:
+// its participants and all the accounts, trust lines and offers changed by
+// its fee, transaction and operation meta. Failed transactions are included
+// because they change the balance and sequence number of the source account.
+func (c *ledgerChanges) addTransaction(tx *core.Transaction, fee *core.TransactionFee) error {
+	c.addAccount(tx.Envelope.Tx.SourceAccount)
+	for i := range tx.Envelope.Tx.Operations {
+		accounts, err := participants.ForOperation(&tx.Envelope.Tx, &tx.Envelope.Tx.Operations[i])
+		if err != nil {
+			return errors.Wrap(err, "participants.ForOperation error")
+		}
+		for _, account := range accounts {
+			c.addAccount(account)
+		}
+	}
+
+	c.addChanges(fee.Changes)
+	if v1, ok := tx.ResultMeta.GetV1(); ok {
+		c.addChanges(v1.TxChanges)
+	}
+	for _, op := range tx.ResultMeta.OperationsMeta() {
+		c.addChanges(op.Changes)
+	}
+	return nil
+}
+
+func (c *ledgerChanges) addChanges(changes xdr.LedgerEntryChanges) {
+	for i, change := range changes {
+		switch change.Type {
+		case xdr.LedgerEntryChangeTypeLedgerEntryCreated:
+			c.addEntry(change.MustCreated())
+		case xdr.LedgerEntryChangeTypeLedgerEntryUpdated:
+			c.addEntry(change.MustUpdated())
+		case xdr.LedgerEntryChangeTypeLedgerEntryState:
+			c.addEntry(change.MustState())
+		case xdr.LedgerEntryChangeTypeLedgerEntryRemoved:
+			// Meta V1 contains the state of every entry before it was removed
+			// (in the preceding change), older meta versions don't.
+			previousState := i > 0 && changes[i-1].Type == xdr.LedgerEntryChangeTypeLedgerEntryState
+			c.addKey(change.MustRemoved(), previousState)
+		}
+	}
+}
+
+func (c *ledgerChanges) addEntry(entry xdr.LedgerEntry) {
+	switch entry.Data.Type {
+	case xdr.LedgerEntryTypeAccount:
+		c.addAccount(entry.Data.MustAccount().AccountId)
+	case xdr.LedgerEntryTypeTrustline:
+		trustline := entry.Data.MustTrustLine()
+		c.addAccount(trustline.AccountId)
+		c.assets[trustline.Asset.String()] = true
+	case xdr.LedgerEntryTypeOffer:
+		offer := entry.Data.MustOffer()
+		c.addAccount(offer.SellerId)
+		c.assets[offer.Selling.String()] = true
+		c.assets[offer.Buying.String()] = true
+	case xdr.LedgerEntryTypeData:
+		c.addAccount(entry.Data.MustData().AccountId)
+	}
+}
+
+func (c *ledgerChanges) addKey(key xdr.LedgerKey, previousState bool) {
+	switch key.Type {
+	case xdr.LedgerEntryTypeAccount:
+		c.addAccount(key.MustAccount().AccountId)
+	case xdr.LedgerEntryTypeTrustline:
+		trustline := key.MustTrustLine()
+		c.addAccount(trustline.AccountId)
+		c.assets[trustline.Asset.String()] = true
+	case xdr.LedgerEntryTypeOffer:
+		c.addAccount(key.MustOffer().SellerId)
+		// Offer keys do not contain the assets so they are unknown unless
+		// they were found in the state of the offer.
+		if !previousState {
+			c.unknown = true
+		}
+	case xdr.LedgerEntryTypeData:
+		c.addAccount(key.MustData().AccountId)
+	}
+}
+
+func (c *ledgerChanges) addAccount(account xdr.AccountId) {
+	c.accounts[account.Address()] = true
+}
+
+// event returns the notification published when the ledger is ingested
+func (c *ledgerChanges) event() pubsub.LedgerClosed {
+	event := pubsub.LedgerClosed{
+		Sequence: c.sequence,
+		Unknown:  c.unknown,
+	}
+	for account := range c.accounts {
+		event.Accounts = append(event.Accounts, account)
+	}
+	for asset := range c.assets {
+		event.Assets = append(event.Assets, asset)
+	}
+	sort.Strings(event.Accounts)
+	sort.Strings(event.Assets)
+	return event
+}
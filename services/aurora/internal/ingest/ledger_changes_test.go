@@ -0,0 +1,95 @@
+package ingest
+
+import (
+	"testing"
+
+	"github.com/diamnet/go/services/aurora/internal/db2/core"
+	"github.com/diamnet/go/services/aurora/internal/pubsub"
+	"github.com/diamnet/go/xdr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLedgerChanges(t *testing.T) {
+	source := xdr.MustAddress("GAXI33UCLQTCKM2NMRBS7XYBR535LLEVAHL5YBN4FTCB4HZHT7ZA5CVK")
+	destination := xdr.MustAddress("GCQZP3IU7XU6EJ63JZXKCQOYT2RNXN3HB5CNHENNUEUHSMA4VUJJJSEN")
+	seller := xdr.MustAddress("GACAR2AEYEKITE2LKI5RMXF5MIVZ6Q7XILROGDT22O7JX4DSWFS7FDDP")
+	usd := xdr.MustNewCreditAsset("USD", seller.Address())
+	eur := xdr.MustNewCreditAsset("EUR", seller.Address())
+
+	offer := xdr.LedgerEntry{
+		Data: xdr.LedgerEntryData{
+			Type: xdr.LedgerEntryTypeOffer,
+			Offer: &xdr.OfferEntry{
+				SellerId: seller,
+				OfferId:  1,
+				Selling:  usd,
+				Buying:   eur,
+			},
+		},
+	}
+	trustline := xdr.LedgerEntry{
+		Data: xdr.LedgerEntryData{
+			Type: xdr.LedgerEntryTypeTrustline,
+			TrustLine: &xdr.TrustLineEntry{
+				AccountId: destination,
+				Asset:     usd,
+			},
+		},
+	}
+	offerKey := xdr.LedgerKey{
+		Type:  xdr.LedgerEntryTypeOffer,
+		Offer: &xdr.LedgerKeyOffer{SellerId: seller, OfferId: 1},
+	}
+
+	tx := &core.Transaction{
+		Envelope: xdr.TransactionEnvelope{
+			Tx: xdr.Transaction{
+				SourceAccount: source,
+				Operations: []xdr.Operation{
+					{
+						Body: xdr.OperationBody{
+							Type: xdr.OperationTypePayment,
+							PaymentOp: &xdr.PaymentOp{
+								Destination: destination,
+								Asset:       usd,
+							},
+						},
+					},
+				},
+			},
+		},
+		ResultMeta: xdr.TransactionMeta{
+			V: 1,
+			V1: &xdr.TransactionMetaV1{
+				Operations: []xdr.OperationMeta{
+					{
+						Changes: xdr.LedgerEntryChanges{
+							{Type: xdr.LedgerEntryChangeTypeLedgerEntryCreated, Created: &trustline},
+							{Type: xdr.LedgerEntryChangeTypeLedgerEntryState, State: &offer},
+							{Type: xdr.LedgerEntryChangeTypeLedgerEntryRemoved, Removed: &offerKey},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	changes := newLedgerChanges(10)
+	assert.NoError(t, changes.addTransaction(tx, &core.TransactionFee{}))
+	event := changes.event()
+	assert.Equal(t, int32(10), event.Sequence)
+	assert.False(t, event.Unknown)
+	assert.ElementsMatch(t, []string{source.Address(), destination.Address(), seller.Address()}, event.Accounts)
+	assert.ElementsMatch(t, []string{usd.String(), eur.String()}, event.Assets)
+
+	// assets of offers removed without their previous state are unknown
+	changes = newLedgerChanges(11)
+	changes.addChanges(xdr.LedgerEntryChanges{
+		{Type: xdr.LedgerEntryChangeTypeLedgerEntryRemoved, Removed: &offerKey},
+	})
+	assert.Equal(t, pubsub.LedgerClosed{
+		Sequence: 11,
+		Accounts: []string{seller.Address()},
+		Unknown:  true,
+	}, changes.event())
+}
@@ -9,6 +9,7 @@ import (
 	sq "github.com/Masterminds/squirrel"
 	metrics "github.com/rcrowley/go-metrics"
 	"github.com/diamnet/go/services/aurora/internal/db2/core"
+	"github.com/diamnet/go/services/aurora/internal/pubsub"
 	"github.com/diamnet/go/support/db"
 	ilog "github.com/diamnet/go/support/log"
 	"github.com/diamnet/go/xdr"
@@ -134,6 +135,9 @@ type System struct {
 	HistoryRetentionCount uint
 	// IngestFailedTransactions toggles whether to ingest failed transactions
 	IngestFailedTransactions bool
+	// Publisher, when set, is notified about the accounts and assets affected
+	// by every ingested ledger.
+	Publisher pubsub.Publisher
 
 	lock    sync.Mutex
 	current *Session
@@ -190,6 +194,9 @@ type Session struct {
 	// ClearExisting causes the session to clear existing data from the aurora db
 	// when the session is run.
 	ClearExisting bool
+	// CreateHistoryIDs causes the session to create the rows of the accounts
+	// and assets of its ledgers, in ledger order, before ingesting them.
+	CreateHistoryIDs bool
 	// SkipCursorUpdate causes the session to skip
 	// reporting the "last imported ledger" cursor to
 	// diamnet-core
@@ -198,6 +205,14 @@ type Session struct {
 	Metrics *IngesterMetrics
 	// AssetStats calculates asset stats
 	AssetStats *AssetStats
+	// Publisher, when set, is notified about the accounts and assets affected
+	// by every ledger ingested in this session once the session is committed.
+	Publisher pubsub.Publisher
+
+	// changes collects the changes of the ledger currently being ingested
+	changes *ledgerChanges
+	// closed contains the notifications published when the session is committed
+	closed []pubsub.LedgerClosed
 
 	//
 	// Results fields
@@ -252,6 +267,7 @@ func NewSession(i *System) *Session {
 		DiamNetCoreURL:   i.DiamNetCoreURL,
 		SkipCursorUpdate: i.SkipCursorUpdate,
 		Metrics:          &i.Metrics,
+		Publisher:        i.Publisher,
 		AssetStats: &AssetStats{
 			CoreSession:    cdb,
 			HistorySession: hdb,
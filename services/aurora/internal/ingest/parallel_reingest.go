@@ -0,0 +1,292 @@
+package ingest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/diamnet/go/services/aurora/internal/util"
+	"github.com/diamnet/go/support/errors"
+	ilog "github.com/diamnet/go/support/log"
+)
+
+const (
+	// DefaultReingestWorkers is the default number of ranges reingested concurrently
+	DefaultReingestWorkers = 10
+	// DefaultReingestChunkSize is the default number of ledgers in a single range
+	// reingested by a worker
+	DefaultReingestChunkSize = 10000
+	// DefaultReingestRetries is the default number of times a failed range is retried
+	DefaultReingestRetries = 3
+)
+
+// ParallelReingestConfig configures System.ReingestRangeParallel.
+type ParallelReingestConfig struct {
+	// Workers is the number of ranges reingested concurrently.
+	Workers int
+	// ChunkSize is the number of ledgers in a single range. Every range is
+	// reingested in a separate ingestion session (and DB transaction).
+	ChunkSize int32
+	// Retries is the number of times a failed range is retried before
+	// ReingestRangeParallel gives up on it.
+	Retries int
+	// ProgressFile is an optional path to a file where completed ranges are
+	// recorded. When ReingestRangeParallel is run again with the same range and
+	// chunk size the completed ranges are skipped so a failed reingestion can
+	// be resumed.
+	ProgressFile string
+	// ProgressInterval is the interval between progress log messages.
+	// Defaults to 10 seconds.
+	ProgressInterval time.Duration
+}
+
+// reingestChunk is a range of ledgers reingested by a single worker
+type reingestChunk struct {
+	from, to int32
+	attempt  int
+}
+
+// reingestProgress is the content of ParallelReingestConfig.ProgressFile
+type reingestProgress struct {
+	From      int32 `json:"from"`
+	To        int32 `json:"to"`
+	ChunkSize int32 `json:"chunk_size"`
+	// Completed contains the first ledger of every completed chunk
+	Completed []int32 `json:"completed"`
+}
+
+// reingestTracker keeps track of completed ranges and persists them
+// in the progress file
+type reingestTracker struct {
+	mutex    sync.Mutex
+	path     string
+	progress reingestProgress
+	done     map[int32]bool
+}
+
+// ReingestRangeParallel reingests the ledgers from `start` to `end` (inclusive).
+// The range is split into chunks of config.ChunkSize ledgers which are reingested
+// concurrently by config.Workers workers. Every chunk is reingested in its own
+// session like ReingestRange so a chunk is either reingested entirely or
+// not at all. All rows in the history tables are identified by ids derived from
+// ledger sequences, except `history_accounts` and `history_assets`. Every chunk
+// creates the rows of its accounts and assets, in ledger order, in its own
+// transaction before ingesting its ledgers, so a failed chunk leaves no rows
+// behind. Ids of accounts and assets first seen in different chunks depend on
+// the order in which the chunks are processed, references to them are always
+// consistent.
+//
+// Failed chunks are retried up to config.Retries times. If some chunks still fail
+// an error is returned after all other chunks are processed and, if
+// config.ProgressFile is set, the reingestion can be resumed by running
+// ReingestRangeParallel again. It returns the number of ledgers reingested.
+func (i *System) ReingestRangeParallel(start, end int32, config ParallelReingestConfig) (int, error) {
+	if start > end {
+		start, end = end, start
+	}
+	if config.Workers <= 0 {
+		config.Workers = DefaultReingestWorkers
+	}
+	if config.ChunkSize <= 0 {
+		config.ChunkSize = DefaultReingestChunkSize
+	}
+	if config.Retries < 0 {
+		config.Retries = 0
+	}
+	if config.ProgressInterval <= 0 {
+		config.ProgressInterval = 10 * time.Second
+	}
+
+	tracker, err := loadReingestTracker(config.ProgressFile, reingestProgress{
+		From:      start,
+		To:        end,
+		ChunkSize: config.ChunkSize,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	var pool util.WorkersPool
+	totalLedgers := int64(end) - int64(start) + 1
+	var doneLedgers int64
+	for current := int64(start); current <= int64(end); current += int64(config.ChunkSize) {
+		chunk := reingestChunk{from: int32(current), to: int32(current) + config.ChunkSize - 1}
+		if int64(chunk.to) > int64(end) || int64(chunk.to) < current {
+			chunk.to = end
+		}
+		if tracker.isDone(chunk.from) {
+			doneLedgers += int64(chunk.to-chunk.from) + 1
+			continue
+		}
+		pool.AddWork(chunk)
+	}
+
+	log.WithFields(ilog.F{
+		"from":    start,
+		"to":      end,
+		"chunks":  pool.WorkSize(),
+		"workers": config.Workers,
+		"skipped": doneLedgers,
+	}).Info("reingest: starting parallel range")
+
+	var (
+		mutex    sync.Mutex
+		ingested int
+		failed   []reingestChunk
+	)
+
+	pool.SetWorker(func(workerID int, job interface{}) {
+		chunk := job.(reingestChunk)
+		localLog := log.WithFields(ilog.F{
+			"worker":  workerID,
+			"from":    chunk.from,
+			"to":      chunk.to,
+			"attempt": chunk.attempt + 1,
+		})
+
+		n, err := i.reingestRange(chunk.from, chunk.to, true)
+		if err != nil {
+			localLog.WithField("err", err.Error()).Error("reingest: range failed")
+			if chunk.attempt < config.Retries {
+				chunk.attempt++
+				pool.AddWork(chunk)
+				return
+			}
+			mutex.Lock()
+			failed = append(failed, chunk)
+			mutex.Unlock()
+			return
+		}
+
+		if err := tracker.markDone(chunk.from); err != nil {
+			localLog.WithField("err", err.Error()).Error("reingest: could not save progress")
+		}
+
+		mutex.Lock()
+		ingested += n
+		doneLedgers += int64(chunk.to-chunk.from) + 1
+		mutex.Unlock()
+	})
+
+	stop := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(config.ProgressInterval)
+		defer ticker.Stop()
+		startTime := time.Now()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+
+			mutex.Lock()
+			done := doneLedgers
+			mutex.Unlock()
+			log.WithFields(ilog.F{
+				"ledgers":  done,
+				"total":    totalLedgers,
+				"progress": float64(done) / float64(totalLedgers) * 100,
+				"elapsed":  time.Since(startTime).String(),
+			}).Info("reingest: progress")
+		}
+	}()
+
+	pool.Start(config.Workers)
+	close(stop)
+
+	if len(failed) > 0 {
+		sort.Slice(failed, func(a, b int) bool { return failed[a].from < failed[b].from })
+		ranges := make([]string, 0, len(failed))
+		for _, chunk := range failed {
+			ranges = append(ranges, fmt.Sprintf("%d-%d", chunk.from, chunk.to))
+		}
+		return ingested, errors.Errorf(
+			"reingestion of %d range(s) failed: %s",
+			len(failed),
+			strings.Join(ranges, ", "),
+		)
+	}
+
+	log.WithFields(ilog.F{
+		"from":     start,
+		"to":       end,
+		"ingested": ingested,
+	}).Info("reingest: parallel range complete")
+	return ingested, nil
+}
+
+// loadReingestTracker reads the progress file at path, if it exists. An error is
+// returned if the progress file was created for a different range or chunk size.
+func loadReingestTracker(path string, expected reingestProgress) (*reingestTracker, error) {
+	tracker := &reingestTracker{
+		path:     path,
+		progress: expected,
+		done:     map[int32]bool{},
+	}
+	if path == "" {
+		return tracker, nil
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return tracker, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "could not read progress file")
+	}
+
+	var saved reingestProgress
+	if err = json.Unmarshal(data, &saved); err != nil {
+		return nil, errors.Wrap(err, "could not parse progress file")
+	}
+	if saved.From != expected.From || saved.To != expected.To || saved.ChunkSize != expected.ChunkSize {
+		return nil, errors.Errorf(
+			"progress file %s was created for range %d-%d with chunk size %d",
+			path,
+			saved.From,
+			saved.To,
+			saved.ChunkSize,
+		)
+	}
+
+	tracker.progress = saved
+	for _, from := range saved.Completed {
+		tracker.done[from] = true
+	}
+	return tracker, nil
+}
+
+func (t *reingestTracker) isDone(from int32) bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.done[from]
+}
+
+// markDone records the chunk starting at `from` as completed and
+// writes the progress file
+func (t *reingestTracker) markDone(from int32) error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.done[from] = true
+	t.progress.Completed = append(t.progress.Completed, from)
+	if t.path == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(t.progress)
+	if err != nil {
+		return errors.Wrap(err, "could not encode progress")
+	}
+	// Write to a temporary file first so the progress file is never corrupted
+	tmpPath := t.path + ".tmp"
+	if err = ioutil.WriteFile(tmpPath, data, 0644); err != nil {
+		return errors.Wrap(err, "could not write progress file")
+	}
+	return errors.Wrap(os.Rename(tmpPath, t.path), "could not rename progress file")
+}
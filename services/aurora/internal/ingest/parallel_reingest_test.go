@@ -0,0 +1,194 @@
+package ingest
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/diamnet/go/services/aurora/internal/ledger"
+	"github.com/diamnet/go/services/aurora/internal/test"
+	"github.com/stretchr/testify/assert"
+)
+
+type historySnapshot struct {
+	Ledgers      []string
+	Transactions []string
+	Operations   []string
+	Effects      []string
+}
+
+func loadHistorySnapshot(tt *test.T) historySnapshot {
+	var snapshot historySnapshot
+	queries := map[*[]string]string{
+		&snapshot.Ledgers:      "SELECT ledger_hash FROM history_ledgers ORDER BY id",
+		&snapshot.Transactions: "SELECT transaction_hash FROM history_transactions ORDER BY id",
+		&snapshot.Operations:   "SELECT id || ':' || type || ':' || details FROM history_operations ORDER BY id",
+		&snapshot.Effects: `SELECT heff.history_operation_id || ':' || heff."order" || ':' || hacc.address || ':' || heff.details
+			FROM history_effects heff
+			JOIN history_accounts hacc ON hacc.id = heff.history_account_id
+			ORDER BY heff.history_operation_id, heff."order"`,
+	}
+	for dest, query := range queries {
+		tt.Require.NoError(tt.AuroraSession().SelectRaw(dest, query))
+	}
+	return snapshot
+}
+
+func TestReingestRangeParallel(t *testing.T) {
+	tt := test.Start(t).Scenario("kahuna")
+	defer tt.Finish()
+	is := sys(tt, Config{EnableAssetStats: false, CursorName: "HORIZON"})
+	is.SkipCursorUpdate = true
+
+	sequential := loadHistorySnapshot(tt)
+	tt.Require.NotEmpty(sequential.Operations)
+
+	dir, err := ioutil.TempDir("", "reingest")
+	tt.Require.NoError(err)
+	defer os.RemoveAll(dir)
+	progressFile := filepath.Join(dir, "progress.json")
+
+	latest := ledger.CurrentState().CoreLatest
+	ingested, err := is.ReingestRangeParallel(latest, 1, ParallelReingestConfig{
+		Workers:      4,
+		ChunkSize:    7,
+		ProgressFile: progressFile,
+	})
+	tt.Require.NoError(err)
+	tt.Assert.Equal(int(latest), ingested)
+	tt.Assert.Equal(sequential, loadHistorySnapshot(tt))
+
+	// all chunks are completed so nothing is reingested
+	ingested, err = is.ReingestRangeParallel(1, latest, ParallelReingestConfig{
+		Workers:      4,
+		ChunkSize:    7,
+		ProgressFile: progressFile,
+	})
+	tt.Require.NoError(err)
+	tt.Assert.Equal(0, ingested)
+
+	// progress file does not match the range
+	_, err = is.ReingestRangeParallel(1, latest, ParallelReingestConfig{
+		Workers:      4,
+		ChunkSize:    8,
+		ProgressFile: progressFile,
+	})
+	tt.Assert.Error(err)
+}
+
+type historyReferencesSnapshot struct {
+	Accounts                []string
+	Assets                  []string
+	Trades                  []string
+	OperationParticipants   []string
+	TransactionParticipants []string
+}
+
+// loadHistoryReferences loads the rows of `history_accounts` and
+// `history_assets` and the rows referencing them, with the references replaced
+// by the addresses and assets they point to.
+func loadHistoryReferences(tt *test.T) historyReferencesSnapshot {
+	var snapshot historyReferencesSnapshot
+	queries := map[*[]string]string{
+		&snapshot.Accounts: "SELECT address FROM history_accounts ORDER BY address",
+		&snapshot.Assets: `SELECT asset_type || ':' || asset_code || ':' || asset_issuer
+			FROM history_assets ORDER BY asset_type, asset_code, asset_issuer`,
+		&snapshot.Trades: `SELECT htrd.history_operation_id || ':' || htrd."order" || ':' ||
+			base_acc.address || ':' || base_asset.asset_code || ':' ||
+			counter_acc.address || ':' || counter_asset.asset_code
+			FROM history_trades htrd
+			JOIN history_accounts base_acc ON base_acc.id = htrd.base_account_id
+			JOIN history_assets base_asset ON base_asset.id = htrd.base_asset_id
+			JOIN history_accounts counter_acc ON counter_acc.id = htrd.counter_account_id
+			JOIN history_assets counter_asset ON counter_asset.id = htrd.counter_asset_id
+			ORDER BY htrd.history_operation_id, htrd."order"`,
+		&snapshot.OperationParticipants: `SELECT hopp.history_operation_id || ':' || hacc.address
+			FROM history_operation_participants hopp
+			JOIN history_accounts hacc ON hacc.id = hopp.history_account_id
+			ORDER BY hopp.history_operation_id, hacc.address`,
+		&snapshot.TransactionParticipants: `SELECT htp.history_transaction_id || ':' || hacc.address
+			FROM history_transaction_participants htp
+			JOIN history_accounts hacc ON hacc.id = htp.history_account_id
+			ORDER BY htp.history_transaction_id, hacc.address`,
+	}
+	for dest, query := range queries {
+		tt.Require.NoError(tt.AuroraSession().SelectRaw(dest, query))
+	}
+	return snapshot
+}
+
+// clearHistory removes all the history, including accounts and assets, and
+// restarts their ids.
+func clearHistory(tt *test.T) {
+	_, err := tt.AuroraSession().ExecRaw(`TRUNCATE history_accounts, history_assets,
+		history_effects, history_ledgers, history_operation_participants,
+		history_operations, history_trades, history_transaction_participants,
+		history_transactions RESTART IDENTITY CASCADE`)
+	tt.Require.NoError(err)
+	_, err = tt.AuroraSession().ExecRaw("ALTER SEQUENCE history_accounts_id_seq RESTART")
+	tt.Require.NoError(err)
+}
+
+func TestReingestRangeParallelReferences(t *testing.T) {
+	tt := test.Start(t).Scenario("kahuna")
+	defer tt.Finish()
+	is := sys(tt, Config{EnableAssetStats: false, CursorName: "HORIZON"})
+	is.SkipCursorUpdate = true
+	latest := ledger.CurrentState().CoreLatest
+
+	clearHistory(tt)
+	_, err := is.ReingestRange(1, latest)
+	tt.Require.NoError(err)
+	sequential := loadHistoryReferences(tt)
+	tt.Require.NotEmpty(sequential.Accounts)
+	tt.Require.NotEmpty(sequential.Trades)
+
+	// ranges are reingested in a different order by every run
+	for run := 0; run < 3; run++ {
+		clearHistory(tt)
+		_, err = is.ReingestRangeParallel(1, latest, ParallelReingestConfig{
+			Workers:   4,
+			ChunkSize: 3,
+		})
+		tt.Require.NoError(err)
+		tt.Assert.Equal(sequential, loadHistoryReferences(tt))
+	}
+}
+
+func TestReingestTracker(t *testing.T) {
+	dir, err := ioutil.TempDir("", "reingest")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "progress.json")
+
+	expected := reingestProgress{From: 1, To: 100, ChunkSize: 10}
+	tracker, err := loadReingestTracker(path, expected)
+	assert.NoError(t, err)
+	assert.False(t, tracker.isDone(1))
+
+	assert.NoError(t, tracker.markDone(11))
+	assert.NoError(t, tracker.markDone(1))
+
+	data, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	var saved reingestProgress
+	assert.NoError(t, json.Unmarshal(data, &saved))
+	assert.Equal(t, []int32{11, 1}, saved.Completed)
+
+	tracker, err = loadReingestTracker(path, expected)
+	assert.NoError(t, err)
+	assert.True(t, tracker.isDone(1))
+	assert.True(t, tracker.isDone(11))
+	assert.False(t, tracker.isDone(21))
+
+	_, err = loadReingestTracker(path, reingestProgress{From: 1, To: 200, ChunkSize: 10})
+	assert.EqualError(t, err, "progress file "+path+" was created for range 1-100 with chunk size 10")
+
+	// progress is not persisted without a path
+	tracker, err = loadReingestTracker("", expected)
+	assert.NoError(t, err)
+	assert.NoError(t, tracker.markDone(1))
+	assert.True(t, tracker.isDone(1))
+}
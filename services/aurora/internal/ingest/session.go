@@ -36,6 +36,14 @@ func (is *Session) Run() {
 
 	defer is.Ingestion.Rollback()
 
+	if is.CreateHistoryIDs {
+		is.Err = is.createHistoryIDs()
+		if is.Err != nil {
+			is.Err = errors.Wrap(is.Err, "createHistoryIDs error")
+			return
+		}
+	}
+
 	var sectionStart, i int32
 
 	for is.Cursor.NextLedger() {
@@ -86,6 +94,7 @@ func (is *Session) Run() {
 		return
 	}
 
+	is.publish()
 	is.Err = errors.Wrap(is.reportCursorState(), "reportCursorState error")
 }
 
@@ -396,10 +405,19 @@ func (is *Session) ingestLedger() {
 		is.Cursor.SuccessfulLedgerOperationCount(),
 	)
 
+	if is.Publisher != nil {
+		is.changes = newLedgerChanges(is.Cursor.LedgerSequence())
+	}
+
 	for is.Cursor.NextTx() {
 		is.ingestTransaction()
 	}
 
+	if is.changes != nil && is.Err == nil {
+		is.closed = append(is.closed, is.changes.event())
+		is.changes = nil
+	}
+
 	is.Ingested++
 	if is.Metrics != nil {
 		is.Metrics.IngestLedgerTimer.Update(time.Since(start))
@@ -524,44 +542,8 @@ func (is *Session) ingestTrades() {
 		return
 	}
 
-	cursor := is.Cursor
-	buyer := cursor.OperationSourceAccount()
-	buyOfferExists := false
-	buyOffer := xdr.OfferEntry{}
-	var trades []xdr.ClaimOfferAtom
-
-	switch cursor.OperationType() {
-	case xdr.OperationTypePathPayment:
-		trades = cursor.OperationResult().
-			MustPathPaymentResult().
-			MustSuccess().
-			Offers
-
-	case xdr.OperationTypeManageBuyOffer:
-		manageOfferResult := cursor.OperationResult().MustManageBuyOfferResult().MustSuccess()
-		trades = manageOfferResult.OffersClaimed
-		buyOffer, buyOfferExists = manageOfferResult.Offer.GetOffer()
-
-	case xdr.OperationTypeManageSellOffer:
-		manageOfferResult := cursor.OperationResult().MustManageSellOfferResult().MustSuccess()
-		trades = manageOfferResult.OffersClaimed
-		buyOffer, buyOfferExists = manageOfferResult.Offer.GetOffer()
-
-	case xdr.OperationTypeCreatePassiveSellOffer:
-		result := cursor.OperationResult()
-
-		// KNOWN ISSUE:  diamnet-core creates results for CreatePassiveOffer operations
-		// with the wrong result arm set.
-		if result.Type == xdr.OperationTypeManageSellOffer {
-			manageOfferResult := result.MustManageSellOfferResult().MustSuccess()
-			trades = manageOfferResult.OffersClaimed
-			buyOffer, buyOfferExists = manageOfferResult.Offer.GetOffer()
-		} else {
-			passiveOfferResult := result.MustCreatePassiveSellOfferResult().MustSuccess()
-			trades = passiveOfferResult.OffersClaimed
-			buyOffer, buyOfferExists = passiveOfferResult.Offer.GetOffer()
-		}
-	}
+	buyer := is.Cursor.OperationSourceAccount()
+	trades, buyOffer, buyOfferExists := operationTrades(is.Cursor)
 	q := history.Q{Session: is.Ingestion.DB}
 	for i, trade := range trades {
 		// diamnet-core will opportunisticly garbage collect invalid offers (in the
@@ -601,6 +583,44 @@ func (is *Session) ingestTrades() {
 	}
 }
 
+// operationTrades returns the offers claimed by the current operation of the
+// cursor, which must be successful, and the offer it created, if any.
+func operationTrades(cursor *Cursor) (trades []xdr.ClaimOfferAtom, buyOffer xdr.OfferEntry, buyOfferExists bool) {
+	switch cursor.OperationType() {
+	case xdr.OperationTypePathPayment:
+		trades = cursor.OperationResult().
+			MustPathPaymentResult().
+			MustSuccess().
+			Offers
+
+	case xdr.OperationTypeManageBuyOffer:
+		manageOfferResult := cursor.OperationResult().MustManageBuyOfferResult().MustSuccess()
+		trades = manageOfferResult.OffersClaimed
+		buyOffer, buyOfferExists = manageOfferResult.Offer.GetOffer()
+
+	case xdr.OperationTypeManageSellOffer:
+		manageOfferResult := cursor.OperationResult().MustManageSellOfferResult().MustSuccess()
+		trades = manageOfferResult.OffersClaimed
+		buyOffer, buyOfferExists = manageOfferResult.Offer.GetOffer()
+
+	case xdr.OperationTypeCreatePassiveSellOffer:
+		result := cursor.OperationResult()
+
+		// KNOWN ISSUE:  diamnet-core creates results for CreatePassiveOffer operations
+		// with the wrong result arm set.
+		if result.Type == xdr.OperationTypeManageSellOffer {
+			manageOfferResult := result.MustManageSellOfferResult().MustSuccess()
+			trades = manageOfferResult.OffersClaimed
+			buyOffer, buyOfferExists = manageOfferResult.Offer.GetOffer()
+		} else {
+			passiveOfferResult := result.MustCreatePassiveSellOfferResult().MustSuccess()
+			trades = passiveOfferResult.OffersClaimed
+			buyOffer, buyOfferExists = passiveOfferResult.Offer.GetOffer()
+		}
+	}
+	return
+}
+
 func (is *Session) ingestTradeEffects(effects *EffectIngestion, buyer xdr.AccountId, claims []xdr.ClaimOfferAtom) {
 	if is.Err != nil {
 		return
@@ -645,6 +665,13 @@ func (is *Session) ingestTransaction() {
 		return
 	}
 
+	if is.changes != nil {
+		is.Err = is.changes.addTransaction(is.Cursor.Transaction(), is.Cursor.TransactionFee())
+		if is.Err != nil {
+			return
+		}
+	}
+
 	if !is.Config.IngestFailedTransactions && !is.Cursor.Transaction().IsSuccessful() {
 		return
 	}
@@ -884,6 +911,20 @@ func (is *Session) operationFlagDetails(result map[string]interface{}, f int32,
 	result[prefix+"_flags_s"] = s
 }
 
+// publish notifies the publisher about the ledgers ingested in this session.
+// Publishing errors are logged only: streams will catch up on the next ledger.
+func (is *Session) publish() {
+	for _, event := range is.closed {
+		if err := is.Publisher.Publish(event); err != nil {
+			log.WithFields(ilog.F{
+				"ledger": event.Sequence,
+				"err":    err.Error(),
+			}).Error("Error publishing ledger notification")
+		}
+	}
+	is.closed = nil
+}
+
 // reportCursorState makes an http request to the configured diamnet-core server
 // to report that it has finished processing the data being ingested.  This
 // allows diamnet-core to free that storage when next it runs its own
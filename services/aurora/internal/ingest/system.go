@@ -183,9 +183,16 @@ func (i *System) ReingestOutdated() (n int, err error) {
 
 // ReingestRange reingests a range of ledgers, from `start` to `end`, inclusive.
 func (i *System) ReingestRange(start, end int32) (int, error) {
+	return i.reingestRange(start, end, false)
+}
+
+// reingestRange reingests a range of ledgers in a single session, see
+// Session.CreateHistoryIDs.
+func (i *System) reingestRange(start, end int32, createHistoryIDs bool) (int, error) {
 	is := NewSession(i)
 	is.Cursor = NewCursor(start, end, i)
 	is.ClearExisting = true
+	is.CreateHistoryIDs = createHistoryIDs
 
 	is.Run()
 	log.WithField("start", start).
@@ -15,6 +15,7 @@ import (
 	"github.com/diamnet/go/services/aurora/internal/db2/history"
 	"github.com/diamnet/go/services/aurora/internal/expingest"
 	"github.com/diamnet/go/services/aurora/internal/ingest"
+	"github.com/diamnet/go/services/aurora/internal/pubsub"
 	"github.com/diamnet/go/services/aurora/internal/simplepath"
 	"github.com/diamnet/go/services/aurora/internal/txsub"
 	results "github.com/diamnet/go/services/aurora/internal/txsub/results/db"
@@ -68,6 +69,14 @@ func initIngester(app *App) {
 
 	app.ingester.SkipCursorUpdate = app.config.SkipCursorUpdate
 	app.ingester.HistoryRetentionCount = app.config.HistoryRetentionCount
+	if app.config.StreamUpdatesPostgres {
+		// notifications reach the local streams through ListenPostgres
+		app.ingester.Publisher = &pubsub.PostgresPublisher{
+			Session: app.AuroraSession(context.Background()),
+		}
+	} else {
+		app.ingester.Publisher = app.ledgerUpdates
+	}
 }
 
 func initExpIngester(app *App, orderBookGraph *orderbook.OrderBookGraph) {
@@ -81,15 +90,16 @@ func initExpIngester(app *App, orderBookGraph *orderbook.OrderBookGraph) {
 
 	var err error
 	app.expingester, err = expingest.NewSystem(expingest.Config{
-		CoreSession:    app.CoreSession(context.Background()),
-		HistorySession: app.AuroraSession(context.Background()),
-		// TODO:
-		// Use the first archive for now. We don't have a mechanism to
-		// use multiple archives at the same time currently.
-		HistoryArchiveURL: app.config.HistoryArchiveURLs[0],
-		DiamNetCoreURL:    app.config.DiamNetCoreURL,
-		OrderBookGraph:    orderBookGraph,
-		TempSet:           tempSet,
+		CoreSession:            app.CoreSession(context.Background()),
+		HistorySession:         app.AuroraSession(context.Background()),
+		HistoryArchiveURLs:     app.config.HistoryArchiveURLs,
+		HistoryArchiveQuorum:   app.config.HistoryArchiveQuorum,
+		HistoryArchiveCacheDir: app.config.HistoryArchiveCacheDir,
+		DiamNetCoreURL:         app.config.DiamNetCoreURL,
+		OrderBookGraph:         orderBookGraph,
+		TempSet:                tempSet,
+
+		OrderBookSnapshotPath: app.config.OrderBookSnapshotPath,
 	})
 	if err != nil {
 		log.Panic(err)
@@ -144,12 +154,14 @@ func initDbMetrics(app *App) {
 	app.auroraConnGauge = metrics.NewGauge()
 	app.coreConnGauge = metrics.NewGauge()
 	app.goroutineGauge = metrics.NewGauge()
+	app.ingestionLagGauge = metrics.NewGauge()
 	app.metrics.Register("history.latest_ledger", app.historyLatestLedgerGauge)
 	app.metrics.Register("history.elder_ledger", app.historyElderLedgerGauge)
 	app.metrics.Register("diamnet_core.latest_ledger", app.coreLatestLedgerGauge)
 	app.metrics.Register("history.open_connections", app.auroraConnGauge)
 	app.metrics.Register("diamnet_core.open_connections", app.coreConnGauge)
 	app.metrics.Register("goroutines", app.goroutineGauge)
+	app.metrics.Register("history.ingestion_lag", app.ingestionLagGauge)
 }
 
 func initIngesterMetrics(app *App) {
@@ -82,6 +82,12 @@ func (action OperationShowAction) Handle(w http.ResponseWriter, r *http.Request)
 	ap.Execute(&action)
 }
 
+func (action OrderBookDepthAction) Handle(w http.ResponseWriter, r *http.Request) {
+	ap := &action.Action
+	ap.Prepare(w, r)
+	ap.Execute(&action)
+}
+
 func (action OrderBookShowAction) Handle(w http.ResponseWriter, r *http.Request) {
 	ap := &action.Action
 	ap.Prepare(w, r)
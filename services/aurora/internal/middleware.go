@@ -2,7 +2,9 @@ package aurora
 
 import (
 	"context"
+	"math"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -11,6 +13,7 @@ import (
 	"github.com/diamnet/go/services/aurora/internal/errors"
 	"github.com/diamnet/go/services/aurora/internal/hchi"
 	"github.com/diamnet/go/services/aurora/internal/httpx"
+	"github.com/diamnet/go/services/aurora/internal/ratelimit"
 	"github.com/diamnet/go/services/aurora/internal/render"
 	hProblem "github.com/diamnet/go/services/aurora/internal/render/problem"
 	"github.com/diamnet/go/support/log"
@@ -108,7 +111,7 @@ func logStartOfRequest(ctx context.Context, r *http.Request, streaming bool) {
 		"ip":             remoteAddrIP(r),
 		"ip_port":        r.RemoteAddr,
 		"method":         r.Method,
-		"path":           r.URL.String(),
+		"path":           requestURL(r),
 		"streaming":      streaming,
 	}).Info("Starting request")
 }
@@ -133,22 +136,70 @@ func logEndOfRequest(ctx context.Context, r *http.Request, duration time.Duratio
 		"ip":             remoteAddrIP(r),
 		"ip_port":        r.RemoteAddr,
 		"method":         r.Method,
-		"path":           r.URL.String(),
+		"path":           requestURL(r),
 		"route":          routePattern,
 		"status":         mw.Status(),
 		"streaming":      streaming,
 	}).Info("Finished request")
 }
 
+// requestURL returns the URL of the request to log, with its API key redacted.
+func requestURL(r *http.Request) string {
+	query := r.URL.Query()
+	if query.Get(ratelimit.APIKeyParam) == "" {
+		return r.URL.String()
+	}
+
+	query.Set(ratelimit.APIKeyParam, "redacted")
+	u := *r.URL
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
 func firstXForwardedFor(r *http.Request) string {
 	return strings.TrimSpace(strings.SplitN(r.Header.Get("X-Forwarded-For"), ",", 2)[0])
 }
 
+// RateLimitMiddleware identifies the client of the request by its API key or
+// its remote IP address, and limits its requests weighted by the cost of the
+// route. The client is stored in the request context so that its streams are
+// limited too.
 func (w *web) RateLimitMiddleware(next http.Handler) http.Handler {
 	if w.rateLimiter == nil {
 		return next
 	}
-	return w.rateLimiter.RateLimit(next)
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		client, err := w.rateLimiter.Client(r, remoteAddrIP(r))
+		if err != nil {
+			problem.Render(r.Context(), rw, err)
+			return
+		}
+		r = r.WithContext(ratelimit.WithClient(r.Context(), client))
+
+		result, err := w.rateLimiter.RateLimit(client, w.rateLimiter.Cost(r.URL.Path))
+		if err != nil {
+			problem.Render(r.Context(), rw, err)
+			return
+		}
+		if result != nil {
+			setRateLimitHeaders(rw, *result)
+			if result.Limited {
+				RateLimitExceededAction{}.ServeHTTP(rw, r)
+				return
+			}
+		}
+
+		next.ServeHTTP(rw, r)
+	})
+}
+
+func setRateLimitHeaders(w http.ResponseWriter, result ratelimit.Result) {
+	w.Header().Set("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+	w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	w.Header().Set("X-RateLimit-Reset", strconv.Itoa(int(math.Ceil(result.ResetAfter.Seconds()))))
+	if result.RetryAfter >= 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(result.RetryAfter.Seconds()))))
+	}
 }
 
 // recoverMiddleware helps the server recover from panics. It ensures that
@@ -170,14 +221,24 @@ func recoverMiddleware(h http.Handler) http.Handler {
 }
 
 // requestMetricsMiddleware records success and failures using a meter, and times every request
+// by route, method and status
 func requestMetricsMiddleware(h http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		app := AppFromContext(r.Context())
 		mw := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
 
-		app.web.requestTimer.Time(func() {
-			h.ServeHTTP(mw.(http.ResponseWriter), r)
-		})
+		start := time.Now()
+		h.ServeHTTP(mw.(http.ResponseWriter), r)
+		duration := time.Since(start)
+		app.web.requestTimer.Update(duration)
+
+		routePattern := chi.RouteContext(r.Context()).RoutePattern()
+		// Can be empty when request did not reached the final route, see
+		// logEndOfRequest
+		if routePattern == "" {
+			routePattern = "undefined"
+		}
+		app.web.requestMetrics.Observe(routePattern, r.Method, mw.Status(), duration)
 
 		if 200 <= mw.Status() && mw.Status() < 400 {
 			// a success is in [200, 400)
@@ -1,13 +1,17 @@
 package aurora
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"strconv"
 	"testing"
 
+	"github.com/diamnet/go/services/aurora/internal/ratelimit"
 	"github.com/diamnet/go/services/aurora/internal/test"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
-	"github.com/throttled/throttled"
 )
 
 type RateLimitMiddlewareTestSuite struct {
@@ -24,10 +28,8 @@ func (suite *RateLimitMiddlewareTestSuite) SetupSuite() {
 
 func (suite *RateLimitMiddlewareTestSuite) SetupTest() {
 	suite.c = NewTestConfig()
-	suite.c.RateQuota = &throttled.RateQuota{
-		MaxRate:  throttled.PerHour(10),
-		MaxBurst: 9,
-	}
+	rateQuota := ratelimit.PerHour(10, 9)
+	suite.c.RateQuota = &rateQuota
 	suite.app = NewApp(suite.c)
 	suite.rh = NewRequestHelper(suite.app)
 }
@@ -118,10 +120,8 @@ func TestRateLimit_Redis(t *testing.T) {
 	ht := StartHTTPTest(t, "base")
 	defer ht.Finish()
 	c := NewTestConfig()
-	c.RateQuota = &throttled.RateQuota{
-		MaxRate:  throttled.PerHour(10),
-		MaxBurst: 9,
-	}
+	rateQuota := ratelimit.PerHour(10, 9)
+	c.RateQuota = &rateQuota
 	c.RedisURL = "redis://127.0.0.1:6379/"
 	app := NewApp(c)
 	defer app.Close()
@@ -142,3 +142,54 @@ func TestRateLimit_Redis(t *testing.T) {
 	w = rh.Get("/", test.RequestHelperRemoteAddr("127.0.0.2"))
 	assert.Equal(t, 200, w.Code)
 }
+
+// Limits requests with an API key by the quota of the key, weighted by the
+// cost of the route.
+func TestRateLimit_APIKey(t *testing.T) {
+	ht := StartHTTPTest(t, "base")
+	defer ht.Finish()
+
+	dir, err := ioutil.TempDir("", "ratelimit")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// key_sha256 is the hash of "foo"
+	path := filepath.Join(dir, "ratelimit.toml")
+	err = ioutil.WriteFile(path, []byte(`
+[costs]
+"/ledgers" = 5
+
+[[api_keys]]
+name = "acme"
+key_sha256 = "2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae"
+requests_per_hour = 20
+burst = 19
+`), 0600)
+	require.NoError(t, err)
+
+	c := NewTestConfig()
+	rateQuota := ratelimit.PerHour(10, 9)
+	c.RateQuota = &rateQuota
+	c.RateLimitConfigPath = path
+	app := NewApp(c)
+	defer app.Close()
+	rh := NewRequestHelper(app)
+
+	w := rh.Get("/", test.RequestHelperAPIKey("foo"))
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "20", w.Header().Get("X-RateLimit-Limit"))
+	assert.Equal(t, "19", w.Header().Get("X-RateLimit-Remaining"))
+
+	w = rh.Get("/ledgers?api_key=foo")
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "14", w.Header().Get("X-RateLimit-Remaining"))
+
+	// requests without an API key are limited by IP address
+	w = rh.Get("/")
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "10", w.Header().Get("X-RateLimit-Limit"))
+	assert.Equal(t, "9", w.Header().Get("X-RateLimit-Remaining"))
+
+	w = rh.Get("/", test.RequestHelperAPIKey("bar"))
+	assert.Equal(t, 401, w.Code)
+}
@@ -22,6 +22,17 @@ type Path struct {
 	DestinationAmount xdr.Int64
 }
 
+// SplitPath is the result returned by a path finder when a payment is divided
+// across several paths. Allocations are the individual paths and SourceAmount /
+// DestinationAmount are the totals for the combined payment.
+type SplitPath struct {
+	Source            xdr.Asset
+	SourceAmount      xdr.Int64
+	Destination       xdr.Asset
+	DestinationAmount xdr.Int64
+	Allocations       []Path
+}
+
 // Finder finds paths.
 type Finder interface {
 	// Returns path for a Query of a maximum length `maxLength`
@@ -36,4 +47,19 @@ type Finder interface {
 		destinationAsset xdr.Asset,
 		maxLength uint,
 	) ([]Path, error)
+	// FindSplitPaths returns, for each source asset in the Query, a payment
+	// which delivers the destination amount by splitting it across at
+	// most `maxParts` paths of a maximum length `maxLength`
+	FindSplitPaths(q Query, maxLength uint, maxParts uint) ([]SplitPath, error)
+	// FindFixedSplitPaths returns a payment which spends `amountToSpend`
+	// of `sourceAsset` by splitting it across at most `maxParts` paths
+	// each of which end with delivering `destinationAsset`
+	FindFixedSplitPaths(
+		sourceAccount *xdr.AccountId,
+		sourceAsset xdr.Asset,
+		amountToSpend xdr.Int64,
+		destinationAsset xdr.Asset,
+		maxLength uint,
+		maxParts uint,
+	) ([]SplitPath, error)
 }
@@ -0,0 +1,19 @@
+// +build go1.11
+
+package prometheus
+
+import "database/sql"
+
+var dbStatsGauges = []dbStat{
+	{"db_max_open_connections", "Maximum number of open connections to the database.", func(s sql.DBStats) float64 { return float64(s.MaxOpenConnections) }},
+	{"db_open_connections", "Number of established connections to the database.", func(s sql.DBStats) float64 { return float64(s.OpenConnections) }},
+	{"db_in_use_connections", "Number of connections currently in use.", func(s sql.DBStats) float64 { return float64(s.InUse) }},
+	{"db_idle_connections", "Number of idle connections.", func(s sql.DBStats) float64 { return float64(s.Idle) }},
+}
+
+var dbStatsCounters = []dbStat{
+	{"db_wait_count_total", "Total number of connections waited for.", func(s sql.DBStats) float64 { return float64(s.WaitCount) }},
+	{"db_wait_duration_seconds_total", "Total time blocked waiting for a new connection.", func(s sql.DBStats) float64 { return s.WaitDuration.Seconds() }},
+	{"db_max_idle_closed_total", "Total number of connections closed due to the maximum of idle connections.", func(s sql.DBStats) float64 { return float64(s.MaxIdleClosed) }},
+	{"db_max_lifetime_closed_total", "Total number of connections closed due to their maximum lifetime.", func(s sql.DBStats) float64 { return float64(s.MaxLifetimeClosed) }},
+}
@@ -0,0 +1,13 @@
+// +build !go1.11
+
+package prometheus
+
+import "database/sql"
+
+// sql.DBStats only has OpenConnections before Go 1.11.
+
+var dbStatsGauges = []dbStat{
+	{"db_open_connections", "Number of established connections to the database.", func(s sql.DBStats) float64 { return float64(s.OpenConnections) }},
+}
+
+var dbStatsCounters []dbStat
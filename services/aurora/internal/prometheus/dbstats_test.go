@@ -0,0 +1,24 @@
+// +build go1.11
+
+package prometheus
+
+import (
+	"bytes"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDBStatsGo111(t *testing.T) {
+	var buf bytes.Buffer
+	NewWriter(&buf, "aurora").DBStats(map[string]sql.DBStats{
+		"history": {OpenConnections: 3, InUse: 1, Idle: 2, WaitDuration: 1500 * time.Millisecond},
+	})
+	out := buf.String()
+
+	assert.Contains(t, out, `aurora_db_in_use_connections{db="history"} 1`+"\n")
+	assert.Contains(t, out, "# TYPE aurora_db_wait_duration_seconds_total counter\n")
+	assert.Contains(t, out, `aurora_db_wait_duration_seconds_total{db="history"} 1.5`+"\n")
+}
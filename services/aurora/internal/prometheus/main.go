@@ -0,0 +1,306 @@
+// Package prometheus writes aurora's metrics in the Prometheus text exposition
+// format, see https://prometheus.io/docs/instrumenting/exposition_formats/
+package prometheus
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	metrics "github.com/rcrowley/go-metrics"
+)
+
+// ContentType is the content type of the Prometheus text exposition format.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// DefaultBuckets are the upper bounds, in seconds, of the buckets of request
+// durations.
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// quantiles reported for go-metrics timers and histograms.
+var quantiles = []float64{0.5, 0.75, 0.95, 0.99, 0.999}
+
+// Label is a label of a sample.
+type Label struct {
+	Name  string
+	Value string
+}
+
+// Writer writes metrics in the Prometheus text exposition format. The names of
+// the metrics are prefixed with the namespace. All the samples of a metric must
+// be written one after the other.
+type Writer struct {
+	w         io.Writer
+	namespace string
+	family    string
+}
+
+// NewWriter returns a Writer writing to w.
+func NewWriter(w io.Writer, namespace string) *Writer {
+	return &Writer{w: w, namespace: namespace}
+}
+
+// Counter writes a sample of a counter.
+func (w *Writer) Counter(name, help string, value float64, labels ...Label) {
+	name = w.describe(name, help, "counter")
+	w.sample(name, value, labels)
+}
+
+// Gauge writes a sample of a gauge.
+func (w *Writer) Gauge(name, help string, value float64, labels ...Label) {
+	name = w.describe(name, help, "gauge")
+	w.sample(name, value, labels)
+}
+
+// Summary writes the samples of a summary. values are the values of the
+// quantiles, in the same order.
+func (w *Writer) Summary(name, help string, quantiles, values []float64, sum float64, count int64, labels ...Label) {
+	name = w.describe(name, help, "summary")
+	for i, q := range quantiles {
+		w.sample(name, values[i], append(labels, Label{"quantile", formatFloat(q)}))
+	}
+	w.sample(name+"_sum", sum, labels)
+	w.sample(name+"_count", float64(count), labels)
+}
+
+// Histogram writes the samples of a histogram. counts are the cumulative
+// counts of the buckets, in the same order as their upper bounds.
+func (w *Writer) Histogram(name, help string, buckets []float64, counts []uint64, sum float64, count uint64, labels ...Label) {
+	name = w.describe(name, help, "histogram")
+	for i, le := range buckets {
+		w.sample(name+"_bucket", float64(counts[i]), append(labels, Label{"le", formatFloat(le)}))
+	}
+	w.sample(name+"_bucket", float64(count), append(labels, Label{"le", "+Inf"}))
+	w.sample(name+"_sum", sum, labels)
+	w.sample(name+"_count", float64(count), labels)
+}
+
+// Registry writes the metrics of a go-metrics registry. The dots of their
+// names are replaced with underscores. Counters and meters are written as
+// counters, gauges as gauges, and timers, in seconds, and histograms as
+// summaries.
+func (w *Writer) Registry(r metrics.Registry) {
+	all := map[string]interface{}{}
+	var names []string
+	r.Each(func(name string, i interface{}) {
+		all[name] = i
+		names = append(names, name)
+	})
+	sort.Strings(names)
+
+	for _, name := range names {
+		help := "aurora metric " + name
+		switch metric := all[name].(type) {
+		case metrics.Counter:
+			w.Counter(name+"_total", help, float64(metric.Count()))
+		case metrics.Gauge:
+			w.Gauge(name, help, float64(metric.Value()))
+		case metrics.GaugeFloat64:
+			w.Gauge(name, help, metric.Value())
+		case metrics.Meter:
+			w.Counter(name+"_total", help, float64(metric.Count()))
+		case metrics.Timer:
+			t := metric.Snapshot()
+			values := t.Percentiles(quantiles)
+			for i := range values {
+				values[i] /= float64(time.Second)
+			}
+			w.Summary(name+"_seconds", help, quantiles, values, float64(t.Sum())/float64(time.Second), t.Count())
+		case metrics.Histogram:
+			h := metric.Snapshot()
+			w.Summary(name, help, quantiles, h.Percentiles(quantiles), float64(h.Sum()), h.Count())
+		}
+	}
+}
+
+// dbStat is a connection pool stat of a database.
+type dbStat struct {
+	name  string
+	help  string
+	value func(sql.DBStats) float64
+}
+
+// DBStats writes the connection pool stats of databases, labeled with the
+// keys of stats. Before Go 1.11 only the number of open connections is known.
+func (w *Writer) DBStats(stats map[string]sql.DBStats) {
+	var dbs []string
+	for db := range stats {
+		dbs = append(dbs, db)
+	}
+	sort.Strings(dbs)
+
+	for _, g := range dbStatsGauges {
+		for _, db := range dbs {
+			w.Gauge(g.name, g.help, g.value(stats[db]), Label{"db", db})
+		}
+	}
+	for _, c := range dbStatsCounters {
+		for _, db := range dbs {
+			w.Counter(c.name, c.help, c.value(stats[db]), Label{"db", db})
+		}
+	}
+}
+
+// describe writes the HELP and TYPE lines of a metric, unless its samples are
+// being written, and returns its full name.
+func (w *Writer) describe(name, help, typ string) string {
+	name = metricName(w.namespace, name)
+	if name == w.family {
+		return name
+	}
+	w.family = name
+	fmt.Fprintf(w.w, "# HELP %s %s\n", name, strings.NewReplacer("\\", `\\`, "\n", `\n`).Replace(help))
+	fmt.Fprintf(w.w, "# TYPE %s %s\n", name, typ)
+	return name
+}
+
+func (w *Writer) sample(name string, value float64, labels []Label) {
+	if len(labels) == 0 {
+		fmt.Fprintf(w.w, "%s %s\n", name, formatFloat(value))
+		return
+	}
+
+	pairs := make([]string, len(labels))
+	for i, l := range labels {
+		pairs[i] = l.Name + `="` + labelValueReplacer.Replace(l.Value) + `"`
+	}
+	fmt.Fprintf(w.w, "%s{%s} %s\n", name, strings.Join(pairs, ","), formatFloat(value))
+}
+
+var labelValueReplacer = strings.NewReplacer("\\", `\\`, "\"", `\"`, "\n", `\n`)
+
+// metricName returns the name of a metric prefixed with the namespace, with
+// the characters which are not valid in metric names replaced with
+// underscores.
+func metricName(namespace, name string) string {
+	if namespace != "" {
+		name = namespace + "_" + name
+	}
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' || r == ':' {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+func formatFloat(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "+Inf"
+	case math.IsInf(f, -1):
+		return "-Inf"
+	case math.IsNaN(f):
+		return "NaN"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+type requestKey struct {
+	route  string
+	method string
+	status int
+}
+
+type requestSeries struct {
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+// RequestMetrics records the durations of HTTP requests in a histogram with
+// route, method and status labels. It's safe for concurrent use.
+type RequestMetrics struct {
+	buckets []float64
+
+	mutex  sync.Mutex
+	series map[requestKey]*requestSeries
+}
+
+// NewRequestMetrics returns RequestMetrics with the DefaultBuckets.
+func NewRequestMetrics() *RequestMetrics {
+	return &RequestMetrics{
+		buckets: DefaultBuckets,
+		series:  map[requestKey]*requestSeries{},
+	}
+}
+
+// methods are the HTTP methods recorded by RequestMetrics, other methods are
+// recorded as "other".
+var methods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodPost:    true,
+	http.MethodPut:     true,
+	http.MethodPatch:   true,
+	http.MethodDelete:  true,
+	http.MethodConnect: true,
+	http.MethodOptions: true,
+	http.MethodTrace:   true,
+}
+
+// Observe records a request. route should be the pattern of the route rather
+// than the path of the request, to keep the number of series bounded. For the
+// same reason unknown methods are recorded as "other".
+func (m *RequestMetrics) Observe(route, method string, status int, duration time.Duration) {
+	if !methods[method] {
+		method = "other"
+	}
+	key := requestKey{route: route, method: method, status: status}
+	seconds := duration.Seconds()
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	s, ok := m.series[key]
+	if !ok {
+		s = &requestSeries{counts: make([]uint64, len(m.buckets))}
+		m.series[key] = s
+	}
+	for i, le := range m.buckets {
+		if seconds <= le {
+			s.counts[i]++
+		}
+	}
+	s.sum += seconds
+	s.count++
+}
+
+// Write writes the request durations histogram.
+func (m *RequestMetrics) Write(w *Writer) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	keys := make([]requestKey, 0, len(m.series))
+	for key := range m.series {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].route != keys[j].route {
+			return keys[i].route < keys[j].route
+		}
+		if keys[i].method != keys[j].method {
+			return keys[i].method < keys[j].method
+		}
+		return keys[i].status < keys[j].status
+	})
+
+	for _, key := range keys {
+		s := m.series[key]
+		w.Histogram(
+			"http_request_duration_seconds",
+			"Duration of HTTP requests by route, method and status.",
+			m.buckets, s.counts, s.sum, s.count,
+			Label{"route", key.route},
+			Label{"method", key.method},
+			Label{"status", strconv.Itoa(key.status)},
+		)
+	}
+}
@@ -0,0 +1,92 @@
+package prometheus
+
+import (
+	"bytes"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	metrics "github.com/rcrowley/go-metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriterRegistry(t *testing.T) {
+	registry := metrics.NewRegistry()
+	gauge := metrics.NewGauge()
+	gauge.Update(42)
+	registry.Register("history.latest_ledger", gauge)
+	meter := metrics.NewMeter()
+	meter.Mark(3)
+	registry.Register("txsub.failed", meter)
+	timer := metrics.NewTimer()
+	timer.Update(2 * time.Second)
+	registry.Register("ingester.ingest_ledger", timer)
+
+	var buf bytes.Buffer
+	NewWriter(&buf, "aurora").Registry(registry)
+
+	assert.Equal(t, `# HELP aurora_history_latest_ledger aurora metric history.latest_ledger
+# TYPE aurora_history_latest_ledger gauge
+aurora_history_latest_ledger 42
+# HELP aurora_ingester_ingest_ledger_seconds aurora metric ingester.ingest_ledger
+# TYPE aurora_ingester_ingest_ledger_seconds summary
+aurora_ingester_ingest_ledger_seconds{quantile="0.5"} 2
+aurora_ingester_ingest_ledger_seconds{quantile="0.75"} 2
+aurora_ingester_ingest_ledger_seconds{quantile="0.95"} 2
+aurora_ingester_ingest_ledger_seconds{quantile="0.99"} 2
+aurora_ingester_ingest_ledger_seconds{quantile="0.999"} 2
+aurora_ingester_ingest_ledger_seconds_sum 2
+aurora_ingester_ingest_ledger_seconds_count 1
+# HELP aurora_txsub_failed_total aurora metric txsub.failed
+# TYPE aurora_txsub_failed_total counter
+aurora_txsub_failed_total 3
+`, buf.String())
+}
+
+func TestRequestMetrics(t *testing.T) {
+	m := NewRequestMetrics()
+	m.Observe("/accounts/{account_id}", "GET", 200, 20*time.Millisecond)
+	m.Observe("/accounts/{account_id}", "GET", 200, 2*time.Second)
+	m.Observe("/accounts/{account_id}", "GET", 404, time.Millisecond)
+	m.Observe("/accounts/{account_id}", "FOO", 405, time.Millisecond)
+	m.Observe("/accounts/{account_id}", "BAR", 405, time.Millisecond)
+
+	var buf bytes.Buffer
+	m.Write(NewWriter(&buf, "aurora"))
+	out := buf.String()
+
+	assert.Equal(t, 1, strings.Count(out, "# TYPE aurora_http_request_duration_seconds histogram\n"))
+	assert.Contains(t, out, `aurora_http_request_duration_seconds_bucket{route="/accounts/{account_id}",method="GET",status="200",le="0.01"} 0`+"\n")
+	assert.Contains(t, out, `aurora_http_request_duration_seconds_bucket{route="/accounts/{account_id}",method="GET",status="200",le="0.025"} 1`+"\n")
+	assert.Contains(t, out, `aurora_http_request_duration_seconds_bucket{route="/accounts/{account_id}",method="GET",status="200",le="2.5"} 2`+"\n")
+	assert.Contains(t, out, `aurora_http_request_duration_seconds_bucket{route="/accounts/{account_id}",method="GET",status="200",le="+Inf"} 2`+"\n")
+	assert.Contains(t, out, `aurora_http_request_duration_seconds_sum{route="/accounts/{account_id}",method="GET",status="200"} 2.02`+"\n")
+	assert.Contains(t, out, `aurora_http_request_duration_seconds_count{route="/accounts/{account_id}",method="GET",status="404"} 1`+"\n")
+	// unknown methods are recorded in the same series
+	assert.Contains(t, out, `aurora_http_request_duration_seconds_count{route="/accounts/{account_id}",method="other",status="405"} 2`+"\n")
+	// series are sorted by status
+	assert.True(t, strings.Index(out, `status="200"`) < strings.Index(out, `status="404"`))
+}
+
+func TestDBStats(t *testing.T) {
+	var buf bytes.Buffer
+	NewWriter(&buf, "aurora").DBStats(map[string]sql.DBStats{
+		"history": {OpenConnections: 3},
+		"core":    {OpenConnections: 1},
+	})
+	out := buf.String()
+
+	assert.Contains(t, out, "# TYPE aurora_db_open_connections gauge\n"+
+		"aurora_db_open_connections{db=\"core\"} 1\n"+
+		"aurora_db_open_connections{db=\"history\"} 3\n")
+}
+
+func TestLabelEscaping(t *testing.T) {
+	var buf bytes.Buffer
+	NewWriter(&buf, "").Gauge("up-time", "a \\ help\nline", 1, Label{"path", "a\"b\\c\nd"})
+	assert.Equal(t, `# HELP up_time a \\ help\nline
+# TYPE up_time gauge
+up_time{path="a\"b\\c\nd"} 1
+`, buf.String())
+}
@@ -0,0 +1,151 @@
+// Package pubsub notifies streaming requests about ledgers ingested into the
+// history database. Every notification contains the accounts and assets affected
+// by the ledger so streams only reload their data when something relevant to
+// them has changed. This package is intended to be at the lowest levels of
+// aurora's dependency tree, please keep it free of dependencies to other aurora
+// packages.
+package pubsub
+
+import (
+	"sync"
+)
+
+// LedgerClosed is published after a ledger was ingested into the history database.
+type LedgerClosed struct {
+	Sequence int32 `json:"sequence"`
+	// Accounts contains the addresses of all the accounts affected by the ledger.
+	Accounts []string `json:"accounts,omitempty"`
+	// Assets contains all the assets affected by the ledger (in the format
+	// returned by xdr.Asset.String).
+	Assets []string `json:"assets,omitempty"`
+	// Unknown is true when the accounts and assets affected by the ledger are
+	// not known. Such notification matches every subscription.
+	Unknown bool `json:"unknown,omitempty"`
+}
+
+// Filter describes the data a subscriber is interested in. The zero value
+// matches every ledger.
+type Filter struct {
+	// Account, when not empty, restricts the notifications to ledgers affecting the account.
+	Account string
+	// Assets, when not empty, restricts the notifications to ledgers affecting all the assets.
+	Assets []string
+}
+
+// Publisher publishes notifications about ingested ledgers.
+type Publisher interface {
+	Publish(event LedgerClosed) error
+}
+
+// Hub is an in-process Publisher which forwards notifications to subscriptions
+// with a matching filter. It is safe for concurrent use.
+type Hub struct {
+	mutex         sync.Mutex
+	subscriptions map[*Subscription]bool
+}
+
+// Subscription receives signals from a Hub when a ledger matching its filter
+// was ingested.
+type Subscription struct {
+	hub     *Hub
+	filter  Filter
+	updates chan struct{}
+}
+
+// NewHub creates a new Hub.
+func NewHub() *Hub {
+	return &Hub{subscriptions: map[*Subscription]bool{}}
+}
+
+// Subscribe creates a new subscription receiving signals about ledgers
+// matching the filter. The subscription must be closed when it is no
+// longer needed.
+func (h *Hub) Subscribe(filter Filter) *Subscription {
+	s := &Subscription{
+		hub:    h,
+		filter: filter,
+		// Signals are coalesced: a subscriber which hasn't consumed a signal yet
+		// will reload its data anyway so there's no need to queue more of them.
+		updates: make(chan struct{}, 1),
+	}
+
+	h.mutex.Lock()
+	h.subscriptions[s] = true
+	h.mutex.Unlock()
+	return s
+}
+
+// Publish signals all subscriptions whose filter matches the event.
+// It never blocks.
+func (h *Hub) Publish(event LedgerClosed) error {
+	accounts, assets := event.index()
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	for s := range h.subscriptions {
+		if event.Unknown || s.filter.matches(accounts, assets) {
+			s.signal()
+		}
+	}
+	return nil
+}
+
+// Len returns the number of open subscriptions.
+func (h *Hub) Len() int {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return len(h.subscriptions)
+}
+
+func (f Filter) matches(accounts, assets map[string]bool) bool {
+	if f.Account != "" && !accounts[f.Account] {
+		return false
+	}
+	for _, asset := range f.Assets {
+		if !assets[asset] {
+			return false
+		}
+	}
+	return true
+}
+
+func (event LedgerClosed) index() (accounts, assets map[string]bool) {
+	accounts = make(map[string]bool, len(event.Accounts))
+	for _, account := range event.Accounts {
+		accounts[account] = true
+	}
+	assets = make(map[string]bool, len(event.Assets))
+	for _, asset := range event.Assets {
+		assets[asset] = true
+	}
+	return accounts, assets
+}
+
+// Updates returns a channel which receives a value when a matching ledger was
+// ingested since the last value was received.
+func (s *Subscription) Updates() <-chan struct{} {
+	return s.updates
+}
+
+// SetFilter changes the filter of the subscription. It's helpful when the
+// subscriber learns what data it needs only after subscribing.
+func (s *Subscription) SetFilter(filter Filter) {
+	s.hub.mutex.Lock()
+	s.filter = filter
+	s.hub.mutex.Unlock()
+}
+
+// Close removes the subscription from the hub.
+func (s *Subscription) Close() {
+	s.hub.mutex.Lock()
+	delete(s.hub.subscriptions, s)
+	s.hub.mutex.Unlock()
+}
+
+func (s *Subscription) signal() {
+	select {
+	case s.updates <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,64 @@
+package pubsub
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func received(s *Subscription) bool {
+	select {
+	case <-s.Updates():
+		return true
+	default:
+		return false
+	}
+}
+
+func TestHub(t *testing.T) {
+	hub := NewHub()
+	all := hub.Subscribe(Filter{})
+	account := hub.Subscribe(Filter{Account: "GA"})
+	pair := hub.Subscribe(Filter{Assets: []string{"native", "credit_alphanum4/USD/GB"}})
+	assert.Equal(t, 3, hub.Len())
+
+	assert.NoError(t, hub.Publish(LedgerClosed{
+		Sequence: 2,
+		Accounts: []string{"GB"},
+		Assets:   []string{"native"},
+	}))
+	assert.True(t, received(all))
+	assert.False(t, received(account))
+	assert.False(t, received(pair))
+
+	assert.NoError(t, hub.Publish(LedgerClosed{
+		Sequence: 3,
+		Accounts: []string{"GA", "GB"},
+		Assets:   []string{"credit_alphanum4/USD/GB", "native"},
+	}))
+	assert.True(t, received(all))
+	assert.True(t, received(account))
+	assert.True(t, received(pair))
+
+	// signals are coalesced
+	assert.NoError(t, hub.Publish(LedgerClosed{Sequence: 4, Accounts: []string{"GA"}}))
+	assert.NoError(t, hub.Publish(LedgerClosed{Sequence: 5, Accounts: []string{"GA"}}))
+	assert.True(t, received(account))
+	assert.False(t, received(account))
+
+	// unknown changes match every filter
+	assert.NoError(t, hub.Publish(LedgerClosed{Sequence: 6, Unknown: true}))
+	assert.True(t, received(account))
+	assert.True(t, received(pair))
+	assert.True(t, received(all))
+
+	account.SetFilter(Filter{Account: "GC"})
+	assert.NoError(t, hub.Publish(LedgerClosed{Sequence: 7, Accounts: []string{"GC"}}))
+	assert.True(t, received(account))
+
+	account.Close()
+	pair.Close()
+	assert.Equal(t, 1, hub.Len())
+	assert.NoError(t, hub.Publish(LedgerClosed{Sequence: 8, Accounts: []string{"GC"}}))
+	assert.False(t, received(account))
+}
@@ -0,0 +1,85 @@
+package pubsub
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/diamnet/go/support/db"
+	"github.com/diamnet/go/support/errors"
+	"github.com/diamnet/go/support/log"
+	"github.com/lib/pq"
+)
+
+// PostgresChannel is the name of the channel used to send notifications
+// between aurora instances sharing the same database.
+const PostgresChannel = "aurora_ledger_closed"
+
+// maxPostgresPayload is the maximum size of a NOTIFY payload accepted by
+// Postgres (8000 bytes) minus some room for the channel name.
+const maxPostgresPayload = 7900
+
+// PostgresPublisher publishes notifications using Postgres NOTIFY so they
+// reach every aurora instance listening with ListenPostgres.
+type PostgresPublisher struct {
+	Session *db.Session
+}
+
+// Publish sends the event to PostgresChannel. When the event is too large
+// to fit in a notification payload a notification with Unknown set to true
+// is sent instead.
+func (p *PostgresPublisher) Publish(event LedgerClosed) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return errors.Wrap(err, "could not encode notification")
+	}
+	if len(payload) > maxPostgresPayload {
+		payload, err = json.Marshal(LedgerClosed{Sequence: event.Sequence, Unknown: true})
+		if err != nil {
+			return errors.Wrap(err, "could not encode notification")
+		}
+	}
+
+	_, err = p.Session.ExecRaw("SELECT pg_notify(?, ?)", PostgresChannel, string(payload))
+	return errors.Wrap(err, "could not send notification")
+}
+
+// ListenPostgres listens to notifications sent by PostgresPublisher and
+// publishes them to the hub until ctx is cancelled. Notifications may be
+// lost while the connection is down so an Unknown notification is published
+// every time the connection is reestablished.
+func ListenPostgres(ctx context.Context, dsn string, hub *Hub) error {
+	listener := pq.NewListener(dsn, time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			log.WithField("err", err.Error()).Warn("pubsub: postgres listener error")
+		}
+	})
+	defer listener.Close()
+
+	if err := listener.Listen(PostgresChannel); err != nil {
+		return errors.Wrap(err, "could not listen to notifications")
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case n := <-listener.Notify:
+			// nil notification is sent after the connection was reestablished
+			if n == nil {
+				hub.Publish(LedgerClosed{Unknown: true})
+				continue
+			}
+
+			var event LedgerClosed
+			if err := json.Unmarshal([]byte(n.Extra), &event); err != nil {
+				log.WithField("err", err.Error()).Warn("pubsub: could not decode notification")
+				event = LedgerClosed{Unknown: true}
+			}
+			hub.Publish(event)
+		case <-time.After(90 * time.Second):
+			// check the connection is alive, pq.Listener reconnects automatically
+			go listener.Ping()
+		}
+	}
+}
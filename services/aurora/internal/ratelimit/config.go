@@ -0,0 +1,121 @@
+package ratelimit
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/diamnet/go/support/config"
+	"github.com/diamnet/go/support/errors"
+)
+
+// Config is the rate limiting configuration file of aurora, for example:
+//
+//	[costs]
+//	"/paths" = 10
+//
+//	[[api_keys]]
+//	name = "acme"
+//	key_sha256 = "2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae"
+//	requests_per_hour = 100000
+//	burst = 1000
+//	max_streams = 50
+type Config struct {
+	Costs   map[string]int `toml:"costs" valid:"optional"`
+	APIKeys []APIKeyConfig `toml:"api_keys" valid:"optional"`
+}
+
+// APIKeyConfig is an API key of the configuration file. Only the SHA-256 hash
+// of the key is stored, so that the file doesn't need to be kept secret.
+type APIKeyConfig struct {
+	Name      string `toml:"name" valid:"required"`
+	KeySHA256 string `toml:"key_sha256" valid:"required"`
+	// RequestsPerHour is the quota of the key, no limit when 0.
+	RequestsPerHour int `toml:"requests_per_hour" valid:"optional"`
+	Burst           int `toml:"burst" valid:"optional"`
+	// MaxStreams is the maximum number of concurrent streams, no limit
+	// when 0.
+	MaxStreams int `toml:"max_streams" valid:"optional"`
+}
+
+// ReadConfig reads and validates the configuration file at path.
+func ReadConfig(path string) (*Config, error) {
+	var cfg Config
+	err := config.Read(path, &cfg)
+	if err != nil {
+		return nil, err
+	}
+	err = cfg.Validate()
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid rate limit config")
+	}
+	return &cfg, nil
+}
+
+// Validate checks the costs and the API keys of the configuration.
+func (c *Config) Validate() error {
+	for path, cost := range c.Costs {
+		if !strings.HasPrefix(path, "/") {
+			return fmt.Errorf("cost of %q: path must start with /", path)
+		}
+		if cost < 0 {
+			return fmt.Errorf("cost of %q must not be negative", path)
+		}
+	}
+
+	names := map[string]bool{}
+	hashes := map[string]bool{}
+	for _, key := range c.APIKeys {
+		if names[key.Name] {
+			return fmt.Errorf("API key %q is defined more than once", key.Name)
+		}
+		names[key.Name] = true
+
+		raw, err := hex.DecodeString(key.KeySHA256)
+		if err != nil || len(raw) != 32 {
+			return fmt.Errorf("API key %q: key_sha256 must be a hex encoded SHA-256 hash", key.Name)
+		}
+		hash := hex.EncodeToString(raw)
+		if hashes[hash] {
+			return fmt.Errorf("API key %q: key_sha256 is used by another key", key.Name)
+		}
+		hashes[hash] = true
+
+		if key.RequestsPerHour < 0 || key.Burst < 0 || key.MaxStreams < 0 {
+			return fmt.Errorf("API key %q: limits must not be negative", key.Name)
+		}
+		if key.RequestsPerHour > 0 {
+			err = c.ValidateQuota(PerHour(key.RequestsPerHour, key.Burst))
+			if err != nil {
+				return fmt.Errorf("API key %q: %v", key.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// ValidateQuota checks that the requests to every path can be allowed by
+// quota: a request costing more than quota.Limit() would always be limited.
+func (c *Config) ValidateQuota(quota Quota) error {
+	for path, cost := range c.Costs {
+		if cost > quota.Limit() {
+			return fmt.Errorf("cost of %q is greater than the burst + 1 (%d) of the quota", path, quota.Limit())
+		}
+	}
+	return nil
+}
+
+// Keys returns the API keys of the configuration by the hash of the key, as
+// expected by Limiter.
+func (c *Config) Keys() map[string]APIKey {
+	keys := map[string]APIKey{}
+	for _, key := range c.APIKeys {
+		apiKey := APIKey{Name: key.Name, MaxStreams: key.MaxStreams}
+		if key.RequestsPerHour > 0 {
+			quota := PerHour(key.RequestsPerHour, key.Burst)
+			apiKey.Quota = &quota
+		}
+		keys[strings.ToLower(key.KeySHA256)] = apiKey
+	}
+	return keys
+}
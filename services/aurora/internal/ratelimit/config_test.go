@@ -0,0 +1,114 @@
+package ratelimit
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readConfig(t *testing.T, content string) (*Config, error) {
+	dir, err := ioutil.TempDir("", "ratelimit")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "ratelimit.toml")
+	require.NoError(t, ioutil.WriteFile(path, []byte(content), 0600))
+	return ReadConfig(path)
+}
+
+func TestReadConfig(t *testing.T) {
+	cfg, err := readConfig(t, `
+[costs]
+"/paths" = 10
+
+[[api_keys]]
+name = "acme"
+key_sha256 = "2C26B46B68FFC68FF99B453C1D30413413422D706483BFA0F98A5E886266E7AE"
+requests_per_hour = 100000
+burst = 1000
+max_streams = 50
+
+[[api_keys]]
+name = "internal"
+key_sha256 = "fcde2b2edba56bf408601fb721fe9b5c338d10ee429ea04fae5511b68fbf8fb9"
+`)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"/paths": 10}, cfg.Costs)
+
+	quota := PerHour(100000, 1000)
+	assert.Equal(t, map[string]APIKey{
+		"2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae": {
+			Name:       "acme",
+			Quota:      &quota,
+			MaxStreams: 50,
+		},
+		"fcde2b2edba56bf408601fb721fe9b5c338d10ee429ea04fae5511b68fbf8fb9": {
+			Name: "internal",
+		},
+	}, cfg.Keys())
+}
+
+func TestReadConfigInvalid(t *testing.T) {
+	for name, content := range map[string]string{
+		"unknown field": `
+[[api_keys]]
+name = "acme"
+key_sha256 = "2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae"
+requests_per_minute = 10
+`,
+		"missing hash": `
+[[api_keys]]
+name = "acme"
+`,
+		"invalid hash": `
+[[api_keys]]
+name = "acme"
+key_sha256 = "2c26b46b"
+`,
+		"duplicate hash": `
+[[api_keys]]
+name = "acme"
+key_sha256 = "2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae"
+
+[[api_keys]]
+name = "other"
+key_sha256 = "2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae"
+`,
+		"negative limit": `
+[[api_keys]]
+name = "acme"
+key_sha256 = "2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae"
+max_streams = -1
+`,
+		"relative path": `
+[costs]
+"paths" = 10
+`,
+		"cost greater than burst": `
+[costs]
+"/paths" = 11
+
+[[api_keys]]
+name = "acme"
+key_sha256 = "2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae"
+requests_per_hour = 100
+burst = 9
+`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := readConfig(t, content)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestValidateQuota(t *testing.T) {
+	cfg := &Config{Costs: map[string]int{"/paths": 10}}
+	assert.NoError(t, cfg.ValidateQuota(PerHour(100, 9)))
+	assert.EqualError(t, cfg.ValidateQuota(PerHour(100, 8)),
+		`cost of "/paths" is greater than the burst + 1 (9) of the quota`)
+}
@@ -0,0 +1,245 @@
+// Package ratelimit limits the rate of requests of aurora's clients, and the
+// number of streams they keep open concurrently. Clients are identified by
+// their API key, when they provide one, or by their IP address. The state of
+// the limits is kept in memory or, to share the limits across aurora
+// instances, in Redis.
+//
+// Requests used to be limited with the throttled package, which keeps its
+// state in a store updated with separate reads and compare-and-swap writes.
+// Sharing the limits across instances requires applying the generic cell-rate
+// algorithm atomically with the clock of Redis, in a script, and limiting the
+// concurrent streams with the same store, so the algorithm is implemented
+// here for both stores.
+package ratelimit
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// APIKeyHeader is the header of requests carrying an API key. Clients which
+// can't set headers, such as browsers opening an EventSource, can use the
+// APIKeyParam query parameter instead.
+const (
+	APIKeyHeader = "X-API-Key"
+	APIKeyParam  = "api_key"
+)
+
+// ErrInvalidAPIKey is returned by Limiter.Client when a request carries an
+// API key which isn't configured.
+var ErrInvalidAPIKey = errors.New("invalid API key")
+
+// Quota is the rate at which requests are allowed: Requests per Period, with
+// bursts of up to Burst requests on top of the first one. Requests are limited
+// with the generic cell-rate algorithm.
+type Quota struct {
+	Requests int
+	Period   time.Duration
+	Burst    int
+}
+
+// PerHour returns a quota allowing requests per hour.
+func PerHour(requests, burst int) Quota {
+	return Quota{Requests: requests, Period: time.Hour, Burst: burst}
+}
+
+// Limit is the maximum number of requests allowed at once.
+func (q Quota) Limit() int {
+	return q.Burst + 1
+}
+
+// emissionInterval is the interval between two requests at the nominal rate.
+func (q Quota) emissionInterval() time.Duration {
+	return q.Period / time.Duration(q.Requests)
+}
+
+// tolerance is how far ahead of the nominal schedule requests can be made.
+func (q Quota) tolerance() time.Duration {
+	return q.emissionInterval() * time.Duration(q.Burst+1)
+}
+
+// Result is the state of the rate limit of a client after a request.
+type Result struct {
+	Limited bool
+	// Limit is the maximum number of requests allowed at once.
+	Limit int
+	// Remaining is the number of requests which can be made now.
+	Remaining int
+	// ResetAfter is the time until the rate limit is fully reset.
+	ResetAfter time.Duration
+	// RetryAfter is the time until the request would be allowed, -1 when it's
+	// allowed or will never be because its cost is higher than the limit.
+	RetryAfter time.Duration
+}
+
+// newResult returns the result of a request with the given cost given the
+// time until its rate limit is reset.
+func newResult(quota Quota, cost int, limited bool, ttl, retryAfter time.Duration) Result {
+	result := Result{
+		Limited:    limited,
+		Limit:      quota.Limit(),
+		ResetAfter: ttl,
+		RetryAfter: -1,
+	}
+	// a request costing more than the limit is never allowed
+	if limited && cost <= quota.Limit() {
+		result.RetryAfter = retryAfter
+	}
+	next := quota.tolerance() - ttl
+	if next > -quota.emissionInterval() {
+		result.Remaining = int(next / quota.emissionInterval())
+	}
+	return result
+}
+
+// Store keeps the state of the rate limits and of the open streams.
+// Implementations must be safe for concurrent use.
+type Store interface {
+	// RateLimit checks whether a request with the given cost is allowed by
+	// the quota of key and, if it is, records it.
+	RateLimit(key string, quota Quota, cost int) (Result, error)
+	// AcquireStream records the stream id as open for key, unless limit
+	// streams are already open. Streams which are not released are
+	// considered closed after ttl.
+	AcquireStream(key, id string, limit int, ttl time.Duration) (bool, error)
+	// ReleaseStream records the stream id as closed.
+	ReleaseStream(key, id string) error
+}
+
+// APIKey is a key issued to a client, with its own limits.
+type APIKey struct {
+	Name string
+	// Quota of the requests of the client, no limit when nil.
+	Quota *Quota
+	// MaxStreams is the maximum number of concurrent streams of the client,
+	// no limit when 0.
+	MaxStreams int
+}
+
+// Client is the client of a request and its limits.
+type Client struct {
+	// Key identifies the client in the Store.
+	Key        string
+	Name       string
+	Quota      *Quota
+	MaxStreams int
+}
+
+// Limiter limits the requests and streams of clients.
+type Limiter struct {
+	Store Store
+	// Quota of the clients without an API key, by IP address, no limit when
+	// nil.
+	Quota *Quota
+	// MaxStreams is the maximum number of concurrent streams of clients
+	// without an API key, no limit when 0.
+	MaxStreams int
+	// StreamTTL is the time after which a stream which is not released is
+	// considered closed, it must be longer than the longest stream.
+	StreamTTL time.Duration
+	// APIKeys are the API keys by the hex encoded SHA-256 hash of the key.
+	APIKeys map[string]APIKey
+	// Costs are the costs of requests by path prefix, 1 when not set.
+	Costs map[string]int
+}
+
+// Client returns the client of the request, identified by its API key or by
+// ip. ErrInvalidAPIKey is returned if the API key isn't configured.
+func (l *Limiter) Client(r *http.Request, ip string) (Client, error) {
+	key := r.Header.Get(APIKeyHeader)
+	if key == "" {
+		key = r.URL.Query().Get(APIKeyParam)
+	}
+	if key == "" {
+		return Client{Key: "ip:" + ip, Quota: l.Quota, MaxStreams: l.MaxStreams}, nil
+	}
+
+	hash := sha256.Sum256([]byte(key))
+	hashHex := hex.EncodeToString(hash[:])
+	apiKey, ok := l.APIKeys[hashHex]
+	if !ok {
+		return Client{}, ErrInvalidAPIKey
+	}
+	return Client{
+		Key:        "key:" + hashHex,
+		Name:       apiKey.Name,
+		Quota:      apiKey.Quota,
+		MaxStreams: apiKey.MaxStreams,
+	}, nil
+}
+
+// Cost returns the cost of a request to path: the cost of the longest
+// matching path prefix, or 1. Prefixes only match whole path segments.
+func (l *Limiter) Cost(path string) int {
+	cost, longest := 1, -1
+	for prefix, c := range l.Costs {
+		prefix = strings.TrimSuffix(prefix, "/")
+		if len(prefix) <= longest {
+			continue
+		}
+		if path == prefix || strings.HasPrefix(path, prefix+"/") {
+			cost, longest = c, len(prefix)
+		}
+	}
+	return cost
+}
+
+// RateLimit checks whether a request of the client with the given cost is
+// allowed. Requests of clients without a quota are always allowed and the
+// returned result is nil.
+func (l *Limiter) RateLimit(client Client, cost int) (*Result, error) {
+	if client.Quota == nil {
+		return nil, nil
+	}
+	result, err := l.Store.RateLimit(client.Key, *client.Quota, cost)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// StartStream records a new stream of the client. It returns false if the
+// client already has the maximum number of concurrent streams open, otherwise
+// the returned function must be called when the stream is closed.
+func (l *Limiter) StartStream(client Client) (bool, func(), error) {
+	if client.MaxStreams <= 0 {
+		return true, func() {}, nil
+	}
+
+	var raw [16]byte
+	_, err := rand.Read(raw[:])
+	if err != nil {
+		return false, nil, err
+	}
+	id := hex.EncodeToString(raw[:])
+
+	ok, err := l.Store.AcquireStream(client.Key, id, client.MaxStreams, l.StreamTTL)
+	if err != nil || !ok {
+		return false, nil, err
+	}
+	return true, func() {
+		// the stream expires after StreamTTL if it can't be released
+		l.Store.ReleaseStream(client.Key, id)
+	}, nil
+}
+
+type contextKey int
+
+const clientContextKey = contextKey(0)
+
+// WithClient returns a context carrying the client of a request.
+func WithClient(ctx context.Context, client Client) context.Context {
+	return context.WithValue(ctx, clientContextKey, client)
+}
+
+// ClientFromContext returns the client of a request set by WithClient.
+func ClientFromContext(ctx context.Context) (Client, bool) {
+	client, ok := ctx.Value(clientContextKey).(Client)
+	return client, ok
+}
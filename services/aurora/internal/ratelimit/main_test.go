@@ -0,0 +1,226 @@
+package ratelimit
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	return c.now
+}
+
+func newTestStore(c *clock) *MemoryStore {
+	store := NewMemoryStore(10)
+	store.now = c.Now
+	return store
+}
+
+func TestRateLimit(t *testing.T) {
+	c := &clock{now: time.Unix(1000, 0)}
+	store := newTestStore(c)
+	quota := PerHour(10, 9)
+
+	for i := 9; i >= 0; i-- {
+		result, err := store.RateLimit("ip:1.1.1.1", quota, 1)
+		require.NoError(t, err)
+		assert.False(t, result.Limited)
+		assert.Equal(t, 10, result.Limit)
+		assert.Equal(t, i, result.Remaining)
+		assert.Equal(t, time.Duration(10-i)*6*time.Minute, result.ResetAfter)
+	}
+
+	result, err := store.RateLimit("ip:1.1.1.1", quota, 1)
+	require.NoError(t, err)
+	assert.True(t, result.Limited)
+	assert.Equal(t, 0, result.Remaining)
+	assert.Equal(t, 6*time.Minute, result.RetryAfter)
+
+	// other clients have their own limit
+	result, err = store.RateLimit("ip:2.2.2.2", quota, 1)
+	require.NoError(t, err)
+	assert.False(t, result.Limited)
+
+	// a request is allowed again after the emission interval
+	c.now = c.now.Add(6 * time.Minute)
+	result, err = store.RateLimit("ip:1.1.1.1", quota, 1)
+	require.NoError(t, err)
+	assert.False(t, result.Limited)
+	assert.Equal(t, 0, result.Remaining)
+}
+
+func TestRateLimitCost(t *testing.T) {
+	c := &clock{now: time.Unix(1000, 0)}
+	store := newTestStore(c)
+	quota := PerHour(10, 9)
+
+	result, err := store.RateLimit("key:a", quota, 4)
+	require.NoError(t, err)
+	assert.False(t, result.Limited)
+	assert.Equal(t, 6, result.Remaining)
+
+	result, err = store.RateLimit("key:a", quota, 7)
+	require.NoError(t, err)
+	assert.True(t, result.Limited)
+	assert.Equal(t, 6*time.Minute, result.RetryAfter)
+
+	// a limited request isn't counted
+	result, err = store.RateLimit("key:a", quota, 6)
+	require.NoError(t, err)
+	assert.False(t, result.Limited)
+	assert.Equal(t, 0, result.Remaining)
+}
+
+func TestRateLimitCostOverLimit(t *testing.T) {
+	c := &clock{now: time.Unix(1000, 0)}
+	store := newTestStore(c)
+	quota := PerHour(10, 9)
+
+	// the request can never be allowed, even once the rate limit is reset
+	result, err := store.RateLimit("key:a", quota, 11)
+	require.NoError(t, err)
+	assert.True(t, result.Limited)
+	assert.Equal(t, 10, result.Remaining)
+	assert.Equal(t, time.Duration(-1), result.RetryAfter)
+
+	result, err = store.RateLimit("key:a", quota, 10)
+	require.NoError(t, err)
+	assert.False(t, result.Limited)
+}
+
+func TestRateLimitEviction(t *testing.T) {
+	c := &clock{now: time.Unix(1000, 0)}
+	store := NewMemoryStore(2)
+	store.now = c.Now
+	quota := PerHour(10, 0)
+
+	store.RateLimit("a", quota, 1)
+	c.now = c.now.Add(time.Hour)
+	store.RateLimit("b", quota, 1)
+	store.RateLimit("c", quota, 1)
+
+	assert.Len(t, store.tats, 2)
+	assert.NotContains(t, store.tats, "a")
+}
+
+func TestStreams(t *testing.T) {
+	c := &clock{now: time.Unix(1000, 0)}
+	store := newTestStore(c)
+
+	ok, err := store.AcquireStream("ip:1.1.1.1", "1", 2, time.Minute)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = store.AcquireStream("ip:1.1.1.1", "2", 2, time.Minute)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = store.AcquireStream("ip:1.1.1.1", "3", 2, time.Minute)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, store.ReleaseStream("ip:1.1.1.1", "1"))
+	ok, err = store.AcquireStream("ip:1.1.1.1", "3", 2, time.Minute)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	// streams which are not released expire
+	c.now = c.now.Add(time.Minute)
+	ok, err = store.AcquireStream("ip:1.1.1.1", "4", 2, time.Minute)
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestLimiterClient(t *testing.T) {
+	quota := PerHour(10, 0)
+	keyQuota := PerHour(1000, 100)
+	limiter := &Limiter{
+		Store:      NewMemoryStore(10),
+		Quota:      &quota,
+		MaxStreams: 1,
+		APIKeys: map[string]APIKey{
+			// sha256("foo")
+			"2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae": {
+				Name:       "acme",
+				Quota:      &keyQuota,
+				MaxStreams: 10,
+			},
+		},
+	}
+
+	r := httptest.NewRequest("GET", "/ledgers", nil)
+	client, err := limiter.Client(r, "1.1.1.1")
+	require.NoError(t, err)
+	assert.Equal(t, Client{Key: "ip:1.1.1.1", Quota: &quota, MaxStreams: 1}, client)
+
+	r = httptest.NewRequest("GET", "/ledgers", nil)
+	r.Header.Set(APIKeyHeader, "foo")
+	client, err = limiter.Client(r, "1.1.1.1")
+	require.NoError(t, err)
+	assert.Equal(t, "acme", client.Name)
+	assert.Equal(t, "key:2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae", client.Key)
+	assert.Equal(t, &keyQuota, client.Quota)
+	assert.Equal(t, 10, client.MaxStreams)
+
+	r = httptest.NewRequest("GET", "/ledgers?api_key=foo", nil)
+	client, err = limiter.Client(r, "1.1.1.1")
+	require.NoError(t, err)
+	assert.Equal(t, "acme", client.Name)
+
+	r = httptest.NewRequest("GET", "/ledgers?api_key=bar", nil)
+	_, err = limiter.Client(r, "1.1.1.1")
+	assert.Equal(t, ErrInvalidAPIKey, err)
+}
+
+func TestLimiterCost(t *testing.T) {
+	limiter := &Limiter{Costs: map[string]int{
+		"/paths":            10,
+		"/paths/strict":     20,
+		"/accounts/":        2,
+		"/order_book/trade": 5,
+	}}
+
+	assert.Equal(t, 10, limiter.Cost("/paths"))
+	assert.Equal(t, 10, limiter.Cost("/paths/strict-send"))
+	assert.Equal(t, 20, limiter.Cost("/paths/strict"))
+	assert.Equal(t, 2, limiter.Cost("/accounts/GABC/payments"))
+	assert.Equal(t, 1, limiter.Cost("/order_book/trades"))
+	assert.Equal(t, 1, limiter.Cost("/ledgers"))
+}
+
+func TestLimiterStreams(t *testing.T) {
+	limiter := &Limiter{Store: NewMemoryStore(10), StreamTTL: time.Minute}
+	client := Client{Key: "ip:1.1.1.1", MaxStreams: 1}
+
+	ok, release, err := limiter.StartStream(client)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, _, err = limiter.StartStream(client)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	release()
+	ok, _, err = limiter.StartStream(client)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	// no limit
+	for i := 0; i < 3; i++ {
+		ok, _, err = limiter.StartStream(Client{Key: "key:a"})
+		require.NoError(t, err)
+		assert.True(t, ok)
+	}
+}
+
+func TestLimiterWithoutQuota(t *testing.T) {
+	limiter := &Limiter{Store: NewMemoryStore(10)}
+	result, err := limiter.RateLimit(Client{Key: "key:a"}, 100)
+	require.NoError(t, err)
+	assert.Nil(t, result)
+}
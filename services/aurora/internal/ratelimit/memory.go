@@ -0,0 +1,109 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// MemoryStore is a Store keeping the state of the limits in memory. The
+// limits are not shared with other aurora instances.
+type MemoryStore struct {
+	maxKeys int
+	now     func() time.Time
+
+	mutex   sync.Mutex
+	tats    map[string]time.Time
+	streams map[string]map[string]time.Time
+}
+
+// NewMemoryStore returns a MemoryStore keeping the rate limits of up to
+// maxKeys clients. When the limit is reached, the clients whose rate limit is
+// reset are forgotten first.
+func NewMemoryStore(maxKeys int) *MemoryStore {
+	return &MemoryStore{
+		maxKeys: maxKeys,
+		now:     time.Now,
+		tats:    map[string]time.Time{},
+		streams: map[string]map[string]time.Time{},
+	}
+}
+
+// RateLimit implements Store.
+func (s *MemoryStore) RateLimit(key string, quota Quota, cost int) (Result, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := s.now()
+	// tat is the theoretical arrival time of the request if requests were
+	// made at exactly the nominal rate
+	tat, ok := s.tats[key]
+	if !ok || tat.Before(now) {
+		tat = now
+	}
+
+	newTat := tat.Add(time.Duration(cost) * quota.emissionInterval())
+	allowAt := newTat.Add(-quota.tolerance())
+	if now.Before(allowAt) {
+		return newResult(quota, cost, true, tat.Sub(now), allowAt.Sub(now)), nil
+	}
+
+	if !ok && len(s.tats) >= s.maxKeys {
+		s.evict(now)
+	}
+	s.tats[key] = newTat
+	return newResult(quota, cost, false, newTat.Sub(now), -1), nil
+}
+
+// evict forgets the clients whose rate limit is reset or, if there are none,
+// an arbitrary client.
+func (s *MemoryStore) evict(now time.Time) {
+	for key, tat := range s.tats {
+		if !tat.After(now) {
+			delete(s.tats, key)
+		}
+	}
+	if len(s.tats) < s.maxKeys {
+		return
+	}
+	for key := range s.tats {
+		delete(s.tats, key)
+		return
+	}
+}
+
+// AcquireStream implements Store.
+func (s *MemoryStore) AcquireStream(key, id string, limit int, ttl time.Duration) (bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := s.now()
+	streams := s.streams[key]
+	for streamID, expiresAt := range streams {
+		if !expiresAt.After(now) {
+			delete(streams, streamID)
+		}
+	}
+	if len(streams) >= limit {
+		return false, nil
+	}
+
+	if streams == nil {
+		streams = map[string]time.Time{}
+		s.streams[key] = streams
+	}
+	streams[id] = now.Add(ttl)
+	return true, nil
+}
+
+// ReleaseStream implements Store.
+func (s *MemoryStore) ReleaseStream(key, id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	streams := s.streams[key]
+	delete(streams, id)
+	if len(streams) == 0 {
+		delete(s.streams, key)
+	}
+	return nil
+}
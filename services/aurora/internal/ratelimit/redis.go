@@ -0,0 +1,107 @@
+package ratelimit
+
+import (
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// rateLimitScript applies the generic cell-rate algorithm atomically, using
+// the clock of Redis so all aurora instances share the same time. Times are in
+// microseconds. It returns whether the request is limited, the time until the
+// rate limit is reset and the time until the request would be allowed.
+var rateLimitScript = redis.NewScript(1, `
+redis.replicate_commands()
+local emission = tonumber(ARGV[1])
+local tolerance = tonumber(ARGV[2])
+local cost = tonumber(ARGV[3])
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000000 + tonumber(time[2])
+
+local tat = tonumber(redis.call('GET', KEYS[1]))
+if tat == nil or tat < now then
+	tat = now
+end
+
+local newTat = tat + cost * emission
+local allowAt = newTat - tolerance
+if now < allowAt then
+	return {1, tat - now, allowAt - now}
+end
+
+redis.call('SET', KEYS[1], newTat, 'PX', math.max(1, math.ceil((newTat - now) / 1000)))
+return {0, newTat - now, 0}
+`)
+
+// acquireStreamScript records a stream in a sorted set scored by the time the
+// stream expires, in milliseconds, unless the set already contains the
+// maximum number of unexpired streams.
+var acquireStreamScript = redis.NewScript(1, `
+redis.replicate_commands()
+local limit = tonumber(ARGV[2])
+local ttl = tonumber(ARGV[3])
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now)
+if redis.call('ZCARD', KEYS[1]) >= limit then
+	return 0
+end
+redis.call('ZADD', KEYS[1], now + ttl, ARGV[1])
+redis.call('PEXPIRE', KEYS[1], ttl)
+return 1
+`)
+
+// RedisStore is a Store keeping the state of the limits in Redis, so they are
+// shared by all the aurora instances using the same Redis server.
+type RedisStore struct {
+	pool   *redis.Pool
+	prefix string
+}
+
+// NewRedisStore returns a RedisStore whose Redis keys start with prefix.
+func NewRedisStore(pool *redis.Pool, prefix string) *RedisStore {
+	return &RedisStore{pool: pool, prefix: prefix}
+}
+
+// RateLimit implements Store.
+func (s *RedisStore) RateLimit(key string, quota Quota, cost int) (Result, error) {
+	conn := s.pool.Get()
+	defer conn.Close()
+
+	values, err := redis.Int64s(rateLimitScript.Do(conn,
+		s.prefix+":rate:"+key,
+		int64(quota.emissionInterval()/time.Microsecond),
+		int64(quota.tolerance()/time.Microsecond),
+		cost,
+	))
+	if err != nil {
+		return Result{}, err
+	}
+
+	ttl := time.Duration(values[1]) * time.Microsecond
+	retryAfter := time.Duration(values[2]) * time.Microsecond
+	return newResult(quota, cost, values[0] == 1, ttl, retryAfter), nil
+}
+
+// AcquireStream implements Store.
+func (s *RedisStore) AcquireStream(key, id string, limit int, ttl time.Duration) (bool, error) {
+	conn := s.pool.Get()
+	defer conn.Close()
+
+	return redis.Bool(acquireStreamScript.Do(conn,
+		s.prefix+":streams:"+key,
+		id,
+		limit,
+		int64(ttl/time.Millisecond),
+	))
+}
+
+// ReleaseStream implements Store.
+func (s *RedisStore) ReleaseStream(key, id string) error {
+	conn := s.pool.Get()
+	defer conn.Close()
+
+	_, err := conn.Do("ZREM", s.prefix+":streams:"+key, id)
+	return err
+}
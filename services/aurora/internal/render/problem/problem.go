@@ -20,6 +20,27 @@ var (
 			"headers.",
 	}
 
+	// TooManyStreams is a well-known problem type.  Use it as a shortcut
+	// in your actions.
+	TooManyStreams = problem.P{
+		Type:   "too_many_streams",
+		Title:  "Too Many Streams",
+		Status: 429,
+		Detail: "The requesting API key or IP address has reached its limit of " +
+			"concurrent streams.  Please close a stream before opening a new one.",
+	}
+
+	// InvalidAPIKey is a well-known problem type.  Use it as a shortcut
+	// in your actions.
+	InvalidAPIKey = problem.P{
+		Type:   "invalid_api_key",
+		Title:  "Invalid API Key",
+		Status: http.StatusUnauthorized,
+		Detail: "The API key of the request, set in the 'X-API-Key' header or " +
+			"the 'api_key' query parameter, is not valid.  Requests without an " +
+			"API key are limited by IP address.",
+	}
+
 	// NotImplemented is a well-known problem type.  Use it as a shortcut
 	// in your actions.
 	NotImplemented = problem.P{
@@ -31,6 +52,17 @@ var (
 			"been completed.",
 	}
 
+	// UnsupportedPathFinding is a well-known problem type.  Use it as a shortcut
+	// in your actions.
+	UnsupportedPathFinding = problem.P{
+		Type:   "unsupported_path_finding",
+		Title:  "Unsupported Path Finding",
+		Status: http.StatusNotImplemented,
+		Detail: "The path finding of this aurora instance does not support this " +
+			"request.  Paths from a source amount and split paths are only found " +
+			"when the experimental ingestion system is enabled.",
+	}
+
 	// NotAcceptable is a well-known problem type.  Use it as a shortcut
 	// in your actions.
 	NotAcceptable = problem.P{
@@ -16,13 +16,15 @@ var (
 	errBadStream = errors.New("Unexpected stream error")
 
 	// known errors
-	errNoObject    = errors.New("Object not found")
-	ErrRateLimited = errors.New("Rate limit exceeded")
+	errNoObject       = errors.New("Object not found")
+	ErrRateLimited    = errors.New("Rate limit exceeded")
+	ErrTooManyStreams = errors.New("Too many streams")
 )
 
 var knownErrors = map[error]struct{}{
-	sql.ErrNoRows:  struct{}{},
-	ErrRateLimited: struct{}{},
+	sql.ErrNoRows:     struct{}{},
+	ErrRateLimited:    struct{}{},
+	ErrTooManyStreams: struct{}{},
 }
 
 type Stream struct {
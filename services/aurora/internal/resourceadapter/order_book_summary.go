@@ -2,7 +2,10 @@ package resourceadapter
 
 import (
 	"context"
+	"math/big"
 
+	"github.com/diamnet/go/amount"
+	"github.com/diamnet/go/exp/orderbook"
 	protocol "github.com/diamnet/go/protocols/aurora"
 	"github.com/diamnet/go/services/aurora/internal/db2/core"
 	"github.com/diamnet/go/support/errors"
@@ -59,3 +62,142 @@ func populatePriceLevels(destp *[]protocol.PriceLevel, rows []core.OrderBookSumm
 
 	return nil
 }
+
+// PopulateInMemoryOrderBookSummary populates an order book summary using the
+// price levels computed by the in memory order book
+func PopulateInMemoryOrderBookSummary(
+	ctx context.Context,
+	dest *protocol.OrderBookSummary,
+	selling xdr.Asset,
+	buying xdr.Asset,
+	summary orderbook.OrderBookSummary,
+) error {
+	err := PopulateAsset(ctx, &dest.Selling, selling)
+	if err != nil {
+		return err
+	}
+	err = PopulateAsset(ctx, &dest.Buying, buying)
+	if err != nil {
+		return err
+	}
+
+	dest.Bids = make([]protocol.PriceLevel, len(summary.Bids))
+	for i, level := range summary.Bids {
+		dest.Bids[i] = inMemoryPriceLevel(level)
+	}
+	dest.Asks = make([]protocol.PriceLevel, len(summary.Asks))
+	for i, level := range summary.Asks {
+		dest.Asks[i] = inMemoryPriceLevel(level)
+	}
+
+	return nil
+}
+
+// PopulateOrderBookDepth populates the depth of an order book. sellImpact is the
+// result of selling the base asset and buyImpact is the result of buying the base
+// asset. Both are optional.
+func PopulateOrderBookDepth(
+	ctx context.Context,
+	dest *protocol.OrderBookDepth,
+	selling xdr.Asset,
+	buying xdr.Asset,
+	summary orderbook.OrderBookSummary,
+	requestedAmount xdr.Int64,
+	sellImpact *orderbook.TradeImpact,
+	buyImpact *orderbook.TradeImpact,
+) error {
+	err := PopulateAsset(ctx, &dest.Selling, selling)
+	if err != nil {
+		return err
+	}
+	err = PopulateAsset(ctx, &dest.Buying, buying)
+	if err != nil {
+		return err
+	}
+
+	dest.Bids = make([]protocol.DepthLevel, len(summary.Bids))
+	for i, level := range summary.Bids {
+		dest.Bids[i] = protocol.DepthLevel{
+			PriceLevel:       inMemoryPriceLevel(level),
+			CumulativeAmount: amount.String(level.CumulativeAmount),
+		}
+	}
+	dest.Asks = make([]protocol.DepthLevel, len(summary.Asks))
+	for i, level := range summary.Asks {
+		dest.Asks[i] = protocol.DepthLevel{
+			PriceLevel:       inMemoryPriceLevel(level),
+			CumulativeAmount: amount.String(level.CumulativeAmount),
+		}
+	}
+
+	if sellImpact != nil {
+		// sell impact prices are expressed in base asset units per counter asset unit
+		dest.SellImpact = populatePriceImpact(
+			requestedAmount,
+			sellImpact.AmountSold,
+			sellImpact.AmountBought,
+			sellImpact.Filled,
+			invertPrice(sellImpact.BestPrice),
+			invertPrice(sellImpact.WorstPrice),
+		)
+	}
+	if buyImpact != nil {
+		dest.BuyImpact = populatePriceImpact(
+			requestedAmount,
+			buyImpact.AmountBought,
+			buyImpact.AmountSold,
+			buyImpact.Filled,
+			buyImpact.BestPrice,
+			buyImpact.WorstPrice,
+		)
+	}
+
+	return nil
+}
+
+func inMemoryPriceLevel(level orderbook.PriceLevel) protocol.PriceLevel {
+	return protocol.PriceLevel{
+		Price:  big.NewRat(int64(level.Price.N), int64(level.Price.D)).FloatString(7),
+		Amount: amount.String(level.Amount),
+		PriceR: protocol.Price{
+			N: int32(level.Price.N),
+			D: int32(level.Price.D),
+		},
+	}
+}
+
+func invertPrice(p xdr.Price) xdr.Price {
+	return xdr.Price{N: p.D, D: p.N}
+}
+
+// populatePriceImpact builds the price impact of a market order for the base asset.
+// bestPrice and worstPrice must be expressed in counter asset units per base asset unit.
+func populatePriceImpact(
+	requestedAmount xdr.Int64,
+	baseAmount xdr.Int64,
+	counterAmount xdr.Int64,
+	filled bool,
+	bestPrice xdr.Price,
+	worstPrice xdr.Price,
+) *protocol.PriceImpact {
+	impact := &protocol.PriceImpact{
+		Amount:        amount.String(requestedAmount),
+		FilledAmount:  amount.String(baseAmount),
+		CounterAmount: amount.String(counterAmount),
+		Filled:        filled,
+	}
+	if baseAmount == 0 || bestPrice.D == 0 || bestPrice.N == 0 || worstPrice.D == 0 {
+		return impact
+	}
+
+	best := big.NewRat(int64(bestPrice.N), int64(bestPrice.D))
+	average := big.NewRat(int64(counterAmount), int64(baseAmount))
+	difference := new(big.Rat).Sub(average, best)
+	relative := new(big.Rat).Quo(difference.Abs(difference), best)
+
+	impact.AveragePrice = average.FloatString(7)
+	impact.BestPrice = best.FloatString(7)
+	impact.WorstPrice = big.NewRat(int64(worstPrice.N), int64(worstPrice.D)).FloatString(7)
+	impact.PriceImpact = relative.FloatString(7)
+	return impact
+}
@@ -41,3 +41,34 @@ func PopulatePath(ctx context.Context, dest *aurora.Path, p paths.Path) (err err
 	}
 	return
 }
+
+// PopulateSplitPath converts the paths.SplitPath into a SplitPath
+func PopulateSplitPath(ctx context.Context, dest *aurora.SplitPath, p paths.SplitPath) (err error) {
+	dest.DestinationAmount = amount.String(p.DestinationAmount)
+	dest.SourceAmount = amount.String(p.SourceAmount)
+
+	err = p.Source.Extract(
+		&dest.SourceAssetType,
+		&dest.SourceAssetCode,
+		&dest.SourceAssetIssuer)
+	if err != nil {
+		return
+	}
+
+	err = p.Destination.Extract(
+		&dest.DestinationAssetType,
+		&dest.DestinationAssetCode,
+		&dest.DestinationAssetIssuer)
+	if err != nil {
+		return
+	}
+
+	dest.Allocations = make([]aurora.Path, len(p.Allocations))
+	for i, allocation := range p.Allocations {
+		err = PopulatePath(ctx, &dest.Allocations[i], allocation)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
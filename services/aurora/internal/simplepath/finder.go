@@ -8,6 +8,10 @@ import (
 	"github.com/diamnet/go/xdr"
 )
 
+// ErrNotImplemented is returned by the Finder for the path finding operations
+// which are only implemented by the InMemoryFinder
+var ErrNotImplemented = errors.New("Not implemented")
+
 // Finder implements the paths.Finder interface and searchs for
 // payment paths using a simple breadth first search of the offers table of a diamnet-core.
 //
@@ -67,5 +71,28 @@ func (f *Finder) FindFixedPaths(
 	destinationAsset xdr.Asset,
 	maxLength uint,
 ) ([]paths.Path, error) {
-	return nil, errors.New("Not implemented")
+	return nil, ErrNotImplemented
+}
+
+// FindSplitPaths will return an error because this implementation
+// does not support this operation
+func (f *Finder) FindSplitPaths(
+	q paths.Query,
+	maxLength uint,
+	maxParts uint,
+) ([]paths.SplitPath, error) {
+	return nil, ErrNotImplemented
+}
+
+// FindFixedSplitPaths will return an error because this implementation
+// does not support this operation
+func (f *Finder) FindFixedSplitPaths(
+	sourceAccount *xdr.AccountId,
+	sourceAsset xdr.Asset,
+	amountToSpend xdr.Int64,
+	destinationAsset xdr.Asset,
+	maxLength uint,
+	maxParts uint,
+) ([]paths.SplitPath, error) {
+	return nil, ErrNotImplemented
 }
@@ -11,6 +11,12 @@ const (
 	maxAssetsPerPath = 5
 	// MaxInMemoryPathLength is the maximum path length which can be queried by the InMemoryFinder
 	MaxInMemoryPathLength = 5
+	// MaxSplitPathParts is the maximum number of parts a payment can be divided
+	// into by the InMemoryFinder when searching for split payment paths
+	MaxSplitPathParts = 20
+	// DefaultSplitPathParts is the number of parts used when searching for
+	// split payment paths if no number of parts is specified
+	DefaultSplitPathParts = 10
 )
 
 var (
@@ -108,3 +114,109 @@ func (finder InMemoryFinder) FindFixedPaths(
 	}
 	return results, err
 }
+
+// FindSplitPaths returns, for each source asset, a payment which delivers the
+// destination amount of the query by dividing it into at most `maxParts` parts.
+// Each part is routed through the cheapest path available after the preceding
+// parts have been allocated, so offers which are shared between paths are only
+// counted once.
+func (finder InMemoryFinder) FindSplitPaths(
+	q paths.Query,
+	maxLength uint,
+	maxParts uint,
+) ([]paths.SplitPath, error) {
+	if finder.graph.IsEmpty() {
+		return nil, ErrEmptyInMemoryOrderBook
+	}
+
+	maxLength, maxParts, err := validateSplitPathParams(maxLength, maxParts)
+	if err != nil {
+		return nil, err
+	}
+
+	splitPaths, err := finder.graph.FindSplitPaths(
+		int(maxLength),
+		int(maxParts),
+		q.DestinationAsset,
+		q.DestinationAmount,
+		q.SourceAccount,
+		q.SourceAssets,
+		q.SourceAssetBalances,
+	)
+	return convertSplitPaths(splitPaths), err
+}
+
+// FindFixedSplitPaths returns a payment which spends `amountToSpend` of `sourceAsset`
+// and delivers `destinationAsset` by dividing `amountToSpend` into at most `maxParts` parts.
+// `sourceAccountID` is optional. if `sourceAccountID` is provided then no offers
+// created by `sourceAccountID` will be considered when evaluating payment paths
+func (finder InMemoryFinder) FindFixedSplitPaths(
+	sourceAccount *xdr.AccountId,
+	sourceAsset xdr.Asset,
+	amountToSpend xdr.Int64,
+	destinationAsset xdr.Asset,
+	maxLength uint,
+	maxParts uint,
+) ([]paths.SplitPath, error) {
+	if finder.graph.IsEmpty() {
+		return nil, ErrEmptyInMemoryOrderBook
+	}
+
+	maxLength, maxParts, err := validateSplitPathParams(maxLength, maxParts)
+	if err != nil {
+		return nil, err
+	}
+
+	splitPaths, err := finder.graph.FindFixedSplitPaths(
+		int(maxLength),
+		int(maxParts),
+		sourceAccount,
+		sourceAsset,
+		amountToSpend,
+		destinationAsset,
+	)
+	return convertSplitPaths(splitPaths), err
+}
+
+func validateSplitPathParams(maxLength, maxParts uint) (uint, uint, error) {
+	if maxLength == 0 {
+		maxLength = MaxInMemoryPathLength
+	}
+	if maxLength > MaxInMemoryPathLength {
+		return 0, 0, errors.New("invalid value of maxLength")
+	}
+
+	if maxParts == 0 {
+		maxParts = DefaultSplitPathParts
+	}
+	if maxParts > MaxSplitPathParts {
+		return 0, 0, errors.New("invalid value of maxParts")
+	}
+
+	return maxLength, maxParts, nil
+}
+
+func convertSplitPaths(splitPaths []orderbook.SplitPath) []paths.SplitPath {
+	results := make([]paths.SplitPath, len(splitPaths))
+	for i, splitPath := range splitPaths {
+		allocations := make([]paths.Path, len(splitPath.Allocations))
+		for j, path := range splitPath.Allocations {
+			allocations[j] = paths.Path{
+				Path:              path.InteriorNodes,
+				Source:            path.SourceAsset,
+				SourceAmount:      path.SourceAmount,
+				Destination:       path.DestinationAsset,
+				DestinationAmount: path.DestinationAmount,
+			}
+		}
+
+		results[i] = paths.SplitPath{
+			Source:            splitPath.SourceAsset,
+			SourceAmount:      splitPath.SourceAmount,
+			Destination:       splitPath.DestinationAsset,
+			DestinationAmount: splitPath.DestinationAmount,
+			Allocations:       allocations,
+		}
+	}
+	return results
+}
@@ -30,6 +30,12 @@ func RequestHelperXFF(xff string) func(r *http.Request) {
 	}
 }
 
+func RequestHelperAPIKey(key string) func(r *http.Request) {
+	return func(r *http.Request) {
+		r.Header.Set("X-API-Key", key)
+	}
+}
+
 func RequestHelperRaw(r *http.Request) {
 	r.Header.Set("Accept", "application/octet-stream")
 }
@@ -19,6 +19,10 @@ var (
 	// ErrNoAccount is returned when the source account for the transaction
 	// cannot be found in the database
 	ErrNoAccount = &FailedTransactionError{"AAAAAAAAAAD////4AAAAAA=="}
+	// ErrInsufficientReplacementFee is returned when a transaction with the same
+	// source account and sequence number as a pending transaction does not pay
+	// a higher fee than the pending transaction so it cannot replace it.
+	ErrInsufficientReplacementFee = &FailedTransactionError{"AAAAAAAAAAD////3AAAAAA=="}
 )
 
 // FailedTransactionError represent an error that occurred because
@@ -12,6 +12,7 @@ type envelopeInfo struct {
 	Hash          string
 	Sequence      uint64
 	SourceAddress string
+	Fee           uint32
 }
 
 func extractEnvelopeInfo(ctx context.Context, env string, passphrase string) (result envelopeInfo, err error) {
@@ -33,6 +34,7 @@ func extractEnvelopeInfo(ctx context.Context, env string, passphrase string) (re
 	}
 
 	result.Sequence = uint64(tx.Tx.SeqNum)
+	result.Fee = uint32(tx.Tx.Fee)
 
 	aid := tx.Tx.SourceAccount.MustEd25519()
 	result.SourceAddress, err = strkey.Encode(strkey.VersionByteAccountID, aid[:])
@@ -42,6 +42,14 @@ type OpenSubmissionList interface {
 	// result is available for the provided transaction hash.
 	Add(context.Context, string, Listener) error
 
+	// Replace registers the provided listener as interested in being notified
+	// when a result is available for the transaction with the provided hash
+	// (second argument) which replaces the pending transaction with the hash
+	// given as the first argument. From then on, all the listeners of both
+	// transactions are notified with the result of whichever transaction is
+	// included in a ledger.
+	Replace(context.Context, string, string, Listener) error
+
 	// Finish forwards the provided result on to any listeners and cleans up any
 	// resources associated with the transaction that this result is for
 	Finish(context.Context, Result) error
@@ -50,7 +58,8 @@ type OpenSubmissionList interface {
 	Clean(context.Context, time.Duration) (int, error)
 
 	// Pending return a list of transaction hashes that have at least one
-	// listener registered to them in this list.
+	// listener registered to them in this list. All the versions of replaced
+	// transactions are returned.
 	Pending(context.Context) []string
 }
 
@@ -20,16 +20,18 @@ func NewDefaultSubmissionList() OpenSubmissionList {
 }
 
 // openSubmission tracks a slice of channels that should be emitted to when we
-// know the result for the transactions with the provided hash
+// know the result for the transactions with the provided hash. When a transaction
+// was replaced, Hashes contains the hashes of all its versions.
 type openSubmission struct {
 	Hash        string
+	Hashes      []string
 	SubmittedAt time.Time
 	Listeners   []Listener
 }
 
 type submissionList struct {
 	sync.Mutex
-	submissions map[string]*openSubmission // hash => `*openSubmission`
+	submissions map[string]*openSubmission // hash => `*openSubmission`, one entry for every version
 	log         *log.Entry
 }
 
@@ -50,6 +52,7 @@ func (s *submissionList) Add(ctx context.Context, hash string, l Listener) error
 	if !ok {
 		os = &openSubmission{
 			Hash:        hash,
+			Hashes:      []string{hash},
 			SubmittedAt: time.Now(),
 			Listeners:   []Listener{},
 		}
@@ -64,6 +67,41 @@ func (s *submissionList) Add(ctx context.Context, hash string, l Listener) error
 	return nil
 }
 
+func (s *submissionList) Replace(ctx context.Context, replacedHash, hash string, l Listener) error {
+	s.Lock()
+	defer s.Unlock()
+
+	if cap(l) == 0 {
+		panic("Unbuffered listener cannot be added to OpenSubmissionList")
+	}
+
+	if len(hash) != 64 {
+		return errors.New("Unexpected transaction hash length: must be 64 hex characters")
+	}
+
+	os, ok := s.submissions[replacedHash]
+	if !ok {
+		return errors.New("Replaced transaction is not pending")
+	}
+
+	if _, ok := s.submissions[hash]; !ok {
+		os.Hash = hash
+		os.Hashes = append(os.Hashes, hash)
+		// The replacement is a new submission to diamnet-core, give it
+		// a full submission timeout.
+		os.SubmittedAt = time.Now()
+		s.submissions[hash] = os
+	}
+	os.Listeners = append(os.Listeners, l)
+
+	s.log.WithFields(log.F{
+		"hash":          hash,
+		"replaced_hash": replacedHash,
+		"listeners":     len(os.Listeners),
+	}).Info("Replaced a pending transaction")
+	return nil
+}
+
 func (s *submissionList) Finish(ctx context.Context, r Result) error {
 	s.Lock()
 	defer s.Unlock()
@@ -84,7 +122,9 @@ func (s *submissionList) Finish(ctx context.Context, r Result) error {
 		close(l)
 	}
 
-	delete(s.submissions, r.Hash)
+	for _, hash := range os.Hashes {
+		delete(s.submissions, hash)
+	}
 	return nil
 }
 
@@ -92,22 +132,32 @@ func (s *submissionList) Clean(ctx context.Context, maxAge time.Duration) (int,
 	s.Lock()
 	defer s.Unlock()
 
-	for _, os := range s.submissions {
+	open := 0
+	for hash, os := range s.submissions {
+		// visit every submission once, using the hash of its latest version
+		if hash != os.Hash {
+			continue
+		}
+
 		if time.Since(os.SubmittedAt) > maxAge {
 			s.log.WithFields(log.F{
 				"hash":      os.Hash,
 				"listeners": len(os.Listeners),
 			}).Warn("Cleared submission due to timeout")
 			r := Result{Err: ErrTimeout}
-			delete(s.submissions, os.Hash)
+			for _, h := range os.Hashes {
+				delete(s.submissions, h)
+			}
 			for _, l := range os.Listeners {
 				l <- r
 				close(l)
 			}
+		} else {
+			open++
 		}
 	}
 
-	return len(s.submissions), nil
+	return open, nil
 }
 
 func (s *submissionList) Pending(ctx context.Context) []string {
@@ -136,6 +136,40 @@ func (suite *SubmissionListTestSuite) TestSubmissionList_Clean() {
 	}
 }
 
+func (suite *SubmissionListTestSuite) TestSubmissionList_Replace() {
+	suite.list.Add(suite.ctx, suite.hashes[0], suite.listeners[0])
+	err := suite.list.Replace(suite.ctx, suite.hashes[0], suite.hashes[1], suite.listeners[1])
+	assert.Nil(suite.T(), err)
+
+	// both versions are pending
+	assert.ElementsMatch(suite.T(), suite.hashes, suite.list.Pending(suite.ctx))
+	assert.True(suite.T(), suite.realList.submissions[suite.hashes[0]] == suite.realList.submissions[suite.hashes[1]])
+
+	// the result of the original transaction is sent to all listeners
+	r := Result{Hash: suite.hashes[0]}
+	suite.list.Finish(suite.ctx, r)
+	assert.Equal(suite.T(), r, <-suite.listeners[0])
+	assert.Equal(suite.T(), r, <-suite.listeners[1])
+	assert.Empty(suite.T(), suite.list.Pending(suite.ctx))
+
+	// errors when the replaced transaction is not pending
+	err = suite.list.Replace(suite.ctx, suite.hashes[0], suite.hashes[1], make(chan Result, 1))
+	assert.NotNil(suite.T(), err)
+}
+
+func (suite *SubmissionListTestSuite) TestSubmissionList_CleanReplaced() {
+	suite.list.Add(suite.ctx, suite.hashes[0], suite.listeners[0])
+	suite.list.Replace(suite.ctx, suite.hashes[0], suite.hashes[1], suite.listeners[1])
+	<-time.After(200 * time.Millisecond)
+
+	left, err := suite.list.Clean(suite.ctx, 200*time.Millisecond)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 0, left)
+	assert.Empty(suite.T(), suite.list.Pending(suite.ctx))
+	assert.Equal(suite.T(), ErrTimeout, (<-suite.listeners[0]).Err)
+	assert.Equal(suite.T(), ErrTimeout, (<-suite.listeners[1]).Err)
+}
+
 //Tests that Pending works as expected
 func (suite *SubmissionListTestSuite) TestSubmissionList_Pending() {
 	assert.Equal(suite.T(), 0, len(suite.list.Pending(suite.ctx)))
@@ -20,6 +20,11 @@ type System struct {
 	tickMutex      sync.Mutex
 	tickInProgress bool
 
+	// replaceable tracks the latest submitted version of pending transactions
+	// so they can be replaced by a transaction paying a higher fee.
+	replaceableMutex sync.Mutex
+	replaceable      map[replaceableKey]replaceableSubmission
+
 	Pending           OpenSubmissionList
 	Results           ResultProvider
 	Sequences         SequenceProvider
@@ -52,8 +57,26 @@ type System struct {
 	}
 }
 
+// replaceableKey identifies the transactions which can replace each other
+type replaceableKey struct {
+	SourceAddress string
+	Sequence      uint64
+}
+
+// replaceableSubmission is the latest submitted version of a pending transaction
+type replaceableSubmission struct {
+	Hash string
+	Fee  uint32
+}
+
 // Submit submits the provided base64 encoded transaction envelope to the
 // network using this submission system.
+//
+// If a transaction with the same source account and sequence number was
+// submitted before and is still pending, the new transaction replaces it
+// provided it pays a higher fee (it fails with ErrInsufficientReplacementFee
+// otherwise). The listeners of both transactions receive the result of
+// whichever version is included in a ledger.
 func (sys *System) Submit(ctx context.Context, env string) (result <-chan Result) {
 	sys.Init()
 	response := make(chan Result, 1)
@@ -88,6 +111,11 @@ func (sys *System) Submit(ctx context.Context, env string) (result <-chan Result
 
 	// From now: r.Err == ErrNoResults
 
+	if pending, ok := sys.pendingReplaceable(info); ok {
+		sys.replace(ctx, env, info, pending, response)
+		return
+	}
+
 	curSeq, err := sys.Sequences.Get([]string{info.SourceAddress})
 	if err != nil {
 		sys.finish(ctx, response, Result{Err: err, EnvelopeXDR: env})
@@ -127,6 +155,7 @@ func (sys *System) Submit(ctx context.Context, env string) (result <-chan Result
 		if sr.Err == nil {
 			// add transactions to open list
 			sys.Pending.Add(ctx, info.Hash, response)
+			sys.setReplaceable(info)
 			// update the submission queue, allowing the next submission to proceed
 			sys.SubmissionQueue.Update(map[string]uint64{info.SourceAddress: info.Sequence})
 			return
@@ -162,6 +191,85 @@ func (sys *System) Submit(ctx context.Context, env string) (result <-chan Result
 	return
 }
 
+// replace submits the transaction replacing the pending transaction. The
+// sequence number of the pending transaction is already taken in the submission
+// queue so the replacement is sent to diamnet-core directly.
+func (sys *System) replace(
+	ctx context.Context,
+	env string,
+	info envelopeInfo,
+	pending replaceableSubmission,
+	response chan Result,
+) {
+	if info.Fee <= pending.Fee {
+		sys.finish(ctx, response, Result{Err: ErrInsufficientReplacementFee, EnvelopeXDR: env})
+		return
+	}
+
+	sys.Log.Ctx(ctx).WithFields(log.F{
+		"hash":          info.Hash,
+		"replaced_hash": pending.Hash,
+		"fee":           info.Fee,
+		"replaced_fee":  pending.Fee,
+	}).Info("Replacing pending transaction")
+
+	sr := sys.submitOnce(ctx, env)
+	if sr.Err != nil {
+		sys.finish(ctx, response, Result{Err: sr.Err, EnvelopeXDR: env})
+		return
+	}
+
+	if err := sys.Pending.Replace(ctx, pending.Hash, info.Hash, response); err != nil {
+		// the replaced transaction was finished in the meantime, track the
+		// replacement as a new submission
+		sys.Pending.Add(ctx, info.Hash, response)
+	}
+	sys.setReplaceable(info)
+}
+
+// pendingReplaceable returns the latest version of the pending transaction
+// which can be replaced by the transaction described by info.
+func (sys *System) pendingReplaceable(info envelopeInfo) (replaceableSubmission, bool) {
+	sys.replaceableMutex.Lock()
+	defer sys.replaceableMutex.Unlock()
+
+	pending, ok := sys.replaceable[replaceableKey{info.SourceAddress, info.Sequence}]
+	if !ok || pending.Hash == info.Hash {
+		return replaceableSubmission{}, false
+	}
+	return pending, true
+}
+
+func (sys *System) setReplaceable(info envelopeInfo) {
+	sys.replaceableMutex.Lock()
+	defer sys.replaceableMutex.Unlock()
+
+	sys.replaceable[replaceableKey{info.SourceAddress, info.Sequence}] = replaceableSubmission{
+		Hash: info.Hash,
+		Fee:  info.Fee,
+	}
+}
+
+// removeFinishedReplaceable stops tracking transactions which are no
+// longer pending.
+func (sys *System) removeFinishedReplaceable(ctx context.Context) {
+	sys.replaceableMutex.Lock()
+	defer sys.replaceableMutex.Unlock()
+
+	// Pending is called with the lock held so transactions added to the list
+	// concurrently are not removed before they are tracked.
+	pending := sys.Pending.Pending(ctx)
+	stillPending := make(map[string]bool, len(pending))
+	for _, hash := range pending {
+		stillPending[hash] = true
+	}
+	for key, submission := range sys.replaceable {
+		if !stillPending[submission.Hash] {
+			delete(sys.replaceable, key)
+		}
+	}
+}
+
 // Submit submits the provided base64 encoded transaction envelope to the
 // network using this submission system.
 func (sys *System) submitOnce(ctx context.Context, env string) SubmissionResult {
@@ -257,6 +365,7 @@ func (sys *System) Tick(ctx context.Context) {
 		return
 	}
 
+	sys.removeFinishedReplaceable(ctx)
 	sys.Metrics.OpenSubmissionsGauge.Update(int64(stillOpen))
 	sys.Metrics.BufferedSubmissionsGauge.Update(int64(sys.SubmissionQueue.Size()))
 }
@@ -271,6 +380,7 @@ func (sys *System) Init() {
 		sys.Metrics.SubmissionTimer = metrics.NewTimer()
 		sys.Metrics.OpenSubmissionsGauge = metrics.NewGauge()
 		sys.Metrics.BufferedSubmissionsGauge = metrics.NewGauge()
+		sys.replaceable = map[replaceableKey]replaceableSubmission{}
 
 		if sys.SubmissionTimeout == 0 {
 			// HTTP clients in SDKs usually timeout in 60 seconds. We want SubmissionTimeout
@@ -10,6 +10,7 @@ import (
 	"github.com/diamnet/go/build"
 	"github.com/diamnet/go/services/aurora/internal/test"
 	"github.com/diamnet/go/services/aurora/internal/txsub/sequence"
+	"github.com/diamnet/go/xdr"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 )
@@ -160,6 +161,55 @@ func (suite *SystemTestSuite) TestTick_Deadlock() {
 	<-testDone
 }
 
+// replacementEnvelope returns the envelope of suite.successTx with a different fee
+func (suite *SystemTestSuite) replacementEnvelope(fee uint32) (string, string) {
+	var env xdr.TransactionEnvelope
+	suite.Require().NoError(xdr.SafeUnmarshalBase64(suite.successTx.EnvelopeXDR, &env))
+	env.Tx.Fee = xdr.Uint32(fee)
+	envXDR, err := xdr.MarshalBase64(env)
+	suite.Require().NoError(err)
+	info, err := extractEnvelopeInfo(suite.ctx, envXDR, suite.system.NetworkPassphrase)
+	suite.Require().NoError(err)
+	return envXDR, info.Hash
+}
+
+// Test that a pending transaction can be replaced by a transaction paying a higher fee
+func (suite *SystemTestSuite) TestSubmit_Replace() {
+	original := suite.system.Submit(suite.ctx, suite.successTx.EnvelopeXDR)
+	assert.Equal(suite.T(), []string{suite.successTx.Hash}, suite.system.Pending.Pending(suite.ctx))
+
+	env, hash := suite.replacementEnvelope(200)
+	replacement := suite.system.Submit(suite.ctx, env)
+	assert.ElementsMatch(
+		suite.T(),
+		[]string{suite.successTx.Hash, hash},
+		suite.system.Pending.Pending(suite.ctx),
+	)
+
+	// the replacement lands so both listeners receive its result
+	result := Result{Hash: hash, LedgerSequence: 2, EnvelopeXDR: env}
+	suite.results.Results = []Result{result}
+	suite.system.Tick(suite.ctx)
+
+	assert.Equal(suite.T(), result, <-original)
+	assert.Equal(suite.T(), result, <-replacement)
+	assert.Empty(suite.T(), suite.system.Pending.Pending(suite.ctx))
+	assert.Empty(suite.T(), suite.system.replaceable)
+}
+
+// Test that a replacement must pay a higher fee than the pending transaction
+func (suite *SystemTestSuite) TestSubmit_ReplaceInsufficientFee() {
+	suite.system.Submit(suite.ctx, suite.successTx.EnvelopeXDR)
+	suite.submitter.WasSubmittedTo = false
+
+	env, _ := suite.replacementEnvelope(50)
+	r := <-suite.system.Submit(suite.ctx, env)
+
+	assert.Equal(suite.T(), ErrInsufficientReplacementFee, r.Err)
+	assert.False(suite.T(), suite.submitter.WasSubmittedTo)
+	assert.Equal(suite.T(), []string{suite.successTx.Hash}, suite.system.Pending.Pending(suite.ctx))
+}
+
 // Test that Tick finishes any available transactions,
 func (suite *SystemTestSuite) TestTick_FinishesTransactions() {
 	l := make(chan Result, 1)
@@ -11,6 +11,7 @@ import (
 
 	"github.com/go-chi/chi"
 	chimiddleware "github.com/go-chi/chi/middleware"
+	"github.com/gomodule/redigo/redis"
 	metrics "github.com/rcrowley/go-metrics"
 	"github.com/rs/cors"
 	"github.com/sebest/xff"
@@ -18,13 +19,16 @@ import (
 	"github.com/diamnet/go/services/aurora/internal/db2/core"
 	"github.com/diamnet/go/services/aurora/internal/db2/history"
 	"github.com/diamnet/go/services/aurora/internal/ledger"
+	"github.com/diamnet/go/services/aurora/internal/prometheus"
+	"github.com/diamnet/go/services/aurora/internal/pubsub"
+	"github.com/diamnet/go/services/aurora/internal/ratelimit"
 	hProblem "github.com/diamnet/go/services/aurora/internal/render/problem"
 	"github.com/diamnet/go/services/aurora/internal/render/sse"
+	"github.com/diamnet/go/services/aurora/internal/simplepath"
 	"github.com/diamnet/go/services/aurora/internal/txsub/sequence"
 	"github.com/diamnet/go/support/db"
 	"github.com/diamnet/go/support/log"
 	"github.com/diamnet/go/support/render/problem"
-	"github.com/throttled/throttled"
 )
 
 const LRUCacheSize = 50000
@@ -34,7 +38,8 @@ const LRUCacheSize = 50000
 type web struct {
 	appCtx             context.Context
 	router             *chi.Mux
-	rateLimiter        *throttled.HTTPRateLimiter
+	rateLimiter        *ratelimit.Limiter
+	ledgerUpdates      *pubsub.Hub
 	sseUpdateFrequency time.Duration
 	staleThreshold     uint
 	ingestFailedTx     bool
@@ -45,6 +50,8 @@ type web struct {
 	requestTimer metrics.Timer
 	failureMeter metrics.Meter
 	successMeter metrics.Meter
+
+	requestMetrics *prometheus.RequestMetrics
 }
 
 func init() {
@@ -55,10 +62,13 @@ func init() {
 	problem.RegisterError(db2.ErrInvalidLimit, problem.BadRequest)
 	problem.RegisterError(db2.ErrInvalidOrder, problem.BadRequest)
 	problem.RegisterError(sse.ErrRateLimited, hProblem.RateLimitExceeded)
+	problem.RegisterError(simplepath.ErrNotImplemented, hProblem.UnsupportedPathFinding)
+	problem.RegisterError(sse.ErrTooManyStreams, hProblem.TooManyStreams)
+	problem.RegisterError(ratelimit.ErrInvalidAPIKey, hProblem.InvalidAPIKey)
 }
 
 // mustInitWeb installed a new Web instance onto the provided app object.
-func mustInitWeb(ctx context.Context, hq *history.Q, cq *core.Q, updateFreq time.Duration, threshold uint, ingestFailedTx bool) *web {
+func mustInitWeb(ctx context.Context, hq *history.Q, cq *core.Q, ledgerUpdates *pubsub.Hub, updateFreq time.Duration, threshold uint, ingestFailedTx bool) *web {
 	if hq == nil {
 		log.Fatal("missing history DB for installing the web instance")
 	}
@@ -71,12 +81,14 @@ func mustInitWeb(ctx context.Context, hq *history.Q, cq *core.Q, updateFreq time
 		router:             chi.NewRouter(),
 		historyQ:           hq,
 		coreQ:              cq,
+		ledgerUpdates:      ledgerUpdates,
 		sseUpdateFrequency: updateFreq,
 		staleThreshold:     threshold,
 		ingestFailedTx:     ingestFailedTx,
 		requestTimer:       metrics.NewTimer(),
 		failureMeter:       metrics.NewMeter(),
 		successMeter:       metrics.NewMeter(),
+		requestMetrics:     prometheus.NewRequestMetrics(),
 	}
 }
 
@@ -185,6 +197,8 @@ func (w *web) mustInstallActions(enableAssetStats bool, friendbotURL *url.URL) {
 		r.Get("/{offer_id}/trades", TradeIndexAction{}.Handle)
 	})
 	r.Get("/order_book", OrderBookShowAction{}.Handle)
+	r.With(requiresExperimentalIngestion).
+		Get("/order_book/depth", OrderBookDepthAction{}.Handle)
 
 	// Transaction submission API
 	r.Post("/transactions", TransactionCreateAction{}.Handle)
@@ -213,30 +227,48 @@ func (w *web) mustInstallActions(enableAssetStats bool, friendbotURL *url.URL) {
 	r.NotFound(NotFoundAction{}.Handle)
 }
 
-func maybeInitWebRateLimiter(rateQuota *throttled.RateQuota) *throttled.HTTPRateLimiter {
+// maybeInitWebRateLimiter returns the limiter of the requests and streams of
+// clients, or nil when no limit and no API key is configured. The state of the
+// limits is kept in Redis, shared by all the aurora instances, when redisPool
+// is set.
+func maybeInitWebRateLimiter(config Config, redisPool *redis.Pool) *ratelimit.Limiter {
+	rateLimitConfig := &ratelimit.Config{}
+	if config.RateLimitConfigPath != "" {
+		var err error
+		rateLimitConfig, err = ratelimit.ReadConfig(config.RateLimitConfigPath)
+		if err != nil {
+			log.Fatalf("unable to read rate limit config: %v", err)
+		}
+	}
+	if config.RateQuota != nil {
+		err := rateLimitConfig.ValidateQuota(*config.RateQuota)
+		if err != nil {
+			log.Fatalf("invalid rate limit config for requests without an API key: %v", err)
+		}
+	}
+
 	// Disabled
-	if rateQuota == nil {
+	if config.RateQuota == nil && config.MaxStreamsPerIP == 0 && len(rateLimitConfig.APIKeys) == 0 {
 		return nil
 	}
 
-	rateLimiter, err := throttled.NewGCRARateLimiter(LRUCacheSize, *rateQuota)
-	if err != nil {
-		log.Fatalf("unable to create RateLimiter: %v", err)
+	var store ratelimit.Store = ratelimit.NewMemoryStore(LRUCacheSize)
+	if redisPool != nil {
+		store = ratelimit.NewRedisStore(redisPool, config.RateLimitRedisKey)
 	}
 
-	return &throttled.HTTPRateLimiter{
-		RateLimiter:   rateLimiter,
-		DeniedHandler: &RateLimitExceededAction{Action{}},
-		VaryBy:        VaryByRemoteIP{},
+	return &ratelimit.Limiter{
+		Store:      store,
+		Quota:      config.RateQuota,
+		MaxStreams: config.MaxStreamsPerIP,
+		// streams are closed by the timeout middleware, streams outliving it
+		// were not released because of a crash
+		StreamTTL: config.ConnectionTimeout + time.Minute,
+		APIKeys:   rateLimitConfig.Keys(),
+		Costs:     rateLimitConfig.Costs,
 	}
 }
 
-type VaryByRemoteIP struct{}
-
-func (v VaryByRemoteIP) Key(r *http.Request) string {
-	return remoteAddrIP(r)
-}
-
 func remoteAddrIP(r *http.Request) string {
 	// To support IPv6
 	lastSemicolon := strings.LastIndex(r.RemoteAddr, ":")
@@ -5,24 +5,27 @@ import (
 	"net/url"
 	"regexp"
 
+	"github.com/diamnet/go/amount"
 	"github.com/diamnet/go/keypair"
 	"github.com/diamnet/go/services/internal/bridge-compliance-shared/protocols"
 )
 
 // Config contains config params of the bridge server
 type Config struct {
-	Port              *int              `valid:"required"`
-	Aurora           string            `valid:"optional"`
-	Compliance        string            `valid:"optional"`
-	LogFormat         string            `valid:"optional" toml:"log_format"`
-	MACKey            string            `valid:"optional" toml:"mac_key"`
-	APIKey            string            `valid:"optional" toml:"api_key"`
-	NetworkPassphrase string            `valid:"optional" toml:"network_passphrase"`
-	Develop           bool              `valid:"optional"`
-	Assets            []protocols.Asset `valid:"optional"`
-	Database          *Database         `valid:"optional"`
-	Accounts          Accounts          `valid:"optional" toml:"accounts"`
-	Callbacks         Callbacks         `valid:"optional" toml:"callbacks"`
+	Port              *int               `valid:"required"`
+	Aurora            string             `valid:"optional"`
+	Compliance        string             `valid:"optional"`
+	LogFormat         string             `valid:"optional" toml:"log_format"`
+	MACKey            string             `valid:"optional" toml:"mac_key"`
+	APIKey            string             `valid:"optional" toml:"api_key"`
+	NetworkPassphrase string             `valid:"optional" toml:"network_passphrase"`
+	Develop           bool               `valid:"optional"`
+	Assets            []protocols.Asset  `valid:"optional"`
+	Database          *Database          `valid:"optional"`
+	Accounts          Accounts           `valid:"optional" toml:"accounts"`
+	Callbacks         Callbacks          `valid:"optional" toml:"callbacks"`
+	ReceivingAccounts []ReceivingAccount `valid:"optional" toml:"receiving_accounts"`
+	Channels          Channels           `valid:"optional" toml:"channels"`
 }
 
 // Accounts contains values of `accounts` config group
@@ -39,6 +42,29 @@ type Callbacks struct {
 	Error   string `valid:"optional"`
 }
 
+// ReceivingAccount contains values of a `receiving_accounts` config group.
+// Assets and callbacks that are not set default to the global ones.
+type ReceivingAccount struct {
+	AccountID string            `valid:"required" toml:"account_id"`
+	Assets    []protocols.Asset `valid:"optional"`
+	Callbacks Callbacks         `valid:"optional" toml:"callbacks"`
+}
+
+// Channels contains values of `channels` config group. When count is set,
+// transactions sent by the bridge server are sourced from a pool of channel
+// accounts created and funded by the base account.
+type Channels struct {
+	Count           int    `valid:"optional" toml:"count"`
+	StartingBalance string `valid:"optional" toml:"starting_balance"`
+}
+
+// maxChannels is the maximum number of channel accounts
+const maxChannels = 100
+
+// defaultChannelStartingBalance is the balance channel accounts are created
+// with when channels.starting_balance is not set
+const defaultChannelStartingBalance = "2"
+
 // Database contains values of `database` config group
 type Database struct {
 	Type string `valid:"required"`
@@ -47,6 +73,30 @@ type Database struct {
 
 var assetCodeMatch = regexp.MustCompile("^[a-zA-Z0-9]{1,12}$")
 
+// GetReceivingAccounts returns all the accounts the bridge server listens to:
+// `accounts.receiving_account_id`, if set, followed by `receiving_accounts`.
+// Assets and callbacks missing from an account are set to the global ones.
+func (c *Config) GetReceivingAccounts() []ReceivingAccount {
+	var accounts []ReceivingAccount
+	if c.Accounts.ReceivingAccountID != "" {
+		accounts = append(accounts, ReceivingAccount{AccountID: c.Accounts.ReceivingAccountID})
+	}
+	accounts = append(accounts, c.ReceivingAccounts...)
+
+	for i := range accounts {
+		if len(accounts[i].Assets) == 0 {
+			accounts[i].Assets = c.Assets
+		}
+		if accounts[i].Callbacks.Receive == "" {
+			accounts[i].Callbacks.Receive = c.Callbacks.Receive
+		}
+		if accounts[i].Callbacks.Error == "" {
+			accounts[i].Callbacks.Error = c.Callbacks.Error
+		}
+	}
+	return accounts
+}
+
 // Validate validates config and returns error if any of config values is incorrect
 func (c *Config) Validate() (err error) {
 	if c.Port == nil {
@@ -70,26 +120,9 @@ func (c *Config) Validate() (err error) {
 		return
 	}
 
-	for _, asset := range c.Assets {
-		if asset.Issuer == "" {
-			if asset.Code != "XLM" {
-				err = errors.New("Issuer param is required for " + asset.Code)
-				return
-			}
-		}
-
-		if asset.Issuer != "" {
-			_, err = keypair.Parse(asset.Issuer)
-			if err != nil {
-				err = errors.New("Issuing account is invalid for " + asset.Code)
-				return
-			}
-		}
-
-		matched := assetCodeMatch.MatchString(asset.Code)
-		if !matched {
-			return errors.New("Invalid asset code: " + asset.Code)
-		}
+	err = validateAssets(c.Assets)
+	if err != nil {
+		return
 	}
 
 	var dbURL *url.URL
@@ -132,6 +165,28 @@ func (c *Config) Validate() (err error) {
 		}
 	}
 
+	if c.Channels.Count < 0 || c.Channels.Count > maxChannels {
+		err = errors.New("channels.count must be between 0 and 100")
+		return
+	}
+
+	if c.Channels.Count > 0 {
+		if c.Accounts.BaseSeed == "" {
+			err = errors.New("accounts.base_seed is required when channels.count is set")
+			return
+		}
+
+		if c.Channels.StartingBalance == "" {
+			c.Channels.StartingBalance = defaultChannelStartingBalance
+		}
+
+		_, err = amount.Parse(c.Channels.StartingBalance)
+		if err != nil {
+			err = errors.New("channels.starting_balance is invalid")
+			return
+		}
+	}
+
 	if c.Accounts.IssuingAccountID != "" {
 		_, err = keypair.Parse(c.Accounts.IssuingAccountID)
 		if err != nil {
@@ -164,5 +219,60 @@ func (c *Config) Validate() (err error) {
 		}
 	}
 
+	accountIDs := map[string]bool{}
+	for _, account := range c.GetReceivingAccounts() {
+		_, err = keypair.Parse(account.AccountID)
+		if err != nil {
+			err = errors.New("receiving_accounts.account_id is invalid: " + account.AccountID)
+			return
+		}
+
+		if accountIDs[account.AccountID] {
+			err = errors.New("Duplicate receiving account: " + account.AccountID)
+			return
+		}
+		accountIDs[account.AccountID] = true
+
+		err = validateAssets(account.Assets)
+		if err != nil {
+			return
+		}
+
+		_, err = url.Parse(account.Callbacks.Receive)
+		if err != nil {
+			err = errors.New("Cannot parse receiving_accounts.callbacks.receive param for " + account.AccountID)
+			return
+		}
+
+		_, err = url.Parse(account.Callbacks.Error)
+		if err != nil {
+			err = errors.New("Cannot parse receiving_accounts.callbacks.error param for " + account.AccountID)
+			return
+		}
+	}
+
 	return
 }
+
+func validateAssets(assets []protocols.Asset) error {
+	for _, asset := range assets {
+		if asset.Issuer == "" {
+			if asset.Code != "XLM" {
+				return errors.New("Issuer param is required for " + asset.Code)
+			}
+		}
+
+		if asset.Issuer != "" {
+			_, err := keypair.Parse(asset.Issuer)
+			if err != nil {
+				return errors.New("Issuing account is invalid for " + asset.Code)
+			}
+		}
+
+		matched := assetCodeMatch.MatchString(asset.Code)
+		if !matched {
+			return errors.New("Invalid asset code: " + asset.Code)
+		}
+	}
+	return nil
+}
@@ -5,6 +5,8 @@
 // migrations/02_payment_id.sql
 // migrations/03_transaction_id.sql
 // migrations/04_table_names.sql
+// migrations/05_receiving_account_cursor.sql
+// migrations/06_callback.sql
 // DO NOT EDIT!
 
 package db
@@ -72,7 +74,7 @@ func (fi bindataFileInfo) Sys() interface{} {
 	return nil
 }
 
-var _latestSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xb4\x58\xdb\x6e\xe2\x48\x13\xbe\xfe\x79\x8a\xba\x23\xd1\x6f\x18\xc8\x42\x26\x03\xca\x85\x07\x1c\x0d\x5a\x30\x19\x30\x3b\x13\x69\x25\xab\xb1\x0b\xa7\x15\xbb\xed\x74\xb7\x73\xd8\xa7\x5f\xb5\x0d\xc1\x27\x0e\x49\xd8\x4b\x77\x57\xd7\xe9\xab\xfa\xaa\xa0\xd1\xa8\x35\x1a\x70\x1b\x0a\xe9\x71\x9c\xff\x1c\x83\x4b\x24\x59\x12\x81\xe0\xc6\x41\x54\x6b\x34\x6a\xea\x7e\x18\x07\x11\xba\xb0\xe2\x61\xb0\x15\x78\x42\x2e\x68\xc8\xe0\x5b\xf3\xb2\xd9\xce\x48\x2d\x5f\x21\xf2\x6c\xf5\xbc\x20\x52\x9b\x1b\x16\x08\x49\x24\x06\xc8\xa4\x2d\x69\x80\x61\x2c\xe1\x1a\x5a\xfd\xe4\xca\x0f\x9d\x87\xf2\x29\x75\x7d\xb4\x29\xb3\x25\x27\x4c\x10\x47\xd2\x90\xd9\x02\x85\xd2\x5b\x16\x76\x7c\xaa\x54\x23\x73\x42\x97\x32\x0f\xae\xa1\xbe\xb0\x6e\xae\xea\xfd\x8d\x6d\xe6\x12\xee\xda\x4e\xc8\x56\x21\x0f\x28\xf3\x6c\x21\x39\x65\x9e\x80\x6b\x08\xd9\x5a\xc7\x3d\x3a\x0f\xf6\x2a\x66\xa9\xad\x65\xe8\x52\x54\xf7\x2b\xe2\x0b\xcc\x99\x09\x28\xb3\x03\x14\x82\x78\x89\xc0\x33\xe1\x8c\x32\x2f\x15\xe1\xe1\xb3\x2d\xd0\x89\x39\x95\xaf\x4a\xf9\x6a\xd5\x57\xa9\x54\x79\x32\x49\x80\x3d\x88\xfc\xc8\x13\x8f\x7e\x1f\xac\xd7\x08\x7b\x60\xfc\xb6\x0c\x73\x3e\x9a\x9a\x7d\x98\x3b\xf7\x18\x90\x1e\x34\xfa\x30\x7d\x66\xc8\x7b\x90\xe0\x30\x98\x19\xba\x65\x6c\x05\x61\x74\x03\xe6\xd4\x02\xe3\xf7\x68\x6e\xcd\x37\xfa\xe0\xd7\xc8\xfa\x01\xf3\xc1\x0f\x63\xa2\x2b\x1c\x1c\x22\x89\x1f\x7a\xfd\x5a\xde\xfa\x56\x4b\xc1\x8f\xc1\x74\x32\x31\x4c\x6b\xb7\x17\xe9\x3d\x4c\xcd\xb2\x0e\x18\xcd\xa1\x7e\x3b\xfe\x12\x79\xaa\x92\x22\x1e\x3a\xe8\xc6\x9c\xf8\xe0\x13\xe6\xc5\xc4\xc3\xba\x72\x23\x41\x02\x09\x77\xee\xed\x88\xc8\x7b\xb8\x86\x28\x5e\xfa\xd4\xd1\xf2\xee\x2a\x31\x17\x57\x24\xf6\xa5\x2d\xc9\xd2\x47\x11\x11\x07\x15\xa2\xf5\xc2\xed\x33\x95\xf7\x76\x48\xdd\x0c\x48\xb9\x58\xbd\x90\x47\x76\x40\x3d\x4e\x14\xa0\x62\x13\xa9\xa5\x7f\x1f\x1b\xdb\x38\x53\x27\xde\x82\x5d\x12\x2e\xf1\x21\x9b\xf8\x44\xbe\xa8\x0c\xce\x6a\x00\x00\xd4\x05\x89\x2f\x32\xc1\xc3\x5c\x8c\xc7\x5a\x72\x4a\xa2\xc8\xa7\xe8\xda\x44\x82\xaa\x54\x21\x49\x10\x81\xf2\x36\xf9\x84\x7f\x42\x86\xb5\x73\x95\x12\x7d\x6c\x19\xb3\x1d\x06\xa6\xbf\x4c\x75\x37\x5d\x7b\x54\x88\x8d\xa3\x83\xf4\x09\x5d\x3b\x22\xaf\xaa\xab\x3e\x17\x5c\x51\xdb\x36\xba\x25\xf5\x28\x2b\xc6\x17\x46\x98\x7a\x69\x53\x17\x9c\x7b\xc2\x89\x23\x91\xc3\x13\xe1\xaf\x94\x79\x67\x17\xdd\xee\x79\xe1\x45\x52\x13\x42\x54\xe5\x44\xf5\xf1\x5b\x5a\x8a\xcf\x88\xa7\x7a\x55\x86\x0f\xc8\x8e\x33\xa4\x68\x26\x16\xc7\xc9\x66\x99\xa5\x32\x90\xcb\xce\x39\x0c\x8d\x1b\x7d\x31\xb6\xa0\x6e\x7e\xd1\xeb\xbd\x5e\x49\xa8\x0c\x64\x29\x99\xc7\x21\xb9\x96\xb6\xa9\x6b\x0b\x7c\xdc\xe0\x39\x37\x7e\x2e\x0c\x73\xf0\x1e\x48\x37\x4f\x76\x68\x4e\x42\x9f\x5b\xfa\xcc\x4a\x29\xa3\x9d\x1c\x8c\xcc\xc1\xcc\x48\x1a\xfc\xfb\xdd\xfa\xc8\x9c\xc2\x64\x64\xfe\xa5\x8f\x17\xc6\xdb\xb7\xfe\x7b\xfb\x3d\xd0\x07\x3f\x0c\x68\xef\x0a\x3f\x6f\xf5\x24\x49\x48\x94\x0c\xe1\xfb\xdd\x31\xd9\x48\x7d\x3a\x90\x8c\x37\x8d\x25\xd4\x9a\xd4\x2d\x72\xa7\x48\xa6\xd7\xb6\x68\x3e\xd7\x73\x45\x6d\xdb\x9e\xa3\x4c\xa2\x87\xfc\x23\xd5\x7a\x64\x33\xb4\x5b\x25\xd1\x30\xe6\x0e\x56\x88\x76\x2f\x4b\xa2\xf1\x32\xa0\x52\xbe\xb7\x97\x45\xec\x38\x88\xee\xc1\x67\xa9\xb4\x8f\xae\x4a\x41\x4a\x3f\xe9\x11\xb2\x27\xf4\xc3\x08\xed\x17\x97\x57\xb1\x2e\x47\xa1\xa6\x82\xba\xdd\xd1\xfe\x9b\x5e\x56\x6f\x2a\x5a\x79\xc3\x39\x9b\x02\xf9\xa0\x9a\x32\x23\x94\xa0\xde\xdf\x0c\x4a\x3c\x8f\xf6\xa9\x18\xa1\x5a\xf3\x7f\xcd\x08\xd5\x56\x4f\x92\x84\xcf\x30\xc2\x1e\xb7\x12\x46\x28\xa2\x56\xc1\x08\x25\xaa\xa7\xee\xc6\xc3\x75\x91\x1c\xef\x57\x9a\xab\xa9\x39\x2e\x73\x11\xa4\x12\x83\xe9\x78\x31\x31\x15\x43\xa8\x2d\x68\x53\x85\x0c\x5f\xe4\x13\xf1\xcf\xea\xd5\x0c\x57\xef\xf5\x38\x7a\x8e\x4f\x84\x38\x3f\x44\x68\x27\x72\xbf\xa4\xf6\x28\xf7\xab\xe1\xa8\x76\x7f\x48\x24\x81\x55\xc8\x8f\x58\xf4\x60\xa8\x5b\xfa\x51\xbd\x32\xbd\xbd\x2b\x6f\x79\xd4\xd5\x32\xeb\xdc\x39\xdc\xcc\xa6\x13\x10\xd2\xa5\xac\x5f\x6b\xb5\x6d\xca\xa8\x6c\x8a\x47\xff\x7f\x17\xad\xf6\x55\xa3\xd5\x69\x5c\x74\xa1\x7d\xd5\xbb\xe8\xf4\x3a\x9d\x66\xb7\xfb\xf5\xdb\x55\xfb\xff\xad\x8b\x5a\xeb\xc2\xde\xa2\xb2\x5b\xfe\x6b\xfb\xb2\xd3\x4d\xe4\xff\xb0\xf3\xa9\xd8\xf3\xe6\xea\xeb\xb7\xf4\x4d\x27\x5d\x97\x6d\x46\x02\x14\xbb\x1f\x5c\xb5\x3b\x4a\xfc\xef\xe6\xae\x6c\xee\x5d\x2d\xdf\x97\xce\xf2\x5e\xa9\xf2\x99\x5d\x1f\xb5\xdc\x6a\xa8\xe5\x36\x3e\x6d\x3d\xc0\xb4\xc2\xec\xcb\xa3\x90\x09\xe4\x5d\xbb\xc4\xdc\x38\xaa\xba\xe7\xc6\xd8\x18\x58\x99\x9f\x28\x4d\x81\x7b\xfb\x4d\x83\xb6\x96\xfe\x1c\xd9\x5d\xb0\x7b\x37\x89\xf7\xa5\xb8\xbc\x46\xa8\xa4\xe6\x13\xb6\x4d\x64\x3a\xe6\xb5\xdc\x0c\xd7\x72\xa3\x59\x5b\x8f\x5e\x2d\x37\x6f\xb5\xcc\x74\xd5\x32\x23\xf2\x00\x14\x47\x92\xf8\xa7\xa1\xd8\xc1\x1d\x95\x50\x54\x52\x46\xf1\xdb\x8e\x1e\xf0\x75\xfb\xd3\xd8\x9c\x5b\x33\x7d\x64\x7e\x8c\x0e\x0b\xaa\x93\x49\xa9\x0f\x87\x19\xb5\x95\xd6\xe1\x76\x36\x9a\xe8\xb3\x3b\xf8\xd3\xb8\x53\xa0\x1e\x66\xef\x4c\x21\xc6\x8c\x3e\xc6\x78\xa2\x00\x8a\x86\xaa\x22\x28\xd9\x86\x85\x39\xfa\xb9\x30\xe0\x2c\x53\x2c\x07\xc7\x67\xb1\xa5\xb2\x4c\x61\x9f\x0e\x91\xa2\xdd\xaa\x80\x0e\xb9\xf2\x16\x5f\xf6\xe2\xfd\x11\x9e\xb0\xce\x3e\x12\xd5\xc7\x0a\xad\xd8\x6e\x27\x0c\xe2\x98\x5a\xab\x32\xbf\x27\x88\x5d\x7f\x72\x82\x13\x06\x91\x8f\x12\x13\x4f\xfe\x0d\x00\x00\xff\xff\x7e\x47\x0b\xb3\x11\x15\x00\x00")
+var _latestSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xc4\x59\x5b\x73\xe2\x3a\x12\x7e\x5e\x7e\x45\xbf\x91\xd4\x1a\x0e\x66\x20\x17\x52\xf3\xc0\x10\xcf\x0e\xb5\xc4\xcc\x04\xb3\x67\xa6\xea\x54\xb9\x84\x2d\x1c\x55\x7c\x1b\x49\x4e\x26\xfb\xeb\xb7\x64\x5b\x60\xcb\x36\x38\x0b\x53\xe7\x2d\x48\xad\xee\xaf\x6f\x9f\x5a\x4e\xaf\xd7\xe9\xf5\xe0\x6b\xc4\xb8\x47\xf1\xea\xdb\x02\x5c\xc4\xd1\x06\x31\x0c\x6e\x12\xc4\x9d\x5e\xaf\x23\xf6\xef\x93\x20\xc6\x2e\x6c\x69\x14\xec\x05\x5e\x30\x65\x24\x0a\xe1\xb6\x7f\xd5\xd7\x0b\x52\x9b\x37\x88\x3d\x5b\x1c\x57\x44\x3a\x2b\xc3\x02\xc6\x11\xc7\x01\x0e\xb9\xcd\x49\x80\xa3\x84\xc3\x47\x18\xdc\xa5\x5b\x7e\xe4\x3c\x57\x57\x89\xeb\x63\x9b\x84\x36\xa7\x28\x64\xc8\xe1\x24\x0a\x6d\x86\x99\xd0\x5b\x15\x76\x7c\x22\x54\xe3\xd0\x89\x5c\x12\x7a\xf0\x11\xba\x6b\xeb\xf3\x4d\xf7\x4e\xda\x0e\x5d\x44\x5d\xdb\x89\xc2\x6d\x44\x03\x12\x7a\x36\xe3\x94\x84\x1e\x83\x8f\x10\x85\xb9\x8e\x27\xec\x3c\xdb\xdb\x24\xcc\x6c\x6d\x22\x97\x60\xb1\xbf\x45\x3e\xc3\x25\x33\x01\x09\xed\x00\x33\x86\xbc\x54\xe0\x15\xd1\x90\x84\x5e\x26\x42\xa3\x57\x9b\x61\x27\xa1\x84\xbf\x09\xe5\xdb\xed\x9d\x08\xa5\x88\x93\x89\x02\x3c\x81\xd8\x8f\x3d\xf6\xd3\xbf\x03\xeb\x2d\xc6\x13\x30\xbe\x5b\x86\xb9\x9a\x2f\xcd\x3b\x58\x39\x4f\x38\x40\x13\xe8\xdd\xc1\xf2\x35\xc4\x74\x02\xe2\x60\x67\xf6\x68\x4c\x2d\x63\x2f\x08\xf3\xcf\x60\x2e\x2d\x30\xbe\xcf\x57\xd6\x4a\xea\x83\x3f\xe7\xd6\x17\x58\xcd\xbe\x18\x0f\x53\x91\x07\x07\x71\xe4\x47\xde\x5d\xa7\x6c\x7d\xaf\x45\xc1\x31\x5b\x3e\x3c\x18\xa6\xd5\x8c\x22\xdb\x87\xa5\x59\xd5\x01\xf3\x15\x74\xbf\x2e\xfe\x88\x3d\x51\x49\x31\x8d\x1c\xec\x26\x14\xf9\xe0\xa3\xd0\x4b\x90\x87\xbb\x02\x86\x88\x0e\xc3\x88\x3a\x4f\x76\x8c\xf8\x13\x7c\x84\x38\xd9\xf8\xc4\xd1\xca\x70\x85\x98\x8b\xb7\x28\xf1\xb9\xcd\xd1\xc6\xc7\x2c\x46\x0e\x16\x19\xed\x2a\xbb\xaf\x84\x3f\xd9\x11\x71\x0b\x49\x2a\xf9\xea\x20\xdf\xdf\x20\xe7\x59\xba\x68\x4d\x3f\x2d\x8c\xbd\x83\x99\xf5\x9d\x97\x1b\x44\x39\x7e\x2e\x46\x3c\x95\xdf\x69\x81\x8b\x0e\x00\x00\x71\x61\x43\x3c\x12\xf2\x34\x07\xe6\x7a\xb1\xd0\xd2\xf5\x28\xc6\x14\xa5\x85\x43\x5c\x70\x9e\x10\x45\x0e\xc7\x14\x5e\x10\x7d\x23\xa1\x77\x31\x1c\x8f\x2f\x95\x13\x09\xf5\x81\xe3\x5f\xaa\xa2\x4d\xe4\xbe\xd5\xad\x8b\x06\x4a\x58\x8d\x6a\x7d\xa0\x6a\x46\x9c\xe3\x20\xe6\x0c\x48\xc8\xb1\x87\x29\xdc\x1b\x9f\xa7\xeb\x85\x05\x03\x45\xd0\x47\x8c\xdb\x98\xd2\x88\xa6\x16\xb3\xd3\x0e\xc5\x88\x63\xd7\x46\x1c\x44\x9f\x31\x8e\x82\x18\x44\xac\xa3\x24\x5b\x81\xff\x46\x21\x56\x34\x85\xf8\x17\xb7\x73\xbb\xef\x3b\xe9\x62\x9f\xbc\x60\x7a\xd4\x60\xe7\x52\x14\xd1\x74\x61\x19\x8f\x6a\x66\x96\x7f\x9a\x62\x71\x99\xe7\xb0\xa1\x0c\x6c\xe2\xda\x0c\xff\x94\xd5\xb0\x32\xbe\xad\x0d\x73\xf6\x9e\x82\x90\x47\x54\x95\x69\x04\x56\xd6\xf4\xd1\xca\xba\x50\x4f\x17\xe6\xe6\xec\xd1\x48\x7b\xe6\xd3\x8f\x7c\xc9\x5c\xc2\xc3\xdc\xfc\xcf\x74\xb1\x36\x76\xbf\xa7\xdf\xf7\xbf\x67\xd3\xd9\x17\x03\xf4\x26\x47\x73\x73\xa7\xf9\x9b\x9e\xbe\x87\x4f\x3f\xda\x38\x9e\xa1\x68\xf2\x7b\xa7\x6a\xb7\xd1\x27\xae\xca\x38\x5e\x44\x63\x3b\x20\x5e\xd6\x1d\xec\xb4\x66\x54\x94\xed\x7b\xb2\xa6\x61\x50\x1c\xfb\xa4\xae\xac\x0e\xd5\x94\x6a\xe0\x70\xa8\x29\x76\x30\x79\xc1\xae\x1d\xa3\x37\x71\xb7\x9d\xe6\x9c\xaa\xed\xec\x8c\x93\x32\x33\x63\x47\x5b\x4d\x3d\x86\x3c\x71\x63\xf2\xe8\x19\x87\xed\x0c\x35\x72\x55\x8d\x6c\xf1\x7e\xaf\x75\xe4\x6a\x74\xb9\x23\xb0\xae\xf9\xc7\xb4\x3b\x99\x54\x84\xaa\x89\xac\x04\xb3\x4d\x26\x85\x9b\xc8\x71\xa2\x24\xe4\xb6\x93\x50\x16\xd1\x73\x64\xb4\xaa\x35\xcf\xac\x5c\xac\x75\x7b\x7c\x75\x79\x42\x1e\x9a\x02\x52\x83\xa5\x5d\x89\xe7\x61\x6c\x20\x95\x77\x44\x66\x47\x26\xf5\x9a\x7f\x37\x97\xd6\x5b\x6d\xd9\xe7\x87\x83\x70\x0a\xb3\x1e\x80\x25\xb8\xba\x52\xce\x35\x44\xcb\xc4\xb9\x42\x37\x9d\x56\xba\xaa\xb6\x3d\x19\xc9\xa1\xa2\x5c\x9c\xad\xda\xb8\x25\x4b\x54\x27\x1a\x16\x25\xd4\xc1\xad\xba\x84\x25\x9b\x80\xf0\x77\x0f\x30\x2c\x71\x1c\x8c\xdd\xa3\xc7\xf2\xc1\x09\xbb\x22\x04\x19\x2f\x67\x4b\x38\x7c\xc1\x7e\x14\x63\xfb\x97\x4b\xeb\xae\x23\x8a\x99\x18\x5a\xc5\x6e\xd5\x8d\x94\x17\x25\xc9\x09\xb7\x6b\x38\x4e\x92\x80\x2c\x90\xff\x53\x4d\x95\x19\x2a\xa9\x3e\xdc\x0c\x42\xbc\x9c\xed\x73\x31\x42\xbd\xe6\xdf\xcd\x08\xf5\x56\x8f\x30\x42\xbb\x20\x9c\xc2\x08\x07\x60\xa5\x8c\xa0\x66\xad\x86\x11\xe4\x54\x06\xc4\x95\xc8\xf2\xe2\x68\x8f\x27\x8b\xd1\xd2\x5c\xec\x87\x3c\xc8\x76\x66\xcb\xc5\xfa\xc1\x14\x8c\x20\x1e\x65\xb2\xea\xc4\x43\xe0\x05\xf9\x17\x5d\x29\x9d\x03\xef\x4e\x26\x14\x7b\x8e\x8f\x18\xbb\x54\x71\xaa\xe4\x76\x26\xbc\x15\xb5\xad\x70\xcb\x53\xf9\xa1\x16\xf0\x2b\xfd\x73\x1e\xf8\x15\xb5\xad\xe0\xd7\x97\x4d\x3d\xfc\x7b\xc4\x11\x6c\x23\x9a\xfb\x21\x33\x26\xc1\x67\xa1\xbc\x9f\x5a\xd3\x36\xf8\x67\xcb\xaf\x85\x12\xb9\x20\xae\x56\x9a\x50\x35\x48\xa8\xaf\xa5\xaf\x5b\x2d\x67\x7e\x6d\xf7\x50\xd5\x0a\x2f\x51\xad\xf0\x00\xd5\xd4\x77\xa5\x56\x7a\x2e\x5e\xc2\xe7\xc7\xe5\x03\x30\xee\x92\xf0\xae\xf3\x57\x5f\xc9\x8b\x04\x93\xc7\xa0\xd2\x9c\x2b\xa3\x55\x62\x56\xc6\xc2\x98\x59\x85\x8f\x14\x7d\x86\xeb\x4b\x5c\x03\x5d\xcb\xbe\x44\x34\x07\x59\x79\x60\x9c\x10\x6b\x45\x53\x16\xf2\xfd\xa3\xa7\x1c\x9c\x81\x6e\x93\x90\xf0\x3e\xfb\xe9\xff\x63\x38\xd0\x6f\x7a\x83\x51\x6f\x38\x06\xfd\x66\x32\x1c\x4d\x46\xa3\xfe\x78\x7c\x7d\x7b\xa3\xff\x73\x30\xec\x0c\x86\xb2\x5f\x6c\xe2\x36\xcb\x5f\xeb\x57\xa3\x71\x2a\xff\xa1\x58\xa4\x87\xcf\xdc\x5c\xdf\x66\x67\x46\xd9\xa7\x1d\x3b\x44\x01\x66\xcd\x07\x6e\xf4\x51\x2a\x3e\xb6\x9b\xa6\x57\x79\xf6\xb6\xa7\x0f\x7a\xfa\x08\xf4\xe1\x64\x70\x33\xf9\xa0\xf7\x87\x83\xd1\xb5\x7e\x95\x9e\xbe\xb2\x65\xa2\x4a\xd2\x43\x1d\xf4\xc1\x64\x34\x9c\xe8\xd7\xfd\x9b\x0f\xb7\xe3\xc1\x07\x21\xfd\x57\xbf\x29\x73\x2a\x9d\x9c\x90\x3a\x55\x55\x5d\xbb\x14\x1f\x6b\x5a\x69\xf6\xdf\xf7\x4f\x39\xf0\x47\xda\x41\x1a\xcd\x6d\xfe\xb6\xae\xa8\xb7\xd3\xaa\x39\x9a\xb2\x7c\x72\xa8\xab\x2a\xe1\x42\xfe\x26\x6e\x39\xbc\x8d\x61\x54\xc0\xaa\xfc\x7c\x02\xc8\x0a\xd5\xa7\xf5\x50\x58\x48\x09\x54\x66\x3d\x1b\x86\xb5\xd2\xa4\xab\x95\x06\x58\x2d\x1f\x50\xb5\xd2\x54\xaa\x15\x66\x50\xad\x30\x48\x1e\xa9\x9b\xfa\x1b\xe5\xfc\x75\xd3\x70\x73\xd5\xd6\x4d\x99\xe0\x77\x7f\xd8\xf1\x33\x7e\x93\xc8\x66\x4b\x73\x65\x3d\x4e\xe7\x66\x2b\x5c\x95\xeb\x57\xea\x4c\x47\xc7\xe9\xfd\x7d\x41\x5f\xd9\x1e\x7c\x7d\x9c\x3f\x4c\x1f\x7f\xc0\xbf\x8d\x1f\xa2\x91\x2b\x30\x55\xa2\x56\x7e\x9f\x13\xb4\xa2\xba\x0e\x7b\x9d\xf5\xa3\x2e\x34\x76\x51\xd3\xc6\x39\x9d\x6a\xb2\x51\xe7\xdd\x41\x3c\x65\x37\xa5\x40\x8d\xbb\x95\x7e\x2c\x70\x59\x12\x92\x9f\x09\x3e\x93\x6b\xaa\xa1\x3a\x97\x2a\xb6\x61\x6d\xce\xbf\xad\x0d\xb8\xd8\xef\x34\x24\xac\x70\xc3\xc8\x05\x79\xa6\x78\xd9\xd8\xe7\xce\xd5\xde\x6e\x73\x8e\x9a\xa1\xec\xfc\x2b\x6e\xbc\xdf\xc3\xf8\xef\xf5\xaa\x55\x5f\x55\x0a\x4d\x25\xc1\x33\x3a\xd1\xa6\xd6\xea\xcc\x1f\x75\x62\xc7\x86\xd9\x05\x65\x2b\x33\xbb\x84\x3f\x37\xef\x8d\xef\xef\xf8\x2c\x90\xca\x1f\x53\x2e\xfe\x1f\x29\x45\x60\xbd\x9a\x9b\xff\x82\x0d\xa7\x18\xc3\x85\xbc\x2d\x95\x03\x05\xf4\x4d\xff\xfd\x06\x27\x0a\x62\x1f\x73\xdc\xe9\xf5\x3a\x9d\xff\x0d\x00\xf9\x49\xb2\x33\x2a\x1f\x00\x00")
 
 func latestSqlBytes() ([]byte, error) {
 	return bindataRead(
@@ -87,7 +89,7 @@ func latestSql() (*asset, error) {
 		return nil, err
 	}
 
-	info := bindataFileInfo{name: "latest.sql", size: 5393, mode: os.FileMode(420), modTime: time.Unix(1530096833, 0)}
+	info := bindataFileInfo{name: "latest.sql", size: 7978, mode: os.FileMode(420), modTime: time.Unix(1571647337, 0)}
 	a := &asset{bytes: bytes, info: info}
 	return a, nil
 }
@@ -172,6 +174,46 @@ func migrations04_table_namesSql() (*asset, error) {
 	return a, nil
 }
 
+var _migrations05_receiving_account_cursorSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xd2\xd5\x55\xd0\xce\xcd\x4c\x2f\x4a\x2c\x49\x55\x08\x2d\xe0\x72\x0e\x72\x75\x0c\x71\x55\x08\x71\x74\xf2\x71\x55\x28\x4a\x4d\x4e\xcd\x2c\xcb\xcc\x4b\x8f\x4f\x4c\x4e\xce\x2f\xcd\x2b\x89\x4f\x2e\x2d\x2a\xce\x2f\x52\xd0\xe0\x52\x50\x80\x09\x65\xa6\x28\x94\x25\x16\x25\x67\x24\x16\x69\x98\x9a\x69\x2a\xf8\xf9\x87\x28\xf8\x85\xfa\xf8\xe8\x70\x29\x28\x14\x24\xa6\x83\x34\x97\xe4\x67\xa7\xe6\xc1\x15\x19\x99\x9a\xa2\xaa\x0a\x08\xf2\xf4\x75\x0c\x8a\x54\xf0\x76\x8d\x54\xd0\x40\x98\xaa\xc9\xa5\x69\xcd\xc5\x85\xec\x3c\x97\xfc\xf2\x3c\x2e\x97\x20\xff\x00\x02\xce\xb3\xe6\x02\x0c\x00\x05\x41\xc6\x27\xd5\x00\x00\x00")
+
+func migrations05_receiving_account_cursorSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations05_receiving_account_cursorSql,
+		"migrations/05_receiving_account_cursor.sql",
+	)
+}
+
+func migrations05_receiving_account_cursorSql() (*asset, error) {
+	bytes, err := migrations05_receiving_account_cursorSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/05_receiving_account_cursor.sql", size: 213, mode: os.FileMode(420), modTime: time.Unix(1571047711, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
+var _migrations06_callbackSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x74\x91\x4f\x6b\xf2\x40\x10\xc6\xef\xfb\x29\xe6\xa8\xbc\x0a\xbe\x05\x4f\x9e\xd2\x66\x0b\xd2\x34\x4a\x88\x50\x4f\xcb\x98\x1d\xec\xd2\xcd\x1f\x66\x47\x6b\xbf\x7d\x09\x69\x82\x49\xe9\x75\xe6\xe1\xf7\x63\xe6\x59\x2e\xe1\x5f\xe9\xce\x8c\x42\x70\x68\xd4\x53\xa6\xa3\x5c\x43\x1e\x3d\x26\x1a\x0a\xf4\xfe\x84\xc5\x07\xcc\x14\x80\xb3\x70\x72\xe7\x40\xec\xd0\x2f\x14\x40\xdd\x10\xa3\xb8\xba\x32\xce\xc2\x15\xb9\x78\x47\x9e\x3d\xac\xd7\x73\x48\x77\x39\xa4\x87\x24\x69\x53\x17\xf6\x20\x74\x93\xd1\xf0\x54\xdb\xaf\xdf\xd3\x20\x28\x97\x30\xa0\xfe\xaf\xc6\x24\x14\xa1\xb2\x91\x00\xae\x12\x3a\x13\x0f\x4b\x88\xf5\x73\x74\x48\x72\x58\xb5\x31\x8f\x41\x0c\x31\xd7\xdc\x19\xfa\x65\x8f\x29\x98\x50\xc8\x1a\x14\x10\x57\x52\x10\x2c\x9b\x91\xa7\xa2\x9b\x98\x1f\xd9\xdf\x29\x4b\xde\x5d\x89\xa7\xa0\xa9\x6d\x9f\x6d\x5f\xa3\xec\x08\x2f\xfa\x08\x33\x67\xe7\x6a\xbe\x51\xfd\x8f\xb7\x69\xac\xdf\x86\x1f\x9b\xee\x7c\x33\xd5\xef\xd2\xbb\x1a\xba\xcc\x02\x26\xa1\x16\x7a\xdf\x63\x5c\x7f\x56\x2a\xce\x76\xfb\x49\x8f\x1b\xf5\x3d\x00\x85\xe4\xea\x9e\xee\x01\x00\x00")
+
+func migrations06_callbackSqlBytes() ([]byte, error) {
+	return bindataRead(
+		_migrations06_callbackSql,
+		"migrations/06_callback.sql",
+	)
+}
+
+func migrations06_callbackSql() (*asset, error) {
+	bytes, err := migrations06_callbackSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "migrations/06_callback.sql", size: 494, mode: os.FileMode(420), modTime: time.Unix(1571647337, 0)}
+	a := &asset{bytes: bytes, info: info}
+	return a, nil
+}
+
 // Asset loads and returns the asset for the given name.
 // It returns an error if the asset could not be found or
 // could not be loaded.
@@ -224,11 +266,13 @@ func AssetNames() []string {
 
 // _bindata is a table, holding each asset generator, mapped to its name.
 var _bindata = map[string]func() (*asset, error){
-	"latest.sql":                       latestSql,
-	"migrations/01_init.sql":           migrations01_initSql,
-	"migrations/02_payment_id.sql":     migrations02_payment_idSql,
-	"migrations/03_transaction_id.sql": migrations03_transaction_idSql,
-	"migrations/04_table_names.sql":    migrations04_table_namesSql,
+	"latest.sql":                                 latestSql,
+	"migrations/01_init.sql":                     migrations01_initSql,
+	"migrations/02_payment_id.sql":               migrations02_payment_idSql,
+	"migrations/03_transaction_id.sql":           migrations03_transaction_idSql,
+	"migrations/04_table_names.sql":              migrations04_table_namesSql,
+	"migrations/05_receiving_account_cursor.sql": migrations05_receiving_account_cursorSql,
+	"migrations/06_callback.sql":                 migrations06_callbackSql,
 }
 
 // AssetDir returns the file names below a certain
@@ -274,10 +318,12 @@ type bintree struct {
 var _bintree = &bintree{nil, map[string]*bintree{
 	"latest.sql": &bintree{latestSql, map[string]*bintree{}},
 	"migrations": &bintree{nil, map[string]*bintree{
-		"01_init.sql":           &bintree{migrations01_initSql, map[string]*bintree{}},
-		"02_payment_id.sql":     &bintree{migrations02_payment_idSql, map[string]*bintree{}},
-		"03_transaction_id.sql": &bintree{migrations03_transaction_idSql, map[string]*bintree{}},
-		"04_table_names.sql":    &bintree{migrations04_table_namesSql, map[string]*bintree{}},
+		"01_init.sql":                     &bintree{migrations01_initSql, map[string]*bintree{}},
+		"02_payment_id.sql":               &bintree{migrations02_payment_idSql, map[string]*bintree{}},
+		"03_transaction_id.sql":           &bintree{migrations03_transaction_idSql, map[string]*bintree{}},
+		"04_table_names.sql":              &bintree{migrations04_table_namesSql, map[string]*bintree{}},
+		"05_receiving_account_cursor.sql": &bintree{migrations05_receiving_account_cursorSql, map[string]*bintree{}},
+		"06_callback.sql":                 &bintree{migrations06_callbackSql, map[string]*bintree{}},
 	}},
 }}
 
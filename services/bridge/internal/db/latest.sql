@@ -34,6 +34,47 @@ SET default_tablespace = '';
 
 SET default_with_oids = false;
 
+--
+-- Name: callback; Type: TABLE; Schema: public; Owner: bartek
+--
+
+CREATE TABLE callback (
+    id bigint NOT NULL,
+    operation_id character varying(255) NOT NULL,
+    url text NOT NULL,
+    body text NOT NULL,
+    status character varying(10) NOT NULL,
+    attempts integer DEFAULT 0 NOT NULL,
+    last_error text,
+    created_at timestamp without time zone NOT NULL,
+    next_attempt_at timestamp without time zone NOT NULL,
+    delivered_at timestamp without time zone
+);
+
+
+ALTER TABLE callback OWNER TO bartek;
+
+--
+-- Name: callback_id_seq; Type: SEQUENCE; Schema: public; Owner: bartek
+--
+
+CREATE SEQUENCE callback_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+
+ALTER TABLE callback_id_seq OWNER TO bartek;
+
+--
+-- Name: callback_id_seq; Type: SEQUENCE OWNED BY; Schema: public; Owner: bartek
+--
+
+ALTER SEQUENCE callback_id_seq OWNED BY callback.id;
+
+
 --
 -- Name: gorp_migrations; Type: TABLE; Schema: public; Owner: bartek
 --
@@ -62,6 +103,18 @@ CREATE TABLE received_payment (
 
 ALTER TABLE received_payment OWNER TO bartek;
 
+--
+-- Name: receiving_account_cursor; Type: TABLE; Schema: public; Owner: bartek
+--
+
+CREATE TABLE receiving_account_cursor (
+    account_id character varying(56) NOT NULL,
+    paging_token character varying(255) NOT NULL
+);
+
+
+ALTER TABLE receiving_account_cursor OWNER TO bartek;
+
 --
 -- Name: receivedpayment_id_seq; Type: SEQUENCE; Schema: public; Owner: bartek
 --
@@ -124,6 +177,13 @@ ALTER TABLE senttransaction_id_seq OWNER TO bartek;
 ALTER SEQUENCE senttransaction_id_seq OWNED BY sent_transaction.id;
 
 
+--
+-- Name: callback id; Type: DEFAULT; Schema: public; Owner: bartek
+--
+
+ALTER TABLE ONLY callback ALTER COLUMN id SET DEFAULT nextval('callback_id_seq'::regclass);
+
+
 --
 -- Name: received_payment id; Type: DEFAULT; Schema: public; Owner: bartek
 --
@@ -138,6 +198,21 @@ ALTER TABLE ONLY received_payment ALTER COLUMN id SET DEFAULT nextval('receivedp
 ALTER TABLE ONLY sent_transaction ALTER COLUMN id SET DEFAULT nextval('senttransaction_id_seq'::regclass);
 
 
+--
+-- Data for Name: callback; Type: TABLE DATA; Schema: public; Owner: bartek
+--
+
+COPY callback (id, operation_id, url, body, status, attempts, last_error, created_at, next_attempt_at, delivered_at) FROM stdin;
+\.
+
+
+--
+-- Name: callback_id_seq; Type: SEQUENCE SET; Schema: public; Owner: bartek
+--
+
+SELECT pg_catalog.setval('callback_id_seq', 1, false);
+
+
 --
 -- Data for Name: gorp_migrations; Type: TABLE DATA; Schema: public; Owner: bartek
 --
@@ -147,6 +222,8 @@ COPY gorp_migrations (id, applied_at) FROM stdin;
 02_payment_id.sql	2018-04-25 18:24:44.571645+02
 03_transaction_id.sql	2018-04-25 18:24:44.578795+02
 04_table_names.sql	2018-04-25 18:24:44.5814+02
+05_receiving_account_cursor.sql	2019-10-14 12:08:31.204716+02
+06_callback.sql	2019-10-21 10:42:17.839503+02
 \.
 
 
@@ -165,6 +242,14 @@ COPY received_payment (id, operation_id, processed_at, paging_token, status, tra
 SELECT pg_catalog.setval('receivedpayment_id_seq', 1, false);
 
 
+--
+-- Data for Name: receiving_account_cursor; Type: TABLE DATA; Schema: public; Owner: bartek
+--
+
+COPY receiving_account_cursor (account_id, paging_token) FROM stdin;
+\.
+
+
 --
 -- Data for Name: sent_transaction; Type: TABLE DATA; Schema: public; Owner: bartek
 --
@@ -180,6 +265,14 @@ COPY sent_transaction (id, transaction_id, status, source, submitted_at, succeed
 SELECT pg_catalog.setval('senttransaction_id_seq', 1, false);
 
 
+--
+-- Name: callback callback_pkey; Type: CONSTRAINT; Schema: public; Owner: bartek
+--
+
+ALTER TABLE ONLY callback
+    ADD CONSTRAINT callback_pkey PRIMARY KEY (id);
+
+
 --
 -- Name: gorp_migrations gorp_migrations_pkey; Type: CONSTRAINT; Schema: public; Owner: bartek
 --
@@ -188,6 +281,14 @@ ALTER TABLE ONLY gorp_migrations
     ADD CONSTRAINT gorp_migrations_pkey PRIMARY KEY (id);
 
 
+--
+-- Name: receiving_account_cursor receiving_account_cursor_pkey; Type: CONSTRAINT; Schema: public; Owner: bartek
+--
+
+ALTER TABLE ONLY receiving_account_cursor
+    ADD CONSTRAINT receiving_account_cursor_pkey PRIMARY KEY (account_id);
+
+
 --
 -- Name: sent_transaction payment_id_unique; Type: CONSTRAINT; Schema: public; Owner: bartek
 --
@@ -220,6 +321,13 @@ ALTER TABLE ONLY sent_transaction
     ADD CONSTRAINT senttransaction_pkey PRIMARY KEY (id);
 
 
+--
+-- Name: callback_status_next_attempt_at; Type: INDEX; Schema: public; Owner: bartek
+--
+
+CREATE INDEX callback_status_next_attempt_at ON callback USING btree (status, next_attempt_at);
+
+
 --
 -- PostgreSQL database dump complete
 --
@@ -6,6 +6,7 @@ import (
 
 	migrate "github.com/rubenv/sql-migrate"
 	"github.com/diamnet/go/support/db"
+	"github.com/diamnet/go/support/errors"
 )
 
 //go:generate go-bindata -ignore .+\.go$ -pkg db -o bindata.go ./...
@@ -19,6 +20,8 @@ var Migrations migrate.MigrationSource = &migrate.AssetMigrationSource{
 
 type Database interface {
 	GetLastCursorValue() (cursor *string, err error)
+	GetReceivingAccountCursor(accountID string) (cursor *string, err error)
+	SaveReceivingAccountCursor(accountID, cursor string) error
 
 	InsertReceivedPayment(payment *ReceivedPayment) error
 	UpdateReceivedPayment(payment *ReceivedPayment) error
@@ -30,6 +33,11 @@ type Database interface {
 	UpdateSentTransaction(transaction *SentTransaction) error
 	GetSentTransactionByPaymentID(paymentID string) (*SentTransaction, error)
 	GetSentTransactions(page, limit uint64) ([]*SentTransaction, error)
+
+	InsertCallback(callback *Callback) error
+	UpdateCallback(callback *Callback) error
+	GetPendingCallbacks(now time.Time, limit uint64) ([]*Callback, error)
+	GetCallbacks(status CallbackStatus, page, limit uint64) ([]*Callback, error)
 }
 
 type PostgresDatabase struct {
@@ -71,3 +79,35 @@ type SentTransaction struct {
 	EnvelopeXdr   string                `db:"envelope_xdr" json:"envelope_xdr"`
 	ResultXdr     *string               `db:"result_xdr" json:"result_xdr"`
 }
+
+// CallbackStatus type represents callback delivery status
+type CallbackStatus string
+
+const (
+	// CallbackStatusPending is a status indicating that callback has not been delivered yet
+	CallbackStatusPending CallbackStatus = "pending"
+	// CallbackStatusDelivered is a status indicating that callback has been successfully delivered
+	CallbackStatusDelivered CallbackStatus = "delivered"
+	// CallbackStatusFailed is a status indicating that callback delivery was given up after too many attempts
+	CallbackStatusFailed CallbackStatus = "failed"
+	// CallbackStatusSuperseded is a status indicating that callback was replaced by a newer callback of the same operation
+	CallbackStatusSuperseded CallbackStatus = "superseded"
+)
+
+// ErrCallbackSuperseded is returned by UpdateCallback when the callback is no
+// longer pending because a newer callback of the same operation was inserted
+var ErrCallbackSuperseded = errors.New("callback has been superseded")
+
+// Callback represents a callback request queued for delivery by the gateway server
+type Callback struct {
+	ID            int64          `db:"id" json:"id"`
+	OperationID   string         `db:"operation_id" json:"operation_id"`
+	URL           string         `db:"url" json:"url"`
+	Body          string         `db:"body" json:"body"`
+	Status        CallbackStatus `db:"status" json:"status"` // pending/delivered/failed/superseded
+	Attempts      int32          `db:"attempts" json:"attempts"`
+	LastError     *string        `db:"last_error" json:"last_error"`
+	CreatedAt     time.Time      `db:"created_at" json:"created_at"`
+	NextAttemptAt time.Time      `db:"next_attempt_at" json:"next_attempt_at"`
+	DeliveredAt   *time.Time     `db:"delivered_at" json:"delivered_at"`
+}
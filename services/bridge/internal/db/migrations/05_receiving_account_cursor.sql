@@ -0,0 +1,9 @@
+-- +migrate Up
+CREATE TABLE receiving_account_cursor (
+  account_id varchar(56) NOT NULL,
+  paging_token varchar(255) NOT NULL,
+  PRIMARY KEY (account_id)
+);
+
+-- +migrate Down
+DROP TABLE receiving_account_cursor;
@@ -0,0 +1,19 @@
+-- +migrate Up
+CREATE TABLE callback (
+  id bigserial,
+  operation_id varchar(255) NOT NULL,
+  url text NOT NULL,
+  body text NOT NULL,
+  status varchar(10) NOT NULL,
+  attempts integer NOT NULL DEFAULT 0,
+  last_error text DEFAULT NULL,
+  created_at timestamp NOT NULL,
+  next_attempt_at timestamp NOT NULL,
+  delivered_at timestamp DEFAULT NULL,
+  PRIMARY KEY (id)
+);
+
+CREATE INDEX callback_status_next_attempt_at ON callback (status, next_attempt_at);
+
+-- +migrate Down
+DROP TABLE callback;
@@ -2,12 +2,14 @@ package db
 
 import (
 	"database/sql"
+	"time"
 
 	"github.com/diamnet/go/support/db"
 	"github.com/diamnet/go/support/errors"
 )
 
 const (
+	callbackTableName        = "callback"
 	receivedPaymentTableName = "received_payment"
 	sentTransactionTableName = "sent_transaction"
 )
@@ -123,6 +125,155 @@ func (d *PostgresDatabase) GetLastCursorValue() (cursor *string, err error) {
 	}
 }
 
+// InsertCallback inserts a new callback into DB. Pending callbacks of the same
+// operation are marked as superseded so they are not retried anymore. After
+// successful insert ID field on `callback` will updated to ID of a new row.
+func (d *PostgresDatabase) InsertCallback(callback *Callback) error {
+	err := d.session.GetRaw(
+		&callback.ID,
+		`WITH superseded AS (
+			UPDATE callback SET status = ? WHERE operation_id = ? AND status = ?
+		)
+		INSERT INTO callback (operation_id, url, body, status, attempts, last_error, created_at, next_attempt_at, delivered_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?) RETURNING id`,
+		CallbackStatusSuperseded,
+		callback.OperationID,
+		CallbackStatusPending,
+		callback.OperationID,
+		callback.URL,
+		callback.Body,
+		callback.Status,
+		callback.Attempts,
+		callback.LastError,
+		callback.CreatedAt,
+		callback.NextAttemptAt,
+		callback.DeliveredAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, "Error inserting callback")
+	}
+
+	return nil
+}
+
+// UpdateCallback updates a pending callback. ErrCallbackSuperseded is returned
+// if the callback was superseded in the meantime, in which case it's left
+// unchanged.
+func (d *PostgresDatabase) UpdateCallback(callback *Callback) error {
+	if callback.ID == 0 {
+		return errors.New("ID equals 0")
+	}
+
+	callbackTable := d.getTable(callbackTableName, nil)
+	result, err := callbackTable.Update(nil, map[string]interface{}{
+		"id":     callback.ID,
+		"status": CallbackStatusPending,
+	}).
+		SetStruct(callback, []string{"id"}).
+		Exec()
+	if err != nil {
+		return errors.Wrap(err, "Error updating callback")
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "Error getting number of updated callbacks")
+	}
+	if rows == 0 {
+		return ErrCallbackSuperseded
+	}
+
+	return nil
+}
+
+// GetPendingCallbacks returns pending callbacks which should be delivered
+// again at `now`, oldest first
+func (d *PostgresDatabase) GetPendingCallbacks(now time.Time, limit uint64) ([]*Callback, error) {
+	callbackTable := d.getTable(callbackTableName, nil)
+	callbacks := []*Callback{}
+
+	err := callbackTable.Select(&callbacks, "status = ? AND next_attempt_at <= ?", CallbackStatusPending, now).
+		Limit(limit).
+		OrderBy("id asc").
+		Exec()
+	if err != nil {
+		switch errors.Cause(err) {
+		case sql.ErrNoRows:
+			return callbacks, nil
+		default:
+			return callbacks, errors.Wrap(err, "Error getting pending callbacks")
+		}
+	}
+
+	return callbacks, nil
+}
+
+// GetCallbacks returns callbacks with a given status, or all callbacks if
+// status is empty
+func (d *PostgresDatabase) GetCallbacks(status CallbackStatus, page, limit uint64) ([]*Callback, error) {
+	callbackTable := d.getTable(callbackTableName, nil)
+	callbacks := []*Callback{}
+
+	if page == 0 {
+		page = 1
+	}
+
+	offset := (page - 1) * limit
+
+	var where interface{} = "1=1"
+	if status != "" {
+		where = map[string]interface{}{"status": status}
+	}
+
+	err := callbackTable.Select(&callbacks, where).Limit(limit).Offset(offset).OrderBy("id desc").Exec()
+	if err != nil {
+		switch errors.Cause(err) {
+		case sql.ErrNoRows:
+			return callbacks, nil
+		default:
+			return callbacks, errors.Wrap(err, "Error getting callbacks")
+		}
+	}
+
+	return callbacks, nil
+}
+
+// GetReceivingAccountCursor returns the cursor of the last payment streamed
+// for a receiving account
+func (d *PostgresDatabase) GetReceivingAccountCursor(accountID string) (*string, error) {
+	var cursor string
+	err := d.session.GetRaw(
+		&cursor,
+		"SELECT paging_token FROM receiving_account_cursor WHERE account_id = ?",
+		accountID,
+	)
+	if err != nil {
+		switch errors.Cause(err) {
+		case sql.ErrNoRows:
+			return nil, nil
+		default:
+			return nil, errors.Wrap(err, "Error getting receiving account cursor")
+		}
+	}
+
+	return &cursor, nil
+}
+
+// SaveReceivingAccountCursor stores the cursor of the last payment streamed
+// for a receiving account
+func (d *PostgresDatabase) SaveReceivingAccountCursor(accountID, cursor string) error {
+	_, err := d.session.ExecRaw(
+		`INSERT INTO receiving_account_cursor (account_id, paging_token) VALUES (?, ?)
+		ON CONFLICT (account_id) DO UPDATE SET paging_token = EXCLUDED.paging_token`,
+		accountID, cursor,
+	)
+	if err != nil {
+		return errors.Wrap(err, "Error saving receiving account cursor")
+	}
+
+	return nil
+}
+
 // GetSentTransactionByPaymentID returns sent transaction searching by payment ID
 func (d *PostgresDatabase) GetSentTransactionByPaymentID(paymentID string) (*SentTransaction, error) {
 	sentTransactionTable := d.getTable(sentTransactionTableName, nil)
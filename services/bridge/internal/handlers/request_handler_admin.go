@@ -152,3 +152,32 @@ func (rh *RequestHandler) AdminSentTransactions(w http.ResponseWriter, r *http.R
 		return
 	}
 }
+
+// AdminCallbacks implements /admin/callbacks endpoint
+func (rh *RequestHandler) AdminCallbacks(w http.ResponseWriter, r *http.Request) {
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	limit := 10
+
+	status := db.CallbackStatus(r.URL.Query().Get("status"))
+	switch status {
+	case "", db.CallbackStatusPending, db.CallbackStatusDelivered, db.CallbackStatusFailed, db.CallbackStatusSuperseded:
+	default:
+		helpers.Write(w, helpers.NewInvalidParameterError("status", "Status must be one of: pending, delivered, failed, superseded."))
+		return
+	}
+
+	callbacks, err := rh.Database.GetCallbacks(status, uint64(page), uint64(limit))
+	if err != nil {
+		log.WithFields(log.Fields{"err": err}).Error("Error loading Callbacks")
+		helpers.Write(w, helpers.InternalServerError)
+		return
+	}
+
+	encoder := json.NewEncoder(w)
+	err = encoder.Encode(callbacks)
+	if err != nil {
+		log.WithFields(log.Fields{"err": err, "callbacks": callbacks}).Error("Error encoding Callbacks")
+		helpers.Write(w, helpers.InternalServerError)
+		return
+	}
+}
@@ -0,0 +1,222 @@
+package listener
+
+import (
+	"io/ioutil"
+	"net/url"
+	"time"
+
+	"github.com/diamnet/go/services/bridge/internal/db"
+	"github.com/diamnet/go/support/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// callbackMinBackoff is the delay before the first retry of a callback,
+	// doubled after every failed attempt up to callbackMaxBackoff.
+	callbackMinBackoff = 10 * time.Second
+	callbackMaxBackoff = time.Hour
+	// callbackMaxAttempts is the number of attempts after which a callback is
+	// marked as failed. With the backoff above, callbacks are retried for
+	// about two days.
+	callbackMaxAttempts = 50
+
+	// callbackReservation is how long the first attempt to deliver a callback
+	// is reserved for, so it isn't retried concurrently. It covers the timeout
+	// of the HTTP client.
+	callbackReservation = callbackTimeout + callbackMinBackoff
+
+	callbackRetryInterval = 10 * time.Second
+	callbackRetryBatch    = 100
+)
+
+// callbackBackoff returns the delay before the next attempt to deliver a
+// callback which failed `attempts` times
+func callbackBackoff(attempts int32) time.Duration {
+	backoff := callbackMinBackoff
+	for i := int32(1); i < attempts; i++ {
+		backoff *= 2
+		if backoff >= callbackMaxBackoff {
+			return callbackMaxBackoff
+		}
+	}
+	return backoff
+}
+
+// deliverCallback stores a callback in the outbox and tries to deliver it.
+// It supersedes the pending callbacks of the same operation, when a payment is
+// reprocessed. When delivery fails the callback is retried in the background
+// by RetryCallbacks.
+func (pl *PaymentListener) deliverCallback(operationID, callbackURL string, form url.Values) error {
+	now := pl.now()
+	callback := &db.Callback{
+		OperationID:   operationID,
+		URL:           callbackURL,
+		Body:          form.Encode(),
+		Status:        db.CallbackStatusPending,
+		CreatedAt:     now,
+		NextAttemptAt: now.Add(callbackReservation),
+	}
+
+	err := pl.database.InsertCallback(callback)
+	if err != nil {
+		return errors.Wrap(err, "Error inserting callback")
+	}
+
+	err = pl.attemptCallback(callback)
+	if err != nil && callback.Status == db.CallbackStatusPending {
+		return callbackPendingError{err}
+	}
+	return err
+}
+
+// callbackPendingError is the error of a failed attempt to deliver a callback
+// which will be retried.
+type callbackPendingError struct {
+	error
+}
+
+// isCallbackPending returns true if err is the error of a callback which will
+// be retried. The error callback is only sent when the receive callback
+// failed for good.
+func isCallbackPending(err error) bool {
+	_, ok := err.(callbackPendingError)
+	return ok
+}
+
+// attemptCallback sends a callback and saves the result of the attempt
+func (pl *PaymentListener) attemptCallback(callback *db.Callback) error {
+	callback.Attempts++
+	err := pl.postCallback(callback)
+
+	now := pl.now()
+	if err == nil {
+		callback.Status = db.CallbackStatusDelivered
+		callback.DeliveredAt = &now
+		callback.LastError = nil
+	} else {
+		lastError := err.Error()
+		callback.LastError = &lastError
+		if callback.Attempts >= callbackMaxAttempts {
+			callback.Status = db.CallbackStatusFailed
+		} else {
+			callback.NextAttemptAt = now.Add(callbackBackoff(callback.Attempts))
+		}
+	}
+
+	dbErr := pl.database.UpdateCallback(callback)
+	if dbErr == db.ErrCallbackSuperseded {
+		pl.log.WithFields(logrus.Fields{"id": callback.ID}).Info("Callback superseded while it was sent")
+		callback.Status = db.CallbackStatusSuperseded
+	} else if dbErr != nil {
+		pl.log.WithFields(logrus.Fields{"id": callback.ID, "err": dbErr}).Error("Error updating callback")
+	}
+
+	return err
+}
+
+func (pl *PaymentListener) postCallback(callback *db.Callback) error {
+	form, err := url.ParseQuery(callback.Body)
+	if err != nil {
+		return errors.Wrap(err, "Error parsing callback body")
+	}
+
+	resp, err := pl.postForm(callback.URL, form)
+	if err != nil {
+		return errors.Wrap(err, "Error sending request to receive callback")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return errors.Wrap(err, "Error reading receive callback response")
+		}
+
+		pl.log.WithFields(logrus.Fields{
+			"status": resp.StatusCode,
+			"body":   string(body),
+		}).Error("Error response from receive callback")
+		return errors.New("Error response from receive callback")
+	}
+
+	return nil
+}
+
+// RetryCallbacks retries the delivery of pending callbacks until the process
+// exits
+func (pl *PaymentListener) RetryCallbacks() {
+	for {
+		err := pl.retryPendingCallbacks()
+		if err != nil {
+			pl.log.WithFields(logrus.Fields{"err": err}).Error("Error retrying callbacks")
+		}
+		time.Sleep(callbackRetryInterval)
+	}
+}
+
+// retryPendingCallbacks retries the delivery of the pending callbacks which
+// are due and updates the status of their payments
+func (pl *PaymentListener) retryPendingCallbacks() error {
+	callbacks, err := pl.database.GetPendingCallbacks(pl.now(), callbackRetryBatch)
+	if err != nil {
+		return err
+	}
+
+	for _, callback := range callbacks {
+		pl.log.WithFields(logrus.Fields{
+			"id":          callback.ID,
+			"operationId": callback.OperationID,
+			"attempts":    callback.Attempts,
+		}).Info("Retrying callback")
+
+		callbackErr := pl.attemptCallback(callback)
+		if callback.Status == db.CallbackStatusSuperseded {
+			// the payment was reprocessed, its status is set by the newer callback
+			continue
+		}
+
+		payment, err := pl.database.GetReceivedPaymentByOperationID(callback.OperationID)
+		if err != nil {
+			return err
+		}
+		if payment == nil {
+			continue
+		}
+
+		if callbackErr != nil {
+			payment.Status = callbackErr.Error()
+			if callback.Status == db.CallbackStatusFailed {
+				pl.notifyCallbackFailed(callback, callbackErr)
+			}
+		} else {
+			pl.log.Info("Payment successfully processed")
+			payment.Status = "Success"
+		}
+		payment.ProcessedAt = pl.now()
+
+		err = pl.database.UpdateReceivedPayment(payment)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// notifyCallbackFailed sends the error callback of the receiving account of a
+// payment when its receive callback failed too many times
+func (pl *PaymentListener) notifyCallbackFailed(callback *db.Callback, callbackErr error) {
+	form, err := url.ParseQuery(callback.Body)
+	if err != nil {
+		pl.log.WithFields(logrus.Fields{"id": callback.ID, "err": err}).Error("Error parsing callback body")
+		return
+	}
+
+	account, ok := pl.receivingAccount(form.Get("to"))
+	if !ok {
+		pl.log.WithFields(logrus.Fields{"id": callback.ID}).Error("Callback was not sent for a receiving account")
+		return
+	}
+
+	pl.notifyError(account, form, callbackErr)
+}
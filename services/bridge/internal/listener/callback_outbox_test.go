@@ -0,0 +1,218 @@
+package listener
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/diamnet/go/services/bridge/internal/db"
+	"github.com/diamnet/go/services/bridge/internal/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCallbackBackoff(t *testing.T) {
+	assert.Equal(t, 10*time.Second, callbackBackoff(1))
+	assert.Equal(t, 20*time.Second, callbackBackoff(2))
+	assert.Equal(t, 80*time.Second, callbackBackoff(4))
+	assert.Equal(t, time.Hour, callbackBackoff(10))
+	assert.Equal(t, time.Hour, callbackBackoff(callbackMaxAttempts))
+}
+
+func TestDeliverCallback(t *testing.T) {
+	database := new(mocks.MockDatabase)
+	httpClient := new(mocks.MockHTTPClient)
+	paymentListener, err := NewPaymentListener(resetConfig(), database, nil, mocks.Now)
+	require.NoError(t, err)
+	paymentListener.client = httpClient
+	mocks.PredefinedTime = time.Now()
+
+	// when callback fails it should be retried later
+	var callback *db.Callback
+	database.On("InsertCallback", mock.AnythingOfType("*db.Callback")).Run(func(args mock.Arguments) {
+		callback = args.Get(0).(*db.Callback)
+		assert.Equal(t, "1", callback.OperationID)
+		assert.Equal(t, "http://receive_callback", callback.URL)
+		assert.Equal(t, "amount=100&id=1", callback.Body)
+		assert.Equal(t, db.CallbackStatusPending, callback.Status)
+		// the first attempt is reserved until the request times out
+		assert.True(t, callback.NextAttemptAt.After(mocks.PredefinedTime.Add(callbackTimeout)))
+		callback.ID = 5
+	}).Return(nil).Once()
+	database.On("UpdateCallback", mock.AnythingOfType("*db.Callback")).Return(nil).Once()
+	httpClient.On("Do", mock.AnythingOfType("*http.Request")).Return(
+		mocks.BuildHTTPResponse(503, "maintenance"),
+		nil,
+	).Once()
+
+	err = paymentListener.deliverCallback(
+		"1",
+		"http://receive_callback",
+		url.Values{"id": {"1"}, "amount": {"100"}},
+	)
+	assert.EqualError(t, err, "Error response from receive callback")
+	database.AssertExpectations(t)
+	httpClient.AssertExpectations(t)
+	assert.Equal(t, db.CallbackStatusPending, callback.Status)
+	assert.Equal(t, int32(1), callback.Attempts)
+	assert.Equal(t, mocks.PredefinedTime.Add(callbackMinBackoff), callback.NextAttemptAt)
+	if assert.NotNil(t, callback.LastError) {
+		assert.Equal(t, "Error response from receive callback", *callback.LastError)
+	}
+
+	// when callback fails too many times it should be marked as failed
+	callback.Attempts = callbackMaxAttempts - 1
+	database.On("UpdateCallback", callback).Return(nil).Once()
+	httpClient.On("Do", mock.AnythingOfType("*http.Request")).Return(
+		mocks.BuildHTTPResponse(503, "maintenance"),
+		nil,
+	).Once()
+
+	err = paymentListener.attemptCallback(callback)
+	assert.Error(t, err)
+	database.AssertExpectations(t)
+	assert.Equal(t, db.CallbackStatusFailed, callback.Status)
+}
+
+func TestRetryPendingCallbacks(t *testing.T) {
+	database := new(mocks.MockDatabase)
+	httpClient := new(mocks.MockHTTPClient)
+	cfg := resetConfig()
+	cfg.MACKey = "SABLR5HOI2IUOYB27TR4TO7HWDJIGSRJTT4UUTXXZOFVVPGQKJ5ME43J"
+	paymentListener, err := NewPaymentListener(cfg, database, nil, mocks.Now)
+	require.NoError(t, err)
+	paymentListener.client = httpClient
+	mocks.PredefinedTime = time.Now()
+
+	lastError := "Error response from receive callback"
+	callback := &db.Callback{
+		ID:          5,
+		OperationID: "1",
+		URL:         "http://receive_callback",
+		Body:        "amount=100&id=1",
+		Status:      db.CallbackStatusPending,
+		Attempts:    3,
+		LastError:   &lastError,
+	}
+	payment := &db.ReceivedPayment{ID: 1, OperationID: "1", Status: lastError}
+
+	database.On("GetPendingCallbacks", mocks.PredefinedTime, uint64(callbackRetryBatch)).
+		Return([]*db.Callback{callback}, nil).Once()
+	database.On("UpdateCallback", callback).Return(nil).Once()
+	database.On("GetReceivedPaymentByOperationID", "1").Return(payment, nil).Once()
+	database.On("UpdateReceivedPayment", payment).Return(nil).Once()
+	httpClient.On(
+		"Do",
+		mock.MatchedBy(func(req *http.Request) bool {
+			return req.URL.String() == "http://receive_callback" && req.Header.Get("X-Payload-Mac") != ""
+		}),
+	).Return(
+		mocks.BuildHTTPResponse(200, "ok"),
+		nil,
+	).Run(func(args mock.Arguments) {
+		req := args.Get(0).(*http.Request)
+		assert.Equal(t, "1", req.PostFormValue("id"))
+		assert.Equal(t, "100", req.PostFormValue("amount"))
+	}).Once()
+
+	require.NoError(t, paymentListener.retryPendingCallbacks())
+	database.AssertExpectations(t)
+	httpClient.AssertExpectations(t)
+	assert.Equal(t, db.CallbackStatusDelivered, callback.Status)
+	assert.Equal(t, int32(4), callback.Attempts)
+	assert.Nil(t, callback.LastError)
+	assert.Equal(t, "Success", payment.Status)
+}
+
+func TestRetrySupersededCallback(t *testing.T) {
+	database := new(mocks.MockDatabase)
+	httpClient := new(mocks.MockHTTPClient)
+	paymentListener, err := NewPaymentListener(resetConfig(), database, nil, mocks.Now)
+	require.NoError(t, err)
+	paymentListener.client = httpClient
+	mocks.PredefinedTime = time.Now()
+
+	callback := &db.Callback{
+		ID:          5,
+		OperationID: "1",
+		URL:         "http://receive_callback",
+		Body:        "amount=100&id=1",
+		Status:      db.CallbackStatusPending,
+		Attempts:    3,
+	}
+
+	// when the payment was reprocessed during the attempt it should not
+	// update the status of the payment
+	database.On("GetPendingCallbacks", mocks.PredefinedTime, uint64(callbackRetryBatch)).
+		Return([]*db.Callback{callback}, nil).Once()
+	database.On("UpdateCallback", callback).Return(db.ErrCallbackSuperseded).Once()
+	httpClient.On("Do", mock.AnythingOfType("*http.Request")).Return(
+		mocks.BuildHTTPResponse(200, "ok"),
+		nil,
+	).Once()
+
+	require.NoError(t, paymentListener.retryPendingCallbacks())
+	database.AssertExpectations(t)
+	database.AssertNotCalled(t, "GetReceivedPaymentByOperationID", "1")
+	httpClient.AssertExpectations(t)
+	assert.Equal(t, db.CallbackStatusSuperseded, callback.Status)
+}
+
+func TestRetryFailedCallback(t *testing.T) {
+	database := new(mocks.MockDatabase)
+	httpClient := new(mocks.MockHTTPClient)
+	cfg := resetConfig()
+	cfg.Callbacks.Error = "http://error_callback"
+	paymentListener, err := NewPaymentListener(cfg, database, nil, mocks.Now)
+	require.NoError(t, err)
+	paymentListener.client = httpClient
+	mocks.PredefinedTime = time.Now()
+
+	callback := &db.Callback{
+		ID:          5,
+		OperationID: "1",
+		URL:         "http://receive_callback",
+		Body:        "amount=100&id=1&to=GATKP6ZQM5CSLECPMTAC5226PE367QALCPM6AFHTSULPPZMT62OOPMQB",
+		Status:      db.CallbackStatusPending,
+		Attempts:    callbackMaxAttempts - 1,
+	}
+	payment := &db.ReceivedPayment{ID: 1, OperationID: "1", Status: "Error response from receive callback"}
+
+	// when the last attempt fails it should call the error callback
+	database.On("GetPendingCallbacks", mocks.PredefinedTime, uint64(callbackRetryBatch)).
+		Return([]*db.Callback{callback}, nil).Once()
+	database.On("UpdateCallback", callback).Return(nil).Once()
+	database.On("GetReceivedPaymentByOperationID", "1").Return(payment, nil).Once()
+	database.On("UpdateReceivedPayment", payment).Return(nil).Once()
+	httpClient.On(
+		"Do",
+		mock.MatchedBy(func(req *http.Request) bool {
+			return req.URL.String() == "http://receive_callback"
+		}),
+	).Return(
+		mocks.BuildHTTPResponse(503, "maintenance"),
+		nil,
+	).Once()
+	httpClient.On(
+		"Do",
+		mock.MatchedBy(func(req *http.Request) bool {
+			return req.URL.String() == "http://error_callback"
+		}),
+	).Run(func(args mock.Arguments) {
+		req := args.Get(0).(*http.Request)
+		require.NoError(t, req.ParseForm())
+		assert.Equal(t, "1", req.PostForm.Get("id"))
+		assert.Equal(t, "100", req.PostForm.Get("amount"))
+		assert.Equal(t, "Error response from receive callback", req.PostForm.Get("error"))
+	}).Return(
+		mocks.BuildHTTPResponse(200, "ok"),
+		nil,
+	).Once()
+
+	require.NoError(t, paymentListener.retryPendingCallbacks())
+	database.AssertExpectations(t)
+	httpClient.AssertExpectations(t)
+	assert.Equal(t, db.CallbackStatusFailed, callback.Status)
+}
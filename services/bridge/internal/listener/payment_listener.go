@@ -20,13 +20,14 @@ import (
 	"github.com/diamnet/go/protocols/aurora/operations"
 	"github.com/diamnet/go/services/bridge/internal/config"
 	"github.com/diamnet/go/services/bridge/internal/db"
+	"github.com/diamnet/go/services/internal/bridge-compliance-shared/protocols"
 	"github.com/diamnet/go/services/internal/bridge-compliance-shared/protocols/bridge"
 	callback "github.com/diamnet/go/services/internal/bridge-compliance-shared/protocols/compliance"
 	"github.com/diamnet/go/strkey"
 	"github.com/diamnet/go/support/errors"
 )
 
-// PaymentListener is listening for a new payments received by ReceivingAccount
+// PaymentListener is listening for a new payments received by the receiving accounts
 type PaymentListener struct {
 	client   HTTP
 	config   *config.Config
@@ -64,47 +65,106 @@ func NewPaymentListener(
 	return
 }
 
-// Listen starts listening for new payments
+// Listen starts listening for new payments to all the receiving accounts
 func (pl *PaymentListener) Listen() (err error) {
-	accountID := pl.config.Accounts.ReceivingAccountID
-	accountRequest := hc.AccountRequest{AccountID: accountID}
-	_, err = pl.aurora.AccountDetail(accountRequest)
-	if err != nil {
-		return
+	accounts := pl.config.GetReceivingAccounts()
+	for _, account := range accounts {
+		accountRequest := hc.AccountRequest{AccountID: account.AccountID}
+		_, err = pl.aurora.AccountDetail(accountRequest)
+		if err != nil {
+			return
+		}
 	}
 
-	go func() {
-		for {
-			cursorValue, err := pl.database.GetLastCursorValue()
-			if err != nil {
-				pl.log.WithFields(logrus.Fields{"error": err}).Error("Could not load last cursor from the DB")
+	for _, account := range accounts {
+		go pl.listen(account.AccountID)
+	}
+	go pl.RetryCallbacks()
+
+	return
+}
+
+// listen streams the payments of a single receiving account, starting from
+// the cursor saved for it
+func (pl *PaymentListener) listen(accountID string) {
+	for {
+		cursorValue, err := pl.getCursor(accountID)
+		if err != nil {
+			pl.log.WithFields(logrus.Fields{"accountId": accountID, "error": err}).Error("Could not load last cursor from the DB")
+			return
+		}
+
+		var cursor string
+		if cursorValue != nil {
+			cursor = *cursorValue
+		} else {
+			// If no last cursor saved set it to: `now`
+			cursor = "now"
+		}
+
+		pl.log.WithFields(logrus.Fields{
+			"accountId": accountID,
+			"cursor":    cursor,
+		}).Info("Started listening for new payments")
+
+		// When a payment can't be saved the stream is stopped without saving
+		// the cursor, so the payment is streamed again when it's restarted.
+		ctx, cancel := context.WithCancel(context.Background())
+		stopped := false
+		paymentRequest := hc.OperationRequest{ForAccount: accountID, Cursor: cursor}
+		err = pl.aurora.StreamPayments(ctx, paymentRequest, func(payment operations.Operation) {
+			if stopped {
 				return
 			}
 
-			var cursor string
-			if cursorValue != nil {
-				cursor = *cursorValue
-			} else {
-				// If no last cursor saved set it to: `now`
-				cursor = "now"
+			err := pl.onPayment(payment)
+			if err != nil {
+				pl.log.WithFields(logrus.Fields{"id": payment.GetID(), "err": err}).Error("Error saving payment")
+				stopped = true
+				cancel()
+				return
 			}
 
-			pl.log.WithFields(logrus.Fields{
-				"accountId": accountID,
-				"cursor":    cursor,
-			}).Info("Started listening for new payments")
-
-			paymentRequest := hc.OperationRequest{ForAccount: accountID, Cursor: cursor}
-			err = pl.aurora.StreamPayments(context.Background(), paymentRequest, pl.onPayment)
+			err = pl.database.SaveReceivingAccountCursor(accountID, payment.PagingToken())
 			if err != nil {
-				pl.log.Error("Error while streaming: ", err)
-				pl.log.Info("Sleeping...")
-				time.Sleep(10 * time.Second)
+				pl.log.WithFields(logrus.Fields{"accountId": accountID, "err": err}).Error("Error saving cursor")
 			}
+		})
+		cancel()
+		if err == nil && stopped {
+			err = errors.New("payment could not be saved")
+		}
+		if err != nil {
+			pl.log.Error("Error while streaming: ", err)
+			pl.log.Info("Sleeping...")
+			time.Sleep(10 * time.Second)
 		}
-	}()
+	}
+}
 
-	return
+// getCursor returns the cursor saved for a receiving account. Before cursors
+// were saved per account, the cursor of `accounts.receiving_account_id` was
+// the paging token of the last received payment.
+func (pl *PaymentListener) getCursor(accountID string) (*string, error) {
+	cursor, err := pl.database.GetReceivingAccountCursor(accountID)
+	if err != nil || cursor != nil {
+		return cursor, err
+	}
+
+	if accountID == pl.config.Accounts.ReceivingAccountID {
+		return pl.database.GetLastCursorValue()
+	}
+	return nil, nil
+}
+
+// receivingAccount returns the config of a receiving account
+func (pl *PaymentListener) receivingAccount(accountID string) (config.ReceivingAccount, bool) {
+	for _, account := range pl.config.GetReceivingAccounts() {
+		if account.AccountID == accountID {
+			return account, true
+		}
+	}
+	return config.ReceivingAccount{}, false
 }
 
 func (pl *PaymentListener) ReprocessPayment(payment bridge.PaymentResponse, force bool) error {
@@ -126,6 +186,12 @@ func (pl *PaymentListener) ReprocessPayment(payment bridge.PaymentResponse, forc
 		return errors.New("Trying to reprocess successful transaction without force")
 	}
 
+	account, ok := pl.receivingAccount(payment.To)
+	if !ok {
+		pl.log.WithFields(logrus.Fields{"id": payment.ID}).Info("Payment was not sent to a receiving account")
+		return errors.New("Payment was not sent to a receiving account")
+	}
+
 	existingPayment.Status = "Reprocessing..."
 	existingPayment.ProcessedAt = pl.now()
 
@@ -134,11 +200,14 @@ func (pl *PaymentListener) ReprocessPayment(payment bridge.PaymentResponse, forc
 		return err
 	}
 
-	err = pl.process(payment)
+	err = pl.process(account, payment)
 
 	if err != nil {
 		pl.log.WithFields(logrus.Fields{"err": err}).Error("Payment reprocessed with errors")
 		existingPayment.Status = err.Error()
+		if !isCallbackPending(err) {
+			pl.notifyError(account, paymentForm(payment), err)
+		}
 	} else {
 		pl.log.Info("Payment successfully reprocessed")
 		existingPayment.Status = "Success"
@@ -147,18 +216,20 @@ func (pl *PaymentListener) ReprocessPayment(payment bridge.PaymentResponse, forc
 	return pl.database.UpdateReceivedPayment(existingPayment)
 }
 
-func (pl *PaymentListener) onPayment(payment operations.Operation) {
+// onPayment saves and processes a received payment. It returns an error when
+// the payment could not be saved, in which case it must be received again.
+// Errors processing the payment are saved in its status.
+func (pl *PaymentListener) onPayment(payment operations.Operation) error {
 	pl.log.WithFields(logrus.Fields{"id": payment.GetID()}).Info("New received payment")
 
 	existingPayment, err := pl.database.GetReceivedPaymentByOperationID(payment.GetID())
 	if err != nil {
-		pl.log.WithFields(logrus.Fields{"err": err}).Error("Error checking if receive payment exists")
-		return
+		return errors.Wrap(err, "Error checking if receive payment exists")
 	}
 
 	if existingPayment != nil {
 		pl.log.WithFields(logrus.Fields{"id": payment.GetID()}).Info("Payment already exists")
-		return
+		return nil
 	}
 
 	dbPayment := &db.ReceivedPayment{
@@ -171,7 +242,7 @@ func (pl *PaymentListener) onPayment(payment operations.Operation) {
 
 	err = pl.database.InsertReceivedPayment(dbPayment)
 	if err != nil {
-		return
+		return errors.Wrap(err, "Error inserting received payment")
 	}
 
 	bPayment, err := pl.ConvertToBridgePayment(payment)
@@ -181,21 +252,23 @@ func (pl *PaymentListener) onPayment(payment operations.Operation) {
 		err = pl.database.UpdateReceivedPayment(dbPayment)
 		if err != nil {
 			pl.log.WithFields(logrus.Fields{"err": err}).Error("Error updating payment")
-			return
 		}
-		return
+		return nil
 	}
 
-	process, status := pl.shouldProcessPayment(bPayment)
-	if !process {
+	account, status := pl.shouldProcessPayment(bPayment)
+	if account == nil {
 		dbPayment.Status = status
 		pl.log.Info(status)
 	} else {
-		err = pl.process(bPayment)
+		err = pl.process(*account, bPayment)
 
 		if err != nil {
 			pl.log.WithFields(logrus.Fields{"err": err}).Error("Payment processed with errors")
 			dbPayment.Status = err.Error()
+			if !isCallbackPending(err) {
+				pl.notifyError(*account, paymentForm(bPayment), err)
+			}
 		} else {
 			pl.log.Info("Payment successfully processed")
 			dbPayment.Status = "Success"
@@ -205,29 +278,31 @@ func (pl *PaymentListener) onPayment(payment operations.Operation) {
 	err = pl.database.UpdateReceivedPayment(dbPayment)
 	if err != nil {
 		pl.log.WithFields(logrus.Fields{"err": err}).Error("Error updating payment")
-		return
 	}
+	return nil
 }
 
-// shouldProcessPayment returns false and text status if payment should not be processed
-// (ex. asset is different than allowed assets).
-func (pl *PaymentListener) shouldProcessPayment(payment bridge.PaymentResponse) (bool, string) {
+// shouldProcessPayment returns the receiving account of the payment, or nil
+// and text status if payment should not be processed (ex. asset is different
+// than allowed assets).
+func (pl *PaymentListener) shouldProcessPayment(payment bridge.PaymentResponse) (*config.ReceivingAccount, string) {
 	if payment.Type != "payment" && payment.Type != "path_payment" && payment.Type != "account_merge" {
-		return false, "Not a payment operation"
+		return nil, "Not a payment operation"
 	}
 
-	if payment.To != pl.config.Accounts.ReceivingAccountID {
-		return false, "Operation type not permitted"
+	account, ok := pl.receivingAccount(payment.To)
+	if !ok {
+		return nil, "Operation type not permitted"
 	}
 
-	if !pl.isAssetAllowed(payment.AssetType, payment.AssetCode, payment.AssetIssuer) {
-		return false, "Asset not allowed"
+	if !isAssetAllowed(account.Assets, payment.AssetType, payment.AssetCode, payment.AssetIssuer) {
+		return nil, "Asset not allowed"
 	}
 
-	return true, ""
+	return &account, ""
 }
 
-func (pl *PaymentListener) process(payment bridge.PaymentResponse) error {
+func (pl *PaymentListener) process(account config.ReceivingAccount, payment bridge.PaymentResponse) error {
 	pl.log.WithFields(logrus.Fields{"memo": payment.Memo, "type": payment.MemoType}).Info("Loaded memo")
 
 	var receiveResponse callback.ReceiveResponse
@@ -282,44 +357,56 @@ func (pl *PaymentListener) process(payment bridge.PaymentResponse) error {
 		route = payment.Memo
 	}
 
-	resp, err := pl.postForm(
-		pl.config.Callbacks.Receive,
-		url.Values{
-			"id":             {payment.ID},
-			"from":           {payment.From},
-			"route":          {route},
-			"amount":         {payment.Amount},
-			"asset_code":     {payment.AssetCode},
-			"asset_issuer":   {payment.AssetIssuer},
-			"memo_type":      {payment.MemoType},
-			"memo":           {payment.Memo},
-			"data":           {receiveResponse.Data},
-			"transaction_id": {payment.TransactionHash},
-		},
-	)
-	if err != nil {
-		return errors.Wrap(err, "Error sending request to receive callback")
+	form := paymentForm(payment)
+	form.Set("route", route)
+	form.Set("data", receiveResponse.Data)
+	return pl.deliverCallback(payment.ID, account.Callbacks.Receive, form)
+}
+
+// paymentForm returns the fields of a payment sent to the callbacks
+func paymentForm(payment bridge.PaymentResponse) url.Values {
+	return url.Values{
+		"id":             {payment.ID},
+		"from":           {payment.From},
+		"to":             {payment.To},
+		"amount":         {payment.Amount},
+		"asset_code":     {payment.AssetCode},
+		"asset_issuer":   {payment.AssetIssuer},
+		"memo_type":      {payment.MemoType},
+		"memo":           {payment.Memo},
+		"transaction_id": {payment.TransactionHash},
 	}
+}
 
-	if resp.StatusCode != 200 {
-		defer resp.Body.Close()
-		body, err := ioutil.ReadAll(resp.Body)
-		if err != nil {
-			return errors.Wrap(err, "Error reading receive callback response")
-		}
+// notifyError sends the error callback of the receiving account, if any, when
+// processing a payment failed and won't be retried. form contains the fields
+// of the payment. Failures of the error callback are only logged.
+func (pl *PaymentListener) notifyError(account config.ReceivingAccount, form url.Values, processErr error) {
+	if account.Callbacks.Error == "" {
+		return
+	}
 
-		pl.log.WithFields(logrus.Fields{
-			"status": resp.StatusCode,
-			"body":   string(body),
-		}).Error("Error response from receive callback")
-		return errors.New("Error response from receive callback")
+	errorForm := url.Values{}
+	for key, values := range form {
+		errorForm[key] = values
 	}
+	errorForm.Set("error", processErr.Error())
 
-	return nil
+	id := form.Get("id")
+	resp, err := pl.postForm(account.Callbacks.Error, errorForm)
+	if err != nil {
+		pl.log.WithFields(logrus.Fields{"id": id, "err": err}).Error("Error sending request to error callback")
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		pl.log.WithFields(logrus.Fields{"id": id, "status": resp.StatusCode}).Error("Error response from error callback")
+	}
 }
 
-func (pl *PaymentListener) isAssetAllowed(assetType string, code string, issuer string) bool {
-	for _, asset := range pl.config.Assets {
+func isAssetAllowed(assets []protocols.Asset, assetType string, code string, issuer string) bool {
+	for _, asset := range assets {
 		if asset.Code == code && asset.Issuer == issuer {
 			return true
 		}
@@ -234,6 +234,10 @@ func TestPaymentListener(t *testing.T) {
 	plconfig.Assets[1].Code = "XLM"
 	plconfig.Assets[1].Issuer = ""
 
+	// receive callbacks are stored in the outbox
+	mockDatabase.On("InsertCallback", mock.AnythingOfType("*db.Callback")).Return(nil)
+	mockDatabase.On("UpdateCallback", mock.AnythingOfType("*db.Callback")).Return(nil)
+
 	mockDatabase.On("InsertReceivedPayment", mock.AnythingOfType("*db.ReceivedPayment")).
 		Run(ensurePaymentStatus(t, paymentOp, "Processing...")).Return(nil).Once()
 
@@ -516,6 +520,181 @@ func TestPaymentListener(t *testing.T) {
 	mockAurora.AssertExpectations(t)
 }
 
+func TestPaymentListenerReceivingAccounts(t *testing.T) {
+	cfg := resetConfig()
+	cfg.ReceivingAccounts = []config.ReceivingAccount{
+		{
+			AccountID: "GBL27BKG2JSDU6KQ5YJKCDWTVIU24VTG4PLB63SF4K2DBZS5XZMWRPVU",
+			Assets: []protocols.Asset{
+				{Code: "GBP", Issuer: "GD4I7AFSLZGTDL34TQLWJOM2NHLIIOEKD5RHHZUW54HERBLSIRKUOXRR"},
+			},
+			Callbacks: config.Callbacks{
+				Receive: "http://gbp_receive_callback",
+				Error:   "http://gbp_error_callback",
+			},
+		},
+	}
+
+	database := new(mocks.MockDatabase)
+	aurora := new(hc.MockClient)
+	httpClient := new(mocks.MockHTTPClient)
+	paymentListener, err := NewPaymentListener(cfg, database, aurora, mocks.Now)
+	require.NoError(t, err)
+	paymentListener.client = httpClient
+
+	// when asset is allowed for another receiving account only it should save the status
+	paymentOp := setDefaultPaymentOperation()
+	paymentOp.To = "GBL27BKG2JSDU6KQ5YJKCDWTVIU24VTG4PLB63SF4K2DBZS5XZMWRPVU"
+	paymentOp.Asset.Code = "USD"
+	paymentOp.Asset.Issuer = "GD4I7AFSLZGTDL34TQLWJOM2NHLIIOEKD5RHHZUW54HERBLSIRKUOXRR"
+
+	database.On("GetReceivedPaymentByOperationID", "1").Return(nil, nil).Once()
+	database.On("InsertReceivedPayment", mock.AnythingOfType("*db.ReceivedPayment")).
+		Run(ensurePaymentStatus(t, paymentOp, "Processing...")).Return(nil).Once()
+	database.On("UpdateReceivedPayment", mock.AnythingOfType("*db.ReceivedPayment")).
+		Run(ensurePaymentStatus(t, paymentOp, "Asset not allowed")).Return(nil).Once()
+	aurora.On("TransactionDetail", mock.AnythingOfType("string")).Return(hProtocol.Transaction{}, nil).Once()
+
+	paymentListener.onPayment(paymentOp)
+	database.AssertExpectations(t)
+	aurora.AssertExpectations(t)
+
+	// when asset is allowed for the receiving account it should call its receive callback
+	paymentOp.Asset.Code = "GBP"
+
+	database.On("InsertCallback", mock.AnythingOfType("*db.Callback")).Return(nil).Once()
+	database.On("UpdateCallback", mock.AnythingOfType("*db.Callback")).Return(nil).Once()
+	database.On("GetReceivedPaymentByOperationID", "1").Return(nil, nil).Once()
+	database.On("InsertReceivedPayment", mock.AnythingOfType("*db.ReceivedPayment")).
+		Run(ensurePaymentStatus(t, paymentOp, "Processing...")).Return(nil).Once()
+	database.On("UpdateReceivedPayment", mock.AnythingOfType("*db.ReceivedPayment")).
+		Run(ensurePaymentStatus(t, paymentOp, "Success")).Return(nil).Once()
+	aurora.On("TransactionDetail", mock.AnythingOfType("string")).Return(hProtocol.Transaction{}, nil).Once()
+	httpClient.On(
+		"Do",
+		mock.MatchedBy(func(req *http.Request) bool {
+			return req.URL.String() == "http://gbp_receive_callback"
+		}),
+	).Return(
+		mocks.BuildHTTPResponse(200, "ok"),
+		nil,
+	).Once()
+
+	paymentListener.onPayment(paymentOp)
+	database.AssertExpectations(t)
+	aurora.AssertExpectations(t)
+	httpClient.AssertExpectations(t)
+
+	// when the receive callback fails it should not call the error callback
+	// as the receive callback is retried
+	database.On("InsertCallback", mock.AnythingOfType("*db.Callback")).Return(nil).Once()
+	database.On("UpdateCallback", mock.AnythingOfType("*db.Callback")).Return(nil).Once()
+	database.On("GetReceivedPaymentByOperationID", "1").Return(nil, nil).Once()
+	database.On("InsertReceivedPayment", mock.AnythingOfType("*db.ReceivedPayment")).
+		Run(ensurePaymentStatus(t, paymentOp, "Processing...")).Return(nil).Once()
+	database.On("UpdateReceivedPayment", mock.AnythingOfType("*db.ReceivedPayment")).
+		Run(ensurePaymentStatus(t, paymentOp, "Error response from receive callback")).Return(nil).Once()
+	aurora.On("TransactionDetail", mock.AnythingOfType("string")).Return(hProtocol.Transaction{}, nil).Once()
+	httpClient.On(
+		"Do",
+		mock.MatchedBy(func(req *http.Request) bool {
+			return req.URL.String() == "http://gbp_receive_callback"
+		}),
+	).Return(
+		mocks.BuildHTTPResponse(500, "error"),
+		nil,
+	).Once()
+
+	assert.NoError(t, paymentListener.onPayment(paymentOp))
+	database.AssertExpectations(t)
+	aurora.AssertExpectations(t)
+	httpClient.AssertExpectations(t)
+
+	// when processing fails for good it should call the error callback of the
+	// receiving account
+	database.On("InsertCallback", mock.AnythingOfType("*db.Callback")).Return(errors.New("db error")).Once()
+	database.On("GetReceivedPaymentByOperationID", "1").Return(nil, nil).Once()
+	database.On("InsertReceivedPayment", mock.AnythingOfType("*db.ReceivedPayment")).
+		Run(ensurePaymentStatus(t, paymentOp, "Processing...")).Return(nil).Once()
+	database.On("UpdateReceivedPayment", mock.AnythingOfType("*db.ReceivedPayment")).
+		Run(ensurePaymentStatus(t, paymentOp, "Error inserting callback: db error")).Return(nil).Once()
+	aurora.On("TransactionDetail", mock.AnythingOfType("string")).Return(hProtocol.Transaction{}, nil).Once()
+	httpClient.On(
+		"Do",
+		mock.MatchedBy(func(req *http.Request) bool {
+			return req.URL.String() == "http://gbp_error_callback"
+		}),
+	).Run(func(args mock.Arguments) {
+		req := args.Get(0).(*http.Request)
+		require.NoError(t, req.ParseForm())
+		assert.Equal(t, "1", req.PostForm.Get("id"))
+		assert.Equal(t, "GBL27BKG2JSDU6KQ5YJKCDWTVIU24VTG4PLB63SF4K2DBZS5XZMWRPVU", req.PostForm.Get("to"))
+		assert.Equal(t, "Error inserting callback: db error", req.PostForm.Get("error"))
+	}).Return(
+		mocks.BuildHTTPResponse(200, "ok"),
+		nil,
+	).Once()
+
+	assert.NoError(t, paymentListener.onPayment(paymentOp))
+	database.AssertExpectations(t)
+	aurora.AssertExpectations(t)
+	httpClient.AssertExpectations(t)
+
+	// when the payment can't be saved it should return an error so the
+	// cursor isn't saved
+	database.On("GetReceivedPaymentByOperationID", "1").Return(nil, nil).Once()
+	database.On("InsertReceivedPayment", mock.AnythingOfType("*db.ReceivedPayment")).
+		Return(errors.New("db error")).Once()
+
+	assert.EqualError(t, paymentListener.onPayment(paymentOp), "Error inserting received payment: db error")
+	database.AssertExpectations(t)
+
+	// the legacy receiving account keeps the global assets and callbacks
+	paymentOp.To = "GATKP6ZQM5CSLECPMTAC5226PE367QALCPM6AFHTSULPPZMT62OOPMQB"
+
+	database.On("GetReceivedPaymentByOperationID", "1").Return(nil, nil).Once()
+	database.On("InsertReceivedPayment", mock.AnythingOfType("*db.ReceivedPayment")).
+		Run(ensurePaymentStatus(t, paymentOp, "Processing...")).Return(nil).Once()
+	database.On("UpdateReceivedPayment", mock.AnythingOfType("*db.ReceivedPayment")).
+		Run(ensurePaymentStatus(t, paymentOp, "Asset not allowed")).Return(nil).Once()
+	aurora.On("TransactionDetail", mock.AnythingOfType("string")).Return(hProtocol.Transaction{}, nil).Once()
+
+	paymentListener.onPayment(paymentOp)
+	database.AssertExpectations(t)
+	aurora.AssertExpectations(t)
+}
+
+func TestPaymentListenerGetCursor(t *testing.T) {
+	cfg := resetConfig()
+	cfg.ReceivingAccounts = []config.ReceivingAccount{
+		{AccountID: "GBL27BKG2JSDU6KQ5YJKCDWTVIU24VTG4PLB63SF4K2DBZS5XZMWRPVU"},
+	}
+
+	database := new(mocks.MockDatabase)
+	paymentListener, err := NewPaymentListener(cfg, database, nil, mocks.Now)
+	require.NoError(t, err)
+
+	saved := "100"
+	last := "50"
+	database.On("GetReceivingAccountCursor", "GATKP6ZQM5CSLECPMTAC5226PE367QALCPM6AFHTSULPPZMT62OOPMQB").Return(&saved, nil).Once()
+	cursor, err := paymentListener.getCursor("GATKP6ZQM5CSLECPMTAC5226PE367QALCPM6AFHTSULPPZMT62OOPMQB")
+	require.NoError(t, err)
+	assert.Equal(t, &saved, cursor)
+
+	// the legacy receiving account falls back to the last received payment
+	database.On("GetReceivingAccountCursor", "GATKP6ZQM5CSLECPMTAC5226PE367QALCPM6AFHTSULPPZMT62OOPMQB").Return((*string)(nil), nil).Once()
+	database.On("GetLastCursorValue").Return(&last, nil).Once()
+	cursor, err = paymentListener.getCursor("GATKP6ZQM5CSLECPMTAC5226PE367QALCPM6AFHTSULPPZMT62OOPMQB")
+	require.NoError(t, err)
+	assert.Equal(t, &last, cursor)
+
+	database.On("GetReceivingAccountCursor", "GBL27BKG2JSDU6KQ5YJKCDWTVIU24VTG4PLB63SF4K2DBZS5XZMWRPVU").Return((*string)(nil), nil).Once()
+	cursor, err = paymentListener.getCursor("GBL27BKG2JSDU6KQ5YJKCDWTVIU24VTG4PLB63SF4K2DBZS5XZMWRPVU")
+	require.NoError(t, err)
+	assert.Nil(t, cursor)
+	database.AssertExpectations(t)
+}
+
 func TestPostForm_MACKey(t *testing.T) {
 	validKey := "SABLR5HOI2IUOYB27TR4TO7HWDJIGSRJTT4UUTXXZOFVVPGQKJ5ME43J"
 	rawkey, err := strkey.Decode(strkey.VersionByteSeed, validKey)
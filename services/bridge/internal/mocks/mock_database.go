@@ -1,6 +1,8 @@
 package mocks
 
 import (
+	"time"
+
 	"github.com/diamnet/go/services/bridge/internal/db"
 	"github.com/stretchr/testify/mock"
 )
@@ -16,6 +18,18 @@ func (m *MockDatabase) GetLastCursorValue() (cursor *string, err error) {
 	return a.Get(0).(*string), a.Error(1)
 }
 
+// GetReceivingAccountCursor is a mocking a method
+func (m *MockDatabase) GetReceivingAccountCursor(accountID string) (cursor *string, err error) {
+	a := m.Called(accountID)
+	return a.Get(0).(*string), a.Error(1)
+}
+
+// SaveReceivingAccountCursor is a mocking a method
+func (m *MockDatabase) SaveReceivingAccountCursor(accountID, cursor string) error {
+	a := m.Called(accountID, cursor)
+	return a.Error(0)
+}
+
 // InsertReceivedPayment is a mocking a method
 func (m *MockDatabase) InsertReceivedPayment(payment *db.ReceivedPayment) error {
 	a := m.Called(payment)
@@ -81,3 +95,27 @@ func (m *MockDatabase) GetSentTransactionByPaymentID(paymentID string) (*db.Sent
 	}
 	return a.Get(0).(*db.SentTransaction), a.Error(1)
 }
+
+// InsertCallback is a mocking a method
+func (m *MockDatabase) InsertCallback(callback *db.Callback) error {
+	a := m.Called(callback)
+	return a.Error(0)
+}
+
+// UpdateCallback is a mocking a method
+func (m *MockDatabase) UpdateCallback(callback *db.Callback) error {
+	a := m.Called(callback)
+	return a.Error(0)
+}
+
+// GetPendingCallbacks is a mocking a method
+func (m *MockDatabase) GetPendingCallbacks(now time.Time, limit uint64) ([]*db.Callback, error) {
+	a := m.Called(now, limit)
+	return a.Get(0).([]*db.Callback), a.Error(1)
+}
+
+// GetCallbacks is a mocking a method
+func (m *MockDatabase) GetCallbacks(status db.CallbackStatus, page, limit uint64) ([]*db.Callback, error) {
+	a := m.Called(status, page, limit)
+	return a.Get(0).([]*db.Callback), a.Error(1)
+}
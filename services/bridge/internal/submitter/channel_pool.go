@@ -0,0 +1,238 @@
+package submitter
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+
+	hc "github.com/diamnet/go/clients/auroraclient"
+	"github.com/diamnet/go/keypair"
+	hProtocol "github.com/diamnet/go/protocols/aurora"
+	shared "github.com/diamnet/go/services/internal/bridge-compliance-shared"
+	"github.com/diamnet/go/strkey"
+	"github.com/diamnet/go/support/errors"
+	"github.com/diamnet/go/txnbuild"
+	"github.com/diamnet/go/xdr"
+	"github.com/sirupsen/logrus"
+)
+
+// channelBaseFee is the fee per operation of transactions sourced from
+// channels
+const channelBaseFee = 100
+
+// ChannelPool is a pool of channel accounts. Transactions submitted by
+// SubmitTransaction are sourced from a free channel, which only provides the
+// sequence number and the fee, so transactions of the same account can be
+// submitted in parallel.
+type ChannelPool struct {
+	free            chan *Account
+	baseSeed        string
+	startingBalance string
+}
+
+// channelKeypair derives the keypair of a channel from the base seed, so
+// channels don't need to be stored and are the same after a restart.
+func channelKeypair(baseSeed string, index int) (*keypair.Full, error) {
+	rawSeed, err := strkey.Decode(strkey.VersionByteSeed, baseSeed)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid base seed")
+	}
+
+	var seed [32]byte
+	h := sha256.New()
+	h.Write(rawSeed)
+	h.Write([]byte("bridge channel " + strconv.Itoa(index)))
+	copy(seed[:], h.Sum(nil))
+	return keypair.FromRawSeed(seed)
+}
+
+// InitChannels creates a pool of `count` channel accounts. Channels missing
+// from the network are created and funded with `startingBalance` from the base
+// account.
+func (ts *TransactionSubmitter) InitChannels(baseSeed string, count int, startingBalance string) error {
+	pool := &ChannelPool{
+		free:            make(chan *Account, count),
+		baseSeed:        baseSeed,
+		startingBalance: startingBalance,
+	}
+
+	var channels []*Account
+	var missing []txnbuild.Operation
+	for i := 0; i < count; i++ {
+		kp, err := channelKeypair(baseSeed, i)
+		if err != nil {
+			return err
+		}
+
+		channel := &Account{Keypair: kp, Seed: kp.Seed()}
+		channels = append(channels, channel)
+
+		err = ts.syncSequenceNumber(channel)
+		if isNotFound(err) {
+			missing = append(missing, &txnbuild.CreateAccount{
+				Destination: kp.Address(),
+				Amount:      startingBalance,
+			})
+		} else if err != nil {
+			return errors.Wrap(err, "Error loading channel "+kp.Address())
+		}
+	}
+
+	if len(missing) > 0 {
+		ts.log.WithFields(logrus.Fields{"count": len(missing)}).Info("Creating channels")
+		err := ts.submitFromBase(pool, missing)
+		if err != nil {
+			return errors.Wrap(err, "Error creating channels")
+		}
+	}
+
+	for _, channel := range channels {
+		if channel.SequenceNumber == 0 {
+			err := ts.syncSequenceNumber(channel)
+			if err != nil {
+				return errors.Wrap(err, "Error loading channel "+channel.Keypair.Address())
+			}
+		}
+		pool.free <- channel
+	}
+
+	ts.Channels = pool
+	return nil
+}
+
+// submitFromBase submits a transaction sourced from the base account, used to
+// create and fund channels
+func (ts *TransactionSubmitter) submitFromBase(pool *ChannelPool, operations []txnbuild.Operation) error {
+	_, submitErr, err := ts.submitTransaction(nil, pool.baseSeed, operations, nil)
+	if err != nil {
+		return err
+	}
+	return submitErr
+}
+
+// submitWithChannel builds and submits a transaction sourced from a free
+// channel, with the account of `seed` as source of the operations.
+func (ts *TransactionSubmitter) submitWithChannel(paymentID *string, seed string, operations []txnbuild.Operation, memo txnbuild.Memo) (response hProtocol.TransactionSuccess, err error) {
+	account, err := ts.LoadAccount(seed)
+	if err != nil {
+		return response, errors.Wrap(err, "Error loading an account")
+	}
+
+	var sourceAccountID xdr.AccountId
+	err = sourceAccountID.SetAddress(account.Keypair.Address())
+	if err != nil {
+		return response, errors.Wrap(err, "unable to set source account")
+	}
+
+	var xdrOperations []xdr.Operation
+	for _, operation := range operations {
+		var xdrOperation xdr.Operation
+		xdrOperation, err = operation.BuildXDR()
+		if err != nil {
+			return response, errors.Wrap(err, "unable to build operation")
+		}
+		if xdrOperation.SourceAccount == nil {
+			xdrOperation.SourceAccount = &sourceAccountID
+		}
+		xdrOperations = append(xdrOperations, xdrOperation)
+	}
+
+	xdrMemo := xdr.Memo{Type: xdr.MemoTypeMemoNone}
+	if memo != nil {
+		xdrMemo, err = memo.ToXDR()
+		if err != nil {
+			return response, errors.Wrap(err, "unable to build memo")
+		}
+	}
+
+	channel := <-ts.Channels.free
+	defer func() {
+		ts.Channels.free <- channel
+	}()
+
+	var channelAccountID xdr.AccountId
+	err = channelAccountID.SetAddress(channel.Keypair.Address())
+	if err != nil {
+		return response, errors.Wrap(err, "unable to set channel account")
+	}
+
+	tx := xdr.Transaction{
+		SourceAccount: channelAccountID,
+		Fee:           xdr.Uint32(channelBaseFee * len(xdrOperations)),
+		SeqNum:        xdr.SequenceNumber(channel.SequenceNumber + 1),
+		TimeBounds:    &xdr.TimeBounds{},
+		Memo:          xdrMemo,
+		Operations:    xdrOperations,
+	}
+
+	hash, err := shared.TransactionHash(&tx, ts.Network)
+	if err != nil {
+		return response, errors.Wrap(err, "unable to get transaction hash")
+	}
+
+	envelope := xdr.TransactionEnvelope{Tx: tx}
+	for _, kp := range []keypair.KP{channel.Keypair, account.Keypair} {
+		var sig xdr.DecoratedSignature
+		sig, err = kp.SignDecorated(hash[:])
+		if err != nil {
+			return response, errors.Wrap(err, "unable to sign transaction")
+		}
+		envelope.Signatures = append(envelope.Signatures, sig)
+	}
+
+	txe, err := xdr.MarshalBase64(envelope)
+	if err != nil {
+		return response, errors.Wrap(err, "unable to encode transaction")
+	}
+
+	response, submitErr, err := ts.submitAndSave(paymentID, account.Keypair.Address(), txe, hex.EncodeToString(hash[:]))
+	if submitErr == nil {
+		channel.SequenceNumber++
+	} else {
+		ts.recoverChannel(channel, submitErr)
+	}
+	return response, err
+}
+
+// recoverChannel brings a channel back to a usable state after a failed
+// transaction: it is recreated if it was merged, funded again if it can't pay
+// fees anymore, and its sequence number is reloaded as failed transactions
+// may or may not have consumed it.
+func (ts *TransactionSubmitter) recoverChannel(channel *Account, submitErr error) {
+	log := ts.log.WithFields(logrus.Fields{"channel": channel.Keypair.Address()})
+
+	var operation txnbuild.Operation
+	if hasTransactionCode(submitErr, "tx_insufficient_balance") {
+		log.Info("Funding channel")
+		operation = &txnbuild.Payment{
+			Destination: channel.Keypair.Address(),
+			Amount:      ts.Channels.startingBalance,
+			Asset:       txnbuild.NativeAsset{},
+		}
+	} else if hasTransactionCode(submitErr, "tx_no_source_account") {
+		log.Info("Creating channel")
+		operation = &txnbuild.CreateAccount{
+			Destination: channel.Keypair.Address(),
+			Amount:      ts.Channels.startingBalance,
+		}
+	}
+
+	if operation != nil {
+		err := ts.submitFromBase(ts.Channels, []txnbuild.Operation{operation})
+		if err != nil {
+			log.WithFields(logrus.Fields{"err": err}).Error("Error recovering channel")
+		}
+	}
+
+	err := ts.syncSequenceNumber(channel)
+	if err != nil {
+		log.WithFields(logrus.Fields{"err": err}).Error("Error recovering channel")
+	}
+}
+
+// isNotFound returns true if err is a not found error returned by Aurora
+func isNotFound(err error) bool {
+	herr, ok := err.(*hc.Error)
+	return ok && herr.Problem.Status == http.StatusNotFound
+}
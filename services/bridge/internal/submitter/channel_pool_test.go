@@ -0,0 +1,168 @@
+package submitter
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	hc "github.com/diamnet/go/clients/auroraclient"
+	hProtocol "github.com/diamnet/go/protocols/aurora"
+	"github.com/diamnet/go/services/bridge/internal/mocks"
+	"github.com/diamnet/go/support/render/problem"
+	"github.com/diamnet/go/txnbuild"
+	"github.com/diamnet/go/xdr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	channelTestSeed      = "SDZT3EJZ7FZRYNTLOZ7VH6G5UYBFO2IO3Q5PGONMILPCZU3AL7QNZHTE"
+	channelTestAccountID = "GCLOMB72ODBFUGK4E2BK7VMR3RNZ5WSTMEOGNA2YUVHFR3WMH2XBAB6H"
+)
+
+func expectAccountDetail(mockAurora *hc.MockClient, accountID string, account hProtocol.Account, err error) {
+	mockAurora.On(
+		"AccountDetail",
+		hc.AccountRequest{AccountID: accountID},
+	).Return(account, err).Once()
+}
+
+func transactionError(code string) *hc.Error {
+	return &hc.Error{Problem: problem.P{
+		Status: http.StatusBadRequest,
+		Extras: map[string]interface{}{
+			"result_codes": map[string]interface{}{"transaction": code},
+		},
+	}}
+}
+
+func TestChannelKeypair(t *testing.T) {
+	kp0, err := channelKeypair(channelTestSeed, 0)
+	require.NoError(t, err)
+	kp1, err := channelKeypair(channelTestSeed, 1)
+	require.NoError(t, err)
+	again, err := channelKeypair(channelTestSeed, 0)
+	require.NoError(t, err)
+
+	assert.Equal(t, kp0.Address(), again.Address())
+	assert.NotEqual(t, kp0.Address(), kp1.Address())
+	assert.NotEqual(t, channelTestAccountID, kp0.Address())
+
+	_, err = channelKeypair("invalidSeed", 0)
+	assert.Error(t, err)
+}
+
+func TestInitChannels(t *testing.T) {
+	mockAurora := new(hc.MockClient)
+	mockDatabase := new(mocks.MockDatabase)
+	mocks.PredefinedTime = time.Now()
+	ts := NewTransactionSubmitter(mockAurora, mockDatabase, "Test SDF Network ; September 2015", mocks.Now)
+
+	kp0, err := channelKeypair(channelTestSeed, 0)
+	require.NoError(t, err)
+	kp1, err := channelKeypair(channelTestSeed, 1)
+	require.NoError(t, err)
+
+	// channel 0 exists, channel 1 must be created by the base account
+	expectAccountDetail(mockAurora, kp0.Address(), hProtocol.Account{Sequence: "100"}, nil)
+	expectAccountDetail(mockAurora, kp1.Address(), hProtocol.Account{}, &hc.Error{Problem: problem.NotFound})
+	expectAccountDetail(mockAurora, channelTestAccountID, hProtocol.Account{Sequence: "10"}, nil)
+	mockDatabase.On("InsertSentTransaction", mock.AnythingOfType("*db.SentTransaction")).Return(nil).Once()
+	mockDatabase.On("UpdateSentTransaction", mock.AnythingOfType("*db.SentTransaction")).Return(nil).Once()
+	mockAurora.On("SubmitTransactionXDR", mock.AnythingOfType("string")).Run(func(args mock.Arguments) {
+		var envelope xdr.TransactionEnvelope
+		require.NoError(t, xdr.SafeUnmarshalBase64(args.String(0), &envelope))
+		assert.Equal(t, channelTestAccountID, envelope.Tx.SourceAccount.Address())
+		assert.Equal(t, xdr.SequenceNumber(11), envelope.Tx.SeqNum)
+		require.Len(t, envelope.Tx.Operations, 1)
+		createAccount := envelope.Tx.Operations[0].Body.MustCreateAccountOp()
+		assert.Equal(t, kp1.Address(), createAccount.Destination.Address())
+		assert.Equal(t, xdr.Int64(20000000), createAccount.StartingBalance)
+	}).Return(hProtocol.TransactionSuccess{Ledger: 1}, nil).Once()
+	expectAccountDetail(mockAurora, kp1.Address(), hProtocol.Account{Sequence: "200"}, nil)
+
+	err = ts.InitChannels(channelTestSeed, 2, "2")
+	require.NoError(t, err)
+	mockAurora.AssertExpectations(t)
+	mockDatabase.AssertExpectations(t)
+
+	require.NotNil(t, ts.Channels)
+	assert.Len(t, ts.Channels.free, 2)
+	assert.Equal(t, uint64(11), ts.Accounts[channelTestSeed].SequenceNumber)
+	channel := <-ts.Channels.free
+	assert.Equal(t, kp0.Address(), channel.Keypair.Address())
+	assert.Equal(t, uint64(100), channel.SequenceNumber)
+	channel = <-ts.Channels.free
+	assert.Equal(t, kp1.Address(), channel.Keypair.Address())
+	assert.Equal(t, uint64(200), channel.SequenceNumber)
+}
+
+func TestSubmitWithChannel(t *testing.T) {
+	mockAurora := new(hc.MockClient)
+	mockDatabase := new(mocks.MockDatabase)
+	mocks.PredefinedTime = time.Now()
+	ts := NewTransactionSubmitter(mockAurora, mockDatabase, "Test SDF Network ; September 2015", mocks.Now)
+
+	kp0, err := channelKeypair(channelTestSeed, 0)
+	require.NoError(t, err)
+	expectAccountDetail(mockAurora, kp0.Address(), hProtocol.Account{Sequence: "100"}, nil)
+	require.NoError(t, ts.InitChannels(channelTestSeed, 1, "2"))
+
+	payment := &txnbuild.Payment{
+		Destination: "GB3W7VQ2A2IOQIS4LUFUMRC2DWXONUDH24ROLE6RS4NGUNHVSXKCABOM",
+		Amount:      "100",
+		Asset:       txnbuild.NativeAsset{},
+	}
+
+	// the transaction is sourced from the channel and the operations from
+	// the base account
+	expectAccountDetail(mockAurora, channelTestAccountID, hProtocol.Account{Sequence: "10"}, nil)
+	mockDatabase.On("InsertSentTransaction", mock.AnythingOfType("*db.SentTransaction")).Return(nil).Once()
+	mockDatabase.On("UpdateSentTransaction", mock.AnythingOfType("*db.SentTransaction")).Return(nil).Once()
+	mockAurora.On("SubmitTransactionXDR", mock.AnythingOfType("string")).Run(func(args mock.Arguments) {
+		var envelope xdr.TransactionEnvelope
+		require.NoError(t, xdr.SafeUnmarshalBase64(args.String(0), &envelope))
+		assert.Equal(t, kp0.Address(), envelope.Tx.SourceAccount.Address())
+		assert.Equal(t, xdr.SequenceNumber(101), envelope.Tx.SeqNum)
+		assert.Equal(t, xdr.Uint32(100), envelope.Tx.Fee)
+		assert.Len(t, envelope.Signatures, 2)
+		require.Len(t, envelope.Tx.Operations, 1)
+		require.NotNil(t, envelope.Tx.Operations[0].SourceAccount)
+		assert.Equal(t, channelTestAccountID, envelope.Tx.Operations[0].SourceAccount.Address())
+	}).Return(hProtocol.TransactionSuccess{Ledger: 1}, nil).Once()
+
+	_, err = ts.SubmitTransaction(nil, channelTestSeed, []txnbuild.Operation{payment}, nil)
+	require.NoError(t, err)
+	mockAurora.AssertExpectations(t)
+	mockDatabase.AssertExpectations(t)
+	assert.Equal(t, uint64(10), ts.Accounts[channelTestSeed].SequenceNumber)
+	channel := <-ts.Channels.free
+	assert.Equal(t, uint64(101), channel.SequenceNumber)
+	ts.Channels.free <- channel
+
+	// when the channel runs out of lumens it is funded by the base account
+	// and its sequence number is reloaded
+	mockDatabase.On("InsertSentTransaction", mock.AnythingOfType("*db.SentTransaction")).Return(nil).Twice()
+	mockDatabase.On("UpdateSentTransaction", mock.AnythingOfType("*db.SentTransaction")).Return(nil).Twice()
+	mockAurora.On("SubmitTransactionXDR", mock.AnythingOfType("string")).
+		Return(hProtocol.TransactionSuccess{}, transactionError("tx_insufficient_balance")).Once()
+	mockAurora.On("SubmitTransactionXDR", mock.AnythingOfType("string")).Run(func(args mock.Arguments) {
+		var envelope xdr.TransactionEnvelope
+		require.NoError(t, xdr.SafeUnmarshalBase64(args.String(0), &envelope))
+		assert.Equal(t, channelTestAccountID, envelope.Tx.SourceAccount.Address())
+		assert.Equal(t, xdr.SequenceNumber(11), envelope.Tx.SeqNum)
+		require.Len(t, envelope.Tx.Operations, 1)
+		funding := envelope.Tx.Operations[0].Body.MustPaymentOp()
+		assert.Equal(t, kp0.Address(), funding.Destination.Address())
+		assert.Equal(t, xdr.Int64(20000000), funding.Amount)
+	}).Return(hProtocol.TransactionSuccess{Ledger: 2}, nil).Once()
+	expectAccountDetail(mockAurora, kp0.Address(), hProtocol.Account{Sequence: "102"}, nil)
+
+	_, err = ts.SubmitTransaction(nil, channelTestSeed, []txnbuild.Operation{payment}, nil)
+	require.NoError(t, err)
+	mockAurora.AssertExpectations(t)
+	mockDatabase.AssertExpectations(t)
+	channel = <-ts.Channels.free
+	assert.Equal(t, uint64(102), channel.SequenceNumber)
+}
@@ -33,6 +33,9 @@ type TransactionSubmitter struct {
 	Network       string
 	log           *logrus.Entry
 	now           func() time.Time
+	// Channels is the pool of channel accounts used by SubmitTransaction,
+	// nil when channels are not configured
+	Channels *ChannelPool
 }
 
 // Account represents account used to signing and sending transactions
@@ -179,32 +182,64 @@ func (ts *TransactionSubmitter) SignAndSubmitRawTransaction(paymentID *string, s
 			return response, herr
 		}
 
-		account.Mutex.Lock()
-		ts.log.Print("Syncing sequence number for ", account.Keypair.Address())
-
-		accountRequest := hc.AccountRequest{AccountID: account.Keypair.Address()}
-		accountResponse, err := ts.Aurora.AccountDetail(accountRequest)
-		if err != nil {
-			ts.log.Error("Error updating sequence number ", err)
-		} else {
-			account.SequenceNumber, _ = strconv.ParseUint(accountResponse.Sequence, 10, 64)
-		}
-		account.Mutex.Unlock()
-
+		ts.syncSequenceNumber(account)
 		return response, herr
 	}
 	return
 }
 
-// SubmitTransaction builds and submits transaction to DiamNet network
+// syncSequenceNumber reloads the sequence number of an account from Aurora
+func (ts *TransactionSubmitter) syncSequenceNumber(account *Account) error {
+	account.Mutex.Lock()
+	defer account.Mutex.Unlock()
+	ts.log.Print("Syncing sequence number for ", account.Keypair.Address())
+
+	accountRequest := hc.AccountRequest{AccountID: account.Keypair.Address()}
+	accountResponse, err := ts.Aurora.AccountDetail(accountRequest)
+	if err != nil {
+		ts.log.Error("Error updating sequence number ", err)
+		return err
+	}
+
+	account.SequenceNumber, err = strconv.ParseUint(accountResponse.Sequence, 10, 64)
+	return err
+}
+
+// hasTransactionCode returns true if err is a transaction error returned by
+// Aurora with the given result code
+func hasTransactionCode(err error, code string) bool {
+	herr, ok := err.(*hc.Error)
+	if !ok {
+		return false
+	}
+	codes, err := herr.ResultCodes()
+	return err == nil && codes.TransactionCode == code
+}
+
+// SubmitTransaction builds and submits transaction to DiamNet network. When
+// channels are configured the transaction is sourced from a free channel
+// account and the operations keep the account of `seed` as source.
 func (ts *TransactionSubmitter) SubmitTransaction(paymentID *string, seed string, operation []txnbuild.Operation, memo txnbuild.Memo) (hProtocol.TransactionSuccess, error) {
+	if ts.Channels != nil {
+		return ts.submitWithChannel(paymentID, seed, operation, memo)
+	}
+	response, _, err := ts.submitTransaction(paymentID, seed, operation, memo)
+	return response, err
+}
+
+// submitTransaction builds and submits transaction sourced from the account
+// of `seed`. It also returns the error returned by aurora when the
+// transaction failed.
+func (ts *TransactionSubmitter) submitTransaction(paymentID *string, seed string, operation []txnbuild.Operation, memo txnbuild.Memo) (response hProtocol.TransactionSuccess, submitErr error, err error) {
 	account, err := ts.LoadAccount(seed)
 	if err != nil {
-		return hProtocol.TransactionSuccess{}, errors.Wrap(err, "Error loading an account")
+		return response, nil, errors.Wrap(err, "Error loading an account")
 	}
 
+	account.Mutex.Lock()
+	sourceAccount := &txnbuild.SimpleAccount{AccountID: account.Keypair.Address(), Sequence: int64(account.SequenceNumber)}
 	tx := txnbuild.Transaction{
-		SourceAccount: &txnbuild.SimpleAccount{AccountID: account.Keypair.Address(), Sequence: int64(account.SequenceNumber)},
+		SourceAccount: sourceAccount,
 		Operations:    operation,
 		Timebounds:    txnbuild.NewInfiniteTimeout(),
 		Network:       ts.Network,
@@ -212,40 +247,55 @@ func (ts *TransactionSubmitter) SubmitTransaction(paymentID *string, seed string
 	}
 
 	err = tx.Build()
+	if err == nil {
+		account.SequenceNumber = uint64(sourceAccount.Sequence)
+	}
+	account.Mutex.Unlock()
 	if err != nil {
 		ts.log.Error("Unable to build transaction")
-		return hProtocol.TransactionSuccess{}, errors.Wrap(err, "unable to build transaction")
+		return response, nil, errors.Wrap(err, "unable to build transaction")
 	}
 
 	kp, err := keypair.Parse(seed)
 	if err != nil {
 		ts.log.Error("Unable to convert seed to keypair")
-		return hProtocol.TransactionSuccess{}, errors.Wrap(err, "unable to convert seed to keypair")
+		return response, nil, errors.Wrap(err, "unable to convert seed to keypair")
 	}
 
 	err = tx.Sign(kp.(*keypair.Full))
 	if err != nil {
 		ts.log.Error("Unable to sign transaction")
-		return hProtocol.TransactionSuccess{}, errors.Wrap(err, "unable to sign transaction")
+		return response, nil, errors.Wrap(err, "unable to sign transaction")
 	}
 
 	txe, err := tx.Base64()
 	if err != nil {
 		ts.log.Error("Unable to encode transaction")
-		return hProtocol.TransactionSuccess{}, errors.Wrap(err, "unable to encode transaction")
+		return response, nil, errors.Wrap(err, "unable to encode transaction")
 	}
 
 	txHashBytes, err := tx.Hash()
 	if err != nil {
 		ts.log.Error("Unable to get transaction hash")
-		return hProtocol.TransactionSuccess{}, errors.Wrap(err, "unable to get transaction hash")
+		return response, nil, errors.Wrap(err, "unable to get transaction hash")
 	}
 
-	return ts.SubmitAndSave(paymentID, tx.SourceAccount.GetAccountID(), txe, hex.EncodeToString(txHashBytes[:]))
+	response, submitErr, err = ts.submitAndSave(paymentID, tx.SourceAccount.GetAccountID(), txe, hex.EncodeToString(txHashBytes[:]))
+	if hasTransactionCode(submitErr, "tx_bad_seq") {
+		ts.syncSequenceNumber(account)
+	}
+	return
 }
 
 // SubmitAndSave sumbits a transaction to aurora and saves the details in the bridge server database.
 func (ts *TransactionSubmitter) SubmitAndSave(paymentID *string, sourceAccount, txeB64, txHash string) (response hProtocol.TransactionSuccess, err error) {
+	response, _, err = ts.submitAndSave(paymentID, sourceAccount, txeB64, txHash)
+	return
+}
+
+// submitAndSave is SubmitAndSave also returning the error returned by aurora
+// when the transaction failed.
+func (ts *TransactionSubmitter) submitAndSave(paymentID *string, sourceAccount, txeB64, txHash string) (response hProtocol.TransactionSuccess, submitErr error, err error) {
 	nullPaymentID := sql.NullString{Valid: false}
 	if paymentID != nil {
 		nullPaymentID = sql.NullString{
@@ -273,6 +323,7 @@ func (ts *TransactionSubmitter) SubmitAndSave(paymentID *string, sourceAccount,
 
 	var herr *hc.Error
 	response, err = ts.Aurora.SubmitTransactionXDR(txeB64)
+	submitErr = err
 	if err == nil {
 		sentTransaction.Status = db.SentTransactionStatusSuccess
 		sentTransaction.Ledger = &response.Ledger
@@ -168,6 +168,14 @@ func NewApp(config config.Config, migrateFlag bool, versionFlag bool, version st
 		if err != nil {
 			return
 		}
+
+		if config.Channels.Count > 0 {
+			log.Print("Initializing channel accounts")
+			err = ts.InitChannels(config.Accounts.BaseSeed, config.Channels.Count, config.Channels.StartingBalance)
+			if err != nil {
+				return
+			}
+		}
 	}
 
 	log.Print("TransactionSubmitter created")
@@ -176,9 +184,18 @@ func NewApp(config config.Config, migrateFlag bool, versionFlag bool, version st
 
 	var paymentListener listener.PaymentListener
 
-	if config.Accounts.ReceivingAccountID == "" {
-		log.Warning("No accounts.receiving_account_id param. Skipping...")
-	} else if config.Callbacks.Receive == "" {
+	receivingAccounts := config.GetReceivingAccounts()
+	missingReceiveCallback := false
+	for _, account := range receivingAccounts {
+		if account.Callbacks.Receive == "" {
+			log.Warning("No callbacks.receive param for receiving account " + account.AccountID)
+			missingReceiveCallback = true
+		}
+	}
+
+	if len(receivingAccounts) == 0 {
+		log.Warning("No accounts.receiving_account_id or receiving_accounts param. Skipping...")
+	} else if missingReceiveCallback {
 		log.Warning("No callbacks.receive param. Skipping...")
 	} else {
 		paymentListener, err = listener.NewPaymentListener(&config, &database, &h, time.Now)
@@ -258,6 +275,7 @@ func (a *App) Serve() {
 	mux.Get("/admin/received-payments", a.requestHandler.AdminReceivedPayments)
 	mux.Get("/admin/received-payments/{id}", a.requestHandler.AdminReceivedPayment)
 	mux.Get("/admin/sent-transactions", a.requestHandler.AdminSentTransactions)
+	mux.Get("/admin/callbacks", a.requestHandler.AdminCallbacks)
 
 	supportHttp.Run(supportHttp.Config{
 		ListenAddr: fmt.Sprintf(":%d", *a.config.Port),
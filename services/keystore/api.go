@@ -34,6 +34,8 @@ func (s *Service) wrapMiddleware(handler http.Handler) http.Handler {
 func ServeMux(s *Service) http.Handler {
 	mux := http.NewServeMux()
 	mux.Handle("/keys", s.wrapMiddleware(s.keysHTTPMethodHandler()))
+	mux.Handle("/keys/versions", s.wrapMiddleware(s.keysVersionsHTTPMethodHandler()))
+	mux.Handle("/keys/restore", s.wrapMiddleware(s.restoreKeysHTTPMethodHandler()))
 	mux.Handle("/health", s.wrapMiddleware(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
 		w.WriteHeader(http.StatusOK)
 	})))
@@ -58,6 +60,30 @@ func (s *Service) keysHTTPMethodHandler() http.Handler {
 	})
 }
 
+func (s *Service) keysVersionsHTTPMethodHandler() http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.Method {
+		case http.MethodGet:
+			jsonHandler(s.listKeysVersions).ServeHTTP(rw, req)
+
+		default:
+			problem.Render(req.Context(), rw, probKeysVersionsMethodNotAllowed)
+		}
+	})
+}
+
+func (s *Service) restoreKeysHTTPMethodHandler() http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.Method {
+		case http.MethodPost:
+			jsonHandler(s.restoreKeys).ServeHTTP(rw, req)
+
+		default:
+			problem.Render(req.Context(), rw, probRestoreKeysMethodNotAllowed)
+		}
+	})
+}
+
 type authResponse struct {
 	UserID string `json:"userID"`
 }
@@ -94,6 +120,18 @@ func authHandler(next http.Handler, authenticator *Authenticator) http.Handler {
 
 		case GraphQL:
 			// to be implemented later
+
+		case JWT, SEP10:
+			userID, err := authenticator.jwtUserID(req)
+			if err != nil {
+				log.Ctx(ctx).WithField("err", err).Info("authenticating the JWT")
+				problem.Render(ctx, rw, probNotAuthorized)
+				return
+			}
+
+			next.ServeHTTP(rw, req.WithContext(withUserID(ctx, userID)))
+			return
+
 		default:
 			problem.Render(ctx, rw, probNotAuthorized)
 			return
@@ -224,3 +224,37 @@ func TestDeleteKeysAPI(t *testing.T) {
 		t.Errorf("expect the keys blob of the user %s to be deleted", userID(ctx))
 	}
 }
+
+func TestRestoreKeysAPIUnknownVersion(t *testing.T) {
+	db := openKeystoreDB(t)
+	defer db.Close() // drop test db
+
+	conn := db.Open()
+	defer conn.Close() // close db connection
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `{"userID":"test-user"}`)
+	}))
+	defer ts.Close()
+
+	h := ServeMux(&Service{
+		db: conn.DB,
+		authenticator: &Authenticator{
+			URL:     ts.URL,
+			APIType: REST,
+		},
+	})
+
+	body, err := json.Marshal(restoreKeysRequest{Version: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest("POST", "/keys/restore", bytes.NewReader(body))
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("POST %s responded with %s, want %s", req.URL, http.StatusText(rr.Code), http.StatusText(http.StatusNotFound))
+	}
+}
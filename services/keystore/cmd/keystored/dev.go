@@ -9,10 +9,15 @@ import (
 
 func getConfig() *keystore.Config {
 	return &keystore.Config{
-		DBURL:          env.String("KEYSTORE_DATABASE_URL", "postgres:///keystore?sslmode=disable"),
-		MaxIdleDBConns: env.Int("DB_MAX_IDLE_CONNS", 5),
-		MaxOpenDBConns: env.Int("DB_MAX_OPEN_CONNS", 5),
-		AUTHURL:        env.String("KEYSTORE_AUTHFORWARDING_URL", ""),
-		ListenerPort:   env.Int("KEYSTORE_LISTENER_PORT", 8000),
+		DBURL:           env.String("KEYSTORE_DATABASE_URL", "postgres:///keystore?sslmode=disable"),
+		MaxIdleDBConns:  env.Int("DB_MAX_IDLE_CONNS", 5),
+		MaxOpenDBConns:  env.Int("DB_MAX_OPEN_CONNS", 5),
+		AUTHURL:         env.String("KEYSTORE_AUTHFORWARDING_URL", ""),
+		JWTSecret:       env.String("KEYSTORE_JWT_SECRET", ""),
+		JWKSFile:        env.String("KEYSTORE_JWKS_FILE", ""),
+		JWTIssuer:       env.String("KEYSTORE_JWT_ISSUER", ""),
+		JWTAudience:     env.String("KEYSTORE_JWT_AUDIENCE", ""),
+		ListenerPort:    env.Int("KEYSTORE_LISTENER_PORT", 8000),
+		MaxKeysVersions: env.Int("KEYSTORE_MAX_KEYS_VERSIONS", 20),
 	}
 }
@@ -6,6 +6,7 @@ import (
 	"database/sql"
 	"flag"
 	"fmt"
+	"io/ioutil"
 	"net"
 	"net/http"
 	"net/url"
@@ -36,7 +37,7 @@ func main() {
 	logFilePath := flag.String("log-file", "", "Log file file path")
 	logLevel := flag.String("log-level", "info", "Log level used by logrus (debug, info, warn, error)")
 	auth := flag.Bool("auth", true, "Enable authentication")
-	apiType := flag.String("api-type", "REST", "Auth Forwarding API Type")
+	apiType := flag.String("api-type", "REST", "Auth API Type (REST, GRAPHQL, JWT or SEP10)")
 
 	flag.Parse()
 	if len(flag.Args()) < 1 {
@@ -72,23 +73,35 @@ func main() {
 		os.Exit(1)
 	}
 
-	if *auth {
-		if cfg.AUTHURL == "" {
-			fmt.Fprintln(os.Stderr, "Auth is enabled but auth forwarding URL is not set")
-			os.Exit(1)
-		}
-		if _, err := url.Parse(cfg.AUTHURL); err != nil {
-			fmt.Fprintln(os.Stderr, "Invalid auth forwarding URL")
-			os.Exit(1)
-		}
+	if cfg.MaxKeysVersions < 0 {
+		fmt.Fprintf(os.Stderr, "Max keys versions %d cannot be negative\n", cfg.MaxKeysVersions)
+		os.Exit(1)
 	}
 
 	aType := strings.ToUpper(*apiType)
-	if aType != keystore.REST && aType != keystore.GraphQL {
-		fmt.Fprintln(os.Stderr, `Auth forwarding endpoint type can only be either "REST" or "GRAPHQL"`)
+	if aType != keystore.REST && aType != keystore.GraphQL && aType != keystore.JWT && aType != keystore.SEP10 {
+		fmt.Fprintln(os.Stderr, `Auth API type can only be "REST", "GRAPHQL", "JWT" or "SEP10"`)
 		os.Exit(1)
 	}
 
+	var jwtKeys *keystore.JWTKeys
+	if *auth {
+		switch aType {
+		case keystore.REST, keystore.GraphQL:
+			if cfg.AUTHURL == "" {
+				fmt.Fprintln(os.Stderr, "Auth is enabled but auth forwarding URL is not set")
+				os.Exit(1)
+			}
+			if _, err := url.Parse(cfg.AUTHURL); err != nil {
+				fmt.Fprintln(os.Stderr, "Invalid auth forwarding URL")
+				os.Exit(1)
+			}
+
+		case keystore.JWT, keystore.SEP10:
+			jwtKeys = getJWTKeys(cfg)
+		}
+	}
+
 	db, err := sql.Open(dbDriverName, cfg.DBURL)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "error opening database: %v\n", err)
@@ -110,14 +123,17 @@ func main() {
 		var authenticator *keystore.Authenticator
 		if *auth {
 			authenticator = &keystore.Authenticator{
-				URL:     cfg.AUTHURL,
-				APIType: aType,
+				URL:         cfg.AUTHURL,
+				APIType:     aType,
+				JWTKeys:     jwtKeys,
+				JWTIssuer:   cfg.JWTIssuer,
+				JWTAudience: cfg.JWTAudience,
 			}
 		}
 
 		server := &http.Server{
 			Addr:    addr,
-			Handler: keystore.ServeMux(keystore.NewService(ctx, db, authenticator)),
+			Handler: keystore.ServeMux(keystore.NewService(ctx, db, authenticator, cfg.MaxKeysVersions)),
 		}
 
 		listener, err := net.Listen("tcp", addr)
@@ -233,6 +249,33 @@ func (ln tcpKeepAliveListener) Accept() (net.Conn, error) {
 	return tc, nil
 }
 
+// getJWTKeys returns the keys verifying the tokens of the JWT and SEP10 auth
+// API types, read from the JWKS file or the shared secret of the config.
+func getJWTKeys(cfg *keystore.Config) *keystore.JWTKeys {
+	switch {
+	case cfg.JWKSFile != "":
+		data, err := ioutil.ReadFile(cfg.JWKSFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error reading JWKS file: %v\n", err)
+			os.Exit(1)
+		}
+		keys, err := keystore.ParseJWKS(data)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error parsing JWKS file: %v\n", err)
+			os.Exit(1)
+		}
+		return keys
+
+	case cfg.JWTSecret != "":
+		return keystore.NewHMACJWTKeys([]byte(cfg.JWTSecret))
+
+	default:
+		fmt.Fprintln(os.Stderr, "Auth is enabled but neither JWKS file nor JWT secret is set")
+		os.Exit(1)
+		return nil
+	}
+}
+
 func getUnappliedMigrations(db *sql.DB) []string {
 	migrations, err := keystoreMigrations.FindMigrations()
 	if err != nil {
@@ -0,0 +1,298 @@
+package keystore
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/diamnet/go/strkey"
+	"github.com/diamnet/go/support/errors"
+)
+
+// jwtKey is a key used to verify the signature of JWTs with the algorithm
+// alg. key is a []byte for HS256, a *rsa.PublicKey for RS256 and an
+// *ecdsa.PublicKey for ES256.
+type jwtKey struct {
+	id  string
+	alg string
+	key interface{}
+}
+
+// JWTKeys are the keys trusted to sign the JWTs used to authenticate with the
+// JWT and SEP10 API types.
+type JWTKeys struct {
+	keys []jwtKey
+}
+
+// NewHMACJWTKeys returns the keys verifying JWTs signed with HS256 and the
+// shared secret.
+func NewHMACJWTKeys(secret []byte) *JWTKeys {
+	return &JWTKeys{keys: []jwtKey{{alg: "HS256", key: secret}}}
+}
+
+// ParseJWKS returns the keys of a JSON Web Key Set. RSA keys verify JWTs
+// signed with RS256, P-256 EC keys JWTs signed with ES256 and symmetric keys
+// JWTs signed with HS256. Other keys are ignored.
+func ParseJWKS(data []byte) (*JWTKeys, error) {
+	var jwks struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			Alg string `json:"alg"`
+			Use string `json:"use"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+			Crv string `json:"crv"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+			K   string `json:"k"`
+		} `json:"keys"`
+	}
+	err := json.Unmarshal(data, &jwks)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing JWKS")
+	}
+
+	var keys JWTKeys
+	for _, k := range jwks.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		key := jwtKey{id: k.Kid}
+		switch k.Kty {
+		case "RSA":
+			n, err := decodeBigInt(k.N)
+			if err != nil {
+				return nil, errors.Wrapf(err, "decoding the modulus of key %q", k.Kid)
+			}
+			e, err := decodeBigInt(k.E)
+			if err != nil || !e.IsInt64() || e.Int64() > 1<<31-1 {
+				return nil, errors.Errorf("invalid exponent of key %q", k.Kid)
+			}
+			key.alg = "RS256"
+			key.key = &rsa.PublicKey{N: n, E: int(e.Int64())}
+
+		case "EC":
+			if k.Crv != "P-256" {
+				continue
+			}
+			x, err := decodeBigInt(k.X)
+			if err != nil {
+				return nil, errors.Wrapf(err, "decoding the x coordinate of key %q", k.Kid)
+			}
+			y, err := decodeBigInt(k.Y)
+			if err != nil {
+				return nil, errors.Wrapf(err, "decoding the y coordinate of key %q", k.Kid)
+			}
+			if !elliptic.P256().IsOnCurve(x, y) {
+				return nil, errors.Errorf("key %q is not on the P-256 curve", k.Kid)
+			}
+			key.alg = "ES256"
+			key.key = &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}
+
+		case "oct":
+			secret, err := base64.RawURLEncoding.DecodeString(k.K)
+			if err != nil {
+				return nil, errors.Wrapf(err, "decoding key %q", k.Kid)
+			}
+			key.alg = "HS256"
+			key.key = secret
+
+		default:
+			continue
+		}
+
+		if k.Alg != "" && k.Alg != key.alg {
+			continue
+		}
+		keys.keys = append(keys.keys, key)
+	}
+
+	if len(keys.keys) == 0 {
+		return nil, errors.New("JWKS doesn't contain any supported key")
+	}
+	return &keys, nil
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, errors.New("empty value")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+func (k jwtKey) verify(signed, sig []byte) bool {
+	hash := sha256.Sum256(signed)
+	switch key := k.key.(type) {
+	case []byte:
+		mac := hmac.New(sha256.New, key)
+		mac.Write(signed)
+		return hmac.Equal(sig, mac.Sum(nil))
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(key, crypto.SHA256, hash[:], sig) == nil
+	case *ecdsa.PublicKey:
+		if len(sig) != 64 {
+			return false
+		}
+		r := new(big.Int).SetBytes(sig[:32])
+		s := new(big.Int).SetBytes(sig[32:])
+		return ecdsa.Verify(key, hash[:], r, s)
+	}
+	return false
+}
+
+type jwtAudience []string
+
+// UnmarshalJSON accepts the aud claim as a single string or as an array of
+// strings.
+func (a *jwtAudience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = jwtAudience{single}
+		return nil
+	}
+	var multiple []string
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return err
+	}
+	*a = jwtAudience(multiple)
+	return nil
+}
+
+type jwtClaims struct {
+	Issuer    string      `json:"iss"`
+	Subject   string      `json:"sub"`
+	Audience  jwtAudience `json:"aud"`
+	ExpiresAt int64       `json:"exp"`
+	NotBefore int64       `json:"nbf"`
+}
+
+// verify verifies the signature of a JWT in compact serialization and returns
+// its claims. The claims are not validated.
+func (k *JWTKeys) verify(token string) (*jwtClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed token")
+	}
+
+	headerJSON, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding token header")
+	}
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	err = json.Unmarshal(headerJSON, &header)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing token header")
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding token signature")
+	}
+
+	// The algorithm of the header is only used to pick a key, each key
+	// verifies signatures with its own algorithm.
+	signed := []byte(parts[0] + "." + parts[1])
+	verified := false
+	for _, key := range k.keys {
+		if key.alg != header.Alg || (header.Kid != "" && key.id != "" && key.id != header.Kid) {
+			continue
+		}
+		if key.verify(signed, sig) {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return nil, errors.New("invalid token signature")
+	}
+
+	claimsJSON, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding token claims")
+	}
+	var claims jwtClaims
+	err = json.Unmarshal(claimsJSON, &claims)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing token claims")
+	}
+	return &claims, nil
+}
+
+// validate returns an error if the token is expired or not valid yet, or if
+// its issuer or audience doesn't match the expected ones, when set.
+func (c *jwtClaims) validate(now time.Time, issuer, audience string) error {
+	if c.ExpiresAt == 0 {
+		return errors.New("token doesn't expire")
+	}
+	if now.Unix() >= c.ExpiresAt {
+		return errors.New("token is expired")
+	}
+	if c.NotBefore != 0 && now.Unix() < c.NotBefore {
+		return errors.New("token is not valid yet")
+	}
+	if issuer != "" && c.Issuer != issuer {
+		return errors.Errorf("invalid token issuer %q", c.Issuer)
+	}
+	if audience != "" {
+		found := false
+		for _, aud := range c.Audience {
+			if aud == audience {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return errors.New("invalid token audience")
+		}
+	}
+	return nil
+}
+
+// jwtUserID returns the userID of a request authenticated with a bearer JWT.
+// For the SEP10 API type the token must have been issued by a SEP-10 web
+// authentication server to a Diamnet account, which is the userID.
+func (a *Authenticator) jwtUserID(req *http.Request) (string, error) {
+	authorization := req.Header.Get("Authorization")
+	if len(authorization) < 7 || !strings.EqualFold(authorization[:7], "Bearer ") {
+		return "", errors.New("missing bearer token")
+	}
+
+	claims, err := a.JWTKeys.verify(strings.TrimSpace(authorization[7:]))
+	if err != nil {
+		return "", err
+	}
+
+	err = claims.validate(time.Now(), a.JWTIssuer, a.JWTAudience)
+	if err != nil {
+		return "", err
+	}
+
+	if a.APIType == SEP10 {
+		_, err = strkey.Decode(strkey.VersionByteAccountID, claims.Subject)
+		if err != nil {
+			return "", errors.Wrap(err, "invalid SEP-10 token subject")
+		}
+	}
+	if claims.Subject == "" {
+		return "", errors.New("missing token subject")
+	}
+	return claims.Subject, nil
+}
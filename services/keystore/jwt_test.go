@@ -0,0 +1,212 @@
+package keystore
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func signTestJWT(t *testing.T, alg, kid string, key interface{}, claims map[string]interface{}) string {
+	header, err := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	hash := sha256.Sum256([]byte(signed))
+
+	var sig []byte
+	switch key := key.(type) {
+	case []byte:
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(signed))
+		sig = mac.Sum(nil)
+	case *rsa.PrivateKey:
+		sig, err = rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, hash[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, key, hash[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		rb, sb := r.Bytes(), s.Bytes()
+		sig = make([]byte, 64)
+		copy(sig[32-len(rb):32], rb)
+		copy(sig[64-len(sb):], sb)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func validTestClaims(sub string) map[string]interface{} {
+	return map[string]interface{}{
+		"sub": sub,
+		"iss": "https://example.com/auth",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+func TestHMACJWTKeys(t *testing.T) {
+	secret := []byte("secret")
+	keys := NewHMACJWTKeys(secret)
+
+	token := signTestJWT(t, "HS256", "", secret, validTestClaims("test-user"))
+	claims, err := keys.verify(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.Subject != "test-user" {
+		t.Errorf("got subject: %s, want: test-user\n", claims.Subject)
+	}
+
+	for name, token := range map[string]string{
+		"wrong secret": signTestJWT(t, "HS256", "", []byte("other"), validTestClaims("test-user")),
+		"tampered":     token[:len(token)-2] + "AA",
+		"none":         signTestJWT(t, "none", "", nil, validTestClaims("test-user")),
+		"malformed":    "not-a-token",
+	} {
+		if _, err := keys.verify(token); err == nil {
+			t.Errorf("%s: expected the token to be rejected", name)
+		}
+	}
+}
+
+func TestParseJWKS(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encode := func(i *big.Int) string {
+		return base64.RawURLEncoding.EncodeToString(i.Bytes())
+	}
+	jwks := fmt.Sprintf(`{"keys": [
+		{"kty": "RSA", "kid": "rsa", "use": "sig", "n": %q, "e": %q},
+		{"kty": "EC", "kid": "ec", "crv": "P-256", "x": %q, "y": %q},
+		{"kty": "RSA", "kid": "enc", "use": "enc", "n": %q, "e": %q}
+	]}`,
+		encode(rsaKey.N), encode(big.NewInt(int64(rsaKey.E))),
+		encode(ecKey.X), encode(ecKey.Y),
+		encode(rsaKey.N), encode(big.NewInt(int64(rsaKey.E))),
+	)
+
+	keys, err := ParseJWKS([]byte(jwks))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys.keys) != 2 {
+		t.Fatalf("got %d keys, want: 2\n", len(keys.keys))
+	}
+
+	for name, token := range map[string]string{
+		"RS256":        signTestJWT(t, "RS256", "rsa", rsaKey, validTestClaims("test-user")),
+		"ES256":        signTestJWT(t, "ES256", "ec", ecKey, validTestClaims("test-user")),
+		"ES256 no kid": signTestJWT(t, "ES256", "", ecKey, validTestClaims("test-user")),
+	} {
+		if _, err := keys.verify(token); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+
+	// the RSA public key used as an HMAC secret
+	token := signTestJWT(t, "HS256", "rsa", rsaKey.N.Bytes(), validTestClaims("test-user"))
+	if _, err := keys.verify(token); err == nil {
+		t.Error("expected the HS256 token to be rejected")
+	}
+	token = signTestJWT(t, "ES256", "rsa", ecKey, validTestClaims("test-user"))
+	if _, err := keys.verify(token); err == nil {
+		t.Error("expected the token with the wrong kid to be rejected")
+	}
+
+	if _, err := ParseJWKS([]byte(`{"keys": []}`)); err == nil {
+		t.Error("expected an empty JWKS to be rejected")
+	}
+}
+
+func TestJWTClaimsValidate(t *testing.T) {
+	now := time.Now()
+	for _, tc := range []struct {
+		name     string
+		claims   jwtClaims
+		audience string
+		valid    bool
+	}{
+		{"valid", jwtClaims{Issuer: "issuer", ExpiresAt: now.Unix() + 10}, "", true},
+		{"no expiration", jwtClaims{Issuer: "issuer"}, "", false},
+		{"expired", jwtClaims{Issuer: "issuer", ExpiresAt: now.Unix()}, "", false},
+		{"not valid yet", jwtClaims{Issuer: "issuer", ExpiresAt: now.Unix() + 10, NotBefore: now.Unix() + 5}, "", false},
+		{"wrong issuer", jwtClaims{Issuer: "other", ExpiresAt: now.Unix() + 10}, "", false},
+		{"audience", jwtClaims{Issuer: "issuer", ExpiresAt: now.Unix() + 10, Audience: jwtAudience{"a", "keystore"}}, "keystore", true},
+		{"wrong audience", jwtClaims{Issuer: "issuer", ExpiresAt: now.Unix() + 10, Audience: jwtAudience{"a"}}, "keystore", false},
+	} {
+		err := tc.claims.validate(now, "issuer", tc.audience)
+		if tc.valid && err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+		}
+		if !tc.valid && err == nil {
+			t.Errorf("%s: expected the claims to be rejected", tc.name)
+		}
+	}
+
+	var aud jwtAudience
+	if err := json.Unmarshal([]byte(`"keystore"`), &aud); err != nil || len(aud) != 1 || aud[0] != "keystore" {
+		t.Errorf("got audience: %v, err: %v, want: [keystore]", aud, err)
+	}
+}
+
+func TestAuthHandlerSEP10(t *testing.T) {
+	secret := []byte("secret")
+	account := "GCLOMB72ODBFUGK4E2BK7VMR3RNZ5WSTMEOGNA2YUVHFR3WMH2XBAB6H"
+	var gotUserID string
+	h := authHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotUserID = userID(r.Context())
+	}), &Authenticator{
+		APIType:   SEP10,
+		JWTKeys:   NewHMACJWTKeys(secret),
+		JWTIssuer: "https://example.com/auth",
+	})
+
+	req := httptest.NewRequest("GET", "/keys", nil)
+	req.Header.Set("Authorization", "Bearer "+signTestJWT(t, "HS256", "", secret, validTestClaims(account)))
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("GET %s responded with %s, want %s", req.URL, http.StatusText(rr.Code), http.StatusText(http.StatusOK))
+	}
+	if gotUserID != account {
+		t.Errorf("got userID: %s, want: %s\n", gotUserID, account)
+	}
+
+	for name, authorization := range map[string]string{
+		"no token":        "",
+		"not an account":  "Bearer " + signTestJWT(t, "HS256", "", secret, validTestClaims("test-user")),
+		"wrong signature": "Bearer " + signTestJWT(t, "HS256", "", []byte("other"), validTestClaims(account)),
+	} {
+		req := httptest.NewRequest("GET", "/keys", nil)
+		req.Header.Set("Authorization", authorization)
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		if rr.Code != http.StatusUnauthorized {
+			t.Errorf("%s: GET %s responded with %s, want %s", name, req.URL, http.StatusText(rr.Code), http.StatusText(http.StatusUnauthorized))
+		}
+	}
+}
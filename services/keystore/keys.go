@@ -2,6 +2,7 @@ package keystore
 
 import (
 	"context"
+	"database/sql"
 	"encoding/base64"
 	"time"
 
@@ -14,6 +15,7 @@ type encryptedKeys struct {
 	KeysBlob      string     `json:"keysBlob"`
 	Salt          string     `json:"salt"`
 	EncrypterName string     `json:"encrypterName"`
+	Version       int64      `json:"version"`
 	CreatedAt     time.Time  `json:"createdAt"`
 	ModifiedAt    *time.Time `json:"modifiedAt,omitempty"`
 }
@@ -22,6 +24,9 @@ type putKeysRequest struct {
 	KeysBlob      string `json:"keysBlob"`
 	Salt          string `json:"salt"`
 	EncrypterName string `json:"encrypterName"`
+	// Version is the version of the keys blob the client is replacing, 0
+	// when the user doesn't have any keys blob yet.
+	Version int64 `json:"version"`
 }
 
 func (s *Service) putKeys(ctx context.Context, in putKeysRequest) (*encryptedKeys, error) {
@@ -50,22 +55,83 @@ func (s *Service) putKeys(ctx context.Context, in putKeysRequest) (*encryptedKey
 		return nil, probInvalidKeysBlob
 	}
 
-	q := `
-		INSERT INTO encrypted_keys (user_id, encrypted_keys_data, salt, encrypter_name)
-		VALUES ($1, $2, $3, $4)
-		ON CONFLICT (user_id) DO UPDATE SET encrypted_keys_data = excluded.encrypted_keys_data, salt = excluded.salt, encrypter_name = excluded.encrypter_name,  modified_at = NOW()
-		RETURNING encrypted_keys_data, salt, encrypter_name, created_at, modified_at
-	`
+	return s.storeKeys(ctx, userID, in.Version, keysData, in.Salt, in.EncrypterName)
+}
+
+// storeKeys replaces the keys blob of a user if its current version is
+// expectedVersion, and keeps the new keys blob as a new version.
+func (s *Service) storeKeys(ctx context.Context, userID string, expectedVersion int64, keysData []byte, salt, encrypterName string) (*encryptedKeys, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "starting transaction")
+	}
+	defer tx.Rollback()
+
+	// New versions are numbered after the versions kept in
+	// encrypted_keys_versions, so that they are unique even after the keys
+	// blob has been deleted.
+	var (
+		q    string
+		args = []interface{}{userID, keysData, salt, encrypterName}
+	)
+	if expectedVersion == 0 {
+		q = `
+			INSERT INTO encrypted_keys (user_id, encrypted_keys_data, salt, encrypter_name, version)
+			VALUES ($1, $2, $3, $4, (SELECT COALESCE(MAX(version), 0) + 1 FROM encrypted_keys_versions WHERE user_id = $1))
+			ON CONFLICT (user_id) DO NOTHING
+			RETURNING encrypted_keys_data, salt, encrypter_name, version, created_at, modified_at
+		`
+	} else {
+		q = `
+			UPDATE encrypted_keys
+			SET encrypted_keys_data = $2, salt = $3, encrypter_name = $4, modified_at = NOW(),
+				version = GREATEST(version, (SELECT COALESCE(MAX(version), 0) FROM encrypted_keys_versions WHERE user_id = $1)) + 1
+			WHERE user_id = $1 AND version = $5
+			RETURNING encrypted_keys_data, salt, encrypter_name, version, created_at, modified_at
+		`
+		args = append(args, expectedVersion)
+	}
+
 	var (
 		keysBlob   []byte
 		out        encryptedKeys
 		modifiedAt pq.NullTime
 	)
-	err = s.db.QueryRowContext(ctx, q, userID, keysData, in.Salt, in.EncrypterName).Scan(&keysBlob, &out.Salt, &out.EncrypterName, &out.CreatedAt, &modifiedAt)
+	err = tx.QueryRowContext(ctx, q, args...).Scan(&keysBlob, &out.Salt, &out.EncrypterName, &out.Version, &out.CreatedAt, &modifiedAt)
+	if err == sql.ErrNoRows {
+		// Either another client updated the keys blob first or the client
+		// doesn't know the keys blob exists.
+		return nil, probVersionConflict
+	}
 	if err != nil {
 		return nil, errors.Wrap(err, "storing keys blob")
 	}
 
+	q = `
+		INSERT INTO encrypted_keys_versions (user_id, version, encrypted_keys_data, salt, encrypter_name)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+	_, err = tx.ExecContext(ctx, q, userID, out.Version, keysBlob, out.Salt, out.EncrypterName)
+	if err != nil {
+		return nil, errors.Wrap(err, "storing keys blob version")
+	}
+
+	if s.maxKeysVersions > 0 {
+		q = `
+			DELETE FROM encrypted_keys_versions
+			WHERE user_id = $1 AND version <= $2
+		`
+		_, err = tx.ExecContext(ctx, q, userID, out.Version-int64(s.maxKeysVersions))
+		if err != nil {
+			return nil, errors.Wrap(err, "deleting old keys blob versions")
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, errors.Wrap(err, "committing transaction")
+	}
+
 	out.KeysBlob = base64.RawURLEncoding.EncodeToString(keysBlob)
 	if modifiedAt.Valid {
 		out.ModifiedAt = &modifiedAt.Time
@@ -80,7 +146,7 @@ func (s *Service) getKeys(ctx context.Context) (*encryptedKeys, error) {
 	}
 
 	q := `
-		SELECT encrypted_keys_data, salt, encrypter_name, created_at, modified_at
+		SELECT encrypted_keys_data, salt, encrypter_name, version, created_at, modified_at
 		FROM encrypted_keys
 		WHERE user_id = $1
 	`
@@ -89,7 +155,7 @@ func (s *Service) getKeys(ctx context.Context) (*encryptedKeys, error) {
 		out        encryptedKeys
 		modifiedAt pq.NullTime
 	)
-	err := s.db.QueryRowContext(ctx, q, userID).Scan(&keysBlob, &out.Salt, &out.EncrypterName, &out.CreatedAt, &modifiedAt)
+	err := s.db.QueryRowContext(ctx, q, userID).Scan(&keysBlob, &out.Salt, &out.EncrypterName, &out.Version, &out.CreatedAt, &modifiedAt)
 	if err != nil {
 		return nil, errors.Wrap(err, "getting keys blob")
 	}
@@ -114,3 +180,92 @@ func (s *Service) deleteKeys(ctx context.Context) error {
 	_, err := s.db.ExecContext(ctx, q, userID)
 	return errors.Wrap(err, "deleting keys blob")
 }
+
+type keysVersion struct {
+	Version       int64     `json:"version"`
+	Salt          string    `json:"salt"`
+	EncrypterName string    `json:"encrypterName"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+type keysVersionsResponse struct {
+	Versions []keysVersion `json:"versions"`
+}
+
+// listKeysVersions returns the versions of the keys blob of a user kept by
+// the retention policy, most recent first. The keys blobs are not included,
+// a version can be brought back with restoreKeys.
+func (s *Service) listKeysVersions(ctx context.Context) (*keysVersionsResponse, error) {
+	userID := userID(ctx)
+	if userID == "" {
+		return nil, probNotAuthorized
+	}
+
+	q := `
+		SELECT version, salt, encrypter_name, created_at
+		FROM encrypted_keys_versions
+		WHERE user_id = $1
+		ORDER BY version DESC
+	`
+	rows, err := s.db.QueryContext(ctx, q, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting keys blob versions")
+	}
+	defer rows.Close()
+
+	out := keysVersionsResponse{Versions: []keysVersion{}}
+	for rows.Next() {
+		var v keysVersion
+		err = rows.Scan(&v.Version, &v.Salt, &v.EncrypterName, &v.CreatedAt)
+		if err != nil {
+			return nil, errors.Wrap(err, "scanning keys blob version")
+		}
+		out.Versions = append(out.Versions, v)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "getting keys blob versions")
+	}
+	return &out, nil
+}
+
+type restoreKeysRequest struct {
+	// Version is the version of the keys blob to restore.
+	Version int64 `json:"version"`
+	// CurrentVersion is the version of the keys blob the client is
+	// replacing, 0 when the user doesn't have any keys blob.
+	CurrentVersion int64 `json:"currentVersion"`
+}
+
+// restoreKeys makes a previous version of the keys blob of a user the
+// current one. The restored keys blob is stored as a new version, like a put.
+func (s *Service) restoreKeys(ctx context.Context, in restoreKeysRequest) (*encryptedKeys, error) {
+	userID := userID(ctx)
+	if userID == "" {
+		return nil, probNotAuthorized
+	}
+
+	if in.Version == 0 {
+		return nil, problem.MakeInvalidFieldProblem("version", errRequiredField)
+	}
+
+	q := `
+		SELECT encrypted_keys_data, salt, encrypter_name
+		FROM encrypted_keys_versions
+		WHERE user_id = $1 AND version = $2
+	`
+	var (
+		keysData      []byte
+		salt          string
+		encrypterName string
+	)
+	err := s.db.QueryRowContext(ctx, q, userID, in.Version).Scan(&keysData, &salt, &encrypterName)
+	if err == sql.ErrNoRows {
+		// The version is unknown or no longer kept.
+		return nil, problem.NotFound
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "getting keys blob version")
+	}
+
+	return s.storeKeys(ctx, userID, in.CurrentVersion, keysData, salt, encrypterName)
+}
@@ -4,10 +4,13 @@ import (
 	"context"
 	"database/sql"
 	"encoding/base64"
+	"fmt"
+	"reflect"
 	"testing"
 	"time"
 
 	"github.com/diamnet/go/support/errors"
+	"github.com/diamnet/go/support/render/problem"
 )
 
 func TestPutKeys(t *testing.T) {
@@ -18,7 +21,7 @@ func TestPutKeys(t *testing.T) {
 	defer conn.Close() // close db connection
 
 	ctx := withUserID(context.Background(), "test-user")
-	s := &Service{conn.DB, nil}
+	s := &Service{db: conn.DB}
 
 	blob := `[{
 		"keyType": "plaintextKey",
@@ -63,7 +66,7 @@ func TestGetKeys(t *testing.T) {
 	defer conn.Close() // close db connection
 
 	ctx := withUserID(context.Background(), "test-user")
-	s := &Service{conn.DB, nil}
+	s := &Service{db: conn.DB}
 
 	blob := `[{
 		"keyType": "plaintextKey",
@@ -113,7 +116,7 @@ func TestDeleteKeys(t *testing.T) {
 	defer conn.Close() // close db connection
 
 	ctx := withUserID(context.Background(), "test-user")
-	s := &Service{conn.DB, nil}
+	s := &Service{db: conn.DB}
 
 	blob := `[{
 		"keyType": "plaintextKey",
@@ -143,3 +146,131 @@ func TestDeleteKeys(t *testing.T) {
 		t.Errorf("expect the keys blob of the user %s to be deleted", userID(ctx))
 	}
 }
+
+func TestPutKeysVersionConflict(t *testing.T) {
+	db := openKeystoreDB(t)
+	defer db.Close() // drop test db
+
+	conn := db.Open()
+	defer conn.Close() // close db connection
+
+	ctx := withUserID(context.Background(), "test-user")
+	s := &Service{db: conn.DB}
+
+	in := putKeysRequest{
+		KeysBlob:      base64.RawURLEncoding.EncodeToString([]byte("blob-1")),
+		EncrypterName: "identity",
+		Salt:          "random-salt",
+	}
+	got, err := s.putKeys(ctx, in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Version != 1 {
+		t.Errorf("got version: %d, want: 1\n", got.Version)
+	}
+
+	// a second client which doesn't know the keys blob exists
+	_, err = s.putKeys(ctx, in)
+	if p, ok := err.(problem.P); !ok || p.Type != probVersionConflict.Type {
+		t.Errorf("got error: %v, want: %v\n", err, probVersionConflict)
+	}
+
+	in.KeysBlob = base64.RawURLEncoding.EncodeToString([]byte("blob-2"))
+	in.Version = 1
+	got, err = s.putKeys(ctx, in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Version != 2 {
+		t.Errorf("got version: %d, want: 2\n", got.Version)
+	}
+
+	// a second client which read the keys blob before the last put
+	in.KeysBlob = base64.RawURLEncoding.EncodeToString([]byte("blob-3"))
+	_, err = s.putKeys(ctx, in)
+	if p, ok := err.(problem.P); !ok || p.Type != probVersionConflict.Type {
+		t.Errorf("got error: %v, want: %v\n", err, probVersionConflict)
+	}
+
+	got, err = s.getKeys(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := base64.RawURLEncoding.EncodeToString([]byte("blob-2")); got.KeysBlob != want {
+		t.Errorf("got blob: %s, want: %s\n", got.KeysBlob, want)
+	}
+}
+
+func TestRestoreKeys(t *testing.T) {
+	db := openKeystoreDB(t)
+	defer db.Close() // drop test db
+
+	conn := db.Open()
+	defer conn.Close() // close db connection
+
+	ctx := withUserID(context.Background(), "test-user")
+	s := &Service{db: conn.DB, maxKeysVersions: 3}
+
+	for i := int64(0); i < 4; i++ {
+		_, err := s.putKeys(ctx, putKeysRequest{
+			KeysBlob:      base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("blob-%d", i+1))),
+			EncrypterName: "identity",
+			Salt:          fmt.Sprintf("salt-%d", i+1),
+			Version:       i,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the oldest version is not kept
+	versions, err := s.listKeysVersions(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []int64
+	for _, v := range versions.Versions {
+		got = append(got, v.Version)
+	}
+	if want := []int64{4, 3, 2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got versions: %v, want: %v\n", got, want)
+	}
+
+	// the oldest version is not found
+	_, err = s.restoreKeys(ctx, restoreKeysRequest{Version: 1, CurrentVersion: 4})
+	if p, ok := err.(problem.P); !ok || p.Type != problem.NotFound.Type {
+		t.Errorf("got error: %v, want: %v\n", err, problem.NotFound)
+	}
+
+	// neither is an unknown version
+	_, err = s.restoreKeys(ctx, restoreKeysRequest{Version: 10, CurrentVersion: 4})
+	if p, ok := err.(problem.P); !ok || p.Type != problem.NotFound.Type {
+		t.Errorf("got error: %v, want: %v\n", err, problem.NotFound)
+	}
+
+	_, err = s.restoreKeys(ctx, restoreKeysRequest{Version: 2, CurrentVersion: 3})
+	if p, ok := err.(problem.P); !ok || p.Type != probVersionConflict.Type {
+		t.Errorf("got error: %v, want: %v\n", err, probVersionConflict)
+	}
+
+	// restoring after the keys blob has been deleted
+	err = s.deleteKeys(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	restored, err := s.restoreKeys(ctx, restoreKeysRequest{Version: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.Version != 5 {
+		t.Errorf("got version: %d, want: 5\n", restored.Version)
+	}
+	if want := base64.RawURLEncoding.EncodeToString([]byte("blob-2")); restored.KeysBlob != want {
+		t.Errorf("got blob: %s, want: %s\n", restored.KeysBlob, want)
+	}
+	if restored.Salt != "salt-2" {
+		t.Errorf("got salt: %s, want: salt-2\n", restored.Salt)
+	}
+}
@@ -0,0 +1,23 @@
+-- +migrate Up
+
+ALTER TABLE public.encrypted_keys ADD COLUMN version integer NOT NULL DEFAULT 1;
+
+CREATE TABLE public.encrypted_keys_versions (
+    user_id text NOT NULL,
+    version integer NOT NULL,
+    encrypted_keys_data bytea NOT NULL,
+    salt text NOT NULL,
+    encrypter_name text NOT NULL,
+    created_at timestamp with time zone NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, version)
+);
+
+INSERT INTO public.encrypted_keys_versions (user_id, version, encrypted_keys_data, salt, encrypter_name, created_at)
+SELECT user_id, version, encrypted_keys_data, salt, encrypter_name, COALESCE(modified_at, created_at)
+FROM public.encrypted_keys;
+
+-- +migrate Down
+
+DROP TABLE public.encrypted_keys_versions;
+
+ALTER TABLE public.encrypted_keys DROP COLUMN version;
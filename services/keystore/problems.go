@@ -25,6 +25,22 @@ var (
 			"The server supports HTTP GET/PUT/DELETE for the /keys endpoint.",
 	}
 
+	probKeysVersionsMethodNotAllowed = problem.P{
+		Type:   "method_not_allowed",
+		Title:  "Method Not Allowed",
+		Status: http.StatusMethodNotAllowed,
+		Detail: "This endpoint does not support the request method you used. " +
+			"The server supports HTTP GET for the /keys/versions endpoint.",
+	}
+
+	probRestoreKeysMethodNotAllowed = problem.P{
+		Type:   "method_not_allowed",
+		Title:  "Method Not Allowed",
+		Status: http.StatusMethodNotAllowed,
+		Detail: "This endpoint does not support the request method you used. " +
+			"The server supports HTTP POST for the /keys/restore endpoint.",
+	}
+
 	probInvalidKeysBlob = problem.P{
 		Type:   "invalid_keys_blob",
 		Title:  "Invalid Keys Blob",
@@ -34,6 +50,15 @@ var (
 			"properly and try again.",
 	}
 
+	probVersionConflict = problem.P{
+		Type:   "version_conflict",
+		Title:  "Version Conflict",
+		Status: http.StatusConflict,
+		Detail: "The keys blob has been modified since the version in your " +
+			"request body. Please get the current keys blob, apply your " +
+			"changes to it and try again with its version.",
+	}
+
 	probNotAuthorized = problem.P{
 		Type:   "not_authorized",
 		Title:  "Not Authorized",
@@ -8,6 +8,10 @@ import (
 const (
 	REST    = "REST"
 	GraphQL = "GRAPHQL"
+	// JWT and SEP10 authenticate requests with a bearer JWT verified by the
+	// keystore itself, the userID is the subject of the token.
+	JWT   = "JWT"
+	SEP10 = "SEP10"
 )
 
 type Config struct {
@@ -17,20 +21,38 @@ type Config struct {
 
 	AUTHURL string
 
+	// JWTSecret or JWKSFile are the keys of the JWT and SEP10 API types,
+	// JWTIssuer and JWTAudience the expected iss and aud claims.
+	JWTSecret   string
+	JWKSFile    string
+	JWTIssuer   string
+	JWTAudience string
+
 	ListenerPort int
+
+	// MaxKeysVersions is the number of versions of a keys blob kept for
+	// each user, 0 keeps all of them.
+	MaxKeysVersions int
 }
 
 type Authenticator struct {
 	URL     string
 	APIType string
 	//GraphQL related fields will be added later
+
+	// JWTKeys verify the tokens of the JWT and SEP10 API types. JWTIssuer
+	// and JWTAudience, when set, must match the claims of the tokens.
+	JWTKeys     *JWTKeys
+	JWTIssuer   string
+	JWTAudience string
 }
 
 type Service struct {
-	db            *sql.DB
-	authenticator *Authenticator
+	db              *sql.DB
+	authenticator   *Authenticator
+	maxKeysVersions int
 }
 
-func NewService(ctx context.Context, db *sql.DB, authenticator *Authenticator) *Service {
-	return &Service{db: db, authenticator: authenticator}
+func NewService(ctx context.Context, db *sql.DB, authenticator *Authenticator, maxKeysVersions int) *Service {
+	return &Service{db: db, authenticator: authenticator, maxKeysVersions: maxKeysVersions}
 }
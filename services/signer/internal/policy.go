@@ -0,0 +1,187 @@
+package internal
+
+import (
+	"math"
+	"sort"
+
+	"github.com/diamnet/go/amount"
+	"github.com/diamnet/go/protocols/aurora/operations"
+	"github.com/diamnet/go/strkey"
+	"github.com/diamnet/go/support/errors"
+	"github.com/diamnet/go/xdr"
+)
+
+// Policy restricts the transactions the signing server agrees to sign.
+type Policy struct {
+	// AllowedOperations are the types of the operations transactions may
+	// contain, named as in Aurora (e.g. "payment").
+	AllowedOperations []string `toml:"allowed_operations" valid:"required"`
+	// Limits are the maximum amounts of assets a single transaction may
+	// send. Transactions sending assets which have no limit and aren't
+	// UnlimitedAssets are rejected.
+	Limits []Limit `toml:"limits" valid:"optional"`
+	// UnlimitedAssets are the assets a single transaction may send without
+	// limit.
+	UnlimitedAssets []Asset `toml:"unlimited_assets" valid:"optional"`
+}
+
+// Asset is an asset of a policy, XLM when the code is XLM and the issuer is
+// empty.
+type Asset struct {
+	AssetCode   string `toml:"asset_code" valid:"required"`
+	AssetIssuer string `toml:"asset_issuer" valid:"optional"`
+}
+
+// Limit is the maximum amount of an asset a single transaction may send,
+// through create_account, payment and path_payment operations.
+type Limit struct {
+	AssetCode   string `toml:"asset_code" valid:"required"`
+	AssetIssuer string `toml:"asset_issuer" valid:"optional"`
+	MaxAmount   string `toml:"max_amount" valid:"diamnet_amount"`
+}
+
+// Validate returns an error if the operation types or the limits of the
+// policy are invalid.
+func (p *Policy) Validate() error {
+	_, err := p.allowedOperations()
+	if err != nil {
+		return err
+	}
+	_, err = p.limits()
+	if err != nil {
+		return err
+	}
+	_, err = p.unlimitedAssets()
+	return err
+}
+
+// Check returns an error describing the first violation of the policy by tx.
+func (p *Policy) Check(tx *xdr.Transaction) error {
+	allowed, err := p.allowedOperations()
+	if err != nil {
+		return err
+	}
+	limits, err := p.limits()
+	if err != nil {
+		return err
+	}
+	unlimited, err := p.unlimitedAssets()
+	if err != nil {
+		return err
+	}
+
+	sent := map[string]xdr.Int64{}
+	for i, op := range tx.Operations {
+		if !allowed[op.Body.Type] {
+			return errors.Errorf("operation %d: %s operations are not allowed", i, operations.TypeNames[op.Body.Type])
+		}
+
+		switch op.Body.Type {
+		case xdr.OperationTypeCreateAccount:
+			native := xdr.MustNewNativeAsset().String()
+			sent[native] = addAmounts(sent[native], op.Body.MustCreateAccountOp().StartingBalance)
+		case xdr.OperationTypePayment:
+			payment := op.Body.MustPaymentOp()
+			sent[payment.Asset.String()] = addAmounts(sent[payment.Asset.String()], payment.Amount)
+		case xdr.OperationTypePathPayment:
+			payment := op.Body.MustPathPaymentOp()
+			sent[payment.SendAsset.String()] = addAmounts(sent[payment.SendAsset.String()], payment.SendMax)
+		}
+	}
+
+	assets := make([]string, 0, len(sent))
+	for asset := range sent {
+		assets = append(assets, asset)
+	}
+	sort.Strings(assets)
+	for _, asset := range assets {
+		limit, ok := limits[asset]
+		if !ok {
+			if unlimited[asset] {
+				continue
+			}
+			return errors.Errorf("the transaction sends %s, which is not allowed by the policy", asset)
+		}
+		if sent[asset] > limit {
+			return errors.Errorf("the transaction sends %s of %s, above the limit of %s",
+				amount.String(sent[asset]), asset, amount.String(limit))
+		}
+	}
+	return nil
+}
+
+// addAmounts returns a+b, or the maximum amount if it overflows.
+func addAmounts(a, b xdr.Int64) xdr.Int64 {
+	if b > 0 && a > math.MaxInt64-b {
+		return math.MaxInt64
+	}
+	return a + b
+}
+
+func (p *Policy) allowedOperations() (map[xdr.OperationType]bool, error) {
+	types := map[string]xdr.OperationType{}
+	for typ, name := range operations.TypeNames {
+		types[name] = typ
+	}
+
+	allowed := map[xdr.OperationType]bool{}
+	for _, name := range p.AllowedOperations {
+		typ, ok := types[name]
+		if !ok {
+			return nil, errors.Errorf("unknown operation type %q", name)
+		}
+		allowed[typ] = true
+	}
+	return allowed, nil
+}
+
+func (p *Policy) limits() (map[string]xdr.Int64, error) {
+	limits := map[string]xdr.Int64{}
+	for _, l := range p.Limits {
+		asset, err := parseAsset(l.AssetCode, l.AssetIssuer)
+		if err != nil {
+			return nil, err
+		}
+
+		maxAmount, err := amount.Parse(l.MaxAmount)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid limit of asset %s", l.AssetCode)
+		}
+		limits[asset.String()] = maxAmount
+	}
+	return limits, nil
+}
+
+func (p *Policy) unlimitedAssets() (map[string]bool, error) {
+	unlimited := map[string]bool{}
+	for _, a := range p.UnlimitedAssets {
+		asset, err := parseAsset(a.AssetCode, a.AssetIssuer)
+		if err != nil {
+			return nil, err
+		}
+		unlimited[asset.String()] = true
+	}
+	return unlimited, nil
+}
+
+func parseAsset(code, issuer string) (xdr.Asset, error) {
+	var asset xdr.Asset
+	if code == "XLM" && issuer == "" {
+		return xdr.MustNewNativeAsset(), nil
+	}
+
+	_, err := strkey.Decode(strkey.VersionByteAccountID, issuer)
+	if err != nil {
+		return asset, errors.Wrapf(err, "invalid issuer of asset %s", code)
+	}
+	var issuerID xdr.AccountId
+	err = issuerID.SetAddress(issuer)
+	if err != nil {
+		return asset, errors.Wrapf(err, "invalid issuer of asset %s", code)
+	}
+	err = asset.SetCredit(code, issuerID)
+	if err != nil {
+		return asset, errors.Wrapf(err, "invalid asset %s", code)
+	}
+	return asset, nil
+}
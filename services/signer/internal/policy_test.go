@@ -0,0 +1,120 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/diamnet/go/xdr"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testIssuer      = "GCOGCYU77DLEVYCXDQM7F32M5PCKES6VU3Z5GURF6U6OA5LFOVTRYPOX"
+	testDestination = "GB3W7VQ2A2IOQIS4LUFUMRC2DWXONUDH24ROLE6RS4NGUNHVSXKCABOM"
+)
+
+func testPolicy() *Policy {
+	return &Policy{
+		AllowedOperations: []string{"create_account", "payment"},
+		Limits: []Limit{
+			{AssetCode: "XLM", MaxAmount: "100"},
+			{AssetCode: "USD", AssetIssuer: testIssuer, MaxAmount: "10"},
+		},
+		UnlimitedAssets: []Asset{
+			{AssetCode: "GBP", AssetIssuer: testIssuer},
+		},
+	}
+}
+
+func paymentOp(asset xdr.Asset, amount xdr.Int64) xdr.Operation {
+	return xdr.Operation{Body: xdr.OperationBody{
+		Type: xdr.OperationTypePayment,
+		PaymentOp: &xdr.PaymentOp{
+			Destination: xdr.MustAddress(testDestination),
+			Asset:       asset,
+			Amount:      amount,
+		},
+	}}
+}
+
+func TestPolicyValidate(t *testing.T) {
+	assert.NoError(t, testPolicy().Validate())
+
+	p := testPolicy()
+	p.AllowedOperations = append(p.AllowedOperations, "steal")
+	assert.EqualError(t, p.Validate(), `unknown operation type "steal"`)
+
+	p = testPolicy()
+	p.Limits[1].AssetIssuer = ""
+	assert.Error(t, p.Validate())
+
+	p = testPolicy()
+	p.Limits[0].MaxAmount = "a lot"
+	assert.Error(t, p.Validate())
+
+	p = testPolicy()
+	p.UnlimitedAssets[0].AssetIssuer = "issuer"
+	assert.Error(t, p.Validate())
+}
+
+func TestPolicyCheck(t *testing.T) {
+	usd := xdr.MustNewCreditAsset("USD", testIssuer)
+	eur := xdr.MustNewCreditAsset("EUR", testIssuer)
+	gbp := xdr.MustNewCreditAsset("GBP", testIssuer)
+	createAccount := xdr.Operation{Body: xdr.OperationBody{
+		Type: xdr.OperationTypeCreateAccount,
+		CreateAccountOp: &xdr.CreateAccountOp{
+			Destination:     xdr.MustAddress(testDestination),
+			StartingBalance: 60 * 10000000,
+		},
+	}}
+
+	for _, tc := range []struct {
+		name       string
+		operations []xdr.Operation
+		err        string
+	}{
+		{
+			name:       "within limits",
+			operations: []xdr.Operation{paymentOp(usd, 10*10000000), createAccount},
+		},
+		{
+			name:       "asset without limit",
+			operations: []xdr.Operation{paymentOp(usd, 1), paymentOp(eur, 1)},
+			err:        "the transaction sends credit_alphanum4/EUR/" + testIssuer + ", which is not allowed by the policy",
+		},
+		{
+			name:       "unlimited asset",
+			operations: []xdr.Operation{paymentOp(gbp, 1000*10000000)},
+		},
+		{
+			name:       "above limit",
+			operations: []xdr.Operation{paymentOp(usd, 10*10000000+1)},
+			err:        "the transaction sends 10.0000001 of credit_alphanum4/USD/" + testIssuer + ", above the limit of 10.0000000",
+		},
+		{
+			name:       "limit across operations",
+			operations: []xdr.Operation{createAccount, paymentOp(xdr.MustNewNativeAsset(), 50*10000000)},
+			err:        "the transaction sends 110.0000000 of native, above the limit of 100.0000000",
+		},
+		{
+			name: "operation not allowed",
+			operations: []xdr.Operation{paymentOp(usd, 1), {Body: xdr.OperationBody{
+				Type:        xdr.OperationTypeAccountMerge,
+				Destination: &xdr.AccountId{},
+			}}},
+			err: "operation 1: account_merge operations are not allowed",
+		},
+	} {
+		err := testPolicy().Check(&xdr.Transaction{Operations: tc.operations})
+		if tc.err == "" {
+			assert.NoError(t, err, tc.name)
+		} else {
+			assert.EqualError(t, err, tc.err, tc.name)
+		}
+	}
+}
+
+func TestAddAmounts(t *testing.T) {
+	assert.Equal(t, xdr.Int64(3), addAmounts(1, 2))
+	assert.Equal(t, xdr.Int64(9223372036854775807), addAmounts(9223372036854775807, 1))
+}
@@ -0,0 +1,121 @@
+package internal
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/diamnet/go/keypair"
+	proto "github.com/diamnet/go/protocols/signer"
+	"github.com/diamnet/go/support/errors"
+	"github.com/diamnet/go/support/render/httpjson"
+	"github.com/diamnet/go/support/render/problem"
+	"github.com/diamnet/go/xdr"
+)
+
+// maxRequestSize is the maximum size of the body of a sign request
+const maxRequestSize = 100 * 1024
+
+var (
+	// ErrUnauthorized is returned when the request doesn't have a valid
+	// bearer token.
+	ErrUnauthorized = problem.P{
+		Type:   "unauthorized",
+		Title:  "Unauthorized",
+		Status: http.StatusUnauthorized,
+		Detail: "The request must be authenticated with a valid bearer token.",
+	}
+
+	// ErrPolicyViolation is returned when the transaction is rejected by the
+	// policy of the server.
+	ErrPolicyViolation = problem.P{
+		Type:   "policy_violation",
+		Title:  "Transaction rejected by policy",
+		Status: http.StatusForbidden,
+	}
+)
+
+// SignHandler signs the transactions compliant with Policy for the network of
+// NetworkPassphrase with Keypair.
+type SignHandler struct {
+	Keypair           *keypair.Full
+	NetworkPassphrase string
+	// AuthToken is the bearer token requests must be authenticated with,
+	// when set.
+	AuthToken string
+	Policy    *Policy
+}
+
+// ServeHTTP is a method that implements http.Handler
+func (handler *SignHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	result, err := handler.doHandle(r)
+	if err != nil {
+		problem.Render(r.Context(), w, err)
+		return
+	}
+
+	httpjson.Render(w, *result, httpjson.JSON)
+}
+
+// doHandle is just a convenience method that returns the object to be rendered
+func (handler *SignHandler) doHandle(r *http.Request) (*proto.SignResponse, error) {
+	if !handler.authorized(r) {
+		return nil, ErrUnauthorized
+	}
+
+	var req proto.SignRequest
+	err := json.NewDecoder(io.LimitReader(r.Body, maxRequestSize)).Decode(&req)
+	if err != nil {
+		return nil, problem.BadRequest
+	}
+
+	if req.NetworkPassphrase != handler.NetworkPassphrase {
+		return nil, problem.MakeInvalidFieldProblem(
+			"network_passphrase",
+			errors.New("the server doesn't sign transactions for this network"),
+		)
+	}
+
+	var tx xdr.Transaction
+	err = xdr.SafeUnmarshalBase64(req.Transaction, &tx)
+	if err != nil {
+		return nil, problem.MakeInvalidFieldProblem("transaction", err)
+	}
+
+	err = handler.Policy.Check(&tx)
+	if err != nil {
+		p := ErrPolicyViolation
+		p.Detail = err.Error()
+		return nil, p
+	}
+
+	sig, err := handler.Keypair.SignTransaction(&tx, handler.NetworkPassphrase)
+	if err != nil {
+		return nil, errors.Wrap(err, "sign transaction failed")
+	}
+
+	sigXDR, err := xdr.MarshalBase64(sig)
+	if err != nil {
+		return nil, errors.Wrap(err, "encode signature failed")
+	}
+
+	return &proto.SignResponse{
+		AccountID: handler.Keypair.Address(),
+		Signature: sigXDR,
+	}, nil
+}
+
+func (handler *SignHandler) authorized(r *http.Request) bool {
+	if handler.AuthToken == "" {
+		return true
+	}
+
+	authorization := r.Header.Get("Authorization")
+	if !strings.HasPrefix(authorization, "Bearer ") {
+		return false
+	}
+	token := strings.TrimPrefix(authorization, "Bearer ")
+	return subtle.ConstantTimeCompare([]byte(token), []byte(handler.AuthToken)) == 1
+}
@@ -0,0 +1,92 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/diamnet/go/keypair"
+	"github.com/diamnet/go/network"
+	proto "github.com/diamnet/go/protocols/signer"
+	"github.com/diamnet/go/support/render/problem"
+	"github.com/diamnet/go/xdr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func postSign(t *testing.T, handler http.Handler, token, txXDR, networkPassphrase string) *httptest.ResponseRecorder {
+	body, err := json.Marshal(proto.SignRequest{
+		Transaction:       txXDR,
+		NetworkPassphrase: networkPassphrase,
+	})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest("POST", "/sign", bytes.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	return rr
+}
+
+func decodeProblem(t *testing.T, rr *httptest.ResponseRecorder) problem.P {
+	var p problem.P
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &p))
+	return p
+}
+
+func TestSignHandler(t *testing.T) {
+	kp := keypair.MustParse("SDZT3EJZ7FZRYNTLOZ7VH6G5UYBFO2IO3Q5PGONMILPCZU3AL7QNZHTE").(*keypair.Full)
+	handler := &SignHandler{
+		Keypair:           kp,
+		NetworkPassphrase: network.TestNetworkPassphrase,
+		AuthToken:         "token",
+		Policy:            testPolicy(),
+	}
+
+	usd := xdr.MustNewCreditAsset("USD", testIssuer)
+	tx := xdr.Transaction{
+		SourceAccount: xdr.MustAddress(kp.Address()),
+		Fee:           100,
+		SeqNum:        1,
+		Operations:    []xdr.Operation{paymentOp(usd, 10000000)},
+	}
+	txXDR, err := xdr.MarshalBase64(tx)
+	require.NoError(t, err)
+
+	// a transaction compliant with the policy is signed
+	rr := postSign(t, handler, "token", txXDR, network.TestNetworkPassphrase)
+	require.Equal(t, http.StatusOK, rr.Code)
+	var resp proto.SignResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+	assert.Equal(t, kp.Address(), resp.AccountID)
+
+	var sig xdr.DecoratedSignature
+	require.NoError(t, xdr.SafeUnmarshalBase64(resp.Signature, &sig))
+	hash, err := network.HashTransaction(&tx, network.TestNetworkPassphrase)
+	require.NoError(t, err)
+	assert.NoError(t, kp.Verify(hash[:], sig.Signature))
+
+	// requests without a valid token are rejected
+	rr = postSign(t, handler, "other", txXDR, network.TestNetworkPassphrase)
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+
+	// transactions of other networks are rejected
+	rr = postSign(t, handler, "token", txXDR, network.PublicNetworkPassphrase)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Equal(t, "network_passphrase", decodeProblem(t, rr).Extras["invalid_field"])
+
+	// invalid transactions are rejected
+	rr = postSign(t, handler, "token", "AAAA", network.TestNetworkPassphrase)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Equal(t, "transaction", decodeProblem(t, rr).Extras["invalid_field"])
+
+	// transactions violating the policy are rejected
+	tx.Operations = []xdr.Operation{paymentOp(usd, 100*10000000)}
+	txXDR, err = xdr.MarshalBase64(tx)
+	require.NoError(t, err)
+	rr = postSign(t, handler, "token", txXDR, network.TestNetworkPassphrase)
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+	assert.Equal(t, "Transaction rejected by policy", decodeProblem(t, rr).Title)
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	stdhttp "net/http"
+	"os"
+
+	"github.com/diamnet/go/keypair"
+	"github.com/diamnet/go/services/signer/internal"
+	"github.com/diamnet/go/support/app"
+	"github.com/diamnet/go/support/config"
+	"github.com/diamnet/go/support/errors"
+	"github.com/diamnet/go/support/http"
+	"github.com/diamnet/go/support/log"
+	"github.com/diamnet/go/support/render/problem"
+	"github.com/go-chi/chi"
+	"github.com/spf13/cobra"
+)
+
+// Config represents the configuration of a signing server
+type Config struct {
+	Port              int             `toml:"port" valid:"required"`
+	SigningSeed       string          `toml:"signing_seed" valid:"diamnet_seed"`
+	NetworkPassphrase string          `toml:"network_passphrase" valid:"required"`
+	AuthToken         string          `toml:"auth_token" valid:"optional"`
+	Policy            internal.Policy `toml:"policy" valid:"required"`
+	TLS               *config.TLS     `valid:"optional"`
+}
+
+func main() {
+
+	rootCmd := &cobra.Command{
+		Use:   "signer",
+		Short: "signing server for DiamNet transactions",
+		Long:  "signing server holding a seed and signing the transactions allowed by its policy, so that the seed doesn't need to be available to the hosts building transactions",
+		Run:   run,
+	}
+
+	rootCmd.PersistentFlags().String("conf", "./signer.cfg", "config file path")
+	rootCmd.PersistentFlags().Bool("allow-unauthenticated", false, "start without auth_token, signing transactions for any client")
+	rootCmd.Execute()
+}
+
+func run(cmd *cobra.Command, args []string) {
+	var (
+		cfg                  Config
+		cfgPath              = cmd.PersistentFlags().Lookup("conf").Value.String()
+		allowUnauthenticated = cmd.PersistentFlags().Lookup("allow-unauthenticated").Value.String() == "true"
+	)
+	log.SetLevel(log.InfoLevel)
+
+	err := config.Read(cfgPath, &cfg)
+	if err != nil {
+		switch cause := errors.Cause(err).(type) {
+		case *config.InvalidConfigError:
+			log.Error("config file: ", cause)
+		default:
+			log.Error(err)
+		}
+		os.Exit(1)
+	}
+
+	err = cfg.Policy.Validate()
+	if err != nil {
+		log.Error("config file: invalid policy: ", err)
+		os.Exit(1)
+	}
+
+	kp, err := keypair.Parse(cfg.SigningSeed)
+	if err != nil {
+		log.Error(err)
+		os.Exit(1)
+	}
+
+	if cfg.AuthToken == "" {
+		if !allowUnauthenticated {
+			log.Error("config file: auth_token is required, use --allow-unauthenticated to start without it")
+			os.Exit(1)
+		}
+		log.Warn("auth_token is not set, the server will sign transactions for any client")
+	}
+
+	router := initRouter(&internal.SignHandler{
+		Keypair:           kp.(*keypair.Full),
+		NetworkPassphrase: cfg.NetworkPassphrase,
+		AuthToken:         cfg.AuthToken,
+		Policy:            &cfg.Policy,
+	})
+
+	addr := fmt.Sprintf("0.0.0.0:%d", cfg.Port)
+
+	http.Run(http.Config{
+		ListenAddr: addr,
+		Handler:    router,
+		TLS:        cfg.TLS,
+		OnStarting: func() {
+			log.Infof("starting signing server - %s", app.Version())
+			log.Infof("signing with %s", kp.Address())
+			log.Infof("listening on %s", addr)
+		},
+	})
+}
+
+func initRouter(handler *internal.SignHandler) *chi.Mux {
+	mux := http.NewAPIMux(false)
+
+	mux.Post("/sign", handler.ServeHTTP)
+	mux.NotFound(stdhttp.HandlerFunc(func(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+		problem.Render(r.Context(), w, problem.NotFound)
+	}))
+
+	return mux
+}
@@ -76,6 +76,50 @@ type orderbookStats struct {
 	SpreadMidPoint float64
 }
 
+// assetInput identifies an asset in the arguments of a query
+type assetInput struct {
+	Code   string
+	Issuer *string
+}
+
+// candle represents the OHLCV data of a pair of assets during
+// a time bucket
+type candle struct {
+	Timestamp     graphql.Time
+	Open          float64
+	High          float64
+	Low           float64
+	Close         float64
+	BaseVolume    float64
+	CounterVolume float64
+	TradeCount    int32
+}
+
+// trade represents a trade between a pair of assets, from the
+// point of view of the requested base asset
+type trade struct {
+	Cursor          string
+	AuroraID        string
+	LedgerCloseTime graphql.Time
+	BaseAccount     string
+	BaseAmount      float64
+	CounterAccount  string
+	CounterAmount   float64
+	BaseIsSeller    bool
+	Price           float64
+}
+
+// orderbook represents the orderbook stats of a pair of assets,
+// along with the orientation of the pair they are expressed in
+type orderbook struct {
+	BaseAssetCode      string
+	BaseAssetIssuer    string
+	CounterAssetCode   string
+	CounterAssetIssuer string
+	UpdatedAt          graphql.Time
+	OrderbookStats     orderbookStats
+}
+
 type resolver struct {
 	db     *tickerdb.TickerSession
 	logger *hlog.Entry
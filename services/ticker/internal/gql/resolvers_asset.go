@@ -2,6 +2,7 @@ package gql
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/diamnet/go/services/ticker/internal/tickerdb"
 )
@@ -22,6 +23,31 @@ func (r *resolver) Assets() (assets []*asset, err error) {
 	return
 }
 
+// resolveAsset returns the ID and the issuer account of the asset identified
+// by a, which must be in the database. The issuer account of XLM is "native".
+func (r *resolver) resolveAsset(a assetInput) (id int32, issuer string, err error) {
+	if a.Issuer != nil {
+		issuer = *a.Issuer
+	} else if a.Code == "XLM" {
+		issuer = "native"
+	} else {
+		err = fmt.Errorf("the issuer of asset %s must be provided", a.Code)
+		return
+	}
+
+	found, id, err := r.db.GetAssetByCodeAndIssuerAccount(a.Code, issuer)
+	if err != nil {
+		// obfuscating sql errors to avoid exposing underlying
+		// implementation
+		err = errors.New("could not retrieve the requested data")
+		return
+	}
+	if !found {
+		err = fmt.Errorf("asset %s:%s not found", a.Code, issuer)
+	}
+	return
+}
+
 // dbAssetToAsset converts a tickerdb.Asset to an *asset
 func dbAssetToAsset(dbAsset tickerdb.Asset) *asset {
 	return &asset{
@@ -0,0 +1,59 @@
+package gql
+
+import (
+	"errors"
+
+	"github.com/diamnet/go/services/ticker/internal/utils"
+	"github.com/graph-gophers/graphql-go"
+)
+
+// Orderbook resolves the orderbook() GraphQL query.
+func (r *resolver) Orderbook(args struct {
+	Base    assetInput
+	Counter assetInput
+}) (*orderbook, error) {
+	baseID, baseIssuer, err := r.resolveAsset(args.Base)
+	if err != nil {
+		return nil, err
+	}
+	counterID, counterIssuer, err := r.resolveAsset(args.Counter)
+	if err != nil {
+		return nil, err
+	}
+
+	found, dbStats, err := r.db.GetOrderbookStats(baseID, counterID)
+	if err != nil {
+		// obfuscating sql errors to avoid exposing underlying
+		// implementation
+		return nil, errors.New("could not retrieve the requested data")
+	}
+	if !found {
+		return nil, nil
+	}
+
+	spread, spreadMidPoint := utils.CalcSpread(dbStats.HighestBid, dbStats.LowestAsk)
+	ob := &orderbook{
+		BaseAssetCode:      args.Base.Code,
+		BaseAssetIssuer:    baseIssuer,
+		CounterAssetCode:   args.Counter.Code,
+		CounterAssetIssuer: counterIssuer,
+		UpdatedAt:          graphql.Time{Time: dbStats.UpdatedAt},
+		OrderbookStats: orderbookStats{
+			BidCount:       BigInt(dbStats.NumBids),
+			BidVolume:      dbStats.BidVolume,
+			BidMax:         dbStats.HighestBid,
+			AskCount:       BigInt(dbStats.NumAsks),
+			AskVolume:      dbStats.AskVolume,
+			AskMin:         dbStats.LowestAsk,
+			Spread:         spread,
+			SpreadMidPoint: spreadMidPoint,
+		},
+	}
+
+	// stats are stored for a single orientation of the pair
+	if dbStats.BaseAssetID != baseID {
+		ob.BaseAssetCode, ob.CounterAssetCode = ob.CounterAssetCode, ob.BaseAssetCode
+		ob.BaseAssetIssuer, ob.CounterAssetIssuer = ob.CounterAssetIssuer, ob.BaseAssetIssuer
+	}
+	return ob, nil
+}
@@ -0,0 +1,206 @@
+package gql
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/diamnet/go/services/ticker/internal/tickerdb"
+	"github.com/graph-gophers/graphql-go"
+)
+
+const (
+	defaultTradesLimit = 50
+	maxTradesLimit     = 200
+	maxCandles         = 1000
+)
+
+// candleResolutions are the accepted candle resolutions, in seconds
+var candleResolutions = []int32{60, 300, 900, 3600, 86400, 604800}
+
+// Candles resolves the candles() GraphQL query.
+func (r *resolver) Candles(args struct {
+	Base       assetInput
+	Counter    assetInput
+	Resolution int32
+	From       graphql.Time
+	To         *graphql.Time
+}) (candles []*candle, err error) {
+	to := time.Now()
+	if args.To != nil {
+		to = args.To.Time
+	}
+	err = validateCandlesRange(args.Resolution, args.From.Time, to)
+	if err != nil {
+		return
+	}
+
+	baseID, _, err := r.resolveAsset(args.Base)
+	if err != nil {
+		return
+	}
+	counterID, _, err := r.resolveAsset(args.Counter)
+	if err != nil {
+		return
+	}
+
+	dbCandles, err := r.db.RetrieveCandles(baseID, counterID, int(args.Resolution), args.From.Time, to)
+	if err != nil {
+		// obfuscating sql errors to avoid exposing underlying
+		// implementation
+		err = errors.New("could not retrieve the requested data")
+		return
+	}
+
+	candles = []*candle{}
+	for _, dbCandle := range dbCandles {
+		candles = append(candles, dbCandleToCandle(dbCandle))
+	}
+	return
+}
+
+// Trades resolves the trades() GraphQL query.
+func (r *resolver) Trades(args struct {
+	Base    assetInput
+	Counter assetInput
+	Cursor  *string
+	Limit   *int32
+}) (trades []*trade, err error) {
+	limit, err := validateTradesLimit(args.Limit)
+	if err != nil {
+		return
+	}
+
+	var beforeCloseTime *time.Time
+	var beforeID int32
+	if args.Cursor != nil {
+		var closeTime time.Time
+		closeTime, beforeID, err = parseTradeCursor(*args.Cursor)
+		if err != nil {
+			return
+		}
+		beforeCloseTime = &closeTime
+	}
+
+	baseID, _, err := r.resolveAsset(args.Base)
+	if err != nil {
+		return
+	}
+	counterID, _, err := r.resolveAsset(args.Counter)
+	if err != nil {
+		return
+	}
+
+	dbTrades, err := r.db.RetrieveTrades(baseID, counterID, beforeCloseTime, beforeID, limit)
+	if err != nil {
+		// obfuscating sql errors to avoid exposing underlying
+		// implementation
+		err = errors.New("could not retrieve the requested data")
+		return
+	}
+
+	trades = []*trade{}
+	for _, dbTrade := range dbTrades {
+		trades = append(trades, dbTradeToTrade(dbTrade))
+	}
+	return
+}
+
+// validateCandlesRange validates if the resolution is one of the accepted
+// ones and if the [from, to) range doesn't span more than maxCandles candles
+func validateCandlesRange(resolution int32, from, to time.Time) error {
+	valid := false
+	for _, r := range candleResolutions {
+		if resolution == r {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return errors.New("resolution must be one of 60, 300, 900, 3600, 86400 or 604800 seconds")
+	}
+
+	if !from.Before(to) {
+		return errors.New("from must be before to")
+	}
+
+	if to.Sub(from) > time.Duration(resolution)*time.Second*maxCandles {
+		return fmt.Errorf("the requested range cannot span more than %d candles", maxCandles)
+	}
+	return nil
+}
+
+// validateTradesLimit validates if the limit parameter is within the
+// accepted range (at most maxTradesLimit)
+func validateTradesLimit(n *int32) (int, error) {
+	if n == nil {
+		return defaultTradesLimit, nil
+	}
+
+	if *n > 0 && *n <= maxTradesLimit {
+		return int(*n), nil
+	}
+
+	return 0, fmt.Errorf("limit must be between 1 and %d", maxTradesLimit)
+}
+
+// tradeCursor returns the cursor of a trade, made of its ledger close time
+// and its ID, as trades are sorted by both.
+func tradeCursor(t tickerdb.Trade) string {
+	return fmt.Sprintf("%d-%d", t.LedgerCloseTime.UnixNano(), t.ID)
+}
+
+// parseTradeCursor parses a cursor returned by tradeCursor
+func parseTradeCursor(cursor string) (closeTime time.Time, id int32, err error) {
+	parts := strings.Split(cursor, "-")
+	if len(parts) != 2 {
+		err = errors.New("invalid cursor")
+		return
+	}
+
+	nanos, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		err = errors.New("invalid cursor")
+		return
+	}
+	id64, err := strconv.ParseInt(parts[1], 10, 32)
+	if err != nil {
+		err = errors.New("invalid cursor")
+		return
+	}
+
+	closeTime = time.Unix(0, nanos)
+	id = int32(id64)
+	return
+}
+
+// dbCandleToCandle converts a tickerdb.Candle to a *candle
+func dbCandleToCandle(dbCandle tickerdb.Candle) *candle {
+	return &candle{
+		Timestamp:     graphql.Time{Time: dbCandle.Timestamp},
+		Open:          dbCandle.Open,
+		High:          dbCandle.High,
+		Low:           dbCandle.Low,
+		Close:         dbCandle.Close,
+		BaseVolume:    dbCandle.BaseVolume,
+		CounterVolume: dbCandle.CounterVolume,
+		TradeCount:    dbCandle.TradeCount,
+	}
+}
+
+// dbTradeToTrade converts a tickerdb.Trade to a *trade
+func dbTradeToTrade(dbTrade tickerdb.Trade) *trade {
+	return &trade{
+		Cursor:          tradeCursor(dbTrade),
+		AuroraID:        dbTrade.AuroraID,
+		LedgerCloseTime: graphql.Time{Time: dbTrade.LedgerCloseTime},
+		BaseAccount:     dbTrade.BaseAccount,
+		BaseAmount:      dbTrade.BaseAmount,
+		CounterAccount:  dbTrade.CounterAccount,
+		CounterAmount:   dbTrade.CounterAmount,
+		BaseIsSeller:    dbTrade.BaseIsSeller,
+		Price:           dbTrade.Price,
+	}
+}
@@ -0,0 +1,60 @@
+package gql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/diamnet/go/services/ticker/internal/tickerdb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateCandlesRange(t *testing.T) {
+	from := time.Date(2019, 7, 1, 0, 0, 0, 0, time.UTC)
+
+	err := validateCandlesRange(60, from, from.Add(time.Hour))
+	assert.NoError(t, err)
+
+	err = validateCandlesRange(86400, from, from.Add(1000*24*time.Hour))
+	assert.NoError(t, err)
+
+	err = validateCandlesRange(61, from, from.Add(time.Hour))
+	assert.EqualError(t, err, "resolution must be one of 60, 300, 900, 3600, 86400 or 604800 seconds")
+
+	err = validateCandlesRange(60, from, from)
+	assert.EqualError(t, err, "from must be before to")
+
+	err = validateCandlesRange(60, from, from.Add(1001*time.Minute))
+	assert.EqualError(t, err, "the requested range cannot span more than 1000 candles")
+}
+
+func TestValidateTradesLimit(t *testing.T) {
+	limit, err := validateTradesLimit(nil)
+	require.NoError(t, err)
+	assert.Equal(t, 50, limit)
+
+	n := int32(200)
+	limit, err = validateTradesLimit(&n)
+	require.NoError(t, err)
+	assert.Equal(t, 200, limit)
+
+	for _, n := range []int32{0, -1, 201} {
+		_, err = validateTradesLimit(&n)
+		assert.EqualError(t, err, "limit must be between 1 and 200")
+	}
+}
+
+func TestTradeCursor(t *testing.T) {
+	closeTime := time.Date(2019, 7, 1, 10, 0, 0, 123456000, time.UTC)
+	cursor := tradeCursor(tickerdb.Trade{ID: 42, LedgerCloseTime: closeTime})
+
+	parsedTime, id, err := parseTradeCursor(cursor)
+	require.NoError(t, err)
+	assert.True(t, closeTime.Equal(parsedTime))
+	assert.Equal(t, int32(42), id)
+
+	for _, cursor := range []string{"", "42", "a-42", "1561975200123456000-a", "1-2-3"} {
+		_, _, err = parseTradeCursor(cursor)
+		assert.EqualError(t, err, "invalid cursor")
+	}
+}
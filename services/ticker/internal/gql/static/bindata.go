@@ -125,36 +125,53 @@ func bindataGraphiqlhtml() (*asset, error) {
 }
 
 var _bindataSchemagql = []byte(
-	"\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xe4\x54\xcd\x6e\xe3\x36\x10\x3e\x4b\x4f\x31\xc1\x5e\x36\x17\x1f\x8a\x9e" +
-		"\x84\xb6\x80\x93\xb4\x68\xd0\x78\xbb\x5d\x67\x8b\x02\x41\x51\x8c\xc5\xb1\x3c\x30\x45\x6a\x87\xa4\x13\x63\x91\x77" +
-		"\x2f\x48\xc9\x0e\x25\x65\xd3\x07\xe8\x49\x9a\x9f\x6f\x38\xf3\xf1\xe3\xb8\x7a\x47\x2d\xc2\xd7\xb2\xf8\x12\x48\x8e" +
-		"\x15\x14\x7f\xc4\x6f\xf9\x5c\x96\xfe\xd8\x11\x24\x2b\x86\xdf\x81\x90\x17\xa6\x03\x01\x6a\x0d\x07\xd4\xac\xd0\x93" +
-		"\x02\x74\x8e\xbc\x03\x6b\xc0\xef\x08\xd6\x9e\xb4\x46\x01\x43\xfe\xd1\xca\x7e\x51\x16\x7d\xbc\x82\x87\x65\xfc\xb9" +
-		"\xf8\xfb\xa2\x7c\xa3\x18\x3b\x17\x48\xde\xa8\x36\x24\x54\xf0\x70\x9b\xfe\x66\xf5\xbc\xa0\x22\x70\x1e\xbd\x83\xad" +
-		"\xd8\x36\xd5\xd1\xe8\x3c\xfc\x60\x42\xfb\xab\x0d\xe2\x96\x8d\xfd\x09\x76\xf1\x2f\x22\xdf\x2b\xda\x62\xd0\x1e\x7e" +
-		"\x84\xef\xbe\xef\xdd\x97\x0b\xb0\x9d\x67\x6b\x50\xeb\x23\x74\x62\x0f\xac\x08\x6a\x1b\x8c\x27\x01\x34\x2a\xe2\x36" +
-		"\xe8\xa8\x1f\x1e\xd8\x6c\x2d\x6c\xad\xc0\x96\xb5\x27\x61\xd3\x2c\xca\xa2\x45\xd9\x93\x77\xef\xcb\xa2\x88\xa9\x69" +
-		"\xfa\x6b\xab\xa8\x82\xb5\x8f\x29\xb9\xbf\x9f\x25\x8b\x0c\x67\xbd\x06\xca\x43\x33\x5c\x36\x62\x05\xb7\xc6\x97\xc5" +
-		"\x65\x05\x0f\xab\xd4\xca\x8c\xf9\xa6\x11\x6a\x12\xed\x23\xd2\xac\x7c\x83\xb3\x88\x4e\xfc\xbc\x4a\x4f\xc4\x18\x6c" +
-		"\x09\xec\x36\xfd\xf7\x35\x3b\x64\x81\xf7\xb4\x88\x8c\xbc\x83\xbf\xee\x56\xff\x5c\xdd\x5f\x5f\x8e\xc9\x02\x21\x17" +
-		"\xb4\x77\x8b\xb2\xf0\x5c\xef\x49\x22\x67\x11\xf8\x01\x5b\xfa\xcf\xe1\x96\xe7\x31\xce\x63\x3e\x97\xa5\xab\x31\x0a" +
-		"\xe7\x8a\x9b\x98\x38\x58\xf7\xdc\xd2\xa0\xeb\x44\x5f\xd4\x75\x9d\xb1\x7b\x71\xd2\xd7\xb2\x4e\x2c\x67\xfe\x08\xca" +
-		"\x4c\x13\xda\x21\xc7\xa5\x56\x2e\xca\x02\x83\xdf\x7d\xa2\x2f\x81\x85\x54\x05\x57\xd6\x6a\x42\x73\xf6\x1f\x6c\x8d" +
-		"\x1b\x4d\xa3\x40\xdb\x9f\xf1\x8b\xb6\x98\x0a\xf4\x97\x6d\xbc\x58\xad\x49\x5d\x1d\x6f\x6c\x8b\x6c\x46\x10\x53\xef" +
-		"\xec\x5c\x15\xe3\xc8\xfd\xb8\x55\x76\xc9\xbb\x4c\x09\xe3\xd6\x14\xbb\x4e\xe3\xf1\x86\x6a\x6e\x51\xbb\x6a\xa0\x2b" +
-		"\xce\x97\x31\x1f\x13\xc9\xd5\x99\x59\x5b\xa3\x38\x0a\xc0\x65\xce\x2d\x3f\x91\xfa\x10\xda\x4d\x14\xe4\xb9\x50\x8b" +
-		"\x4f\x33\x1f\xbb\xcf\x46\x73\xcb\x7e\xdc\x8d\x90\xa2\x36\xe9\xea\xd6\x38\x2f\xa1\x9e\x9e\x50\x5b\xad\xd1\x93\xa0" +
-		"\x5e\x2a\x25\xe4\x1c\xbd\x19\x5d\x73\x63\xd0\x07\x99\x64\x05\x13\xf5\x9f\xfb\xa2\xee\x83\x9b\x89\xe0\xf6\x66\xb8" +
-		"\xda\xd3\x2e\xec\xf5\x15\x45\x93\xb4\xfd\x11\x59\x32\xd0\xab\x8f\x3c\xf7\x8f\x1f\xeb\xa9\x97\x57\x1e\xf9\x24\x34" +
-		"\xc3\xc5\x8a\x7f\x5a\x1d\xe2\x15\x9d\xc4\x33\x00\xa6\xee\xd4\xe8\x75\xaf\xb3\x9e\x7c\xdb\x91\x79\x89\x6b\xfb\xf8" +
-		"\x62\xec\xb8\xd9\x65\x15\x77\x68\x9a\xfc\x04\x6d\x5d\x66\x72\x3c\xee\x80\x7a\xed\x51\x7c\x95\x9e\x56\x12\x81\x38" +
-		"\x7f\x47\xaa\x21\xb9\x8e\xf9\xd1\x7d\x0e\xc6\x8d\xf2\xad\x98\x15\x45\xb2\xb1\x76\xbf\x8e\x4b\xa8\x82\xdf\x47\xf6" +
-		"\xcb\x1d\x4c\x5f\xfb\x5b\xb7\xf1\x7f\xe5\x68\xec\x87\xaf\x25\x14\x1b\x56\xc3\x84\xe7\x57\xb8\x61\x35\x65\x62\xc3" +
-		"\x6a\x85\x4f\xf9\x46\xda\x4f\x51\xe8\xf6\x53\x14\xba\xfd\x8a\x33\xbe\x5c\x27\x84\x6a\x6a\xaf\x58\x7d\xb4\x9c\xed" +
-		"\xbb\x53\xb7\xbd\xbc\xe3\x3d\x76\x61\xa3\xb9\xfe\x8d\x8e\xf9\xa2\x1d\x2f\xa2\x20\x3a\x5f\xca\xb6\xd5\x9f\x3f\xdd" +
-		"\xe5\x4b\x88\x14\x09\xc6\xc5\xb1\x26\x39\x8c\x5e\x4d\xdc\xc3\x33\xa7\x17\x34\x6e\x4b\x32\x0b\x3c\xd2\x66\x19\xfc" +
-		"\xee\x67\xa3\xba\xbe\xeb\x6c\x17\x76\xd6\xb1\x9f\x21\xac\x34\xf7\x8f\xec\x7d\xee\x7c\x2e\xff\x0d\x00\x00\xff\xff" +
-		"\xd5\x4d\x39\xf2\x60\x09\x00\x00")
+	"\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xe4\x57\xdf\x6b\x1b\xb9\x13\x7f\xf6\xfe\x15\x13\xf2\x92\x40\x30\xe6\xdb" +
+		"\x7e\x43\xcf\xe4\x0c\x4e\x72\x47\xc3\x25\x6d\xaf\x49\x4b\xa1\x1c\x87\x76\x35\xf6\x0a\x6b\xa5\xad\x34\xb2\x63\x42" +
+		"\xff\xf7\x43\xd2\xae\xad\xdd\x75\x72\x94\x83\x7b\xb9\x17\x47\x9a\x5f\x9a\xf9\xcc\xaf\x8d\x2d\x4a\xac\x18\x3c\x65" +
+		"\xa3\x6f\x0e\xcd\x76\x0a\xa3\xdf\xfd\xdf\xec\x7b\x96\xd1\xb6\x46\x08\x37\xcf\x3e\x06\x83\x64\x04\xae\x11\x98\x94" +
+		"\xb0\x66\x52\x70\x46\xc8\x81\x59\x8b\x64\x41\x2b\xa0\x12\xe1\x5a\xb0\xea\x1d\x12\x28\xa4\x8d\x36\xab\x71\x36\x8a" +
+		"\xfc\x29\x7c\x9d\xfb\xc3\xd1\x1f\x47\xd9\x0b\xc6\x84\xb5\x0e\xcd\x0b\xd6\x1a\x81\x29\x7c\xbd\x09\xa7\x81\x3d\x32" +
+		"\x8c\x23\x58\x62\x64\x61\x61\x74\x15\xec\x48\x66\x09\x2e\x94\xab\xde\x6a\x67\xec\x7c\xa9\x67\x50\xfa\x93\xd7\x3c" +
+		"\xe1\xb8\x60\x4e\x12\xfc\x0c\xff\x7b\x1d\xc9\xa7\x63\xd0\x35\x09\xad\x98\x94\x5b\xa8\x8d\x5e\x0b\x8e\x50\x68\xa7" +
+		"\x08\x0d\x30\xc5\xbd\x5e\xce\x2c\xc6\xe0\x41\xa8\x85\x86\x85\x36\xb0\x10\x92\xd0\x08\xb5\x1c\x67\xa3\x8a\x99\x15" +
+		"\x92\x3d\xc9\x46\x23\x2f\x1a\xa2\xbf\xd2\x1c\xa7\x70\x4f\x5e\x24\xa5\xc7\x58\x12\x4e\xf3\xd6\x21\xa5\x94\x35\xd0" +
+		"\x4b\x42\x9c\xc2\x8d\xa2\x6c\x74\x3a\x85\xaf\x77\xc1\x95\x01\xf2\xcb\xa5\xc1\x65\x80\xbd\x03\x9a\x36\xcf\x60\xe6" +
+		"\xb5\x03\x3e\x07\xe1\xf1\x3a\x8a\x55\x08\x7a\x11\xce\xd1\x66\xcd\x84\x81\x13\x1c\x7b\x44\x8e\xe1\xcb\xed\xdd\x9f" +
+		"\x97\x0f\x57\xa7\x5d\xb0\xc0\xa0\x75\x92\xec\x38\x1b\x91\x28\x56\x68\x3c\x66\x5e\xf1\x1d\xab\xf0\x6f\x83\x9b\xef" +
+		"\xc2\x38\x1c\xa6\xf7\xe5\xfd\xdb\xdb\xab\xcf\x50\x30\xc5\x25\xda\x8e\x83\x16\x72\xa4\x0d\xa2\x82\x0b\x9f\x8d\x59" +
+		"\x9b\xdd\x8b\x06\xe6\x59\x2c\xa2\x0b\xff\x3b\x03\xa7\x48\x48\xb8\x20\x3d\x4b\xcb\x46\xe9\xcd\xe9\x19\xe4\xae\x58" +
+		"\x61\xa8\x61\xe5\x0d\x08\xaf\xbd\x66\x32\x3c\x77\x61\xd0\x6a\xe9\x3c\x66\x33\xb0\x58\x68\xc5\x2d\x9c\x9c\x4f\xce" +
+		"\xe0\xd5\x64\x72\x06\x3f\xf9\x9f\x57\xe7\xfe\xf7\xcd\xf9\xeb\xc9\xc4\xeb\x6b\x03\xe7\x93\xd7\x6f\x26\x93\xd3\x71" +
+		"\x62\x6b\x23\xa8\xd4\x8e\x5a\xdf\x99\x41\xd0\x95\x20\x42\x3e\xce\x46\x4d\x7c\x6d\xc5\x4d\x21\xd6\x88\xaa\x1d\x1d" +
+		"\xed\x0b\xa7\x4f\xde\xbb\x16\x40\xf5\x24\x1f\xec\x14\x1e\x44\x85\xfe\x46\x3a\x9e\x23\xdc\x57\xe1\x91\xa3\x43\x28" +
+		"\x3f\x0b\xe8\x1e\xcd\x33\xa8\xb4\x25\x30\x58\xa0\xcf\xdf\x31\x2c\x84\xb1\x34\xee\x14\x51\xe1\x8c\xd5\xa6\xcd\x52" +
+		"\x28\xc3\x58\x4b\x7a\x01\x0c\x6a\xb6\x44\x20\x3d\x78\x5c\xe1\x23\x81\x56\x38\x86\x0b\x29\x2a\x41\x33\x68\x12\x64" +
+		"\x81\x34\xfc\x7f\x02\x27\x15\x7b\xf4\x3d\xee\x11\xcd\x46\xd1\xd7\x1f\x84\x2a\x7a\x96\x54\x64\x78\x29\xa9\xc5\x07" +
+		"\x6f\xf5\x20\x36\xda\x70\x34\xb9\xd6\xab\xa6\xcb\x9a\xe8\x0e\x61\x14\x7a\x26\xb4\x4b\x14\xf5\x49\xc6\xc7\xda\xa0" +
+		"\xb5\xa1\xba\x1a\x7b\x02\x15\x31\x9f\x36\x70\x9e\x9e\x6f\x03\x3d\x36\xd0\x99\xd7\xde\x94\xa2\x28\xa1\x62\x5b\xc8" +
+		"\xa3\x0f\x06\xd7\x68\xec\xae\x41\x0d\x7e\x73\x68\x7d\xc5\x7a\xd8\xb2\xd1\xce\xc5\x1f\x43\xe5\x74\x0a\xef\x5b\x4d" +
+		"\xbf\x36\x6c\xc1\x24\x33\x70\x29\x96\x1e\x96\xe6\x16\xea\x27\x3b\x06\xa6\xe2\xc8\x3c\x03\xc1\x51\x91\x58\x88\xe8" +
+		"\xba\x20\x0b\x85\xe6\x18\x90\x88\x33\x1e\x58\x11\x1e\x1c\x07\x67\x23\x2d\x3b\xf6\x4d\x0c\xf9\xae\xea\xc3\x20\xf9" +
+		"\x72\x7b\x37\xce\x84\x77\x27\xf1\xcc\xef\xac\x22\x99\x9c\x47\xed\xee\x68\x09\xbb\x15\x17\x74\x9e\x15\x9f\x47\x2f" +
+		"\x12\xba\x57\x4a\xae\xca\x55\x8d\x8c\x6d\x1b\x88\x39\x2a\x3f\xe2\x37\x27\x0c\xf2\x29\x5c\x6a\x2d\x91\xa9\x1d\x7d" +
+		"\xad\x0b\x96\x4b\xec\x30\xaa\xf8\xc6\xaf\x52\xb3\x60\x20\xce\x7d\x45\x46\x4b\x89\xfc\x72\x7b\xad\x2b\x26\x54\x47" +
+		"\x45\x15\xa5\x1e\x2e\x88\x2e\xe7\xa1\xeb\xaa\xb0\x81\x3a\x0f\x02\x5d\xd7\xb8\xb0\xb5\x64\xdb\x6b\x2c\x44\xc5\xa4" +
+		"\x9d\x36\xf9\xf3\xf1\x25\x43\xd8\x0b\xa2\x2d\x92\xab\x1f\x65\xc2\x57\xa1\x4d\x88\x0b\xf1\x88\xfc\x9d\xab\x72\x34" +
+		"\x89\xa1\x8a\x3d\x0e\x68\xc2\x7e\x52\xa1\x89\xba\xde\x18\xe4\x58\x85\x15\x73\xa3\x2c\x19\x57\xf4\x5f\x28\xb4\x94" +
+		"\x8c\xd0\x30\x39\xe7\x3c\x74\xc6\x8b\xdc\x7b\xb1\x54\x8c\x9c\xe9\x49\x39\xe5\x3b\x34\xa5\xf9\x8e\x73\x76\x50\x04" +
+		"\x37\xd7\x4d\x6a\xdb\x9a\x89\xab\x06\x9e\x9a\x41\xf2\x81\x09\x93\x28\x1d\xdc\xf7\x29\xbd\xbb\xb7\x8f\xb2\xce\x52" +
+		"\xef\x29\x3d\xbf\xef\x1b\x8b\x9f\xb5\x74\x15\xee\x8b\xa7\x51\xe8\x93\x83\xa3\x57\xb1\xce\x22\xf8\xba\x46\xb5\xe7" +
+		"\x4b\xbd\xd9\x5f\x4a\xb1\x2c\x13\x8b\x25\x53\xcb\xf4\x05\xa9\x6d\x72\x6d\xd7\xd3\x3d\x31\x43\xbb\xbd\x11\x26\xfb" +
+		"\x2d\xf2\x25\x9a\x2b\x2f\xef\xc9\x3b\xa6\x64\xcf\xf3\x76\x73\xe8\x9e\x18\xd9\x64\xba\x84\xfb\x3e\x07\xfd\xc5\xff" +
+		"\x52\x36\xfe\xab\x18\x75\xe9\xf0\x94\xc1\x28\x17\xbc\x89\x70\xd7\x85\xb9\xe0\x7d\x24\x72\xc1\xef\xd8\x63\x3a\x91" +
+		"\x56\x7d\x2d\x66\x57\x7d\x2d\x66\x57\x77\x22\xc1\xcb\xd6\x06\x19\xef\xdf\xef\x04\xff\xa0\x45\x32\xef\x5a\x6f\xe3" +
+		"\xb7\x45\xc8\xa3\xa8\xd0\x12\xab\xea\x7d\xc4\x9d\x44\x74\xb1\xef\xa4\xa5\x07\xfc\x3f\xc9\x7e\xeb\x57\x58\xeb\x61" +
+		"\x43\x74\x3e\x00\xc2\x44\x37\xda\xb0\x9b\xeb\x84\x24\x9f\x49\x59\x68\xfe\xc1\x32\x09\xd4\xde\xf4\x6f\x3b\x7e\x20" +
+		"\xdc\x32\x7a\xf2\xde\xc6\x8d\xbd\x47\x29\xd1\xa4\x53\xb4\x36\xa2\xc0\x01\xc8\xbb\x92\x80\xa7\xa8\xfa\xef\x4c\x2a" +
+		"\x57\x87\xff\xf0\xe6\xf4\xc3\x35\x1c\x4d\x79\x6f\x6b\x97\x4b\x51\xfc\x86\xdb\xc4\x6e\x6f\x3d\x39\x23\x93\x1b\xe9" +
+		"\x4a\x7e\xfa\x78\x9b\xae\x26\xe4\x68\xc2\x67\xd3\x3d\x9a\x35\x76\x73\x49\xe5\x80\x48\x86\x29\xbb\x40\x33\x60\x6c" +
+		"\x30\x9f\x3b\x2a\x7f\x51\xbc\x8e\xb5\x9c\x6c\xc8\x5a\x5b\x41\x03\x0d\x6d\x96\x0f\x1b\x41\x94\x12\xbf\x67\x7f\x0d" +
+		"\x00\xc6\x77\x79\xdd\x81\x0f\x00\x00")
 
 func bindataSchemagqlBytes() ([]byte, error) {
 	return bindataRead(
@@ -171,10 +188,10 @@ func bindataSchemagql() (*asset, error) {
 
 	info := bindataFileInfo{
 		name:        "schema.gql",
-		size:        2400,
+		size:        3969,
 		md5checksum: "",
 		mode:        os.FileMode(420),
-		modTime:     time.Unix(1557448839, 0),
+		modTime:     time.Unix(1792201121, 0),
 	}
 
 	a := &asset{bytes: bytes, info: info}
@@ -160,6 +160,20 @@ type PartialMarket struct {
 	LastLedgerCloseTime  time.Time `db:"last_ledger_close_time"`
 }
 
+// Candle represents the OHLCV data of the trades of a pair of assets
+// during a time bucket.
+// Note: this struct does *not* directly map to a db entity.
+type Candle struct {
+	Timestamp     time.Time `db:"timestamp"`
+	Open          float64   `db:"open_price"`
+	High          float64   `db:"highest_price"`
+	Low           float64   `db:"lowest_price"`
+	Close         float64   `db:"close_price"`
+	BaseVolume    float64   `db:"base_volume"`
+	CounterVolume float64   `db:"counter_volume"`
+	TradeCount    int32     `db:"trade_count"`
+}
+
 // CreateSession returns a new TickerSession that connects to the given db settings
 func CreateSession(driverName, dataSourceName string) (session TickerSession, err error) {
 	dbconn, err := sqlx.Connect(driverName, dataSourceName)
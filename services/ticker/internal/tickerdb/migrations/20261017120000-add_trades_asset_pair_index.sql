@@ -0,0 +1,5 @@
+-- +migrate Up
+CREATE INDEX trades_base_counter_ledger_close_time_idx ON public.trades (base_asset_id, counter_asset_id, ledger_close_time);
+
+-- +migrate Down
+DROP INDEX IF EXISTS trades_base_counter_ledger_close_time_idx;
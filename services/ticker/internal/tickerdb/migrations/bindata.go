@@ -10,6 +10,7 @@
 // migrations/20190411165735-data_seed_and_indices.sql
 // migrations/20190425110313-add_orderbook_stats.sql
 // migrations/20190426092321-add_aggregated_orderbook_view.sql
+// migrations/20261017120000-add_trades_asset_pair_index.sql
 
 package bdata
 
@@ -483,6 +484,40 @@ func bindataMigrations20190426092321addaggregatedorderbookviewsql() (*asset, err
 	return a, nil
 }
 
+var _bindataMigrations20261017120000addtradesassetpairindexsql = []byte(
+	"\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xd2\xd5\x55\xd0\xce\xcd\x4c\x2f\x4a\x2c\x49\x55\x08\x2d\xe0\x72\x0e\x72" +
+		"\x75\x0c\x71\x55\xf0\xf4\x73\x71\x8d\x50\x28\x29\x4a\x4c\x49\x2d\x8e\x4f\x4a\x2c\x4e\x8d\x4f\xce\x2f\xcd\x2b\x49" +
+		"\x2d\x8a\xcf\x49\x4d\x49\x4f\x2d\x8a\x4f\xce\xc9\x2f\x4e\x8d\x2f\xc9\xcc\x4d\x8d\xcf\x4c\xa9\x50\xf0\xf7\x53\x28" +
+		"\x28\x4d\xca\xc9\x4c\xd6\x83\xe8\x51\xd0\x00\x6b\x4a\x2c\x2e\x4e\x2d\x89\xcf\x4c\xd1\x51\x80\x69\x47\x88\x60\x18" +
+		"\xa4\x69\xcd\xc5\x85\xec\x1a\x97\xfc\xf2\x3c\x2e\x97\x20\xff\x00\xa8\x6b\x3c\xdd\x14\x5c\x23\x3c\x83\x43\x82\x89" +
+		"\x77\x97\x35\x17\x60\x00\x0f\xb5\xe7\x37\xdf\x00\x00\x00")
+
+func bindataMigrations20261017120000addtradesassetpairindexsqlBytes() ([]byte, error) {
+	return bindataRead(
+		_bindataMigrations20261017120000addtradesassetpairindexsql,
+		"migrations/20261017120000-add_trades_asset_pair_index.sql",
+	)
+}
+
+func bindataMigrations20261017120000addtradesassetpairindexsql() (*asset, error) {
+	bytes, err := bindataMigrations20261017120000addtradesassetpairindexsqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{
+		name:        "migrations/20261017120000-add_trades_asset_pair_index.sql",
+		size:        223,
+		md5checksum: "",
+		mode:        os.FileMode(420),
+		modTime:     time.Unix(1792201087, 0),
+	}
+
+	a := &asset{bytes: bytes, info: info}
+
+	return a, nil
+}
+
 //
 // Asset loads and returns the asset for the given name.
 // It returns an error if the asset could not be found or
@@ -556,6 +591,7 @@ var _bindata = map[string]func() (*asset, error){
 	"migrations/20190411165735-data_seed_and_indices.sql":           bindataMigrations20190411165735dataseedandindicessql,
 	"migrations/20190425110313-add_orderbook_stats.sql":             bindataMigrations20190425110313addorderbookstatssql,
 	"migrations/20190426092321-add_aggregated_orderbook_view.sql":   bindataMigrations20190426092321addaggregatedorderbookviewsql,
+	"migrations/20261017120000-add_trades_asset_pair_index.sql":     bindataMigrations20261017120000addtradesassetpairindexsql,
 }
 
 //
@@ -620,6 +656,7 @@ var _bintree = &bintree{Func: nil, Children: map[string]*bintree{
 		"20190411165735-data_seed_and_indices.sql":           {Func: bindataMigrations20190411165735dataseedandindicessql, Children: map[string]*bintree{}},
 		"20190425110313-add_orderbook_stats.sql":             {Func: bindataMigrations20190425110313addorderbookstatssql, Children: map[string]*bintree{}},
 		"20190426092321-add_aggregated_orderbook_view.sql":   {Func: bindataMigrations20190426092321addaggregatedorderbookviewsql, Children: map[string]*bintree{}},
+		"20261017120000-add_trades_asset_pair_index.sql":     {Func: bindataMigrations20261017120000addtradesassetpairindexsql, Children: map[string]*bintree{}},
 	}},
 }}
 
@@ -5,3 +5,30 @@ package tickerdb
 func (s *TickerSession) InsertOrUpdateOrderbookStats(o *OrderbookStats, preserveFields []string) (err error) {
 	return s.performUpsertQuery(*o, "orderbook_stats", "orderbook_stats_base_counter_asset_key", preserveFields)
 }
+
+// GetOrderbookStats returns the orderbook stats of the pair of assets with
+// IDs baseAssetID and counterAssetID. Stats are stored for a single
+// orientation of each pair, so the returned stats may have the base and
+// counter assets swapped.
+func (s *TickerSession) GetOrderbookStats(
+	baseAssetID int32,
+	counterAssetID int32,
+) (found bool, stats OrderbookStats, err error) {
+	var obStats []OrderbookStats
+	err = s.SelectRaw(&obStats, `
+		SELECT * FROM orderbook_stats
+		WHERE (base_asset_id = ? AND counter_asset_id = ?) OR (base_asset_id = ? AND counter_asset_id = ?)
+		ORDER BY base_asset_id = ? DESC
+		LIMIT 1`,
+		baseAssetID, counterAssetID, counterAssetID, baseAssetID, baseAssetID,
+	)
+	if err != nil {
+		return
+	}
+
+	if len(obStats) > 0 {
+		stats = obStats[0]
+		found = true
+	}
+	return
+}
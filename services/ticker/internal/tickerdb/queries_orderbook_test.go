@@ -177,3 +177,82 @@ func TestInsertOrUpdateOrderbokStats(t *testing.T) {
 	assert.Equal(t, 0.7, dbOS2.SpreadMidPoint)
 	assert.WithinDuration(t, obTime2.Local(), dbOS2.UpdatedAt.Local(), 10*time.Millisecond)
 }
+
+func TestGetOrderbookStats(t *testing.T) {
+	db := dbtest.Postgres(t)
+	defer db.Close()
+
+	var session TickerSession
+	session.DB = db.Open()
+	defer session.DB.Close()
+
+	// Run migrations to make sure the tests are run
+	// on the most updated schema version
+	migrations := &migrate.FileMigrationSource{
+		Dir: "./migrations",
+	}
+	_, err := migrate.Exec(session.DB.DB, "postgres", migrations, migrate.Up)
+	require.NoError(t, err)
+
+	// Adding a seed issuer to be used later:
+	tbl := session.GetTable("issuers")
+	_, err = tbl.Insert(Issuer{
+		PublicKey: "GCF3TQXKZJNFJK7HCMNE2O2CUNKCJH2Y2ROISTBPLC7C5EIA5NNG2XZB",
+		Name:      "FOO BAR",
+	}).IgnoreCols("id").Exec()
+	require.NoError(t, err)
+	var issuer Issuer
+	err = session.GetRaw(&issuer, `
+		SELECT *
+		FROM issuers
+		ORDER BY id DESC
+		LIMIT 1`,
+	)
+	require.NoError(t, err)
+
+	// Adding the assets of the pair:
+	var assets []Asset
+	for _, code := range []string{"BTC", "ETH"} {
+		err = session.InsertOrUpdateAsset(&Asset{
+			Code:     code,
+			IssuerID: issuer.ID,
+		}, []string{"code", "issuer_id"})
+		require.NoError(t, err)
+		var asset Asset
+		err = session.GetRaw(&asset, `
+			SELECT *
+			FROM assets
+			ORDER BY id DESC
+			LIMIT 1`,
+		)
+		require.NoError(t, err)
+		assets = append(assets, asset)
+	}
+	btc, eth := assets[0], assets[1]
+
+	found, _, err := session.GetOrderbookStats(btc.ID, eth.ID)
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	err = session.InsertOrUpdateOrderbookStats(&OrderbookStats{
+		BaseAssetID:    btc.ID,
+		CounterAssetID: eth.ID,
+		NumBids:        15,
+		HighestBid:     20.0,
+		NumAsks:        17,
+		LowestAsk:      21.0,
+		UpdatedAt:      time.Now(),
+	}, []string{"base_asset_id", "counter_asset_id"})
+	require.NoError(t, err)
+
+	// Stats are found for both orientations of the pair:
+	for _, pair := range [][2]int32{{btc.ID, eth.ID}, {eth.ID, btc.ID}} {
+		found, stats, err := session.GetOrderbookStats(pair[0], pair[1])
+		require.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, btc.ID, stats.BaseAssetID)
+		assert.Equal(t, eth.ID, stats.CounterAssetID)
+		assert.Equal(t, 15, stats.NumBids)
+		assert.Equal(t, 21.0, stats.LowestAsk)
+	}
+}
@@ -1,6 +1,7 @@
 package tickerdb
 
 import (
+	"fmt"
 	"math"
 	"strings"
 	"time"
@@ -37,6 +38,80 @@ func (s *TickerSession) DeleteOldTrades(minDate time.Time) error {
 	return err
 }
 
+// RetrieveTrades retrieves up to limit trades between the assets with IDs
+// baseAssetID and counterAssetID, most recent first. Trades are returned
+// with baseAssetID as base asset, even if they are stored the other way
+// around. If beforeCloseTime is not nil, only trades older than
+// (beforeCloseTime, beforeID) are returned.
+func (s *TickerSession) RetrieveTrades(
+	baseAssetID int32,
+	counterAssetID int32,
+	beforeCloseTime *time.Time,
+	beforeID int32,
+	limit int,
+) (trades []Trade, err error) {
+	q := `
+		SELECT * FROM trades
+		WHERE ((base_asset_id = ? AND counter_asset_id = ?) OR (base_asset_id = ? AND counter_asset_id = ?))
+	`
+	args := []interface{}{baseAssetID, counterAssetID, counterAssetID, baseAssetID}
+	if beforeCloseTime != nil {
+		q += " AND (ledger_close_time, id) < (?, ?)"
+		args = append(args, *beforeCloseTime, beforeID)
+	}
+	q += " ORDER BY ledger_close_time DESC, id DESC LIMIT ?"
+	args = append(args, limit)
+
+	err = s.SelectRaw(&trades, q, args...)
+	if err != nil {
+		return
+	}
+
+	for i, trade := range trades {
+		if trade.BaseAssetID != baseAssetID {
+			trades[i] = invertTrade(trade)
+		}
+	}
+	return
+}
+
+// RetrieveCandles retrieves the OHLCV candles of the trades between the
+// assets with IDs baseAssetID and counterAssetID that happened in [from, to),
+// bucketed in intervals of resolution seconds. Prices and volumes are
+// expressed with baseAssetID as base asset. Buckets without trades are
+// omitted.
+func (s *TickerSession) RetrieveCandles(
+	baseAssetID int32,
+	counterAssetID int32,
+	resolution int,
+	from time.Time,
+	to time.Time,
+) (candles []Candle, err error) {
+	q := fmt.Sprintf(candlesQuery, resolution, resolution)
+	err = s.SelectRaw(
+		&candles,
+		q,
+		baseAssetID, baseAssetID, baseAssetID,
+		baseAssetID, counterAssetID, counterAssetID, baseAssetID,
+		from, to,
+	)
+	return
+}
+
+// invertTrade swaps the base and counter sides of a trade.
+func invertTrade(t Trade) Trade {
+	inverted := t
+	inverted.BaseOfferID, inverted.CounterOfferID = t.CounterOfferID, t.BaseOfferID
+	inverted.BaseAccount, inverted.CounterAccount = t.CounterAccount, t.BaseAccount
+	inverted.BaseAmount, inverted.CounterAmount = t.CounterAmount, t.BaseAmount
+	inverted.BaseAssetID, inverted.CounterAssetID = t.CounterAssetID, t.BaseAssetID
+	inverted.BaseIsSeller = !t.BaseIsSeller
+	if t.Price != 0 {
+		inverted.Price = 1 / t.Price
+	}
+	return inverted
+}
+
 // chunkifyDBTrades transforms a slice into a slice of chunks (also slices) of chunkSize
 // e.g.: Chunkify([b, c, d, e, f], 2) = [[b c] [d e] [f]]
 func chunkifyDBTrades(sl []Trade, chunkSize int) [][]Trade {
@@ -86,3 +161,29 @@ func performInsertTrades(s *TickerSession, trades []Trade) (err error) {
 	_, err = s.ExecRaw(qs, dbValues...)
 	return
 }
+
+var candlesQuery = `
+WITH pair_trades AS (
+	SELECT
+		t.id,
+		t.ledger_close_time,
+		CASE WHEN t.base_asset_id = ? THEN t.base_amount ELSE t.counter_amount END AS base_amount,
+		CASE WHEN t.base_asset_id = ? THEN t.counter_amount ELSE t.base_amount END AS counter_amount,
+		CASE WHEN t.base_asset_id = ? THEN t.price ELSE 1.0 / NULLIF(t.price, 0) END AS price
+	FROM trades AS t
+	WHERE ((t.base_asset_id = ? AND t.counter_asset_id = ?) OR (t.base_asset_id = ? AND t.counter_asset_id = ?))
+		AND t.ledger_close_time >= ? AND t.ledger_close_time < ?
+)
+SELECT
+	to_timestamp(floor(extract(epoch from ledger_close_time) / %d) * %d) AS timestamp,
+	COALESCE((array_agg(price ORDER BY ledger_close_time ASC, id ASC))[1], 0.0) AS open_price,
+	COALESCE(max(price), 0.0) AS highest_price,
+	COALESCE(min(price), 0.0) AS lowest_price,
+	COALESCE((array_agg(price ORDER BY ledger_close_time DESC, id DESC))[1], 0.0) AS close_price,
+	sum(base_amount) AS base_volume,
+	sum(counter_amount) AS counter_volume,
+	count(*) AS trade_count
+FROM pair_trades
+GROUP BY 1
+ORDER BY 1 ASC;
+`
@@ -347,3 +347,176 @@ func TestDeleteOldTrades(t *testing.T) {
 	assert.WithinDuration(t, now.Local(), trade1.LedgerCloseTime.Local(), 10*time.Millisecond)
 	assert.WithinDuration(t, oneDayAgo.Local(), trade2.LedgerCloseTime.Local(), 10*time.Millisecond)
 }
+
+func TestRetrieveTradesAndCandles(t *testing.T) {
+	db := dbtest.Postgres(t)
+	defer db.Close()
+
+	var session TickerSession
+	session.DB = db.Open()
+	defer session.DB.Close()
+
+	// Run migrations to make sure the tests are run
+	// on the most updated schema version
+	migrations := &migrate.FileMigrationSource{
+		Dir: "./migrations",
+	}
+	_, err := migrate.Exec(session.DB.DB, "postgres", migrations, migrate.Up)
+	require.NoError(t, err)
+
+	// Adding a seed issuer to be used later:
+	tbl := session.GetTable("issuers")
+	_, err = tbl.Insert(Issuer{
+		PublicKey: "GCF3TQXKZJNFJK7HCMNE2O2CUNKCJH2Y2ROISTBPLC7C5EIA5NNG2XZB",
+		Name:      "FOO BAR",
+	}).IgnoreCols("id").Exec()
+	require.NoError(t, err)
+	var issuer Issuer
+	err = session.GetRaw(&issuer, `
+		SELECT *
+		FROM issuers
+		ORDER BY id DESC
+		LIMIT 1`,
+	)
+	require.NoError(t, err)
+
+	// Adding the assets of the pair:
+	var assets []Asset
+	for _, code := range []string{"BTC", "ETH"} {
+		err = session.InsertOrUpdateAsset(&Asset{
+			Code:     code,
+			IssuerID: issuer.ID,
+		}, []string{"code", "issuer_id"})
+		require.NoError(t, err)
+		var asset Asset
+		err = session.GetRaw(&asset, `
+			SELECT *
+			FROM assets
+			ORDER BY id DESC
+			LIMIT 1`,
+		)
+		require.NoError(t, err)
+		assets = append(assets, asset)
+	}
+	btc, eth := assets[0], assets[1]
+	assert.NotEqual(t, btc.ID, eth.ID)
+
+	// The last trade is stored the other way around, as ETH/BTC:
+	start := time.Date(2019, 7, 1, 10, 0, 0, 0, time.UTC)
+	trades := []Trade{
+		Trade{
+			AuroraID:        "hrzid1",
+			BaseAssetID:     btc.ID,
+			BaseAmount:      1.0,
+			CounterAssetID:  eth.ID,
+			CounterAmount:   10.0,
+			Price:           10.0,
+			LedgerCloseTime: start,
+		},
+		Trade{
+			AuroraID:        "hrzid2",
+			BaseAssetID:     btc.ID,
+			BaseAmount:      2.0,
+			CounterAssetID:  eth.ID,
+			CounterAmount:   40.0,
+			Price:           20.0,
+			LedgerCloseTime: start.Add(30 * time.Second),
+		},
+		Trade{
+			AuroraID:        "hrzid3",
+			BaseAssetID:     eth.ID,
+			BaseAmount:      50.0,
+			BaseIsSeller:    true,
+			CounterAssetID:  btc.ID,
+			CounterAmount:   10.0,
+			Price:           0.2,
+			LedgerCloseTime: start.Add(90 * time.Second),
+		},
+	}
+	err = session.BulkInsertTrades(trades)
+	require.NoError(t, err)
+
+	// Trades are returned most recent first, from the point of view of
+	// the requested base asset:
+	dbTrades, err := session.RetrieveTrades(btc.ID, eth.ID, nil, 0, 2)
+	require.NoError(t, err)
+	require.Len(t, dbTrades, 2)
+	assert.Equal(t, "hrzid3", dbTrades[0].AuroraID)
+	assert.Equal(t, btc.ID, dbTrades[0].BaseAssetID)
+	assert.Equal(t, 10.0, dbTrades[0].BaseAmount)
+	assert.Equal(t, 50.0, dbTrades[0].CounterAmount)
+	assert.InDelta(t, 5.0, dbTrades[0].Price, 1e-9)
+	assert.False(t, dbTrades[0].BaseIsSeller)
+	assert.Equal(t, "hrzid2", dbTrades[1].AuroraID)
+
+	// Paginating from the last trade of the first page:
+	beforeCloseTime := dbTrades[1].LedgerCloseTime
+	dbTrades, err = session.RetrieveTrades(btc.ID, eth.ID, &beforeCloseTime, dbTrades[1].ID, 2)
+	require.NoError(t, err)
+	require.Len(t, dbTrades, 1)
+	assert.Equal(t, "hrzid1", dbTrades[0].AuroraID)
+
+	// The first two trades share the first 1-minute candle:
+	candles, err := session.RetrieveCandles(btc.ID, eth.ID, 60, start, start.Add(time.Hour))
+	require.NoError(t, err)
+	require.Len(t, candles, 2)
+	assert.True(t, start.Equal(candles[0].Timestamp))
+	assert.Equal(t, 10.0, candles[0].Open)
+	assert.Equal(t, 20.0, candles[0].High)
+	assert.Equal(t, 10.0, candles[0].Low)
+	assert.Equal(t, 20.0, candles[0].Close)
+	assert.Equal(t, 3.0, candles[0].BaseVolume)
+	assert.Equal(t, 50.0, candles[0].CounterVolume)
+	assert.Equal(t, int32(2), candles[0].TradeCount)
+	assert.True(t, start.Add(time.Minute).Equal(candles[1].Timestamp))
+	assert.InDelta(t, 5.0, candles[1].Open, 1e-9)
+	assert.Equal(t, 10.0, candles[1].BaseVolume)
+	assert.Equal(t, 50.0, candles[1].CounterVolume)
+	assert.Equal(t, int32(1), candles[1].TradeCount)
+
+	// Candles of the reversed pair:
+	candles, err = session.RetrieveCandles(eth.ID, btc.ID, 3600, start, start.Add(time.Hour))
+	require.NoError(t, err)
+	require.Len(t, candles, 1)
+	assert.InDelta(t, 0.1, candles[0].Open, 1e-9)
+	assert.InDelta(t, 0.05, candles[0].Low, 1e-9)
+	assert.InDelta(t, 0.2, candles[0].Close, 1e-9)
+	assert.Equal(t, 100.0, candles[0].BaseVolume)
+	assert.Equal(t, 13.0, candles[0].CounterVolume)
+	assert.Equal(t, int32(3), candles[0].TradeCount)
+
+	// Trades outside of the requested range are ignored:
+	candles, err = session.RetrieveCandles(btc.ID, eth.ID, 60, start.Add(time.Hour), start.Add(2*time.Hour))
+	require.NoError(t, err)
+	assert.Len(t, candles, 0)
+}
+
+func TestInvertTrade(t *testing.T) {
+	trade := Trade{
+		BaseOfferID:    "1",
+		BaseAccount:    "base",
+		BaseAmount:     1.0,
+		BaseAssetID:    1,
+		CounterOfferID: "2",
+		CounterAccount: "counter",
+		CounterAmount:  4.0,
+		CounterAssetID: 2,
+		BaseIsSeller:   true,
+		Price:          4.0,
+	}
+
+	inverted := invertTrade(trade)
+	assert.Equal(t, Trade{
+		BaseOfferID:    "2",
+		BaseAccount:    "counter",
+		BaseAmount:     4.0,
+		BaseAssetID:    2,
+		CounterOfferID: "1",
+		CounterAccount: "base",
+		CounterAmount:  1.0,
+		CounterAssetID: 1,
+		BaseIsSeller:   false,
+		Price:          0.25,
+	}, inverted)
+	assert.Equal(t, trade, invertTrade(inverted))
+}
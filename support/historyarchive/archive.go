@@ -36,6 +36,14 @@ type ConnectOptions struct {
 	S3Region         string
 	S3Endpoint       string
 	UnsignedRequests bool
+	// CacheDir is a local directory where files read from the archive are
+	// cached, see CachingArchiveBackend. Files are not cached when empty.
+	// Every archive is cached in its own subdirectory, named after the hash
+	// of its URL, so archives sharing CacheDir never read each other's files.
+	CacheDir string
+	// CacheSize is the maximum size of the cached files in bytes,
+	// DefaultCacheSize is used when 0.
+	CacheSize int64
 }
 
 type ArchiveBackend interface {
@@ -78,11 +86,11 @@ type Archive struct {
 	expectTxResultSetHashes map[uint32]Hash
 	actualTxResultSetHashes map[uint32]Hash
 
-	invalidBuckets int
+	invalidBuckets map[Hash]bool
 
-	invalidLedgers      int
-	invalidTxSets       int
-	invalidTxResultSets int
+	invalidLedgers      []uint32
+	invalidTxSets       []uint32
+	invalidTxResultSets []uint32
 
 	backend ArchiveBackend
 }
@@ -226,6 +234,7 @@ func Connect(u string, opts ConnectOptions) (*Archive, error) {
 		actualTxSetHashes:       make(map[uint32]Hash),
 		expectTxResultSetHashes: make(map[uint32]Hash),
 		actualTxResultSetHashes: make(map[uint32]Hash),
+		invalidBuckets:          make(map[Hash]bool),
 	}
 	for _, cat := range Categories() {
 		arch.checkpointFiles[cat] = make(map[uint32]bool)
@@ -256,7 +265,17 @@ func Connect(u string, opts ConnectOptions) (*Archive, error) {
 	} else {
 		err = errors.New("unknown URL scheme: '" + parsed.Scheme + "'")
 	}
-	return &arch, err
+	if err != nil {
+		return &arch, err
+	}
+	if opts.CacheDir != "" {
+		cache, err := MakeCachingBackend(arch.backend, archiveCacheDir(opts.CacheDir, u), opts.CacheSize)
+		if err != nil {
+			return &arch, err
+		}
+		arch.backend = cache
+	}
+	return &arch, nil
 }
 
 func MustConnect(u string, opts ConnectOptions) *Archive {
@@ -148,6 +148,21 @@ func TestScan(t *testing.T) {
 	GetRandomPopulatedArchive().Scan(opts)
 }
 
+func TestScanCheckpointsExcludeHigh(t *testing.T) {
+	defer cleanup()
+	opts := testOptions()
+	for _, scan := range []func(*Archive, *CommandOptions) error{
+		(*Archive).ScanCheckpointsFast,
+		(*Archive).ScanCheckpointsSlow,
+	} {
+		arch := GetRandomPopulatedArchive()
+		assert.NoError(t, arch.AddRandomCheckpoint(opts.Range.High))
+		assert.NoError(t, scan(arch, opts))
+		assert.True(t, arch.checkpointFiles["history"][opts.Range.High-CheckpointFreq])
+		assert.False(t, arch.checkpointFiles["history"][opts.Range.High])
+	}
+}
+
 func countMissing(arch *Archive, opts *CommandOptions) int {
 	n := 0
 	arch.Scan(opts)
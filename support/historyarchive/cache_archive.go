@@ -0,0 +1,341 @@
+// Copyright 2016 DiamNet Development Foundation and contributors. Licensed
+// under the Apache License, Version 2.0. See the COPYING file at the root
+// of this distribution or at http://www.apache.org/licenses/LICENSE-2.0
+
+package historyarchive
+
+import (
+	"compress/gzip"
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/diamnet/go/support/errors"
+)
+
+// DefaultCacheSize is the maximum size of the files stored by a
+// CachingArchiveBackend when no size is given.
+const DefaultCacheSize = 10 << 30
+
+// cacheTempPrefix is the prefix of the files being downloaded to the cache
+// directory. They are removed when a CachingArchiveBackend is created.
+const cacheTempPrefix = ".download-"
+
+var bucketPathRx = regexp.MustCompile("^bucket" + hexPrefixPat + "bucket-([0-9a-f]{64})\\.xdr\\.gz$")
+
+// CachingArchiveBackend is an ArchiveBackend storing the files read from
+// another backend in a local directory, keyed by their path in the archive.
+// Files published in history archives never change so they are cached until
+// the total size of the cache exceeds its limit, when the least recently used
+// files are removed. The root history archive state is the only file that is
+// never cached.
+//
+// Bucket files are named after the hash of their content: their hash is
+// verified when they are downloaded and every time they are read from the
+// cache, corrupted files are downloaded again.
+type CachingArchiveBackend struct {
+	upstream ArchiveBackend
+	dir      string
+	maxSize  int64
+
+	mutex sync.Mutex
+	size  int64
+	// lru contains the *cacheEntry of every cached file, the most recently
+	// used at the front.
+	lru   *list.List
+	files map[string]*list.Element
+}
+
+type cacheEntry struct {
+	path string
+	size int64
+}
+
+// MakeCachingBackend returns a CachingArchiveBackend reading files from
+// upstream and caching them in dir, which is created if needed. Files
+// already in dir are kept. When maxSize is 0, DefaultCacheSize is used.
+func MakeCachingBackend(upstream ArchiveBackend, dir string, maxSize int64) (*CachingArchiveBackend, error) {
+	if maxSize <= 0 {
+		maxSize = DefaultCacheSize
+	}
+	b := &CachingArchiveBackend{
+		upstream: upstream,
+		dir:      dir,
+		maxSize:  maxSize,
+		lru:      list.New(),
+		files:    map[string]*list.Element{},
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, errors.Wrap(err, "could not create cache directory")
+	}
+	if err := b.load(); err != nil {
+		return nil, errors.Wrap(err, "could not load cache directory")
+	}
+	return b, nil
+}
+
+// load adds the files found in the cache directory to the LRU list, ordered
+// by modification time which is updated every time a file is used.
+func (b *CachingArchiveBackend) load() error {
+	type cachedFile struct {
+		path    string
+		size    int64
+		modTime time.Time
+	}
+	var found []cachedFile
+
+	err := filepath.Walk(b.dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		if strings.HasPrefix(info.Name(), cacheTempPrefix) {
+			// interrupted download
+			return os.Remove(p)
+		}
+		rel, err := filepath.Rel(b.dir, p)
+		if err != nil {
+			return err
+		}
+		found = append(found, cachedFile{filepath.ToSlash(rel), info.Size(), info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	sort.Slice(found, func(i, j int) bool {
+		return found[i].modTime.Before(found[j].modTime)
+	})
+	for _, f := range found {
+		b.add(f.path, f.size)
+	}
+	return nil
+}
+
+// archiveCacheDir returns the directory caching the files of the archive at
+// url in the cache directory dir.
+func archiveCacheDir(dir, url string) string {
+	hash := sha256.Sum256([]byte(url))
+	return filepath.Join(dir, hex.EncodeToString(hash[:8]))
+}
+
+func (b *CachingArchiveBackend) cachePath(pth string) string {
+	return filepath.Join(b.dir, filepath.FromSlash(pth))
+}
+
+// cacheKey returns the path of the file in the cache, and false if the file
+// must not be cached.
+func cacheKey(pth string) (string, bool) {
+	pth = strings.TrimPrefix(path.Clean("/"+pth), "/")
+	if pth == "" || pth == rootHASPath {
+		return "", false
+	}
+	return pth, true
+}
+
+// add records a file stored in the cache, then removes the least recently
+// used files until the cache size is within its limit.
+func (b *CachingArchiveBackend) add(pth string, size int64) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if elem, ok := b.files[pth]; ok {
+		b.size -= elem.Value.(*cacheEntry).size
+		b.lru.Remove(elem)
+	}
+	b.files[pth] = b.lru.PushFront(&cacheEntry{path: pth, size: size})
+	b.size += size
+
+	for b.size > b.maxSize {
+		oldest := b.lru.Back()
+		if oldest == nil {
+			break
+		}
+		b.removeLocked(oldest.Value.(*cacheEntry).path)
+	}
+}
+
+// touch marks a cached file as the most recently used and returns false if
+// the file is not cached.
+func (b *CachingArchiveBackend) touch(pth string) bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	elem, ok := b.files[pth]
+	if !ok {
+		return false
+	}
+	b.lru.MoveToFront(elem)
+	now := time.Now()
+	// the modification time orders the files when the cache is loaded again
+	os.Chtimes(b.cachePath(pth), now, now)
+	return true
+}
+
+func (b *CachingArchiveBackend) remove(pth string) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.removeLocked(pth)
+}
+
+func (b *CachingArchiveBackend) removeLocked(pth string) {
+	if elem, ok := b.files[pth]; ok {
+		b.size -= elem.Value.(*cacheEntry).size
+		b.lru.Remove(elem)
+		delete(b.files, pth)
+	}
+	os.Remove(b.cachePath(pth))
+}
+
+// Size returns the total size of the files in the cache.
+func (b *CachingArchiveBackend) Size() int64 {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.size
+}
+
+// verifyCachedFile checks the hash of a bucket file stored in the cache
+// directory. Other files are not verified.
+func verifyCachedFile(pth, file string) error {
+	m := bucketPathRx.FindStringSubmatch(pth)
+	if m == nil {
+		return nil
+	}
+	expected := MustDecodeHash(m[1])
+
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	rdr, err := gzip.NewReader(bufReadCloser(f))
+	if err != nil {
+		return err
+	}
+	defer rdr.Close()
+	hsh := sha256.New()
+	if _, err := io.Copy(hsh, rdr); err != nil {
+		return err
+	}
+	return checkBucketHash(hsh, expected)
+}
+
+// getCached returns the cached file, or nil if it is not in the cache or is
+// corrupted.
+func (b *CachingArchiveBackend) getCached(pth string) *os.File {
+	if !b.touch(pth) {
+		return nil
+	}
+	file := b.cachePath(pth)
+	if err := verifyCachedFile(pth, file); err != nil {
+		b.remove(pth)
+		return nil
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		b.remove(pth)
+		return nil
+	}
+	return f
+}
+
+// fetch downloads a file from upstream to the cache and returns it. The file
+// is not cached if it's a bucket and its hash is invalid.
+func (b *CachingArchiveBackend) fetch(pth string) (*os.File, error) {
+	rdr, err := b.upstream.GetFile(pth)
+	if err != nil {
+		return nil, err
+	}
+	defer rdr.Close()
+
+	tmp, err := ioutil.TempFile(b.dir, cacheTempPrefix)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create cache file")
+	}
+	size, err := io.Copy(tmp, rdr)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return nil, errors.Wrap(err, "could not download "+pth)
+	}
+	if err = verifyCachedFile(pth, tmp.Name()); err != nil {
+		os.Remove(tmp.Name())
+		return nil, errors.Wrap(err, "invalid file "+pth)
+	}
+
+	file := b.cachePath(pth)
+	if err = os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		os.Remove(tmp.Name())
+		return nil, errors.Wrap(err, "could not create cache directory")
+	}
+	if err = os.Rename(tmp.Name(), file); err != nil {
+		os.Remove(tmp.Name())
+		return nil, errors.Wrap(err, "could not move file to cache")
+	}
+
+	// The file is opened before being added to the cache so it can still be
+	// read if it is evicted right away.
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	b.add(pth, size)
+	return f, nil
+}
+
+func (b *CachingArchiveBackend) GetFile(pth string) (io.ReadCloser, error) {
+	key, ok := cacheKey(pth)
+	if !ok {
+		return b.upstream.GetFile(pth)
+	}
+	if f := b.getCached(key); f != nil {
+		return f, nil
+	}
+	f, err := b.fetch(key)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (b *CachingArchiveBackend) Exists(pth string) (bool, error) {
+	if key, ok := cacheKey(pth); ok {
+		b.mutex.Lock()
+		_, cached := b.files[key]
+		b.mutex.Unlock()
+		if cached {
+			return true, nil
+		}
+	}
+	return b.upstream.Exists(pth)
+}
+
+func (b *CachingArchiveBackend) PutFile(pth string, in io.ReadCloser) error {
+	if key, ok := cacheKey(pth); ok {
+		b.remove(key)
+	}
+	return b.upstream.PutFile(pth, in)
+}
+
+func (b *CachingArchiveBackend) ListFiles(pth string) (chan string, chan error) {
+	return b.upstream.ListFiles(pth)
+}
+
+func (b *CachingArchiveBackend) CanListFiles() bool {
+	return b.upstream.CanListFiles()
+}
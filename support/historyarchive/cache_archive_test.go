@@ -0,0 +1,175 @@
+// Copyright 2016 DiamNet Development Foundation and contributors. Licensed
+// under the Apache License, Version 2.0. See the COPYING file at the root
+// of this distribution or at http://www.apache.org/licenses/LICENSE-2.0
+
+package historyarchive
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/rand"
+	"crypto/sha256"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingBackend counts the files read from the wrapped backend
+type countingBackend struct {
+	ArchiveBackend
+	gets map[string]int
+}
+
+func (b *countingBackend) GetFile(pth string) (io.ReadCloser, error) {
+	b.gets[pth]++
+	return b.ArchiveBackend.GetFile(pth)
+}
+
+func makeTestCachingBackend(t *testing.T, maxSize int64) (*CachingArchiveBackend, *countingBackend, string) {
+	dir, err := ioutil.TempDir("", "archive-cache")
+	require.NoError(t, err)
+	upstream := &countingBackend{makeMockBackend(ConnectOptions{}), map[string]int{}}
+	b, err := MakeCachingBackend(upstream, dir, maxSize)
+	require.NoError(t, err)
+	return b, upstream, dir
+}
+
+// putBucket stores a gzipped bucket of random content in the backend and
+// returns its path and gzipped content.
+func putBucket(t *testing.T, b ArchiveBackend) (string, []byte) {
+	content := make([]byte, 1024)
+	_, err := rand.Read(content)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	_, err = w.Write(content)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	pth := BucketPath(sha256.Sum256(content))
+	require.NoError(t, b.PutFile(pth, ioutil.NopCloser(bytes.NewReader(buf.Bytes()))))
+	return pth, buf.Bytes()
+}
+
+func readFile(t *testing.T, b ArchiveBackend, pth string) []byte {
+	rdr, err := b.GetFile(pth)
+	require.NoError(t, err)
+	defer rdr.Close()
+	content, err := ioutil.ReadAll(rdr)
+	require.NoError(t, err)
+	return content
+}
+
+func TestCachingBackendBuckets(t *testing.T) {
+	b, upstream, dir := makeTestCachingBackend(t, 0)
+	defer os.RemoveAll(dir)
+
+	pth, content := putBucket(t, upstream)
+	assert.Equal(t, content, readFile(t, b, pth))
+	assert.Equal(t, content, readFile(t, b, pth))
+	assert.Equal(t, 1, upstream.gets[pth])
+	assert.Equal(t, int64(len(content)), b.Size())
+
+	exists, err := b.Exists(pth)
+	assert.NoError(t, err)
+	assert.True(t, exists)
+
+	// corrupted buckets are downloaded again
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, pth), []byte("corrupted"), 0644))
+	assert.Equal(t, content, readFile(t, b, pth))
+	assert.Equal(t, 2, upstream.gets[pth])
+
+	// buckets with an invalid hash are not cached
+	invalid := BucketPath(Hash{1})
+	require.NoError(t, upstream.PutFile(invalid, ioutil.NopCloser(bytes.NewReader(content))))
+	_, err = b.GetFile(invalid)
+	assert.Contains(t, err.Error(), "Bucket hash mismatch")
+	_, err = os.Stat(filepath.Join(dir, invalid))
+	assert.True(t, os.IsNotExist(err))
+	assert.Equal(t, int64(len(content)), b.Size())
+}
+
+func TestCachingBackendRootHAS(t *testing.T) {
+	b, upstream, dir := makeTestCachingBackend(t, 0)
+	defer os.RemoveAll(dir)
+
+	require.NoError(t, upstream.PutFile(rootHASPath, ioutil.NopCloser(bytes.NewReader([]byte("1")))))
+	assert.Equal(t, []byte("1"), readFile(t, b, rootHASPath))
+	require.NoError(t, upstream.PutFile(rootHASPath, ioutil.NopCloser(bytes.NewReader([]byte("2")))))
+	assert.Equal(t, []byte("2"), readFile(t, b, rootHASPath))
+	assert.Equal(t, int64(0), b.Size())
+
+	// other files are cached until they are replaced with PutFile
+	chk := CategoryCheckpointPath("history", 63)
+	require.NoError(t, upstream.PutFile(chk, ioutil.NopCloser(bytes.NewReader([]byte("1")))))
+	assert.Equal(t, []byte("1"), readFile(t, b, chk))
+	require.NoError(t, upstream.PutFile(chk, ioutil.NopCloser(bytes.NewReader([]byte("2")))))
+	assert.Equal(t, []byte("1"), readFile(t, b, chk))
+	require.NoError(t, b.PutFile(chk, ioutil.NopCloser(bytes.NewReader([]byte("3")))))
+	assert.Equal(t, []byte("3"), readFile(t, b, chk))
+}
+
+func TestCachingBackendEviction(t *testing.T) {
+	b, upstream, dir := makeTestCachingBackend(t, 1)
+	defer os.RemoveAll(dir)
+
+	pth1, content1 := putBucket(t, upstream)
+	pth2, content2 := putBucket(t, upstream)
+	pth3, content3 := putBucket(t, upstream)
+	b.maxSize = int64(len(content1) + len(content2))
+
+	readFile(t, b, pth1)
+	readFile(t, b, pth2)
+	// pth1 becomes the most recently used file
+	readFile(t, b, pth1)
+	readFile(t, b, pth3)
+	assert.Equal(t, int64(len(content1)+len(content3)), b.Size())
+
+	_, err := os.Stat(filepath.Join(dir, pth2))
+	assert.True(t, os.IsNotExist(err))
+	assert.Equal(t, content1, readFile(t, b, pth1))
+	assert.Equal(t, content3, readFile(t, b, pth3))
+	assert.Equal(t, 1, upstream.gets[pth1])
+	assert.Equal(t, 1, upstream.gets[pth3])
+
+	// the cache is reloaded from the directory
+	reloaded, err := MakeCachingBackend(upstream, dir, b.maxSize)
+	require.NoError(t, err)
+	assert.Equal(t, b.Size(), reloaded.Size())
+	assert.Equal(t, content3, readFile(t, reloaded, pth3))
+	assert.Equal(t, 1, upstream.gets[pth3])
+}
+
+func TestConnectCacheDirPerArchive(t *testing.T) {
+	cacheDir, err := ioutil.TempDir("", "archive-cache")
+	require.NoError(t, err)
+	defer os.RemoveAll(cacheDir)
+	srcDir, err := ioutil.TempDir("", "archive-src")
+	require.NoError(t, err)
+	defer os.RemoveAll(srcDir)
+	dstDir, err := ioutil.TempDir("", "archive-dst")
+	require.NoError(t, err)
+	defer os.RemoveAll(dstDir)
+
+	opts := ConnectOptions{CacheDir: cacheDir}
+	src, err := Connect("file://"+srcDir, opts)
+	require.NoError(t, err)
+	dst, err := Connect("file://"+dstDir, opts)
+	require.NoError(t, err)
+
+	pth, content := putBucket(t, src.backend)
+	assert.Equal(t, content, readFile(t, src.backend, pth))
+
+	// the file cached from src is not visible from dst
+	exists, err := dst.backend.Exists(pth)
+	require.NoError(t, err)
+	assert.False(t, exists)
+	_, err = dst.backend.GetFile(pth)
+	assert.Error(t, err)
+}
@@ -55,3 +55,16 @@ func (h Hash) IsZero() bool {
 	}
 	return true
 }
+
+func (h Hash) MarshalText() ([]byte, error) {
+	return []byte(h.String()), nil
+}
+
+func (h *Hash) UnmarshalText(text []byte) error {
+	decoded, err := DecodeHash(string(text))
+	if err != nil {
+		return err
+	}
+	*h = decoded
+	return nil
+}
@@ -0,0 +1,311 @@
+// Copyright 2016 DiamNet Development Foundation and contributors. Licensed
+// under the Apache License, Version 2.0. See the COPYING file at the root
+// of this distribution or at http://www.apache.org/licenses/LICENSE-2.0
+
+package historyarchive
+
+import (
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/diamnet/go/support/errors"
+	"github.com/diamnet/go/xdr"
+)
+
+// LedgerChainErrorType is the kind of hash mismatch found by
+// VerifyLedgerChain.
+type LedgerChainErrorType string
+
+const (
+	// LedgerHashMismatch means the hash published with a ledger header is
+	// not the hash of the header. Expected is the published hash and Actual
+	// the hash of the header.
+	LedgerHashMismatch LedgerChainErrorType = "ledger hash"
+	// PreviousLedgerHashMismatch means the previousLedgerHash of a ledger
+	// header is not the hash of the previous ledger header. Expected is the
+	// hash of the previous ledger header and Actual the previousLedgerHash.
+	PreviousLedgerHashMismatch LedgerChainErrorType = "previous ledger hash"
+	// TxSetHashMismatch means the transaction set published for a ledger
+	// doesn't match the txSetHash of its header. Expected is the txSetHash
+	// and Actual the hash of the transaction set.
+	TxSetHashMismatch LedgerChainErrorType = "transaction set hash"
+	// TxSetResultHashMismatch means the transaction results published for a
+	// ledger don't match the txSetResultHash of its header. Expected is the
+	// txSetResultHash and Actual the hash of the results.
+	TxSetResultHashMismatch LedgerChainErrorType = "transaction set result hash"
+	// BucketListHashMismatch means the buckets of a checkpoint's history
+	// archive state don't match the bucketListHash of the checkpoint ledger
+	// header. Expected is the bucketListHash and Actual the hash of the
+	// buckets.
+	BucketListHashMismatch LedgerChainErrorType = "bucket list hash"
+	// MissingLedger means the header of a ledger is missing from its
+	// checkpoint file.
+	MissingLedger LedgerChainErrorType = "missing ledger"
+)
+
+// LedgerChainError is returned by VerifyLedgerChain when the data published
+// for a ledger doesn't match the chain of ledger headers.
+type LedgerChainError struct {
+	Type     LedgerChainErrorType
+	Ledger   uint32
+	Expected Hash
+	Actual   Hash
+}
+
+func (e *LedgerChainError) Error() string {
+	if e.Type == MissingLedger {
+		return fmt.Sprintf("ledger %d: missing ledger header", e.Ledger)
+	}
+	return fmt.Sprintf("ledger %d: %s mismatch: expected %s, got %s",
+		e.Ledger, e.Type, e.Expected, e.Actual)
+}
+
+// checkpointLedgers are the ledgers of a verified checkpoint.
+type checkpointLedgers struct {
+	first  uint32
+	hashes map[uint32]Hash
+	// previous is the previousLedgerHash of the first ledger
+	previous Hash
+}
+
+// VerifyLedgerChain verifies the ledgers of the checkpoints from
+// opts.Range.Low to opts.Range.High, both included, using only the files
+// published in the archive:
+//
+//   - the hash of every ledger header matches the hash published with it,
+//   - the previousLedgerHash of every ledger header is the hash of the
+//     previous ledger header,
+//   - the transaction set and transaction results of every ledger match the
+//     txSetHash and txSetResultHash of its header,
+//   - the bucket list of the history archive state of every checkpoint
+//     matches the bucketListHash of the checkpoint ledger header.
+//
+// The previousLedgerHash of the first ledger of the range isn't verified, it
+// can be compared with a trusted hash of the previous ledger.
+//
+// It returns the hashes of the ledger headers, which can be compared with
+// the ones stored by Aurora or diamnet-core. When a ledger doesn't match the
+// chain, the error is a *LedgerChainError for the lowest ledger found.
+func (arch *Archive) VerifyLedgerChain(opts *CommandOptions) (map[uint32]Hash, error) {
+	if opts.Concurrency == 0 {
+		return nil, errors.New("Zero concurrency")
+	}
+	rng := opts.Range
+	if (rng.Low+1)%CheckpointFreq != 0 || (rng.High+1)%CheckpointFreq != 0 || rng.Low > rng.High {
+		return nil, errors.Errorf("invalid checkpoint range %s", rng)
+	}
+
+	var checkpoints []uint32
+	for chk := uint64(rng.Low); chk <= uint64(rng.High); chk += uint64(CheckpointFreq) {
+		checkpoints = append(checkpoints, uint32(chk))
+	}
+	results := make([]checkpointLedgers, len(checkpoints))
+	errs := make([]error, len(checkpoints))
+
+	var wg sync.WaitGroup
+	req := make(chan int)
+	for i := 0; i < opts.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range req {
+				results[i], errs[i] = arch.verifyCheckpointLedgers(checkpoints[i])
+			}
+		}()
+	}
+	for i := range checkpoints {
+		req <- i
+	}
+	close(req)
+	wg.Wait()
+
+	hashes := make(map[uint32]Hash)
+	for i, result := range results {
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+		if i > 0 {
+			expected := hashes[result.first-1]
+			if result.previous != expected {
+				return nil, &LedgerChainError{
+					Type:     PreviousLedgerHashMismatch,
+					Ledger:   result.first,
+					Expected: expected,
+					Actual:   result.previous,
+				}
+			}
+		}
+		for seq, h := range result.hashes {
+			hashes[seq] = h
+		}
+	}
+	return hashes, nil
+}
+
+// verifyCheckpointLedgers verifies the ledgers of a checkpoint and returns
+// their hashes.
+func (arch *Archive) verifyCheckpointLedgers(chk uint32) (checkpointLedgers, error) {
+	result := checkpointLedgers{first: 1, hashes: make(map[uint32]Hash)}
+	if chk >= CheckpointFreq {
+		result.first = chk - CheckpointFreq + 1
+	}
+
+	var headers []xdr.LedgerHeaderHistoryEntry
+	err := arch.readCheckpointFile("ledger", chk, func(rdr *XdrStream) error {
+		var entry xdr.LedgerHeaderHistoryEntry
+		if err := rdr.ReadOne(&entry); err != nil {
+			return err
+		}
+		headers = append(headers, entry)
+		return nil
+	})
+	if err != nil {
+		return result, err
+	}
+
+	txSets := make(map[uint32]Hash)
+	err = arch.readCheckpointFile("transactions", chk, func(rdr *XdrStream) error {
+		var entry xdr.TransactionHistoryEntry
+		if err := rdr.ReadOne(&entry); err != nil {
+			return err
+		}
+		h, err := HashTxSet(&entry.TxSet)
+		if err != nil {
+			return err
+		}
+		txSets[uint32(entry.LedgerSeq)] = h
+		return nil
+	})
+	if err != nil {
+		return result, err
+	}
+
+	txResults := make(map[uint32]Hash)
+	err = arch.readCheckpointFile("results", chk, func(rdr *XdrStream) error {
+		var entry xdr.TransactionHistoryResultEntry
+		if err := rdr.ReadOne(&entry); err != nil {
+			return err
+		}
+		h, err := HashXdr(&entry.TxResultSet)
+		if err != nil {
+			return err
+		}
+		txResults[uint32(entry.LedgerSeq)] = h
+		return nil
+	})
+	if err != nil {
+		return result, err
+	}
+
+	has, err := arch.GetCheckpointHAS(chk)
+	if err != nil {
+		return result, errors.Wrapf(err, "could not read history archive state of checkpoint 0x%8.8x", chk)
+	}
+	bucketListHash, err := has.BucketListHash()
+	if err != nil {
+		return result, errors.Wrapf(err, "invalid history archive state of checkpoint 0x%8.8x", chk)
+	}
+
+	emptyXdrArrayHash := EmptyXdrArrayHash()
+	var previous Hash
+	for i, entry := range headers {
+		seq := result.first + uint32(i)
+		header := entry.Header
+		if uint32(header.LedgerSeq) != seq {
+			return result, &LedgerChainError{Type: MissingLedger, Ledger: seq}
+		}
+
+		h, err := HashXdr(&header)
+		if err != nil {
+			return result, err
+		}
+		if h != Hash(entry.Hash) {
+			return result, &LedgerChainError{
+				Type:     LedgerHashMismatch,
+				Ledger:   seq,
+				Expected: Hash(entry.Hash),
+				Actual:   h,
+			}
+		}
+
+		if i == 0 {
+			result.previous = Hash(header.PreviousLedgerHash)
+		} else if Hash(header.PreviousLedgerHash) != previous {
+			return result, &LedgerChainError{
+				Type:     PreviousLedgerHashMismatch,
+				Ledger:   seq,
+				Expected: previous,
+				Actual:   Hash(header.PreviousLedgerHash),
+			}
+		}
+
+		txSet, ok := txSets[seq]
+		if !ok {
+			// Ledgers without transactions are not published.
+			txSet = HashEmptyTxSet(Hash(header.PreviousLedgerHash))
+		}
+		if txSet != Hash(header.ScpValue.TxSetHash) {
+			return result, &LedgerChainError{
+				Type:     TxSetHashMismatch,
+				Ledger:   seq,
+				Expected: Hash(header.ScpValue.TxSetHash),
+				Actual:   txSet,
+			}
+		}
+
+		txResult, ok := txResults[seq]
+		if !ok {
+			txResult = emptyXdrArrayHash
+		}
+		if txResult != Hash(header.TxSetResultHash) {
+			return result, &LedgerChainError{
+				Type:     TxSetResultHashMismatch,
+				Ledger:   seq,
+				Expected: Hash(header.TxSetResultHash),
+				Actual:   txResult,
+			}
+		}
+
+		result.hashes[seq] = h
+		previous = h
+	}
+
+	if uint32(len(headers)) != chk-result.first+1 {
+		return result, &LedgerChainError{
+			Type:   MissingLedger,
+			Ledger: result.first + uint32(len(headers)),
+		}
+	}
+
+	last := headers[len(headers)-1].Header
+	if bucketListHash != last.BucketListHash {
+		return result, &LedgerChainError{
+			Type:     BucketListHashMismatch,
+			Ledger:   chk,
+			Expected: Hash(last.BucketListHash),
+			Actual:   Hash(bucketListHash),
+		}
+	}
+	return result, nil
+}
+
+// readCheckpointFile calls read until it reads all the entries of a
+// checkpoint file.
+func (arch *Archive) readCheckpointFile(cat string, chk uint32, read func(*XdrStream) error) error {
+	pth := CategoryCheckpointPath(cat, chk)
+	rdr, err := arch.GetXdrStream(pth)
+	if err != nil {
+		return errors.Wrap(err, "could not read "+pth)
+	}
+	defer rdr.Close()
+	for {
+		err = read(rdr)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.Wrap(err, "could not read "+pth)
+		}
+	}
+}
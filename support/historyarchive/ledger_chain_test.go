@@ -0,0 +1,248 @@
+// Copyright 2016 DiamNet Development Foundation and contributors. Licensed
+// under the Apache License, Version 2.0. See the COPYING file at the root
+// of this distribution or at http://www.apache.org/licenses/LICENSE-2.0
+
+package historyarchive
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"testing"
+
+	"github.com/diamnet/go/xdr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testLedgerChain is a valid chain of ledgers, modified by tests before
+// being written to an archive.
+type testLedgerChain struct {
+	headers []xdr.LedgerHeaderHistoryEntry
+	txSets  map[uint32]xdr.TransactionHistoryEntry
+	results map[uint32]xdr.TransactionHistoryResultEntry
+	states  map[uint32]HistoryArchiveState
+}
+
+func hashTestHeader(t *testing.T, entry *xdr.LedgerHeaderHistoryEntry) {
+	h, err := HashXdr(&entry.Header)
+	require.NoError(t, err)
+	entry.Hash = xdr.Hash(h)
+}
+
+func makeTestLedgerChain(t *testing.T, lastCheckpoint uint32) *testLedgerChain {
+	chain := &testLedgerChain{
+		txSets:  map[uint32]xdr.TransactionHistoryEntry{},
+		results: map[uint32]xdr.TransactionHistoryResultEntry{},
+		states:  map[uint32]HistoryArchiveState{},
+	}
+	var previous xdr.Hash
+	for seq := uint32(1); seq <= lastCheckpoint; seq++ {
+		header := xdr.LedgerHeader{
+			LedgerSeq:          xdr.Uint32(seq),
+			PreviousLedgerHash: previous,
+		}
+
+		if seq%10 == 0 {
+			txSet := xdr.TransactionSet{
+				PreviousLedgerHash: previous,
+				Txs: []xdr.TransactionEnvelope{
+					{Tx: xdr.Transaction{
+						SourceAccount: xdr.MustAddress("GAXI33UCLQTCKM2NMRBS7XYBR535LLEVAHL5YBN4FTCB4HZHT7ZA5CVK"),
+						SeqNum:        xdr.SequenceNumber(seq),
+					}},
+				},
+			}
+			h, err := HashTxSet(&txSet)
+			require.NoError(t, err)
+			header.ScpValue.TxSetHash = xdr.Hash(h)
+			chain.txSets[seq] = xdr.TransactionHistoryEntry{LedgerSeq: xdr.Uint32(seq), TxSet: txSet}
+
+			resultSet := xdr.TransactionResultSet{
+				Results: []xdr.TransactionResultPair{
+					{Result: xdr.TransactionResult{
+						FeeCharged: 100,
+						Result:     xdr.TransactionResultResult{Code: xdr.TransactionResultCodeTxBadSeq},
+					}},
+				},
+			}
+			h, err = HashXdr(&resultSet)
+			require.NoError(t, err)
+			header.TxSetResultHash = xdr.Hash(h)
+			chain.results[seq] = xdr.TransactionHistoryResultEntry{LedgerSeq: xdr.Uint32(seq), TxResultSet: resultSet}
+		} else {
+			header.ScpValue.TxSetHash = xdr.Hash(HashEmptyTxSet(Hash(previous)))
+			header.TxSetResultHash = xdr.Hash(EmptyXdrArrayHash())
+		}
+
+		if (seq+1)%CheckpointFreq == 0 {
+			has := testHAS(seq, Hash{byte(seq)}.String())
+			has.CurrentBuckets[0].Snap = Hash{}.String()
+			for i := 1; i < NumLevels; i++ {
+				has.CurrentBuckets[i].Curr = Hash{}.String()
+				has.CurrentBuckets[i].Snap = Hash{}.String()
+			}
+			h, err := has.BucketListHash()
+			require.NoError(t, err)
+			header.BucketListHash = h
+			chain.states[seq] = has
+		}
+
+		entry := xdr.LedgerHeaderHistoryEntry{Header: header}
+		hashTestHeader(t, &entry)
+		chain.headers = append(chain.headers, entry)
+		previous = entry.Hash
+	}
+	return chain
+}
+
+func putTestXdrFile(t *testing.T, arch *Archive, pth string, entries []interface{}) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	for _, entry := range entries {
+		require.NoError(t, WriteFramedXdr(w, entry))
+	}
+	require.NoError(t, w.Close())
+	require.NoError(t, arch.backend.PutFile(pth, ioutil.NopCloser(&buf)))
+}
+
+func (chain *testLedgerChain) archive(t *testing.T) *Archive {
+	arch := GetTestMockArchive()
+	for chk, has := range chain.states {
+		putHAS(t, arch, false, has)
+
+		var headers, txSets, results []interface{}
+		for _, entry := range chain.headers {
+			seq := uint32(entry.Header.LedgerSeq)
+			if seq > chk || seq+CheckpointFreq <= chk {
+				continue
+			}
+			headers = append(headers, entry)
+			if txSet, ok := chain.txSets[seq]; ok {
+				txSets = append(txSets, txSet)
+			}
+			if result, ok := chain.results[seq]; ok {
+				results = append(results, result)
+			}
+		}
+		putTestXdrFile(t, arch, CategoryCheckpointPath("ledger", chk), headers)
+		putTestXdrFile(t, arch, CategoryCheckpointPath("transactions", chk), txSets)
+		putTestXdrFile(t, arch, CategoryCheckpointPath("results", chk), results)
+	}
+	return arch
+}
+
+func TestVerifyLedgerChain(t *testing.T) {
+	chain := makeTestLedgerChain(t, 191)
+	opts := &CommandOptions{Range: Range{Low: 63, High: 191}, Concurrency: 4}
+
+	hashes, err := chain.archive(t).VerifyLedgerChain(opts)
+	require.NoError(t, err)
+	assert.Len(t, hashes, 191)
+	for _, entry := range chain.headers {
+		assert.Equal(t, Hash(entry.Hash), hashes[uint32(entry.Header.LedgerSeq)])
+	}
+
+	opts.Range = Range{Low: 127, High: 191}
+	hashes, err = chain.archive(t).VerifyLedgerChain(opts)
+	require.NoError(t, err)
+	assert.Len(t, hashes, 128)
+
+	opts.Range = Range{Low: 64, High: 191}
+	_, err = chain.archive(t).VerifyLedgerChain(opts)
+	assert.EqualError(t, err, "invalid checkpoint range [0x00000040, 0x000000bf]")
+}
+
+func TestVerifyLedgerChainErrors(t *testing.T) {
+	for _, testCase := range []struct {
+		name     string
+		modify   func(chain *testLedgerChain)
+		expected LedgerChainErrorType
+		ledger   uint32
+	}{
+		{
+			name: "modified header",
+			modify: func(chain *testLedgerChain) {
+				chain.headers[99].Header.TotalCoins = 1
+			},
+			expected: LedgerHashMismatch,
+			ledger:   100,
+		},
+		{
+			name: "forged header",
+			modify: func(chain *testLedgerChain) {
+				chain.headers[99].Header.TotalCoins = 1
+				hashTestHeader(t, &chain.headers[99])
+			},
+			expected: PreviousLedgerHashMismatch,
+			ledger:   101,
+		},
+		{
+			name: "forged checkpoint header",
+			modify: func(chain *testLedgerChain) {
+				chain.headers[126].Header.TotalCoins = 1
+				hashTestHeader(t, &chain.headers[126])
+			},
+			expected: PreviousLedgerHashMismatch,
+			ledger:   128,
+		},
+		{
+			name: "missing header",
+			modify: func(chain *testLedgerChain) {
+				chain.headers = append(chain.headers[:69], chain.headers[70:]...)
+			},
+			expected: MissingLedger,
+			ledger:   70,
+		},
+		{
+			name: "modified transaction set",
+			modify: func(chain *testLedgerChain) {
+				txSet := chain.txSets[150]
+				txSet.TxSet.Txs[0].Tx.SeqNum++
+			},
+			expected: TxSetHashMismatch,
+			ledger:   150,
+		},
+		{
+			name: "removed transaction set",
+			modify: func(chain *testLedgerChain) {
+				delete(chain.txSets, 150)
+			},
+			expected: TxSetHashMismatch,
+			ledger:   150,
+		},
+		{
+			name: "added results",
+			modify: func(chain *testLedgerChain) {
+				result := chain.results[150]
+				result.LedgerSeq = 151
+				chain.results[151] = result
+			},
+			expected: TxSetResultHashMismatch,
+			ledger:   151,
+		},
+		{
+			name: "modified buckets",
+			modify: func(chain *testLedgerChain) {
+				has := chain.states[127]
+				has.CurrentBuckets[1].Curr = Hash{1}.String()
+				chain.states[127] = has
+			},
+			expected: BucketListHashMismatch,
+			ledger:   127,
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			chain := makeTestLedgerChain(t, 191)
+			testCase.modify(chain)
+			opts := &CommandOptions{Range: Range{Low: 63, High: 191}, Concurrency: 4}
+
+			_, err := chain.archive(t).VerifyLedgerChain(opts)
+			require.Error(t, err)
+			chainErr, ok := err.(*LedgerChainError)
+			require.True(t, ok, err.Error())
+			assert.Equal(t, testCase.expected, chainErr.Type)
+			assert.Equal(t, testCase.ledger, chainErr.Ledger)
+		})
+	}
+}
@@ -0,0 +1,379 @@
+// Copyright 2016 DiamNet Development Foundation and contributors. Licensed
+// under the Apache License, Version 2.0. See the COPYING file at the root
+// of this distribution or at http://www.apache.org/licenses/LICENSE-2.0
+
+package historyarchive
+
+import (
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/diamnet/go/support/errors"
+)
+
+// MultiArchive is an ArchiveInterface reading from several archives of the
+// same network. Files are read from one archive at a time: when a file is
+// missing or can't be read, it's read from the next archive and the archive
+// which succeeded is tried first for the next files.
+//
+// When the quorum is greater than one, history archive states are only
+// trusted when at least quorum archives publish the same buckets for the same
+// ledger, and GetRootHAS returns the latest checkpoint published by at least
+// quorum archives. Bucket files are content-addressed so a bucket can be read
+// from a single archive once the history archive state referencing it is
+// trusted: GetXdrStreamForHash downloads the bucket to a temporary file and
+// checks its hash before returning it, reading it from the next archive when
+// the bucket is corrupt. When the quorum is one, buckets are streamed and
+// their hash is checked when the stream is closed.
+//
+// Writes are applied to every archive.
+type MultiArchive struct {
+	archives []ArchiveInterface
+	quorum   int
+
+	mutex sync.Mutex
+	// preferred is the index of the archive tried first
+	preferred int
+}
+
+var _ ArchiveInterface = &MultiArchive{}
+
+// NewMultiArchive returns a MultiArchive reading from archives. quorum is the
+// number of archives which must agree on a history archive state, 0 and 1
+// disable cross-checking.
+func NewMultiArchive(archives []ArchiveInterface, quorum int) (*MultiArchive, error) {
+	if len(archives) == 0 {
+		return nil, errors.New("no archives")
+	}
+	if quorum < 1 {
+		quorum = 1
+	}
+	if quorum > len(archives) {
+		return nil, errors.Errorf("quorum %d is greater than the number of archives (%d)", quorum, len(archives))
+	}
+	return &MultiArchive{archives: archives, quorum: quorum}, nil
+}
+
+// ConnectMulti connects to every archive in urls and returns a MultiArchive
+// reading from them, see NewMultiArchive.
+func ConnectMulti(urls []string, quorum int, opts ConnectOptions) (*MultiArchive, error) {
+	archives := make([]ArchiveInterface, 0, len(urls))
+	for _, u := range urls {
+		arch, err := Connect(u, opts)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not connect to "+u)
+		}
+		archives = append(archives, arch)
+	}
+	return NewMultiArchive(archives, quorum)
+}
+
+// order returns the indexes of the archives, starting with the preferred one.
+func (m *MultiArchive) order() []int {
+	m.mutex.Lock()
+	preferred := m.preferred
+	m.mutex.Unlock()
+
+	indexes := make([]int, len(m.archives))
+	for i := range indexes {
+		indexes[i] = (preferred + i) % len(m.archives)
+	}
+	return indexes
+}
+
+// try calls f with every archive until it succeeds and returns the last error
+// if it never does.
+func (m *MultiArchive) try(f func(ArchiveInterface) error) error {
+	var err error
+	for _, i := range m.order() {
+		err = f(m.archives[i])
+		if err == nil {
+			m.mutex.Lock()
+			m.preferred = i
+			m.mutex.Unlock()
+			return nil
+		}
+		log.Printf("Error reading from archive %d, trying the next archive: %v", i, err)
+	}
+	return err
+}
+
+// sameBuckets returns true if both history archive states describe the same
+// ledger and bucket list.
+func sameBuckets(a, b HistoryArchiveState) bool {
+	if a.CurrentLedger != b.CurrentLedger {
+		return false
+	}
+	for i := range a.CurrentBuckets {
+		if a.CurrentBuckets[i].Curr != b.CurrentBuckets[i].Curr ||
+			a.CurrentBuckets[i].Snap != b.CurrentBuckets[i].Snap {
+			return false
+		}
+	}
+	return true
+}
+
+// getQuorumHAS reads a history archive state from the archives until quorum
+// archives agree on it.
+func (m *MultiArchive) getQuorumHAS(get func(ArchiveInterface) (HistoryArchiveState, error)) (HistoryArchiveState, error) {
+	if m.quorum == 1 {
+		var has HistoryArchiveState
+		err := m.try(func(a ArchiveInterface) error {
+			var err error
+			has, err = get(a)
+			return err
+		})
+		return has, err
+	}
+
+	var states []HistoryArchiveState
+	var counts []int
+	var lastErr error
+	for _, i := range m.order() {
+		has, err := get(m.archives[i])
+		if err != nil {
+			log.Printf("Error reading history archive state from archive %d: %v", i, err)
+			lastErr = err
+			continue
+		}
+
+		found := false
+		for j := range states {
+			if sameBuckets(states[j], has) {
+				counts[j]++
+				found = true
+				if counts[j] >= m.quorum {
+					return states[j], nil
+				}
+				break
+			}
+		}
+		if !found {
+			states = append(states, has)
+			counts = append(counts, 1)
+		}
+	}
+
+	if len(states) > 1 {
+		return HistoryArchiveState{}, errors.Errorf("archives disagree on history archive state, no quorum of %d", m.quorum)
+	}
+	if lastErr != nil {
+		return HistoryArchiveState{}, errors.Wrapf(lastErr, "no quorum of %d archives", m.quorum)
+	}
+	return HistoryArchiveState{}, errors.Errorf("no quorum of %d archives", m.quorum)
+}
+
+func (m *MultiArchive) GetPathHAS(path string) (HistoryArchiveState, error) {
+	return m.getQuorumHAS(func(a ArchiveInterface) (HistoryArchiveState, error) {
+		return a.GetPathHAS(path)
+	})
+}
+
+func (m *MultiArchive) GetCheckpointHAS(chk uint32) (HistoryArchiveState, error) {
+	return m.getQuorumHAS(func(a ArchiveInterface) (HistoryArchiveState, error) {
+		return a.GetCheckpointHAS(chk)
+	})
+}
+
+// GetRootHAS returns the history archive state of the latest checkpoint. When
+// the quorum is greater than one, archives may lag behind each other so it's
+// the latest checkpoint published by at least quorum archives, read with
+// GetCheckpointHAS.
+func (m *MultiArchive) GetRootHAS() (HistoryArchiveState, error) {
+	if m.quorum == 1 {
+		return m.getQuorumHAS(func(a ArchiveInterface) (HistoryArchiveState, error) {
+			return a.GetRootHAS()
+		})
+	}
+
+	var ledgers []uint32
+	for i, a := range m.archives {
+		has, err := a.GetRootHAS()
+		if err != nil {
+			log.Printf("Error reading root history archive state from archive %d: %v", i, err)
+			continue
+		}
+		ledgers = append(ledgers, has.CurrentLedger)
+	}
+	if len(ledgers) < m.quorum {
+		return HistoryArchiveState{}, errors.Errorf("only %d archives returned a root history archive state, no quorum of %d", len(ledgers), m.quorum)
+	}
+
+	sort.Slice(ledgers, func(i, j int) bool { return ledgers[i] > ledgers[j] })
+	return m.GetCheckpointHAS(ledgers[m.quorum-1])
+}
+
+func (m *MultiArchive) BucketExists(bucket Hash) (bool, error) {
+	return m.exists(func(a ArchiveInterface) (bool, error) {
+		return a.BucketExists(bucket)
+	})
+}
+
+func (m *MultiArchive) CategoryCheckpointExists(cat string, chk uint32) (bool, error) {
+	return m.exists(func(a ArchiveInterface) (bool, error) {
+		return a.CategoryCheckpointExists(cat, chk)
+	})
+}
+
+// exists returns true if the file exists in any archive. An error is only
+// returned if no archive could be checked.
+func (m *MultiArchive) exists(f func(ArchiveInterface) (bool, error)) (bool, error) {
+	var lastErr error
+	checked := false
+	for _, i := range m.order() {
+		exists, err := f(m.archives[i])
+		if err != nil {
+			log.Printf("Error reading from archive %d, trying the next archive: %v", i, err)
+			lastErr = err
+			continue
+		}
+		if exists {
+			m.mutex.Lock()
+			m.preferred = i
+			m.mutex.Unlock()
+			return true, nil
+		}
+		checked = true
+	}
+	if checked {
+		return false, nil
+	}
+	return false, lastErr
+}
+
+func (m *MultiArchive) GetXdrStreamForHash(hash Hash) (*XdrStream, error) {
+	// Without cross-checking, or without another archive to read a corrupt
+	// bucket from, the bucket is streamed and its hash is checked when the
+	// stream is closed.
+	if m.quorum == 1 || len(m.archives) == 1 {
+		var stream *XdrStream
+		err := m.try(func(a ArchiveInterface) error {
+			var err error
+			stream, err = a.GetXdrStreamForHash(hash)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		stream.SetExpectedHash(hash)
+		return stream, nil
+	}
+
+	var file *os.File
+	err := m.try(func(a ArchiveInterface) error {
+		var err error
+		file, err = downloadBucket(a, hash)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	stream := NewXdrStream(tempFile{file})
+	stream.SetExpectedHash(hash)
+	return stream, nil
+}
+
+// downloadBucket reads the bucket from the archive to a temporary file and
+// returns the file if the hash of the bucket is valid.
+func downloadBucket(a ArchiveInterface, hash Hash) (*os.File, error) {
+	stream, err := a.GetXdrStreamForHash(hash)
+	if err != nil {
+		return nil, err
+	}
+	stream.SetExpectedHash(hash)
+
+	file, err := ioutil.TempFile("", "bucket")
+	if err != nil {
+		stream.Close()
+		return nil, errors.Wrap(err, "could not create temporary bucket file")
+	}
+	_, err = io.Copy(file, stream.rdr)
+	// Close checks the hash of the bucket
+	if closeErr := stream.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		_, err = file.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		tempFile{file}.Close()
+		return nil, errors.Wrap(err, "could not download bucket "+hash.String())
+	}
+	return file, nil
+}
+
+// tempFile is a temporary file which is removed when it's closed.
+type tempFile struct {
+	*os.File
+}
+
+func (f tempFile) Close() error {
+	err := f.File.Close()
+	os.Remove(f.Name())
+	return err
+}
+
+func (m *MultiArchive) GetXdrStream(pth string) (*XdrStream, error) {
+	var stream *XdrStream
+	err := m.try(func(a ArchiveInterface) error {
+		var err error
+		stream, err = a.GetXdrStream(pth)
+		return err
+	})
+	return stream, err
+}
+
+// preferredArchive returns the archive tried first, used for listing files
+// as errors are only known once the listing is done.
+func (m *MultiArchive) preferredArchive() ArchiveInterface {
+	return m.archives[m.order()[0]]
+}
+
+func (m *MultiArchive) ListBucket(dp DirPrefix) (chan string, chan error) {
+	return m.preferredArchive().ListBucket(dp)
+}
+
+func (m *MultiArchive) ListAllBuckets() (chan string, chan error) {
+	return m.preferredArchive().ListAllBuckets()
+}
+
+func (m *MultiArchive) ListAllBucketHashes() (chan Hash, chan error) {
+	return m.preferredArchive().ListAllBucketHashes()
+}
+
+func (m *MultiArchive) ListCategoryCheckpoints(cat string, pth string) (chan uint32, chan error) {
+	return m.preferredArchive().ListCategoryCheckpoints(cat, pth)
+}
+
+// forEach calls f with every archive and returns the first error.
+func (m *MultiArchive) forEach(f func(ArchiveInterface) error) error {
+	var firstErr error
+	for _, a := range m.archives {
+		if err := f(a); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (m *MultiArchive) PutPathHAS(path string, has HistoryArchiveState, opts *CommandOptions) error {
+	return m.forEach(func(a ArchiveInterface) error {
+		return a.PutPathHAS(path, has, opts)
+	})
+}
+
+func (m *MultiArchive) PutCheckpointHAS(chk uint32, has HistoryArchiveState, opts *CommandOptions) error {
+	return m.forEach(func(a ArchiveInterface) error {
+		return a.PutCheckpointHAS(chk, has, opts)
+	})
+}
+
+func (m *MultiArchive) PutRootHAS(has HistoryArchiveState, opts *CommandOptions) error {
+	return m.forEach(func(a ArchiveInterface) error {
+		return a.PutRootHAS(has, opts)
+	})
+}
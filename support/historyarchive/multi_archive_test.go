@@ -0,0 +1,160 @@
+// Copyright 2016 DiamNet Development Foundation and contributors. Licensed
+// under the Apache License, Version 2.0. See the COPYING file at the root
+// of this distribution or at http://www.apache.org/licenses/LICENSE-2.0
+
+package historyarchive
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testHAS(ledger uint32, bucket string) HistoryArchiveState {
+	var has HistoryArchiveState
+	has.CurrentLedger = ledger
+	has.CurrentBuckets[0].Curr = bucket
+	return has
+}
+
+func putHAS(t *testing.T, arch *Archive, root bool, has HistoryArchiveState) {
+	opts := &CommandOptions{Force: true}
+	require.NoError(t, arch.PutCheckpointHAS(has.CurrentLedger, has, opts))
+	if root {
+		require.NoError(t, arch.PutRootHAS(has, opts))
+	}
+}
+
+func TestMultiArchiveFailover(t *testing.T) {
+	a1 := GetTestMockArchive()
+	a2 := GetTestMockArchive()
+	putHAS(t, a2, true, testHAS(63, "a"))
+	bucket, err := a2.AddRandomBucket()
+	require.NoError(t, err)
+
+	multi, err := NewMultiArchive([]ArchiveInterface{a1, a2}, 1)
+	require.NoError(t, err)
+
+	has, err := multi.GetRootHAS()
+	require.NoError(t, err)
+	assert.Equal(t, uint32(63), has.CurrentLedger)
+	// the archive which succeeded is tried first
+	assert.Equal(t, 1, multi.preferred)
+
+	exists, err := multi.BucketExists(bucket)
+	assert.NoError(t, err)
+	assert.True(t, exists)
+	exists, err = multi.BucketExists(Hash{1})
+	assert.NoError(t, err)
+	assert.False(t, exists)
+
+	_, err = multi.GetCheckpointHAS(127)
+	assert.Error(t, err)
+}
+
+func TestMultiArchiveQuorum(t *testing.T) {
+	a1 := GetTestMockArchive()
+	a2 := GetTestMockArchive()
+	a3 := GetTestMockArchive()
+
+	putHAS(t, a1, false, testHAS(63, "a"))
+	putHAS(t, a1, true, testHAS(127, "b"))
+	putHAS(t, a2, true, testHAS(63, "a"))
+	// a3 is compromised
+	putHAS(t, a3, true, testHAS(63, "c"))
+
+	multi, err := NewMultiArchive([]ArchiveInterface{a1, a2, a3}, 2)
+	require.NoError(t, err)
+
+	has, err := multi.GetCheckpointHAS(63)
+	require.NoError(t, err)
+	assert.Equal(t, testHAS(63, "a"), has)
+
+	// 127 is only published by a1
+	_, err = multi.GetCheckpointHAS(127)
+	assert.Error(t, err)
+
+	// the latest checkpoint published by 2 archives
+	has, err = multi.GetRootHAS()
+	require.NoError(t, err)
+	assert.Equal(t, testHAS(63, "a"), has)
+
+	multi, err = NewMultiArchive([]ArchiveInterface{a1, a2, a3}, 3)
+	require.NoError(t, err)
+	_, err = multi.GetCheckpointHAS(63)
+	assert.EqualError(t, err, "archives disagree on history archive state, no quorum of 3")
+
+	_, err = NewMultiArchive([]ArchiveInterface{a1, a2, a3}, 4)
+	assert.EqualError(t, err, "quorum 4 is greater than the number of archives (3)")
+}
+
+func TestMultiArchiveCorruptBucket(t *testing.T) {
+	a1 := GetTestMockArchive()
+	a2 := GetTestMockArchive()
+	pth, gz := putBucket(t, a2.backend)
+	// a1 has a corrupt copy of the bucket
+	_, corrupt := putBucket(t, a1.backend)
+	require.NoError(t, a1.backend.PutFile(pth, ioutil.NopCloser(bytes.NewReader(corrupt))))
+
+	rdr, err := gzip.NewReader(bytes.NewReader(gz))
+	require.NoError(t, err)
+	content, err := ioutil.ReadAll(rdr)
+	require.NoError(t, err)
+	hash := Hash(sha256.Sum256(content))
+
+	// without cross-checking the hash is checked when the stream is closed
+	multi, err := NewMultiArchive([]ArchiveInterface{a1, a2}, 1)
+	require.NoError(t, err)
+	stream, err := multi.GetXdrStreamForHash(hash)
+	require.NoError(t, err)
+	_, err = ioutil.ReadAll(stream.rdr)
+	require.NoError(t, err)
+	assert.Error(t, stream.Close())
+
+	multi, err = NewMultiArchive([]ArchiveInterface{a1, a2}, 2)
+	require.NoError(t, err)
+
+	// the bucket is read from the archive with a valid copy
+	stream, err = multi.GetXdrStreamForHash(hash)
+	require.NoError(t, err)
+	read, err := ioutil.ReadAll(stream.rdr)
+	require.NoError(t, err)
+	assert.Equal(t, content, read)
+	assert.NoError(t, stream.Close())
+	assert.Equal(t, 1, multi.preferred)
+
+	// no archive has a valid copy
+	require.NoError(t, a2.backend.PutFile(pth, ioutil.NopCloser(bytes.NewReader(corrupt))))
+	_, err = multi.GetXdrStreamForHash(hash)
+	assert.Error(t, err)
+}
+
+func TestConnectMultiCacheDir(t *testing.T) {
+	cacheDir, err := ioutil.TempDir("", "archive-cache")
+	require.NoError(t, err)
+	defer os.RemoveAll(cacheDir)
+
+	var urls []string
+	for _, bucket := range []string{"a", "b"} {
+		dir, err := ioutil.TempDir("", "archive")
+		require.NoError(t, err)
+		defer os.RemoveAll(dir)
+		arch, err := Connect("file://"+dir, ConnectOptions{})
+		require.NoError(t, err)
+		putHAS(t, arch, true, testHAS(63, bucket))
+		urls = append(urls, "file://"+dir)
+	}
+
+	// the history archive state cached from one archive is not used for
+	// the other archive
+	multi, err := ConnectMulti(urls, 2, ConnectOptions{CacheDir: cacheDir})
+	require.NoError(t, err)
+	_, err = multi.GetCheckpointHAS(63)
+	assert.EqualError(t, err, "archives disagree on history archive state, no quorum of 2")
+}
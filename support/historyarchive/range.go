@@ -13,8 +13,8 @@ import (
 const CheckpointFreq = uint32(64)
 
 type Range struct {
-	Low  uint32
-	High uint32
+	Low  uint32 `json:"low"`
+	High uint32 `json:"high"`
 }
 
 func PrevCheckpoint(i uint32) uint32 {
@@ -32,6 +32,10 @@ func (arch *Archive) ScanCheckpoints(opts *CommandOptions) error {
 
 	log.Printf("Scanning checkpoint files in range: %s", opts.Range)
 
+	return arch.scanCheckpointsInRange(opts)
+}
+
+func (arch *Archive) scanCheckpointsInRange(opts *CommandOptions) error {
 	if arch.backend.CanListFiles() {
 		return arch.ScanCheckpointsFast(opts)
 	} else {
@@ -133,6 +137,10 @@ func (arch *Archive) ScanCheckpointsFast(opts *CommandOptions) error {
 				}
 				ch, es := arch.ListCategoryCheckpoints(r.category, r.pathprefix)
 				for n := range ch {
+					// Listed directories can contain checkpoints out of range.
+					if n < opts.Range.Low || n >= opts.Range.High {
+						continue
+					}
 					tick <- true
 					arch.NoteCheckpointFile(r.category, n, true)
 					if opts.Verify {
@@ -204,6 +212,21 @@ func (arch *Archive) ScanBuckets(opts *CommandOptions) error {
 		errs += noteError(arch.ScanAllBuckets())
 	}
 
+	n, invalid := arch.scanReferencedBuckets(opts, doList)
+	errs += n + invalid
+	if errs != 0 {
+		return fmt.Errorf("%d errors while scanning buckets", errs)
+	}
+	return nil
+}
+
+// scanReferencedBuckets notes the buckets referenced by the checkpoints
+// scanned so far, checking they exist unless all buckets were listed
+// and verifying them if requested. It returns the number of errors and of
+// invalid buckets.
+func (arch *Archive) scanReferencedBuckets(opts *CommandOptions, doList bool) (uint32, uint32) {
+	var errs, invalid uint32
+
 	// Grab the set of checkpoints we have HASs for, to read references.
 	arch.mutex.Lock()
 	hists := arch.checkpointFiles["history"]
@@ -266,10 +289,10 @@ func (arch *Archive) ScanBuckets(opts *CommandOptions) error {
 								} else {
 									n = noteError(arch.VerifyBucketHash(bucket))
 								}
-								atomic.AddUint32(&errs, n)
+								atomic.AddUint32(&invalid, n)
 								if n != 0 {
 									arch.mutex.Lock()
-									arch.invalidBuckets++
+									arch.invalidBuckets[bucket] = true
 									arch.mutex.Unlock()
 								}
 							}
@@ -285,10 +308,7 @@ func (arch *Archive) ScanBuckets(opts *CommandOptions) error {
 	wg.Wait()
 	arch.ReportBucketStats()
 	close(tick)
-	if errs != 0 {
-		return fmt.Errorf("%d errors while scanning buckets", errs)
-	}
-	return nil
+	return errs, invalid
 }
 
 func (arch *Archive) ClearCachedInfo() {
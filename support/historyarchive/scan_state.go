@@ -0,0 +1,471 @@
+// Copyright 2016 DiamNet Development Foundation and contributors. Licensed
+// under the Apache License, Version 2.0. See the COPYING file at the root
+// of this distribution or at http://www.apache.org/licenses/LICENSE-2.0
+
+package historyarchive
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/diamnet/go/support/errors"
+)
+
+// scanChunkCheckpoints is the number of checkpoints scanned by
+// ScanIncrementally between two saves of the scan state.
+var scanChunkCheckpoints = uint32(1024)
+
+// ScanReport lists the problems found by a scan, it's meant to be read by
+// other programs once encoded to JSON.
+type ScanReport struct {
+	// Range contains the checkpoints scanned, from Range.Low up to but
+	// excluding Range.High.
+	Range Range `json:"range"`
+	// MissingCheckpointFiles lists the missing checkpoints of each required
+	// category.
+	MissingCheckpointFiles map[string][]uint32 `json:"missing_checkpoint_files"`
+	MissingBuckets         []Hash              `json:"missing_buckets"`
+	// InvalidBuckets, InvalidLedgers, InvalidTxSets and InvalidTxResultSets
+	// are only filled when verifying files.
+	InvalidBuckets      []Hash   `json:"invalid_buckets"`
+	InvalidLedgers      []uint32 `json:"invalid_ledgers"`
+	InvalidTxSets       []uint32 `json:"invalid_tx_sets"`
+	InvalidTxResultSets []uint32 `json:"invalid_tx_result_sets"`
+}
+
+func newScanReport(rng Range) ScanReport {
+	return ScanReport{
+		Range:                  rng,
+		MissingCheckpointFiles: map[string][]uint32{},
+		MissingBuckets:         []Hash{},
+		InvalidBuckets:         []Hash{},
+		InvalidLedgers:         []uint32{},
+		InvalidTxSets:          []uint32{},
+		InvalidTxResultSets:    []uint32{},
+	}
+}
+
+// Problems returns the number of missing or invalid files, buckets,
+// ledgers and transaction sets in the report.
+func (r *ScanReport) Problems() int {
+	n := len(r.MissingBuckets) + len(r.InvalidBuckets) +
+		len(r.InvalidLedgers) + len(r.InvalidTxSets) + len(r.InvalidTxResultSets)
+	for _, missing := range r.MissingCheckpointFiles {
+		n += len(missing)
+	}
+	return n
+}
+
+// merge adds the problems of other to the report.
+func (r *ScanReport) merge(other ScanReport) {
+	if r.MissingCheckpointFiles == nil {
+		r.MissingCheckpointFiles = map[string][]uint32{}
+	}
+	for cat, missing := range other.MissingCheckpointFiles {
+		r.MissingCheckpointFiles[cat] = mergeUint32s(r.MissingCheckpointFiles[cat], missing)
+	}
+	r.MissingBuckets = mergeHashes(r.MissingBuckets, other.MissingBuckets)
+	r.InvalidBuckets = mergeHashes(r.InvalidBuckets, other.InvalidBuckets)
+	r.InvalidLedgers = mergeUint32s(r.InvalidLedgers, other.InvalidLedgers)
+	r.InvalidTxSets = mergeUint32s(r.InvalidTxSets, other.InvalidTxSets)
+	r.InvalidTxResultSets = mergeUint32s(r.InvalidTxResultSets, other.InvalidTxResultSets)
+}
+
+// replaceCheckpoint replaces the missing files and invalid ledgers and
+// transaction sets of the checkpoint chk with the ones found in other.
+func (r *ScanReport) replaceCheckpoint(chk uint32, other ScanReport) {
+	inCheckpoint := func(seq uint32) bool {
+		return NextCheckpoint(seq) == chk
+	}
+	outOfCheckpoint := func(seq uint32) bool {
+		return !inCheckpoint(seq)
+	}
+
+	for cat, missing := range r.MissingCheckpointFiles {
+		r.MissingCheckpointFiles[cat] = filterUint32s(missing, outOfCheckpoint)
+		if len(r.MissingCheckpointFiles[cat]) == 0 {
+			delete(r.MissingCheckpointFiles, cat)
+		}
+	}
+	r.InvalidLedgers = filterUint32s(r.InvalidLedgers, outOfCheckpoint)
+	r.InvalidTxSets = filterUint32s(r.InvalidTxSets, outOfCheckpoint)
+	r.InvalidTxResultSets = filterUint32s(r.InvalidTxResultSets, outOfCheckpoint)
+
+	found := ScanReport{MissingCheckpointFiles: map[string][]uint32{}}
+	for cat, missing := range other.MissingCheckpointFiles {
+		if missing = filterUint32s(missing, inCheckpoint); len(missing) != 0 {
+			found.MissingCheckpointFiles[cat] = missing
+		}
+	}
+	found.InvalidLedgers = filterUint32s(other.InvalidLedgers, inCheckpoint)
+	found.InvalidTxSets = filterUint32s(other.InvalidTxSets, inCheckpoint)
+	found.InvalidTxResultSets = filterUint32s(other.InvalidTxResultSets, inCheckpoint)
+	r.merge(found)
+}
+
+func filterUint32s(values []uint32, keep func(uint32) bool) []uint32 {
+	filtered := []uint32{}
+	for _, v := range values {
+		if keep(v) {
+			filtered = append(filtered, v)
+		}
+	}
+	return filtered
+}
+
+func mergeUint32s(a, b []uint32) []uint32 {
+	seen := make(map[uint32]bool, len(a)+len(b))
+	merged := []uint32{}
+	for _, v := range append(append([]uint32{}, a...), b...) {
+		if !seen[v] {
+			seen[v] = true
+			merged = append(merged, v)
+		}
+	}
+	sort.Sort(byUint32(merged))
+	return merged
+}
+
+func mergeHashes(a, b []Hash) []Hash {
+	seen := make(map[Hash]bool, len(a)+len(b))
+	merged := []Hash{}
+	for _, h := range append(append([]Hash{}, a...), b...) {
+		if !seen[h] {
+			seen[h] = true
+			merged = append(merged, h)
+		}
+	}
+	sortHashes(merged)
+	return merged
+}
+
+func sortHashes(hashes []Hash) {
+	sort.Slice(hashes, func(i, j int) bool {
+		return bytes.Compare(hashes[i][:], hashes[j][:]) < 0
+	})
+}
+
+// Report returns the problems found by the last scan of opts.Range. Invalid
+// buckets, ledgers and transaction sets are only known once ReportInvalid
+// has been called.
+func (arch *Archive) Report(opts *CommandOptions) ScanReport {
+	report := newScanReport(opts.Range)
+	for cat, missing := range arch.CheckCheckpointFilesMissing(opts) {
+		if categoryRequired(cat) && len(missing) != 0 {
+			sort.Sort(byUint32(missing))
+			report.MissingCheckpointFiles[cat] = missing
+		}
+	}
+	for bucket := range arch.CheckBucketsMissing() {
+		report.MissingBuckets = append(report.MissingBuckets, bucket)
+	}
+	sortHashes(report.MissingBuckets)
+
+	arch.mutex.Lock()
+	defer arch.mutex.Unlock()
+	for bucket := range arch.invalidBuckets {
+		report.InvalidBuckets = append(report.InvalidBuckets, bucket)
+	}
+	sortHashes(report.InvalidBuckets)
+	report.InvalidLedgers = append(report.InvalidLedgers, arch.invalidLedgers...)
+	report.InvalidTxSets = append(report.InvalidTxSets, arch.invalidTxSets...)
+	report.InvalidTxResultSets = append(report.InvalidTxResultSets, arch.invalidTxResultSets...)
+	return report
+}
+
+// ScanState is the progress of ScanIncrementally, saved to a file between
+// runs.
+type ScanState struct {
+	Verify   bool `json:"verify"`
+	Thorough bool `json:"thorough"`
+	// Next is the first checkpoint the next run scans, Next is 0 when nothing
+	// was scanned yet.
+	Next uint32 `json:"next"`
+	// LastLedgerHash is the hash of the ledger header of the checkpoint
+	// before Next, used to verify the chain of ledgers continues in the next
+	// checkpoints.
+	LastLedgerHash *Hash `json:"last_ledger_hash,omitempty"`
+	// CheckedBuckets are the buckets referenced by the checkpoint before Next
+	// which were found (and verified), they are not checked again when
+	// referenced by the next checkpoints.
+	CheckedBuckets []Hash `json:"checked_buckets"`
+	// Report contains all the problems found since the first run.
+	Report ScanReport `json:"report"`
+}
+
+// LoadScanState reads the scan state saved in path, an empty state is
+// returned if the file doesn't exist.
+func LoadScanState(path string) (*ScanState, error) {
+	state := &ScanState{
+		CheckedBuckets: []Hash{},
+		Report:         newScanReport(Range{}),
+	}
+	buf, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read scan state")
+	}
+	if err = json.Unmarshal(buf, state); err != nil {
+		return nil, errors.Wrap(err, "could not decode scan state")
+	}
+	return state, nil
+}
+
+// Save writes the scan state to path. The file is replaced atomically so an
+// interrupted save doesn't lose the previous state.
+func (s *ScanState) Save(path string) error {
+	buf, err := json.MarshalIndent(s, "", "    ")
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(path), ".scan-state-")
+	if err != nil {
+		return errors.Wrap(err, "could not create scan state file")
+	}
+	_, err = tmp.Write(buf)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return errors.Wrap(err, "could not write scan state")
+	}
+	return nil
+}
+
+// ScanIncrementally scans the checkpoints of opts.Range not scanned by
+// previous runs, saving its progress to the scan state in statePath every
+// 1024 checkpoints. An interrupted scan resumes from the last save, and once
+// a scan is complete the next run only scans the checkpoints published since.
+//
+// The returned state contains the problems found by every run. Missing and
+// invalid files are reported without stopping the scan, but other errors,
+// like failing to read from the archive, stop it without saving the progress
+// of the current checkpoints so they are scanned again by the next run. The
+// problems found by the previous runs are checked again first, the ones which
+// were repaired since are removed from the report.
+func (arch *Archive) ScanIncrementally(opts *CommandOptions, statePath string) (*ScanState, error) {
+	if opts.Concurrency == 0 {
+		return nil, errors.New("Zero concurrency")
+	}
+
+	state, err := LoadScanState(statePath)
+	if err != nil {
+		return nil, err
+	}
+
+	has, err := arch.GetRootHAS()
+	if err != nil {
+		return state, err
+	}
+	rng := opts.Range.clamp(has.Range())
+
+	if state.Next != 0 && state.Report.Problems() != 0 &&
+		state.Verify == opts.Verify && state.Thorough == opts.Thorough {
+		if err = arch.recheckReport(opts, state); err != nil {
+			return state, err
+		}
+		if err = state.Save(statePath); err != nil {
+			return state, err
+		}
+	}
+
+	if state.Next == 0 {
+		state.Verify = opts.Verify
+		state.Thorough = opts.Thorough
+		state.Report.Range.Low = rng.Low
+	} else {
+		if state.Verify != opts.Verify || state.Thorough != opts.Thorough {
+			return state, errors.New("scan state was saved with different verify or thorough options")
+		}
+		if rng.Low > state.Next {
+			return state, errors.Errorf("scan state ends at checkpoint 0x%8.8x, scanning from 0x%8.8x would leave a gap",
+				state.Next, rng.Low)
+		}
+		rng.Low = state.Next
+	}
+
+	if rng.Low >= rng.High {
+		log.Printf("No new checkpoints to scan since 0x%8.8x", rng.Low)
+		return state, nil
+	}
+	log.Printf("Scanning checkpoint files in range: %s", rng)
+
+	doList := arch.backend.CanListFiles()
+	if doList {
+		if err = arch.ScanAllBuckets(); err != nil {
+			return state, err
+		}
+	}
+
+	for low := rng.Low; low < rng.High; {
+		high := rng.High
+		if uint64(low)+uint64(scanChunkCheckpoints*CheckpointFreq) < uint64(high) {
+			high = low + scanChunkCheckpoints*CheckpointFreq
+		}
+		chunkOpts := *opts
+		chunkOpts.Range = Range{Low: low, High: high}
+		if err = arch.scanChunk(&chunkOpts, state, doList); err != nil {
+			return state, err
+		}
+		if err = state.Save(statePath); err != nil {
+			return state, err
+		}
+		low = high
+	}
+	return state, nil
+}
+
+// scanChunk scans the checkpoints of opts.Range following the ones recorded
+// in state, and records them in state.
+func (arch *Archive) scanChunk(opts *CommandOptions, state *ScanState, doList bool) error {
+	log.Printf("Scanning checkpoints %s", opts.Range)
+	arch.clearScanResults(state)
+
+	errs := noteError(arch.scanCheckpointsInRange(opts))
+	n, _ := arch.scanReferencedBuckets(opts, doList)
+	errs += n
+	if errs != 0 {
+		return fmt.Errorf("%d errors scanning checkpoints %s", errs, opts.Range)
+	}
+
+	arch.ReportMissing(opts)
+	// Invalid files are recorded in the report.
+	arch.ReportInvalid(opts)
+	state.Report.merge(arch.Report(opts))
+	state.Report.Range.High = opts.Range.High
+
+	last := opts.Range.High - CheckpointFreq
+	state.Next = opts.Range.High
+	state.LastLedgerHash = nil
+	arch.mutex.Lock()
+	if h, ok := arch.actualLedgerHashes[last]; ok {
+		state.LastLedgerHash = &h
+	}
+	arch.mutex.Unlock()
+	state.CheckedBuckets = arch.checkedBuckets(last)
+	return nil
+}
+
+// recheckReport checks again the problems recorded in state and removes the
+// ones which were repaired. Checkpoints with missing files or invalid ledgers
+// are scanned again, with the next checkpoint which verifies the hash of their
+// last ledger.
+func (arch *Archive) recheckReport(opts *CommandOptions, state *ScanState) error {
+	report := &state.Report
+	log.Printf("Checking again the %d problems found by previous scans", report.Problems())
+
+	checkpoints := map[uint32]bool{}
+	for _, missing := range report.MissingCheckpointFiles {
+		for _, chk := range missing {
+			checkpoints[chk] = true
+		}
+	}
+	for _, invalid := range [][]uint32{report.InvalidLedgers, report.InvalidTxSets, report.InvalidTxResultSets} {
+		for _, seq := range invalid {
+			checkpoints[NextCheckpoint(seq)] = true
+		}
+	}
+	sorted := make([]uint32, 0, len(checkpoints))
+	for chk := range checkpoints {
+		sorted = append(sorted, chk)
+	}
+	sort.Sort(byUint32(sorted))
+
+	for _, chk := range sorted {
+		chkOpts := *opts
+		chkOpts.Range = Range{Low: chk, High: chk + 2*CheckpointFreq}
+		if chkOpts.Range.High > state.Next {
+			chkOpts.Range.High = state.Next
+		}
+		arch.clearScanResults(&ScanState{})
+		if err := arch.scanCheckpointsInRange(&chkOpts); err != nil {
+			return err
+		}
+		arch.ReportInvalid(&chkOpts)
+		report.replaceCheckpoint(chk, arch.Report(&chkOpts))
+	}
+
+	missingBuckets := []Hash{}
+	invalidBuckets := []Hash{}
+	for _, bucket := range append(append([]Hash{}, report.MissingBuckets...), report.InvalidBuckets...) {
+		exists, err := arch.BucketExists(bucket)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			missingBuckets = append(missingBuckets, bucket)
+		} else if state.Verify && noteError(arch.VerifyBucketHash(bucket)) != 0 {
+			invalidBuckets = append(invalidBuckets, bucket)
+		}
+	}
+	report.MissingBuckets = mergeHashes(missingBuckets, nil)
+	report.InvalidBuckets = mergeHashes(invalidBuckets, nil)
+
+	log.Printf("%d problems remain", report.Problems())
+	return nil
+}
+
+// clearScanResults forgets the results of the previous scans, except the
+// list of all buckets, and restores the ones recorded in state.
+func (arch *Archive) clearScanResults(state *ScanState) {
+	arch.mutex.Lock()
+	defer arch.mutex.Unlock()
+	for _, cat := range Categories() {
+		arch.checkpointFiles[cat] = make(map[uint32]bool)
+	}
+	arch.referencedBuckets = make(map[Hash]bool)
+	arch.expectLedgerHashes = make(map[uint32]Hash)
+	arch.actualLedgerHashes = make(map[uint32]Hash)
+	arch.expectTxSetHashes = make(map[uint32]Hash)
+	arch.actualTxSetHashes = make(map[uint32]Hash)
+	arch.expectTxResultSetHashes = make(map[uint32]Hash)
+	arch.actualTxResultSetHashes = make(map[uint32]Hash)
+	arch.invalidBuckets = make(map[Hash]bool)
+	arch.invalidLedgers = nil
+	arch.invalidTxSets = nil
+	arch.invalidTxResultSets = nil
+
+	for _, bucket := range state.CheckedBuckets {
+		arch.referencedBuckets[bucket] = true
+		arch.allBuckets[bucket] = true
+	}
+	if state.LastLedgerHash != nil && state.Next > CheckpointFreq {
+		// The first ledger scanned points to the last ledger of the
+		// previous run.
+		arch.actualLedgerHashes[state.Next-CheckpointFreq] = *state.LastLedgerHash
+	}
+}
+
+// checkedBuckets returns the buckets referenced by a checkpoint which were
+// found and aren't invalid.
+func (arch *Archive) checkedBuckets(chk uint32) []Hash {
+	checked := []Hash{}
+	has, err := arch.GetCheckpointHAS(chk)
+	if err != nil {
+		return checked
+	}
+	buckets, err := has.Buckets()
+	if err != nil {
+		return checked
+	}
+	arch.mutex.Lock()
+	defer arch.mutex.Unlock()
+	for _, bucket := range buckets {
+		if arch.allBuckets[bucket] && !arch.invalidBuckets[bucket] {
+			checked = append(checked, bucket)
+		}
+	}
+	return checked
+}
@@ -0,0 +1,176 @@
+// Copyright 2016 DiamNet Development Foundation and contributors. Licensed
+// under the Apache License, Version 2.0. See the COPYING file at the root
+// of this distribution or at http://www.apache.org/licenses/LICENSE-2.0
+
+package historyarchive
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingBackend fails to read one file of the wrapped backend
+type failingBackend struct {
+	ArchiveBackend
+	fail string
+}
+
+func (b *failingBackend) GetFile(pth string) (io.ReadCloser, error) {
+	if pth == b.fail {
+		return nil, errors.New("failing " + pth)
+	}
+	return b.ArchiveBackend.GetFile(pth)
+}
+
+func makeTestScanState(t *testing.T) (*Archive, *MockArchiveBackend, string, func()) {
+	chunk := scanChunkCheckpoints
+	scanChunkCheckpoints = 4
+	dir, err := ioutil.TempDir("", "scan-state")
+	require.NoError(t, err)
+
+	arch := GetRandomPopulatedArchive()
+	mock := arch.backend.(*MockArchiveBackend)
+	return arch, mock, filepath.Join(dir, "state.json"), func() {
+		scanChunkCheckpoints = chunk
+		os.RemoveAll(dir)
+	}
+}
+
+func TestScanIncrementally(t *testing.T) {
+	arch, mock, statePath, done := makeTestScanState(t)
+	defer done()
+	upstream := &countingBackend{mock, map[string]int{}}
+	arch.backend = upstream
+	opts := &CommandOptions{Range: MakeRange(0, 0xffffffff), Concurrency: 16}
+
+	state, err := arch.ScanIncrementally(opts, statePath)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(0x37f), state.Next)
+	assert.Equal(t, Range{Low: 63, High: 0x37f}, state.Report.Range)
+	assert.Equal(t, 0, state.Report.Problems())
+	assert.Len(t, state.CheckedBuckets, 3*NumLevels)
+	assert.Equal(t, 1, upstream.gets[CategoryCheckpointPath("history", 63)])
+
+	saved, err := LoadScanState(statePath)
+	require.NoError(t, err)
+	assert.Equal(t, state, saved)
+
+	// only the checkpoints published since the last run are scanned
+	require.NoError(t, arch.AddRandomCheckpoint(0x3bf))
+	require.NoError(t, arch.AddRandomCheckpoint(0x3ff))
+	results := mock.files[CategoryCheckpointPath("results", 0x3bf)]
+	delete(mock.files, CategoryCheckpointPath("results", 0x3bf))
+	upstream.gets = map[string]int{}
+
+	state, err = arch.ScanIncrementally(opts, statePath)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(0x3ff), state.Next)
+	assert.Equal(t, Range{Low: 63, High: 0x3ff}, state.Report.Range)
+	assert.Equal(t, map[string][]uint32{"results": {0x3bf}}, state.Report.MissingCheckpointFiles)
+	assert.Equal(t, 1, state.Report.Problems())
+	assert.Equal(t, 0, upstream.gets[CategoryCheckpointPath("history", 0x33f)])
+	assert.Equal(t, 1, upstream.gets[CategoryCheckpointPath("history", 0x37f)])
+
+	// problems which were not repaired are still reported
+	state, err = arch.ScanIncrementally(opts, statePath)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(0x3ff), state.Next)
+	assert.Equal(t, 1, state.Report.Problems())
+
+	// repaired problems are removed from the report
+	mock.files[CategoryCheckpointPath("results", 0x3bf)] = results
+	state, err = arch.ScanIncrementally(opts, statePath)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(0x3ff), state.Next)
+	assert.Empty(t, state.Report.MissingCheckpointFiles)
+	assert.Equal(t, 0, state.Report.Problems())
+
+	opts.Verify = true
+	_, err = arch.ScanIncrementally(opts, statePath)
+	assert.EqualError(t, err, "scan state was saved with different verify or thorough options")
+}
+
+func TestScanIncrementallyResume(t *testing.T) {
+	arch, mock, statePath, done := makeTestScanState(t)
+	defer done()
+	opts := &CommandOptions{Range: MakeRange(0, 0xffffffff), Concurrency: 16}
+
+	arch.backend = &failingBackend{mock, CategoryCheckpointPath("history", 0x1bf)}
+	_, err := arch.ScanIncrementally(opts, statePath)
+	assert.Error(t, err)
+	state, err := LoadScanState(statePath)
+	require.NoError(t, err)
+	// the first chunk of 4 checkpoints was scanned
+	assert.Equal(t, uint32(0x13f), state.Next)
+
+	upstream := &countingBackend{mock, map[string]int{}}
+	arch.backend = upstream
+	state, err = arch.ScanIncrementally(opts, statePath)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(0x37f), state.Next)
+	assert.Equal(t, Range{Low: 63, High: 0x37f}, state.Report.Range)
+	assert.Equal(t, 0, state.Report.Problems())
+	assert.Equal(t, 0, upstream.gets[CategoryCheckpointPath("history", 0xff)])
+	assert.Equal(t, 1, upstream.gets[CategoryCheckpointPath("history", 0x1bf)])
+}
+
+func TestScanReportMerge(t *testing.T) {
+	report := newScanReport(Range{})
+	report.merge(ScanReport{
+		MissingCheckpointFiles: map[string][]uint32{"ledger": {0xff, 0x3f}},
+		MissingBuckets:         []Hash{{2}, {1}},
+	})
+	report.merge(ScanReport{
+		MissingCheckpointFiles: map[string][]uint32{"ledger": {0x7f, 0x3f}},
+		MissingBuckets:         []Hash{{1}},
+		InvalidLedgers:         []uint32{10},
+	})
+	assert.Equal(t, []uint32{0x3f, 0x7f, 0xff}, report.MissingCheckpointFiles["ledger"])
+	assert.Equal(t, []Hash{{1}, {2}}, report.MissingBuckets)
+	assert.Equal(t, []uint32{10}, report.InvalidLedgers)
+	assert.Equal(t, 6, report.Problems())
+}
+
+func TestScanReportReplaceCheckpoint(t *testing.T) {
+	report := newScanReport(Range{})
+	report.merge(ScanReport{
+		MissingCheckpointFiles: map[string][]uint32{"ledger": {0x3f, 0x7f}, "results": {0x7f}},
+		InvalidLedgers:         []uint32{0x50, 0x90},
+	})
+	report.replaceCheckpoint(0x7f, ScanReport{
+		MissingCheckpointFiles: map[string][]uint32{"transactions": {0x7f, 0xbf}},
+		InvalidLedgers:         []uint32{0x41, 0x90},
+	})
+	assert.Equal(t, map[string][]uint32{"ledger": {0x3f}, "transactions": {0x7f}}, report.MissingCheckpointFiles)
+	assert.Equal(t, []uint32{0x41, 0x90}, report.InvalidLedgers)
+}
+
+func TestScanIncrementallyRecheckBuckets(t *testing.T) {
+	arch, mock, statePath, done := makeTestScanState(t)
+	defer done()
+	opts := &CommandOptions{Range: MakeRange(0, 0xffffffff), Concurrency: 16}
+
+	has, err := arch.GetCheckpointHAS(0x13f)
+	require.NoError(t, err)
+	buckets, err := has.Buckets()
+	require.NoError(t, err)
+	bucket := mock.files[BucketPath(buckets[0])]
+	delete(mock.files, BucketPath(buckets[0]))
+
+	state, err := arch.ScanIncrementally(opts, statePath)
+	require.NoError(t, err)
+	assert.Equal(t, []Hash{buckets[0]}, state.Report.MissingBuckets)
+
+	mock.files[BucketPath(buckets[0])] = bucket
+	state, err = arch.ScanIncrementally(opts, statePath)
+	require.NoError(t, err)
+	assert.Empty(t, state.Report.MissingBuckets)
+	assert.Equal(t, 0, state.Report.Problems())
+}
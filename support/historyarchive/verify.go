@@ -251,21 +251,22 @@ func reportValidity(ty string, nbad int, total int) {
 }
 
 func compareHashMaps(expect map[uint32]Hash, actual map[uint32]Hash, ty string,
-	passOn func(eledger uint32, ehash Hash) bool) int {
-	n := 0
+	passOn func(eledger uint32, ehash Hash) bool) []uint32 {
+	mismatched := []uint32{}
 	for eledger, ehash := range expect {
 		ahash, ok := actual[eledger]
 		if !ok && passOn(eledger, ehash) {
 			continue
 		}
 		if ahash != ehash {
-			n++
+			mismatched = append(mismatched, eledger)
 			log.Printf("Error: mismatched hash on %s 0x%8.8x: expected %s, got %s",
 				ty, eledger, ehash, ahash)
 		}
 	}
-	reportValidity(ty, n, len(expect))
-	return n
+	sort.Sort(byUint32(mismatched))
+	reportValidity(ty, len(mismatched), len(expect))
+	return mismatched
 }
 
 func (arch *Archive) ReportInvalid(opts *CommandOptions) error {
@@ -308,12 +309,12 @@ func (arch *Archive) ReportInvalid(opts *CommandOptions) error {
 			return ehash == emptyXdrArrayHash
 		})
 
-	reportValidity("bucket", arch.invalidBuckets, len(arch.referencedBuckets))
+	reportValidity("bucket", len(arch.invalidBuckets), len(arch.referencedBuckets))
 
-	totalInvalid := arch.invalidBuckets
-	totalInvalid += arch.invalidLedgers
-	totalInvalid += arch.invalidTxSets
-	totalInvalid += arch.invalidTxResultSets
+	totalInvalid := len(arch.invalidBuckets)
+	totalInvalid += len(arch.invalidLedgers)
+	totalInvalid += len(arch.invalidTxSets)
+	totalInvalid += len(arch.invalidTxResultSets)
 
 	if totalInvalid != 0 {
 		return fmt.Errorf("Detected %d objects with unexpected hashes", totalInvalid)
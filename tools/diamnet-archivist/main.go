@@ -5,7 +5,9 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"log"
 	"net/http"
 	_ "net/http/pprof"
@@ -44,6 +46,8 @@ type Options struct {
 	High        uint32
 	Last        int
 	Profile     bool
+	StateFile   string
+	ReportFile  string
 	CommandOpts historyarchive.CommandOptions
 	ConnectOpts historyarchive.ConnectOptions
 }
@@ -75,12 +79,17 @@ func (opts *Options) MaybeProfile() {
 func scan(a string, opts *Options) {
 	arch := historyarchive.MustConnect(a, opts.ConnectOpts)
 	opts.SetRange(arch)
+	if opts.StateFile != "" {
+		scanIncrementally(arch, opts)
+		return
+	}
 	e1 := arch.Scan(&opts.CommandOpts)
 	e2 := arch.ReportMissing(&opts.CommandOpts)
 	e3 := arch.ReportInvalid(&opts.CommandOpts)
 	if e1 != nil {
 		log.Fatal(e1)
 	}
+	writeReport(arch.Report(&opts.CommandOpts), opts)
 	if e2 != nil {
 		log.Fatal(e2)
 	}
@@ -89,6 +98,40 @@ func scan(a string, opts *Options) {
 	}
 }
 
+func scanIncrementally(arch *historyarchive.Archive, opts *Options) {
+	state, err := arch.ScanIncrementally(&opts.CommandOpts, opts.StateFile)
+	if state != nil {
+		writeReport(state.Report, opts)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	if n := state.Report.Problems(); n != 0 {
+		log.Fatalf("Archive has %d missing or invalid files since checkpoint 0x%8.8x",
+			n, state.Report.Range.Low)
+	}
+}
+
+// writeReport writes the JSON report to the --report file, "-" being stdout.
+func writeReport(report historyarchive.ScanReport, opts *Options) {
+	if opts.ReportFile == "" {
+		return
+	}
+	buf, err := json.MarshalIndent(report, "", "    ")
+	if err != nil {
+		log.Fatal(errors.Wrap(err, "Error encoding report"))
+	}
+	buf = append(buf, '\n')
+	if opts.ReportFile == "-" {
+		_, err = os.Stdout.Write(buf)
+	} else {
+		err = ioutil.WriteFile(opts.ReportFile, buf, 0644)
+	}
+	if err != nil {
+		log.Fatal(errors.Wrap(err, "Error writing report"))
+	}
+}
+
 func mirror(src string, dst string, opts *Options) {
 	srcArch := historyarchive.MustConnect(src, opts.ConnectOpts)
 	dstArch := historyarchive.MustConnect(dst, opts.ConnectOpts)
@@ -167,6 +210,20 @@ func main() {
 		"S3 endpoint to use",
 	)
 
+	rootCmd.PersistentFlags().StringVar(
+		&opts.ConnectOpts.CacheDir,
+		"cache-dir",
+		"",
+		"local directory caching the files read from archives",
+	)
+
+	rootCmd.PersistentFlags().Int64Var(
+		&opts.ConnectOpts.CacheSize,
+		"cache-size",
+		historyarchive.DefaultCacheSize,
+		"maximum size in bytes of the files in the cache directory",
+	)
+
 	rootCmd.PersistentFlags().BoolVarP(
 		&opts.CommandOpts.DryRun,
 		"dryrun",
@@ -197,6 +254,20 @@ func main() {
 		"decode and re-encode all buckets",
 	)
 
+	rootCmd.PersistentFlags().StringVar(
+		&opts.StateFile,
+		"state-file",
+		"",
+		"file saving the progress of scan, to resume it and only scan new checkpoints on the next run",
+	)
+
+	rootCmd.PersistentFlags().StringVar(
+		&opts.ReportFile,
+		"report",
+		"",
+		"file where scan writes a JSON report of missing and invalid files, - for stdout",
+	)
+
 	rootCmd.PersistentFlags().BoolVar(
 		&opts.Profile,
 		"profile",
@@ -1,7 +1,9 @@
 // diamnet-sign is a small interactive utility to help you contribute a
 // signature to a transaction envelope.
 //
-// It prompts you for a key
+// It prompts you for a key, or for the password of an encrypted key file
+// passed with -keyfile. With -aurora, it loads the signers and thresholds of the
+// source accounts and reports which thresholds are met by the signatures.
 package main
 
 import (
@@ -10,22 +12,52 @@ import (
 	"fmt"
 	"io/ioutil"
 	"log"
+	"net/http"
 	"os"
 	"strings"
 
 	"github.com/howeyc/gopass"
 	"github.com/diamnet/go/build"
+	"github.com/diamnet/go/clients/auroraclient"
+	"github.com/diamnet/go/keypair"
+	"github.com/diamnet/go/network"
+	"github.com/diamnet/go/txnbuild"
 	"github.com/diamnet/go/xdr"
 )
 
 var in *bufio.Reader
 
 var infile = flag.String("infile", "", "transaction envelope")
+var keyfile = flag.String("keyfile", "", "encrypted key file to sign with instead of a seed")
+var createKeyfile = flag.Bool("create-keyfile", false, "encrypt a seed into a new key file at -keyfile and exit")
+var auroraURL = flag.String("aurora", "", "aurora server to load the signers of the source accounts from, to report which thresholds are met")
+var check = flag.Bool("check", false, "only report which thresholds are met, without signing, requires -aurora")
+var testnet = flag.Bool("testnet", false, "sign for the test network instead of the public network")
 
 func main() {
 	flag.Parse()
 	in = bufio.NewReader(os.Stdin)
 
+	if *createKeyfile {
+		if *keyfile == "" {
+			log.Fatal("-create-keyfile requires -keyfile")
+		}
+		err := createKeyFile(*keyfile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if *check && *auroraURL == "" {
+		log.Fatal("-check requires -aurora")
+	}
+
+	passphrase := network.PublicNetworkPassphrase
+	if *testnet {
+		passphrase = network.TestNetworkPassphrase
+	}
+
 	var (
 		env string
 		err error
@@ -70,8 +102,16 @@ func main() {
 
 	// TODO: add operation details
 
+	if *check {
+		err = reportSignatures(env, passphrase)
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	// read seed
-	seed, err := readLine("Enter seed: ", true)
+	seed, err := readSeed()
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -79,7 +119,7 @@ func main() {
 	// sign the transaction
 	b := &build.TransactionEnvelopeBuilder{E: &txe}
 	b.Init()
-	err = b.MutateTX(build.PublicNetwork)
+	err = b.MutateTX(build.Network{Passphrase: passphrase})
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -93,6 +133,15 @@ func main() {
 		log.Fatal(err)
 	}
 
+	// the signature is rejected if it doesn't belong to a signer of the source
+	// accounts or duplicates another signature
+	if *auroraURL != "" {
+		err = reportSignatures(newEnv, passphrase)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
 	fmt.Print("\n==== Result ====\n\n")
 	fmt.Print("```\n")
 	fmt.Println(newEnv)
@@ -100,6 +149,123 @@ func main() {
 
 }
 
+// reportSignatures prints, for the transaction and each of its operations,
+// whether the threshold of the source account is met and which signers are
+// still needed. An error is returned when the envelope has duplicate
+// signatures or signatures from keys which aren't signers of the source
+// accounts.
+func reportSignatures(env, passphrase string) error {
+	tx, err := txnbuild.TransactionFromXDR(strings.TrimSpace(env))
+	if err != nil {
+		return err
+	}
+	tx.Network = passphrase
+
+	client := &auroraclient.Client{AuroraURL: *auroraURL, HTTP: http.DefaultClient}
+	accounts := map[string]txnbuild.AccountSigners{}
+	for _, accountID := range tx.SigningAccounts() {
+		account, err := client.AccountDetail(auroraclient.AccountRequest{AccountID: accountID})
+		if err != nil {
+			return fmt.Errorf("loading account %s: %v", accountID, err)
+		}
+		accounts[accountID] = txnbuild.AccountSigners{
+			LowThreshold:  txnbuild.Threshold(account.Thresholds.LowThreshold),
+			MedThreshold:  txnbuild.Threshold(account.Thresholds.MedThreshold),
+			HighThreshold: txnbuild.Threshold(account.Thresholds.HighThreshold),
+			Signers:       account.SignerSummary(),
+		}
+	}
+
+	report, err := txnbuild.CheckSignatures(tx, accounts)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("")
+	fmt.Println("Signatures:")
+	fmt.Printf("  signed by: %s\n", strings.Join(report.Signers, ", "))
+	printThresholdStatus("transaction", report.Transaction)
+	for i, status := range report.Operations {
+		printThresholdStatus(fmt.Sprintf("op %d", i), status)
+	}
+	if report.Met() {
+		fmt.Println("  the transaction is sufficiently signed")
+	}
+	fmt.Println("")
+	return nil
+}
+
+func printThresholdStatus(name string, status txnbuild.ThresholdStatus) {
+	met := "met"
+	if !status.Met {
+		met = "NOT met"
+	}
+	fmt.Printf("  %s: %s threshold of %s %s (weight %d of %d)\n",
+		name, status.Category, status.AccountID, met, status.Weight, status.Threshold)
+	if !status.Met && len(status.MissingSigners) > 0 {
+		fmt.Printf("    missing signers: %s\n", strings.Join(status.MissingSigners, ", "))
+	}
+}
+
+// readSeed reads the seed from the terminal or decrypts it from the key file.
+func readSeed() (string, error) {
+	if *keyfile == "" {
+		return readLine("Enter seed: ", true)
+	}
+
+	f, err := keypair.LoadKeyFile(*keyfile)
+	if err != nil {
+		return "", err
+	}
+	fmt.Printf("Signing with %s\n", f.Address)
+
+	password, err := readLine("Enter key file password: ", true)
+	if err != nil {
+		return "", err
+	}
+	kp, err := f.Decrypt([]byte(password))
+	if err != nil {
+		return "", err
+	}
+	return kp.Seed(), nil
+}
+
+// createKeyFile encrypts a seed read from the terminal into a new key file at
+// path.
+func createKeyFile(path string) error {
+	seed, err := readLine("Enter seed: ", true)
+	if err != nil {
+		return err
+	}
+	kp, err := keypair.Parse(seed)
+	if err != nil {
+		return err
+	}
+	full, ok := kp.(*keypair.Full)
+	if !ok {
+		return fmt.Errorf("%s is not a seed", seed)
+	}
+
+	password, err := readLine("Enter key file password: ", true)
+	if err != nil {
+		return err
+	}
+	confirmation, err := readLine("Confirm key file password: ", true)
+	if err != nil {
+		return err
+	}
+	if password != confirmation {
+		return fmt.Errorf("passwords don't match")
+	}
+
+	err = keypair.SaveKeyFile(path, full, []byte(password))
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Saved the seed of %s to %s\n", full.Address(), path)
+	return nil
+}
+
 func readLine(prompt string, private bool) (string, error) {
 	fmt.Println(prompt)
 	var line string
@@ -30,3 +30,15 @@ func (am *AccountMerge) BuildXDR() (xdr.Operation, error) {
 	SetOpSourceAccount(&op, am.SourceAccount)
 	return op, nil
 }
+
+// FromXDR for AccountMerge initialises the txnbuild struct from the corresponding xdr Operation.
+func (am *AccountMerge) FromXDR(xdrOp xdr.Operation) error {
+	destination, ok := xdrOp.Body.GetDestination()
+	if !ok {
+		return errors.New("error parsing account_merge operation from xdr")
+	}
+
+	am.SourceAccount = accountFromXDR(xdrOp.SourceAccount)
+	am.Destination = destination.Address()
+	return nil
+}
@@ -1,6 +1,8 @@
 package txnbuild
 
 import (
+	"strings"
+
 	"github.com/diamnet/go/support/errors"
 	"github.com/diamnet/go/xdr"
 )
@@ -49,3 +51,30 @@ func (at *AllowTrust) BuildXDR() (xdr.Operation, error) {
 	SetOpSourceAccount(&op, at.SourceAccount)
 	return op, nil
 }
+
+// FromXDR for AllowTrust initialises the txnbuild struct from the corresponding xdr Operation.
+// The XDR operation only contains the code of the asset so the issuer of Type is left empty.
+func (at *AllowTrust) FromXDR(xdrOp xdr.Operation) error {
+	result, ok := xdrOp.Body.GetAllowTrustOp()
+	if !ok {
+		return errors.New("error parsing allow_trust operation from xdr")
+	}
+
+	var code string
+	switch result.Asset.Type {
+	case xdr.AssetTypeAssetTypeCreditAlphanum4:
+		assetCode := result.Asset.MustAssetCode4()
+		code = strings.TrimRight(string(assetCode[:]), "\x00")
+	case xdr.AssetTypeAssetTypeCreditAlphanum12:
+		assetCode := result.Asset.MustAssetCode12()
+		code = strings.TrimRight(string(assetCode[:]), "\x00")
+	default:
+		return errors.New("invalid asset type for allow_trust operation")
+	}
+
+	at.SourceAccount = accountFromXDR(xdrOp.SourceAccount)
+	at.Trustor = result.Trustor.Address()
+	at.Type = CreditAsset{Code: code}
+	at.Authorize = result.Authorize
+	return nil
+}
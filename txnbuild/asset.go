@@ -96,3 +96,34 @@ func (ca CreditAsset) ToXDR() (xdr.Asset, error) {
 
 	return xdrAsset, nil
 }
+
+// assetFromXDR returns the Asset corresponding to an XDR asset.
+func assetFromXDR(xAsset xdr.Asset) (Asset, error) {
+	var assetType, code, issuer string
+	err := xAsset.Extract(&assetType, &code, &issuer)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to extract asset")
+	}
+
+	switch xAsset.Type {
+	case xdr.AssetTypeAssetTypeNative:
+		return NativeAsset{}, nil
+	case xdr.AssetTypeAssetTypeCreditAlphanum4, xdr.AssetTypeAssetTypeCreditAlphanum12:
+		return CreditAsset{Code: code, Issuer: issuer}, nil
+	}
+
+	return nil, errors.Errorf("unknown asset type: %d", xAsset.Type)
+}
+
+// assetsFromXDR returns the Assets corresponding to a list of XDR assets.
+func assetsFromXDR(xAssets []xdr.Asset) ([]Asset, error) {
+	var assets []Asset
+	for _, xAsset := range xAssets {
+		asset, err := assetFromXDR(xAsset)
+		if err != nil {
+			return nil, err
+		}
+		assets = append(assets, asset)
+	}
+	return assets, nil
+}
@@ -61,3 +61,21 @@ func (ct *ChangeTrust) BuildXDR() (xdr.Operation, error) {
 	SetOpSourceAccount(&op, ct.SourceAccount)
 	return op, nil
 }
+
+// FromXDR for ChangeTrust initialises the txnbuild struct from the corresponding xdr Operation.
+func (ct *ChangeTrust) FromXDR(xdrOp xdr.Operation) error {
+	result, ok := xdrOp.Body.GetChangeTrustOp()
+	if !ok {
+		return errors.New("error parsing change_trust operation from xdr")
+	}
+
+	line, err := assetFromXDR(result.Line)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse 'Line'")
+	}
+
+	ct.SourceAccount = accountFromXDR(xdrOp.SourceAccount)
+	ct.Line = line
+	ct.Limit = amount.String(result.Limit)
+	return nil
+}
@@ -55,3 +55,28 @@ func (cpo *CreatePassiveSellOffer) BuildXDR() (xdr.Operation, error) {
 	SetOpSourceAccount(&op, cpo.SourceAccount)
 	return op, nil
 }
+
+// FromXDR for CreatePassiveSellOffer initialises the txnbuild struct from the corresponding xdr Operation.
+func (cpo *CreatePassiveSellOffer) FromXDR(xdrOp xdr.Operation) error {
+	result, ok := xdrOp.Body.GetCreatePassiveSellOfferOp()
+	if !ok {
+		return errors.New("error parsing create_passive_sell_offer operation from xdr")
+	}
+
+	selling, err := assetFromXDR(result.Selling)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse 'Selling'")
+	}
+
+	buying, err := assetFromXDR(result.Buying)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse 'Buying'")
+	}
+
+	cpo.SourceAccount = accountFromXDR(xdrOp.SourceAccount)
+	cpo.Selling = selling
+	cpo.Buying = buying
+	cpo.Amount = amount.String(result.Amount)
+	cpo.Price = result.Price.String()
+	return nil
+}
@@ -516,3 +516,27 @@ func ExampleBuildChallengeTx() {
 
 	check(err)
 }
+
+func ExampleVerifyChallengeTxThreshold() {
+	serverAccountID := "GB7BDSZU2Y27LYNLALKKALB52WS2IZWYBDGY6EQBLEED3TJOCVMZRH7H"
+	// signed challenge received from the client
+	challengeTx := "AAAAAH4RyzTWNfXhqwLUoCw91aWkZtgIzY8SAVkIPc0uFVmY..."
+
+	_, clientAccountID, err := ReadChallengeTx(challengeTx, serverAccountID, network.TestNetworkPassphrase)
+	check(err)
+
+	// load the signers and thresholds of the client account
+	client := auroraclient.DefaultTestNetClient
+	account, err := client.AccountDetail(auroraclient.AccountRequest{AccountID: clientAccountID})
+	check(err)
+
+	signersFound, err := VerifyChallengeTxThreshold(
+		challengeTx,
+		serverAccountID,
+		network.TestNetworkPassphrase,
+		Threshold(account.Thresholds.MedThreshold),
+		account.SignerSummary(),
+	)
+	check(err)
+	fmt.Println(signersFound)
+}
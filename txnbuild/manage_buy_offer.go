@@ -57,3 +57,29 @@ func (mo *ManageBuyOffer) BuildXDR() (xdr.Operation, error) {
 	SetOpSourceAccount(&op, mo.SourceAccount)
 	return op, nil
 }
+
+// FromXDR for ManageBuyOffer initialises the txnbuild struct from the corresponding xdr Operation.
+func (mo *ManageBuyOffer) FromXDR(xdrOp xdr.Operation) error {
+	result, ok := xdrOp.Body.GetManageBuyOfferOp()
+	if !ok {
+		return errors.New("error parsing manage_buy_offer operation from xdr")
+	}
+
+	selling, err := assetFromXDR(result.Selling)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse 'Selling'")
+	}
+
+	buying, err := assetFromXDR(result.Buying)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse 'Buying'")
+	}
+
+	mo.SourceAccount = accountFromXDR(xdrOp.SourceAccount)
+	mo.Selling = selling
+	mo.Buying = buying
+	mo.Amount = amount.String(result.BuyAmount)
+	mo.Price = result.Price.String()
+	mo.OfferID = int64(result.OfferId)
+	return nil
+}
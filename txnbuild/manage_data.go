@@ -34,3 +34,19 @@ func (md *ManageData) BuildXDR() (xdr.Operation, error) {
 	SetOpSourceAccount(&op, md.SourceAccount)
 	return op, nil
 }
+
+// FromXDR for ManageData initialises the txnbuild struct from the corresponding xdr Operation.
+func (md *ManageData) FromXDR(xdrOp xdr.Operation) error {
+	result, ok := xdrOp.Body.GetManageDataOp()
+	if !ok {
+		return errors.New("error parsing manage_data operation from xdr")
+	}
+
+	md.SourceAccount = accountFromXDR(xdrOp.SourceAccount)
+	md.Name = string(result.DataName)
+	md.Value = nil
+	if result.DataValue != nil {
+		md.Value = []byte(*result.DataValue)
+	}
+	return nil
+}
@@ -121,3 +121,29 @@ func (mo *ManageSellOffer) BuildXDR() (xdr.Operation, error) {
 	SetOpSourceAccount(&op, mo.SourceAccount)
 	return op, nil
 }
+
+// FromXDR for ManageSellOffer initialises the txnbuild struct from the corresponding xdr Operation.
+func (mo *ManageSellOffer) FromXDR(xdrOp xdr.Operation) error {
+	result, ok := xdrOp.Body.GetManageSellOfferOp()
+	if !ok {
+		return errors.New("error parsing manage_sell_offer operation from xdr")
+	}
+
+	selling, err := assetFromXDR(result.Selling)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse 'Selling'")
+	}
+
+	buying, err := assetFromXDR(result.Buying)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse 'Buying'")
+	}
+
+	mo.SourceAccount = accountFromXDR(xdrOp.SourceAccount)
+	mo.Selling = selling
+	mo.Buying = buying
+	mo.Amount = amount.String(result.Amount)
+	mo.Price = result.Price.String()
+	mo.OfferID = int64(result.OfferId)
+	return nil
+}
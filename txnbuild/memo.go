@@ -49,3 +49,22 @@ func (mh MemoHash) ToXDR() (xdr.Memo, error) {
 func (mr MemoReturn) ToXDR() (xdr.Memo, error) {
 	return xdr.NewMemo(xdr.MemoTypeMemoReturn, xdr.Hash(mr))
 }
+
+// memoFromXDR returns the Memo corresponding to an XDR memo, or nil when the
+// memo type is MemoTypeMemoNone.
+func memoFromXDR(memo xdr.Memo) (Memo, error) {
+	switch memo.Type {
+	case xdr.MemoTypeMemoNone:
+		return nil, nil
+	case xdr.MemoTypeMemoText:
+		return MemoText(memo.MustText()), nil
+	case xdr.MemoTypeMemoId:
+		return MemoID(memo.MustId()), nil
+	case xdr.MemoTypeMemoHash:
+		return MemoHash(memo.MustHash()), nil
+	case xdr.MemoTypeMemoReturn:
+		return MemoReturn(memo.MustRetHash()), nil
+	}
+
+	return nil, fmt.Errorf("unknown memo type: %d", memo.Type)
+}
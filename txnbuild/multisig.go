@@ -0,0 +1,267 @@
+package txnbuild
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"sort"
+
+	"github.com/diamnet/go/keypair"
+	"github.com/diamnet/go/strkey"
+	"github.com/diamnet/go/support/errors"
+	"github.com/diamnet/go/xdr"
+)
+
+// ThresholdCategory is the threshold of the source account an operation must meet. See
+// https://www.diamnet.org/developers/guides/concepts/multi-sig.html#thresholds
+type ThresholdCategory string
+
+// ThresholdCategory values
+const (
+	ThresholdLow    ThresholdCategory = "low"
+	ThresholdMedium ThresholdCategory = "medium"
+	ThresholdHigh   ThresholdCategory = "high"
+)
+
+// AccountSigners contains the signers and thresholds of an account, usually loaded from
+// aurora:
+//
+//	account, err := client.AccountDetail(auroraclient.AccountRequest{AccountID: accountID})
+//	...
+//	signers := txnbuild.AccountSigners{
+//		LowThreshold:  txnbuild.Threshold(account.Thresholds.LowThreshold),
+//		MedThreshold:  txnbuild.Threshold(account.Thresholds.MedThreshold),
+//		HighThreshold: txnbuild.Threshold(account.Thresholds.HighThreshold),
+//		Signers:       account.SignerSummary(),
+//	}
+type AccountSigners struct {
+	LowThreshold  Threshold
+	MedThreshold  Threshold
+	HighThreshold Threshold
+	Signers       SignerSummary
+}
+
+func (a AccountSigners) threshold(category ThresholdCategory) Threshold {
+	switch category {
+	case ThresholdLow:
+		return a.LowThreshold
+	case ThresholdHigh:
+		return a.HighThreshold
+	default:
+		return a.MedThreshold
+	}
+}
+
+// ThresholdStatus reports whether the signatures of a transaction authorize one of its
+// source accounts at the threshold required by the transaction or by one of its operations.
+type ThresholdStatus struct {
+	AccountID string
+	Category  ThresholdCategory
+	Threshold Threshold
+	// Weight is the total weight of the signers of the account which signed the
+	// transaction.
+	Weight int32
+	// Met is true when Weight meets Threshold. As on the network, at least one signer
+	// with a non-zero weight is needed when Threshold is 0.
+	Met bool
+	// MissingSigners are the signers of the account which did not sign the transaction,
+	// by decreasing weight. Pre-authorized transaction signers are not listed as they
+	// cannot sign.
+	MissingSigners []string
+}
+
+// SignatureReport reports which thresholds are met by the signatures of a transaction.
+type SignatureReport struct {
+	// Transaction is the status of the source account of the transaction, which must
+	// meet its low threshold for the fee and the sequence number.
+	Transaction ThresholdStatus
+	// Operations are the statuses of the source accounts of the operations, in the order
+	// of the operations.
+	Operations []ThresholdStatus
+	// Signers are the signers with a valid signature on the transaction, in the order of
+	// the signatures.
+	Signers []string
+}
+
+// Met returns true when the transaction and all its operations are sufficiently signed.
+func (r SignatureReport) Met() bool {
+	if !r.Transaction.Met {
+		return false
+	}
+	for _, op := range r.Operations {
+		if !op.Met {
+			return false
+		}
+	}
+	return true
+}
+
+// SigningAccounts returns the accounts whose signers must authorize the transaction: its
+// source account and the source accounts of its operations, without duplicates.
+func (tx *Transaction) SigningAccounts() []string {
+	accounts := []string{tx.xdrTransaction.SourceAccount.Address()}
+	seen := map[string]bool{accounts[0]: true}
+	for _, op := range tx.xdrTransaction.Operations {
+		if op.SourceAccount == nil {
+			continue
+		}
+		account := op.SourceAccount.Address()
+		if !seen[account] {
+			seen[account] = true
+			accounts = append(accounts, account)
+		}
+	}
+	return accounts
+}
+
+// CheckSignatures reports, for the transaction and each of its operations, whether the
+// signatures of the transaction meet the threshold of the source account and which of its
+// signers are still needed. accounts must contain the signers of every account returned by
+// SigningAccounts.
+//
+// An error is returned when a signature doesn't belong to any signer of the source
+// accounts, or when a signer signed more than once, as the network rejects such
+// transactions.
+func CheckSignatures(tx Transaction, accounts map[string]AccountSigners) (SignatureReport, error) {
+	var report SignatureReport
+	if tx.xdrEnvelope == nil {
+		return report, errors.New("transaction has not been built")
+	}
+
+	hash, err := tx.Hash()
+	if err != nil {
+		return report, errors.Wrap(err, "failed to hash transaction")
+	}
+
+	candidates := map[string]bool{}
+	for _, accountID := range tx.SigningAccounts() {
+		account, ok := accounts[accountID]
+		if !ok {
+			return report, errors.Errorf("missing signers of account %s", accountID)
+		}
+		for signer, weight := range account.Signers {
+			if weight > 0 {
+				candidates[signer] = true
+			}
+		}
+	}
+
+	signed := map[string]bool{}
+	for signer := range candidates {
+		if signer[0] == 'T' && isPreAuthTx(signer, hash) {
+			signed[signer] = true
+		}
+	}
+
+	keys := make([]string, 0, len(candidates))
+	for signer := range candidates {
+		keys = append(keys, signer)
+	}
+	sort.Strings(keys)
+
+	for i, sig := range tx.xdrEnvelope.Signatures {
+		signer := ""
+		for _, key := range keys {
+			if signatureMatches(key, sig, hash) {
+				signer = key
+				break
+			}
+		}
+		if signer == "" {
+			return report, errors.Errorf("signature %d is not from a signer of the source accounts", i)
+		}
+		if signed[signer] {
+			return report, errors.Errorf("signature %d is a duplicate signature of %s", i, signer)
+		}
+		signed[signer] = true
+		report.Signers = append(report.Signers, signer)
+	}
+
+	txSource := tx.xdrTransaction.SourceAccount.Address()
+	report.Transaction = thresholdStatus(txSource, ThresholdLow, accounts[txSource], signed)
+	for _, op := range tx.xdrTransaction.Operations {
+		source := txSource
+		if op.SourceAccount != nil {
+			source = op.SourceAccount.Address()
+		}
+		status := thresholdStatus(source, operationThreshold(op), accounts[source], signed)
+		report.Operations = append(report.Operations, status)
+	}
+
+	return report, nil
+}
+
+func thresholdStatus(accountID string, category ThresholdCategory, account AccountSigners, signed map[string]bool) ThresholdStatus {
+	status := ThresholdStatus{
+		AccountID: accountID,
+		Category:  category,
+		Threshold: account.threshold(category),
+	}
+
+	for signer, weight := range account.Signers {
+		if weight <= 0 {
+			continue
+		}
+		if signed[signer] {
+			status.Weight += weight
+		} else if signer[0] != 'T' {
+			status.MissingSigners = append(status.MissingSigners, signer)
+		}
+	}
+	sort.Slice(status.MissingSigners, func(i, j int) bool {
+		wi, wj := account.Signers[status.MissingSigners[i]], account.Signers[status.MissingSigners[j]]
+		if wi != wj {
+			return wi > wj
+		}
+		return status.MissingSigners[i] < status.MissingSigners[j]
+	})
+
+	status.Met = status.Weight > 0 && status.Weight >= int32(status.Threshold)
+	return status
+}
+
+// operationThreshold returns the threshold category of an operation. See
+// https://www.diamnet.org/developers/guides/concepts/multi-sig.html#thresholds
+func operationThreshold(op xdr.Operation) ThresholdCategory {
+	switch op.Body.Type {
+	case xdr.OperationTypeAllowTrust, xdr.OperationTypeBumpSequence, xdr.OperationTypeInflation:
+		return ThresholdLow
+	case xdr.OperationTypeAccountMerge:
+		return ThresholdHigh
+	case xdr.OperationTypeSetOptions:
+		setOptions := op.Body.MustSetOptionsOp()
+		if setOptions.MasterWeight != nil || setOptions.LowThreshold != nil ||
+			setOptions.MedThreshold != nil || setOptions.HighThreshold != nil ||
+			setOptions.Signer != nil {
+			return ThresholdHigh
+		}
+	}
+	return ThresholdMedium
+}
+
+// signatureMatches returns true if sig is a valid signature of the transaction hash by an
+// ed25519 signer, or the preimage of a hash(x) signer.
+func signatureMatches(signer string, sig xdr.DecoratedSignature, hash [32]byte) bool {
+	switch signer[0] {
+	case 'G':
+		kp, err := keypair.Parse(signer)
+		if err != nil || sig.Hint != xdr.SignatureHint(kp.Hint()) {
+			return false
+		}
+		return kp.Verify(hash[:], sig.Signature) == nil
+	case 'X':
+		hashX, err := strkey.Decode(strkey.VersionByteHashX, signer)
+		if err != nil || !bytes.Equal(sig.Hint[:], hashX[len(hashX)-4:]) {
+			return false
+		}
+		preimageHash := sha256.Sum256(sig.Signature)
+		return bytes.Equal(preimageHash[:], hashX)
+	}
+	return false
+}
+
+// isPreAuthTx returns true if signer is a pre-authorized transaction signer for the
+// transaction hash.
+func isPreAuthTx(signer string, hash [32]byte) bool {
+	preAuth, err := strkey.Decode(strkey.VersionByteHashTx, signer)
+	return err == nil && bytes.Equal(preAuth, hash[:])
+}
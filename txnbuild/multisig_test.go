@@ -0,0 +1,138 @@
+package txnbuild
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/diamnet/go/network"
+	"github.com/diamnet/go/strkey"
+	"github.com/diamnet/go/xdr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckSignatures(t *testing.T) {
+	kp0, kp1, kp2 := newKeypair0(), newKeypair1(), newKeypair2()
+	treasury := NewSimpleAccount(kp0.Address(), 9605939170639897)
+	other := NewSimpleAccount(kp1.Address(), 0)
+
+	tx := Transaction{
+		SourceAccount: &treasury,
+		Operations: []Operation{
+			&Payment{Destination: kp2.Address(), Amount: "10", Asset: NativeAsset{}},
+			&BumpSequence{BumpTo: 9605939170639900, SourceAccount: &other},
+			&SetOptions{Signer: &Signer{Address: kp2.Address(), Weight: 1}},
+		},
+		Timebounds: NewInfiniteTimeout(),
+		Network:    network.TestNetworkPassphrase,
+	}
+	require.NoError(t, tx.Build())
+
+	assert.Equal(t, []string{kp0.Address(), kp1.Address()}, tx.SigningAccounts())
+
+	accounts := map[string]AccountSigners{
+		kp0.Address(): {
+			LowThreshold:  1,
+			MedThreshold:  2,
+			HighThreshold: 3,
+			Signers: SignerSummary{
+				kp0.Address(): 1,
+				kp1.Address(): 2,
+				kp2.Address(): 0,
+			},
+		},
+		kp1.Address(): {
+			Signers: SignerSummary{kp1.Address(): 1},
+		},
+	}
+
+	report, err := CheckSignatures(tx, accounts)
+	require.NoError(t, err)
+	assert.False(t, report.Met())
+	assert.Empty(t, report.Signers)
+	assert.False(t, report.Transaction.Met)
+	assert.Equal(t, []string{kp1.Address(), kp0.Address()}, report.Transaction.MissingSigners)
+	require.Len(t, report.Operations, 3)
+	assert.Equal(t, ThresholdMedium, report.Operations[0].Category)
+	assert.Equal(t, ThresholdLow, report.Operations[1].Category)
+	assert.Equal(t, kp1.Address(), report.Operations[1].AccountID)
+	assert.Equal(t, ThresholdHigh, report.Operations[2].Category)
+
+	require.NoError(t, tx.Sign(kp0))
+	report, err = CheckSignatures(tx, accounts)
+	require.NoError(t, err)
+	assert.Equal(t, []string{kp0.Address()}, report.Signers)
+	assert.True(t, report.Transaction.Met)
+	assert.False(t, report.Operations[0].Met)
+	assert.Equal(t, int32(1), report.Operations[0].Weight)
+	assert.Equal(t, []string{kp1.Address()}, report.Operations[0].MissingSigners)
+	// a threshold of 0 still requires a signer
+	assert.False(t, report.Operations[1].Met)
+
+	require.NoError(t, tx.Sign(kp1))
+	report, err = CheckSignatures(tx, accounts)
+	require.NoError(t, err)
+	assert.True(t, report.Met())
+	assert.Equal(t, int32(3), report.Operations[2].Weight)
+	assert.Empty(t, report.Operations[2].MissingSigners)
+
+	// signers with a weight of 0 are irrelevant
+	require.NoError(t, tx.Sign(kp2))
+	_, err = CheckSignatures(tx, accounts)
+	assert.EqualError(t, err, "signature 2 is not from a signer of the source accounts")
+
+	tx.xdrEnvelope.Signatures = tx.xdrEnvelope.Signatures[:2]
+	require.NoError(t, tx.Sign(kp1))
+	_, err = CheckSignatures(tx, accounts)
+	assert.EqualError(t, err, "signature 2 is a duplicate signature of "+kp1.Address())
+
+	delete(accounts, kp1.Address())
+	_, err = CheckSignatures(tx, accounts)
+	assert.EqualError(t, err, "missing signers of account "+kp1.Address())
+}
+
+func TestCheckSignaturesHashXAndPreAuthTx(t *testing.T) {
+	kp0 := newKeypair0()
+	sourceAccount := NewSimpleAccount(kp0.Address(), 9605939170639897)
+
+	tx := Transaction{
+		SourceAccount: &sourceAccount,
+		Operations:    []Operation{&BumpSequence{BumpTo: 9605939170639900}},
+		Timebounds:    NewTimebounds(0, 10),
+		Network:       network.TestNetworkPassphrase,
+	}
+	require.NoError(t, tx.Build())
+	hash, err := tx.Hash()
+	require.NoError(t, err)
+
+	preimage := []byte("treasury preimage")
+	preimageHash := sha256.Sum256(preimage)
+	hashX := strkey.MustEncode(strkey.VersionByteHashX, preimageHash[:])
+	preAuthTx := strkey.MustEncode(strkey.VersionByteHashTx, hash[:])
+
+	accounts := map[string]AccountSigners{
+		kp0.Address(): {
+			LowThreshold: 2,
+			Signers: SignerSummary{
+				kp0.Address(): 1,
+				hashX:         1,
+				preAuthTx:     1,
+			},
+		},
+	}
+
+	report, err := CheckSignatures(tx, accounts)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), report.Transaction.Weight)
+	assert.Equal(t, []string{kp0.Address(), hashX}, report.Transaction.MissingSigners)
+
+	require.NoError(t, tx.SignHashX(preimage))
+	report, err = CheckSignatures(tx, accounts)
+	require.NoError(t, err)
+	assert.True(t, report.Met())
+	assert.Equal(t, []string{hashX}, report.Signers)
+
+	tx.xdrEnvelope.Signatures[0].Signature = xdr.Signature("wrong preimage")
+	_, err = CheckSignatures(tx, accounts)
+	assert.Error(t, err)
+}
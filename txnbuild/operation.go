@@ -1,6 +1,9 @@
 package txnbuild
 
 import (
+	"fmt"
+
+	"github.com/diamnet/go/support/errors"
 	"github.com/diamnet/go/xdr"
 )
 
@@ -18,3 +21,57 @@ func SetOpSourceAccount(op *xdr.Operation, sourceAccount Account) {
 	opSourceAccountID.SetAddress(sourceAccount.GetAccountID())
 	op.SourceAccount = &opSourceAccountID
 }
+
+// accountFromXDR returns the Account described by an XDR account ID, or nil
+// when no account is set.
+func accountFromXDR(account *xdr.AccountId) Account {
+	if account == nil {
+		return nil
+	}
+	return &SimpleAccount{AccountID: account.Address()}
+}
+
+// operationFromXDR returns the Operation corresponding to the type of the XDR
+// operation, populated with its fields.
+func operationFromXDR(xdrOp xdr.Operation) (Operation, error) {
+	var op interface {
+		Operation
+		FromXDR(xdr.Operation) error
+	}
+	switch xdrOp.Body.Type {
+	case xdr.OperationTypeCreateAccount:
+		op = &CreateAccount{}
+	case xdr.OperationTypePayment:
+		op = &Payment{}
+	case xdr.OperationTypePathPayment:
+		op = &PathPayment{}
+	case xdr.OperationTypeManageSellOffer:
+		op = &ManageSellOffer{}
+	case xdr.OperationTypeCreatePassiveSellOffer:
+		op = &CreatePassiveSellOffer{}
+	case xdr.OperationTypeSetOptions:
+		op = &SetOptions{}
+	case xdr.OperationTypeChangeTrust:
+		op = &ChangeTrust{}
+	case xdr.OperationTypeAllowTrust:
+		op = &AllowTrust{}
+	case xdr.OperationTypeAccountMerge:
+		op = &AccountMerge{}
+	case xdr.OperationTypeInflation:
+		op = &Inflation{}
+	case xdr.OperationTypeManageData:
+		op = &ManageData{}
+	case xdr.OperationTypeBumpSequence:
+		op = &BumpSequence{}
+	case xdr.OperationTypeManageBuyOffer:
+		op = &ManageBuyOffer{}
+	default:
+		return nil, errors.Errorf("unknown operation type: %d", xdrOp.Body.Type)
+	}
+
+	err := op.FromXDR(xdrOp)
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("failed to parse operation %T", op))
+	}
+	return op, nil
+}
@@ -85,3 +85,35 @@ func (pp *PathPayment) BuildXDR() (xdr.Operation, error) {
 	SetOpSourceAccount(&op, pp.SourceAccount)
 	return op, nil
 }
+
+// FromXDR for PathPayment initialises the txnbuild struct from the corresponding xdr Operation.
+func (pp *PathPayment) FromXDR(xdrOp xdr.Operation) error {
+	result, ok := xdrOp.Body.GetPathPaymentOp()
+	if !ok {
+		return errors.New("error parsing path_payment operation from xdr")
+	}
+
+	sendAsset, err := assetFromXDR(result.SendAsset)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse send asset")
+	}
+
+	destAsset, err := assetFromXDR(result.DestAsset)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse destination asset")
+	}
+
+	path, err := assetsFromXDR(result.Path)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse path")
+	}
+
+	pp.SourceAccount = accountFromXDR(xdrOp.SourceAccount)
+	pp.SendAsset = sendAsset
+	pp.SendMax = amount.String(result.SendMax)
+	pp.Destination = result.Destination.Address()
+	pp.DestAsset = destAsset
+	pp.DestAmount = amount.String(result.DestAmount)
+	pp.Path = path
+	return nil
+}
@@ -51,3 +51,22 @@ func (p *Payment) BuildXDR() (xdr.Operation, error) {
 	SetOpSourceAccount(&op, p.SourceAccount)
 	return op, nil
 }
+
+// FromXDR for Payment initialises the txnbuild struct from the corresponding xdr Operation.
+func (p *Payment) FromXDR(xdrOp xdr.Operation) error {
+	result, ok := xdrOp.Body.GetPaymentOp()
+	if !ok {
+		return errors.New("error parsing payment operation from xdr")
+	}
+
+	asset, err := assetFromXDR(result.Asset)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse asset")
+	}
+
+	p.SourceAccount = accountFromXDR(xdrOp.SourceAccount)
+	p.Destination = result.Destination.Address()
+	p.Amount = amount.String(result.Amount)
+	p.Asset = asset
+	return nil
+}
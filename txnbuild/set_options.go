@@ -200,3 +200,55 @@ func (so *SetOptions) handleSigner() (err error) {
 	}
 	return nil
 }
+
+// FromXDR for SetOptions initialises the txnbuild struct from the corresponding xdr Operation.
+func (so *SetOptions) FromXDR(xdrOp xdr.Operation) error {
+	result, ok := xdrOp.Body.GetSetOptionsOp()
+	if !ok {
+		return errors.New("error parsing set_options operation from xdr")
+	}
+
+	so.SourceAccount = accountFromXDR(xdrOp.SourceAccount)
+	if result.InflationDest != nil {
+		so.InflationDestination = NewInflationDestination(result.InflationDest.Address())
+	}
+	if result.ClearFlags != nil {
+		so.ClearFlags = accountFlagsFromXDR(*result.ClearFlags)
+	}
+	if result.SetFlags != nil {
+		so.SetFlags = accountFlagsFromXDR(*result.SetFlags)
+	}
+	so.MasterWeight = thresholdFromXDR(result.MasterWeight)
+	so.LowThreshold = thresholdFromXDR(result.LowThreshold)
+	so.MediumThreshold = thresholdFromXDR(result.MedThreshold)
+	so.HighThreshold = thresholdFromXDR(result.HighThreshold)
+	if result.HomeDomain != nil {
+		so.HomeDomain = NewHomeDomain(string(*result.HomeDomain))
+	}
+	if result.Signer != nil {
+		so.Signer = &Signer{
+			Address: result.Signer.Key.Address(),
+			Weight:  Threshold(result.Signer.Weight),
+		}
+	}
+	return nil
+}
+
+// accountFlagsFromXDR splits an XDR account flags bitmask into AccountFlags.
+func accountFlagsFromXDR(flags xdr.Uint32) []AccountFlag {
+	var accountFlags []AccountFlag
+	for _, flag := range []AccountFlag{AuthRequired, AuthRevocable, AuthImmutable} {
+		if flags&xdr.Uint32(flag) != 0 {
+			accountFlags = append(accountFlags, flag)
+		}
+	}
+	return accountFlags
+}
+
+// thresholdFromXDR returns the Threshold corresponding to an optional XDR weight.
+func thresholdFromXDR(weight *xdr.Uint32) *Threshold {
+	if weight == nil {
+		return nil
+	}
+	return NewThreshold(Threshold(*weight))
+}
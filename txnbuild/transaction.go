@@ -155,20 +155,21 @@ func (tx *Transaction) Build() error {
 }
 
 // Sign for Transaction signs a previously built transaction. A signed transaction may be
-// submitted to the network.
-func (tx *Transaction) Sign(kps ...*keypair.Full) error {
+// submitted to the network. Signers may be *keypair.Full, signing in process, or any
+// other keypair.Signer, e.g. one delegating signing to a remote signing service.
+func (tx *Transaction) Sign(signers ...keypair.Signer) error {
 	// TODO: Only sign if Transaction has been previously built
 	// TODO: Validate network set before sign
 
 	// Hash the transaction
-	hash, err := tx.Hash()
+	_, err := tx.Hash()
 	if err != nil {
 		return errors.Wrap(err, "failed to hash transaction")
 	}
 
-	// Sign the hash
-	for _, kp := range kps {
-		sig, err := kp.SignDecorated(hash[:])
+	// Sign the transaction
+	for _, signer := range signers {
+		sig, err := signer.SignTransaction(&tx.xdrTransaction, tx.Network)
 		if err != nil {
 			return errors.Wrap(err, "failed to sign transaction")
 		}
@@ -181,13 +182,13 @@ func (tx *Transaction) Sign(kps ...*keypair.Full) error {
 
 // BuildSignEncode performs all the steps to produce a final transaction suitable
 // for submitting to the network.
-func (tx *Transaction) BuildSignEncode(keypairs ...*keypair.Full) (string, error) {
+func (tx *Transaction) BuildSignEncode(signers ...keypair.Signer) (string, error) {
 	err := tx.Build()
 	if err != nil {
 		return "", errors.Wrap(err, "couldn't build transaction")
 	}
 
-	err = tx.Sign(keypairs...)
+	err = tx.Sign(signers...)
 	if err != nil {
 		return "", errors.Wrap(err, "couldn't sign transaction")
 	}
@@ -274,6 +275,195 @@ func generateRandomNonce(n int) ([]byte, error) {
 	return bytes, err
 }
 
+// SignerSummary maps the signers of an account to their weight. The signers of an
+// account loaded from aurora are summarised by aurora.Account.SignerSummary.
+type SignerSummary map[string]int32
+
+// ReadChallengeTx reads a SEP 10 challenge transaction and returns the decoded transaction
+// and the ID of the client account which must sign it. It verifies that the transaction was
+// built by BuildChallengeTx for serverAccountID and is signed by the server, but it does not
+// verify the signatures of the client.
+// More details on SEP 10: https://github.com/diamnet/diamnet-protocol/blob/master/ecosystem/sep-0010.md
+func ReadChallengeTx(challengeTx, serverAccountID, network string) (tx Transaction, clientAccountID string, err error) {
+	tx, err = TransactionFromXDR(challengeTx)
+	if err != nil {
+		return tx, clientAccountID, err
+	}
+	tx.Network = network
+
+	if tx.xdrTransaction.SourceAccount.Address() != serverAccountID {
+		return tx, clientAccountID, errors.New("transaction source account is not equal to server's account")
+	}
+
+	if tx.xdrTransaction.SeqNum != 0 {
+		return tx, clientAccountID, errors.New("transaction sequence number must be 0")
+	}
+
+	if tx.xdrTransaction.TimeBounds == nil {
+		return tx, clientAccountID, errors.New("transaction requires timebounds")
+	}
+
+	// a MaxTime of 0 means the challenge never expires, see BuildChallengeTx
+	now := time.Now().UTC().Unix()
+	if now < tx.Timebounds.MinTime || (tx.Timebounds.MaxTime > 0 && now > tx.Timebounds.MaxTime) {
+		return tx, clientAccountID, errors.New("transaction is not within range of the specified timebounds")
+	}
+
+	if len(tx.Operations) != 1 {
+		return tx, clientAccountID, errors.New("transaction requires a single manage_data operation")
+	}
+
+	op, ok := tx.Operations[0].(*ManageData)
+	if !ok {
+		return tx, clientAccountID, errors.New("operation type should be manage_data")
+	}
+	if op.SourceAccount == nil {
+		return tx, clientAccountID, errors.New("operation should have a source account")
+	}
+	clientAccountID = op.SourceAccount.GetAccountID()
+
+	if len(op.Value) != 64 {
+		return tx, clientAccountID, errors.New("random nonce encoded as base64 should be 64 bytes long")
+	}
+
+	signersFound, err := verifyTxSignatures(tx, serverAccountID)
+	if err != nil {
+		return tx, clientAccountID, err
+	}
+	if len(signersFound) == 0 {
+		return tx, clientAccountID, errors.Errorf("transaction not signed by %s", serverAccountID)
+	}
+
+	return tx, clientAccountID, nil
+}
+
+// VerifyChallengeTx verifies that a SEP 10 challenge transaction is valid, as checked by
+// ReadChallengeTx, and is signed by the server and by the master key of the client account.
+// Use VerifyChallengeTxThreshold for client accounts with multiple signers.
+// More details on SEP 10: https://github.com/diamnet/diamnet-protocol/blob/master/ecosystem/sep-0010.md
+func VerifyChallengeTx(challengeTx, serverAccountID, network string) (bool, error) {
+	_, clientAccountID, err := ReadChallengeTx(challengeTx, serverAccountID, network)
+	if err != nil {
+		return false, err
+	}
+
+	signersFound, err := VerifyChallengeTxSigners(challengeTx, serverAccountID, network, clientAccountID)
+	if err != nil {
+		return false, err
+	}
+	if len(signersFound) == 0 {
+		return false, errors.Errorf("transaction not signed by %s", clientAccountID)
+	}
+
+	return true, nil
+}
+
+// VerifyChallengeTxThreshold verifies that a SEP 10 challenge transaction is valid, as
+// checked by ReadChallengeTx, and that the total weight of the client signers which signed
+// it meets the threshold. signerSummary contains the signers of the client account and their
+// weights, usually loaded from aurora:
+//
+//	account, err := client.AccountDetail(auroraclient.AccountRequest{AccountID: clientAccountID})
+//	...
+//	signersFound, err := txnbuild.VerifyChallengeTxThreshold(challengeTx, serverAccountID, network,
+//		txnbuild.Threshold(account.Thresholds.MedThreshold), account.SignerSummary())
+//
+// The signers found are returned. An error is returned when the transaction has signatures
+// from keys other than the server and the signers in signerSummary.
+func VerifyChallengeTxThreshold(challengeTx, serverAccountID, network string, threshold Threshold, signerSummary SignerSummary) (signersFound []string, err error) {
+	signers := make([]string, 0, len(signerSummary))
+	for signer := range signerSummary {
+		signers = append(signers, signer)
+	}
+
+	signersFound, err = VerifyChallengeTxSigners(challengeTx, serverAccountID, network, signers...)
+	if err != nil {
+		return nil, err
+	}
+
+	weight := int32(0)
+	for _, signer := range signersFound {
+		weight += signerSummary[signer]
+	}
+	if weight < int32(threshold) {
+		return nil, errors.Errorf("signers with weight %d do not meet threshold %d", weight, threshold)
+	}
+
+	return signersFound, nil
+}
+
+// VerifyChallengeTxSigners verifies that a SEP 10 challenge transaction is valid, as checked
+// by ReadChallengeTx, and returns the client signers which signed it. Signers which are not
+// ed25519 public keys are ignored. An error is returned when none of the signers signed the
+// transaction or when it has signatures from keys other than the server and the signers.
+func VerifyChallengeTxSigners(challengeTx, serverAccountID, network string, signers ...string) ([]string, error) {
+	tx, _, err := ReadChallengeTx(challengeTx, serverAccountID, network)
+	if err != nil {
+		return nil, err
+	}
+
+	// ignore the server and keys that cannot sign a transaction envelope, such as
+	// pre-authorized transactions and hash(x) signers
+	var clientSigners []string
+	for _, signer := range signers {
+		if signer == serverAccountID {
+			continue
+		}
+		if _, err := keypair.Parse(signer); err != nil || signer[0] != 'G' {
+			continue
+		}
+		clientSigners = append(clientSigners, signer)
+	}
+
+	signersFound, err := verifyTxSignatures(tx, append([]string{serverAccountID}, clientSigners...)...)
+	if err != nil {
+		return nil, err
+	}
+	// the server signature was checked by ReadChallengeTx
+	signersFound = signersFound[1:]
+	if len(signersFound) == 0 {
+		return nil, errors.New("transaction not signed by any of the client signers")
+	}
+
+	if len(signersFound)+1 != len(tx.xdrEnvelope.Signatures) {
+		return nil, errors.New("transaction has unrecognized signatures")
+	}
+
+	return signersFound, nil
+}
+
+// verifyTxSignatures returns the signers, in the order they are given, which have a valid
+// signature on the transaction. Each signature can only be attributed to one signer.
+func verifyTxSignatures(tx Transaction, signers ...string) ([]string, error) {
+	hash, err := tx.Hash()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to hash transaction")
+	}
+
+	used := map[int]bool{}
+	var signersFound []string
+	for _, signer := range signers {
+		kp, err := keypair.Parse(signer)
+		if err != nil {
+			return nil, errors.Wrap(err, "signer is not a valid account id")
+		}
+
+		hint := kp.Hint()
+		for i, sig := range tx.xdrEnvelope.Signatures {
+			if used[i] || sig.Hint != xdr.SignatureHint(hint) {
+				continue
+			}
+			if kp.Verify(hash[:], sig.Signature) == nil {
+				used[i] = true
+				signersFound = append(signersFound, signer)
+				break
+			}
+		}
+	}
+
+	return signersFound, nil
+}
+
 // HashHex returns the hex-encoded hash of the transaction.
 func (tx *Transaction) HashHex() (string, error) {
 	hashByte, err := tx.Hash()
@@ -288,6 +478,58 @@ func (tx *Transaction) TxEnvelope() *xdr.TransactionEnvelope {
 	return tx.xdrEnvelope
 }
 
+// TransactionFromXDR parses the supplied transaction envelope in base64 XDR and returns
+// the corresponding Transaction. The operations, memo and timebounds of the transaction are
+// parsed into their txnbuild types and the signatures of the envelope are kept.
+//
+// The returned transaction is already built: once its Network is set it can be hashed,
+// signed with additional signers and encoded again, but it must not be built again.
+// To modify it, create a new Transaction from its fields. The sequence number of its
+// SourceAccount is the one preceding the sequence number of the transaction so that
+// building the new Transaction reuses the same sequence number.
+func TransactionFromXDR(txeB64 string) (Transaction, error) {
+	var xdrEnv xdr.TransactionEnvelope
+	err := xdr.SafeUnmarshalBase64(txeB64, &xdrEnv)
+	if err != nil {
+		return Transaction{}, errors.Wrap(err, "unable to unmarshal transaction envelope")
+	}
+
+	xdrTx := xdrEnv.Tx
+	if len(xdrTx.Operations) == 0 {
+		return Transaction{}, errors.New("transaction has no operations")
+	}
+
+	memo, err := memoFromXDR(xdrTx.Memo)
+	if err != nil {
+		return Transaction{}, errors.Wrap(err, "failed to parse memo")
+	}
+
+	tx := Transaction{
+		SourceAccount: &SimpleAccount{
+			AccountID: xdrTx.SourceAccount.Address(),
+			Sequence:  int64(xdrTx.SeqNum) - 1,
+		},
+		BaseFee:        uint32(xdrTx.Fee) / uint32(len(xdrTx.Operations)),
+		Memo:           memo,
+		xdrTransaction: xdrTx,
+		xdrEnvelope:    &xdrEnv,
+	}
+
+	if xdrTx.TimeBounds != nil {
+		tx.Timebounds = NewTimebounds(int64(xdrTx.TimeBounds.MinTime), int64(xdrTx.TimeBounds.MaxTime))
+	}
+
+	for i, xdrOp := range xdrTx.Operations {
+		op, err := operationFromXDR(xdrOp)
+		if err != nil {
+			return Transaction{}, errors.Wrap(err, fmt.Sprintf("failed to parse operation %d", i))
+		}
+		tx.Operations = append(tx.Operations, op)
+	}
+
+	return tx, nil
+}
+
 func (tx *Transaction) setTransactionFee() error {
 	if tx.BaseFee == 0 {
 		return errors.New("base fee can not be zero")
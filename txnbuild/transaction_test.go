@@ -2,9 +2,11 @@ package txnbuild
 
 import (
 	"crypto/sha256"
+	"sort"
 	"testing"
 	"time"
 
+	"github.com/diamnet/go/keypair"
 	"github.com/diamnet/go/network"
 	"github.com/diamnet/go/strkey"
 	"github.com/diamnet/go/xdr"
@@ -998,3 +1000,249 @@ func TestHashXTransaction(t *testing.T) {
 	assert.Equal(t, expected, txeB64, "Base 64 XDR should match")
 
 }
+
+func TestTransactionFromXDR(t *testing.T) {
+	kp0 := newKeypair0()
+	kp1 := newKeypair1()
+	kp2 := newKeypair2()
+	sourceAccount := NewSimpleAccount(kp0.Address(), int64(9605939170639897))
+	opSourceAccount := NewSimpleAccount(kp1.Address(), 0)
+	usd := CreditAsset{Code: "USD", Issuer: kp1.Address()}
+	abcdefghijkl := CreditAsset{Code: "ABCDEFGHIJKL", Issuer: kp1.Address()}
+
+	operations := []Operation{
+		&CreateAccount{Destination: kp2.Address(), Amount: "10"},
+		&Payment{Destination: kp2.Address(), Amount: "1.5", Asset: usd, SourceAccount: &opSourceAccount},
+		&PathPayment{
+			SendAsset:   NativeAsset{},
+			SendMax:     "10",
+			Destination: kp2.Address(),
+			DestAsset:   usd,
+			DestAmount:  "1",
+			Path:        []Asset{abcdefghijkl},
+		},
+		&ManageSellOffer{Selling: NativeAsset{}, Buying: usd, Amount: "100", Price: "0.5000000", OfferID: 2},
+		&ManageBuyOffer{Selling: usd, Buying: NativeAsset{}, Amount: "20", Price: "2.0000000"},
+		&CreatePassiveSellOffer{Selling: usd, Buying: abcdefghijkl, Amount: "3", Price: "1.0000000"},
+		&SetOptions{
+			InflationDestination: NewInflationDestination(kp1.Address()),
+			SetFlags:             []AccountFlag{AuthRequired, AuthRevocable},
+			MasterWeight:         NewThreshold(10),
+			LowThreshold:         NewThreshold(1),
+			HomeDomain:           NewHomeDomain("diamnet.org"),
+			Signer:               &Signer{Address: kp2.Address(), Weight: 5},
+		},
+		&ChangeTrust{Line: usd, Limit: "1000"},
+		&AllowTrust{Trustor: kp2.Address(), Type: CreditAsset{Code: "USD"}, Authorize: true},
+		&AccountMerge{Destination: kp2.Address()},
+		&Inflation{},
+		&ManageData{Name: "name", Value: []byte("value")},
+		&ManageData{Name: "removed"},
+		&BumpSequence{BumpTo: 100},
+	}
+
+	tx := Transaction{
+		SourceAccount: &sourceAccount,
+		Operations:    operations,
+		Memo:          MemoText("memo"),
+		Timebounds:    NewTimebounds(10, 1000),
+		Network:       network.TestNetworkPassphrase,
+		BaseFee:       200,
+	}
+	txeB64 := buildSignEncode(t, tx, kp0)
+
+	parsed, err := TransactionFromXDR(txeB64)
+	require.NoError(t, err)
+	assert.Equal(t, &SimpleAccount{AccountID: kp0.Address(), Sequence: 9605939170639897}, parsed.SourceAccount)
+	assert.Equal(t, uint32(200), parsed.BaseFee)
+	assert.Equal(t, MemoText("memo"), parsed.Memo)
+	assert.Equal(t, NewTimebounds(10, 1000), parsed.Timebounds)
+	require.Len(t, parsed.Operations, len(operations))
+
+	payment := parsed.Operations[1].(*Payment)
+	assert.Equal(t, &SimpleAccount{AccountID: kp1.Address()}, payment.SourceAccount)
+	assert.Equal(t, "1.5000000", payment.Amount)
+	assert.Equal(t, usd, payment.Asset)
+
+	pathPayment := parsed.Operations[2].(*PathPayment)
+	assert.Equal(t, NativeAsset{}, pathPayment.SendAsset)
+	assert.Equal(t, []Asset{abcdefghijkl}, pathPayment.Path)
+
+	setOptions := parsed.Operations[6].(*SetOptions)
+	assert.Equal(t, []AccountFlag{AuthRequired, AuthRevocable}, setOptions.SetFlags)
+	assert.Nil(t, setOptions.ClearFlags)
+	assert.Equal(t, NewThreshold(10), setOptions.MasterWeight)
+	assert.Nil(t, setOptions.HighThreshold)
+	assert.Equal(t, &Signer{Address: kp2.Address(), Weight: 5}, setOptions.Signer)
+
+	assert.Equal(t, &ManageData{Name: "removed"}, parsed.Operations[12])
+
+	// the parsed transaction keeps its signatures and can be signed again
+	parsed.Network = network.TestNetworkPassphrase
+	parsedB64, err := parsed.Base64()
+	require.NoError(t, err)
+	assert.Equal(t, txeB64, parsedB64)
+	require.NoError(t, parsed.Sign(kp1))
+	assert.Len(t, parsed.TxEnvelope().Signatures, 2)
+
+	// a new transaction built from the parsed fields is identical
+	rebuilt := Transaction{
+		SourceAccount: parsed.SourceAccount,
+		Operations:    parsed.Operations,
+		Memo:          parsed.Memo,
+		Timebounds:    parsed.Timebounds,
+		Network:       network.TestNetworkPassphrase,
+		BaseFee:       parsed.BaseFee,
+	}
+	assert.Equal(t, txeB64, buildSignEncode(t, rebuilt, kp0))
+}
+
+func TestTransactionFromXDRInvalid(t *testing.T) {
+	_, err := TransactionFromXDR("AAAA")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "unable to unmarshal transaction envelope")
+	}
+}
+
+func signChallengeTx(t *testing.T, challengeTx string, kps ...keypair.Signer) string {
+	tx, err := TransactionFromXDR(challengeTx)
+	require.NoError(t, err)
+	tx.Network = network.TestNetworkPassphrase
+	require.NoError(t, tx.Sign(kps...))
+
+	txeBase64, err := tx.Base64()
+	require.NoError(t, err)
+	return txeBase64
+}
+
+func TestReadChallengeTx(t *testing.T) {
+	serverKP := newKeypair0()
+	clientKP := newKeypair1()
+
+	challenge, err := BuildChallengeTx(serverKP.Seed(), clientKP.Address(), "SDF", network.TestNetworkPassphrase, time.Minute)
+	require.NoError(t, err)
+
+	tx, clientAccountID, err := ReadChallengeTx(challenge, serverKP.Address(), network.TestNetworkPassphrase)
+	assert.NoError(t, err)
+	assert.Equal(t, clientKP.Address(), clientAccountID)
+	assert.Equal(t, "SDF auth", tx.Operations[0].(*ManageData).Name)
+
+	_, _, err = ReadChallengeTx(challenge, clientKP.Address(), network.TestNetworkPassphrase)
+	assert.EqualError(t, err, "transaction source account is not equal to server's account")
+
+	_, _, err = ReadChallengeTx(challenge, serverKP.Address(), network.PublicNetworkPassphrase)
+	assert.EqualError(t, err, "transaction not signed by "+serverKP.Address())
+
+	// challenge signed by another key than the server's
+	sa := SimpleAccount{AccountID: serverKP.Address(), Sequence: -1}
+	unsigned := Transaction{
+		SourceAccount: &sa,
+		Operations: []Operation{
+			&ManageData{SourceAccount: &SimpleAccount{AccountID: clientKP.Address()}, Name: "SDF auth", Value: make([]byte, 64)},
+		},
+		Timebounds: NewInfiniteTimeout(),
+		Network:    network.TestNetworkPassphrase,
+		BaseFee:    100,
+	}
+	_, _, err = ReadChallengeTx(buildSignEncode(t, unsigned, clientKP), serverKP.Address(), network.TestNetworkPassphrase)
+	assert.EqualError(t, err, "transaction not signed by "+serverKP.Address())
+
+	expired := Transaction{
+		SourceAccount: &SimpleAccount{AccountID: serverKP.Address(), Sequence: -1},
+		Operations:    unsigned.Operations,
+		Timebounds:    NewTimebounds(1, 100),
+		Network:       network.TestNetworkPassphrase,
+		BaseFee:       100,
+	}
+	_, _, err = ReadChallengeTx(buildSignEncode(t, expired, serverKP), serverKP.Address(), network.TestNetworkPassphrase)
+	assert.EqualError(t, err, "transaction is not within range of the specified timebounds")
+
+	wrongSequence := Transaction{
+		SourceAccount: &SimpleAccount{AccountID: serverKP.Address()},
+		Operations:    unsigned.Operations,
+		Timebounds:    NewInfiniteTimeout(),
+		Network:       network.TestNetworkPassphrase,
+		BaseFee:       100,
+	}
+	_, _, err = ReadChallengeTx(buildSignEncode(t, wrongSequence, serverKP), serverKP.Address(), network.TestNetworkPassphrase)
+	assert.EqualError(t, err, "transaction sequence number must be 0")
+
+	wrongOperation := Transaction{
+		SourceAccount: &SimpleAccount{AccountID: serverKP.Address(), Sequence: -1},
+		Operations:    []Operation{&BumpSequence{SourceAccount: &SimpleAccount{AccountID: clientKP.Address()}, BumpTo: 1}},
+		Timebounds:    NewInfiniteTimeout(),
+		Network:       network.TestNetworkPassphrase,
+		BaseFee:       100,
+	}
+	_, _, err = ReadChallengeTx(buildSignEncode(t, wrongOperation, serverKP), serverKP.Address(), network.TestNetworkPassphrase)
+	assert.EqualError(t, err, "operation type should be manage_data")
+
+	shortNonce := Transaction{
+		SourceAccount: &SimpleAccount{AccountID: serverKP.Address(), Sequence: -1},
+		Operations: []Operation{
+			&ManageData{SourceAccount: &SimpleAccount{AccountID: clientKP.Address()}, Name: "SDF auth", Value: make([]byte, 32)},
+		},
+		Timebounds: NewInfiniteTimeout(),
+		Network:    network.TestNetworkPassphrase,
+		BaseFee:    100,
+	}
+	_, _, err = ReadChallengeTx(buildSignEncode(t, shortNonce, serverKP), serverKP.Address(), network.TestNetworkPassphrase)
+	assert.EqualError(t, err, "random nonce encoded as base64 should be 64 bytes long")
+}
+
+func TestVerifyChallengeTx(t *testing.T) {
+	serverKP := newKeypair0()
+	clientKP := newKeypair1()
+
+	challenge, err := BuildChallengeTx(serverKP.Seed(), clientKP.Address(), "SDF", network.TestNetworkPassphrase, time.Minute)
+	require.NoError(t, err)
+
+	_, err = VerifyChallengeTx(challenge, serverKP.Address(), network.TestNetworkPassphrase)
+	assert.EqualError(t, err, "transaction not signed by any of the client signers")
+
+	ok, err := VerifyChallengeTx(signChallengeTx(t, challenge, clientKP), serverKP.Address(), network.TestNetworkPassphrase)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	_, err = VerifyChallengeTx(signChallengeTx(t, challenge, newKeypair2()), serverKP.Address(), network.TestNetworkPassphrase)
+	assert.EqualError(t, err, "transaction not signed by any of the client signers")
+
+	_, err = VerifyChallengeTx(signChallengeTx(t, challenge, clientKP, newKeypair2()), serverKP.Address(), network.TestNetworkPassphrase)
+	assert.EqualError(t, err, "transaction has unrecognized signatures")
+}
+
+func TestVerifyChallengeTxThreshold(t *testing.T) {
+	serverKP := newKeypair0()
+	clientKP := newKeypair1()
+	signerKP := newKeypair2()
+	signerSummary := SignerSummary{
+		clientKP.Address(): 1,
+		signerKP.Address(): 2,
+		// pre-authorized transaction signers are ignored
+		"TBU2ERG2Z2QWTGSDRA3SGEKTJJYGVYVG7PVZNFKEHCJYBGVQXR2KYXNC": 10,
+	}
+
+	challenge, err := BuildChallengeTx(serverKP.Seed(), clientKP.Address(), "SDF", network.TestNetworkPassphrase, time.Minute)
+	require.NoError(t, err)
+
+	signersFound, err := VerifyChallengeTxThreshold(signChallengeTx(t, challenge, clientKP, signerKP), serverKP.Address(), network.TestNetworkPassphrase, 3, signerSummary)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{clientKP.Address(), signerKP.Address()}, sortedStrings(signersFound))
+
+	signersFound, err = VerifyChallengeTxThreshold(signChallengeTx(t, challenge, signerKP), serverKP.Address(), network.TestNetworkPassphrase, 2, signerSummary)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{signerKP.Address()}, signersFound)
+
+	_, err = VerifyChallengeTxThreshold(signChallengeTx(t, challenge, clientKP), serverKP.Address(), network.TestNetworkPassphrase, 2, signerSummary)
+	assert.EqualError(t, err, "signers with weight 1 do not meet threshold 2")
+
+	// a signature cannot be counted twice
+	_, err = VerifyChallengeTxThreshold(signChallengeTx(t, challenge, clientKP, clientKP), serverKP.Address(), network.TestNetworkPassphrase, 2, signerSummary)
+	assert.EqualError(t, err, "transaction has unrecognized signatures")
+}
+
+func sortedStrings(s []string) []string {
+	sorted := append([]string{}, s...)
+	sort.Strings(sorted)
+	return sorted
+}